@@ -0,0 +1,311 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultAdaptiveInterval       = time.Second
+	DefaultAdaptiveRecoverRatio   = 0.8
+	DefaultAdaptiveRecoverSamples = 3
+)
+
+// adaptiveLevelKey marks the notification entries logged on level transitions
+const adaptiveLevelKey = "adaptive_level"
+
+// AdaptiveLevelConfig holds configuration for the adaptive level controller. A
+// zero threshold disables the corresponding signal.
+type AdaptiveLevelConfig struct {
+	ReducedLevel logrus.Level // level used while under pressure, defaults to info
+	// CPUThreshold is the process CPU usage, as a fraction of the available
+	// cores, above which verbosity is reduced
+	CPUThreshold float64
+	// CPUUsage samples the process CPU usage, defaults to a getrusage based
+	// sampler where available. It returns a negative value when unavailable.
+	CPUUsage func() float64
+	// QueueDepth reports the depth of an application or sink queue, checked
+	// against QueueThreshold
+	QueueDepth     func() int
+	QueueThreshold int
+	// WriteLatencyThreshold is the average output write latency over a sampling
+	// interval above which verbosity is reduced
+	WriteLatencyThreshold time.Duration
+	Interval              time.Duration // sampling interval, defaults to DefaultAdaptiveInterval
+	// RecoverRatio and RecoverSamples add hysteresis: the level is restored only
+	// once every signal has stayed below RecoverRatio times its threshold for
+	// RecoverSamples consecutive samples
+	RecoverRatio   float64
+	RecoverSamples int
+}
+
+// adaptiveLevelController lowers the verbosity of a logger under load and
+// restores it when the pressure subsides. WithAdaptiveLevel registers it like a
+// hook for the panic level so closing the logger stops it, but firing it does
+// nothing.
+type adaptiveLevelController struct {
+	cfg     AdaptiveLevelConfig
+	logger  *logrus.Logger
+	latency *latencyWriter
+
+	mu      sync.Mutex
+	reduced bool
+	normal  logrus.Level
+	calm    int
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// adaptiveSample is a single reading of the load signals
+type adaptiveSample struct {
+	cpu          float64
+	queueDepth   int
+	writeLatency time.Duration
+}
+
+// NewAdaptiveLevelController starts a controller sampling the load signals of l
+// every interval. Write latency is measured by wrapping the current output of l,
+// so outputs set afterwards are not measured.
+func NewAdaptiveLevelController(l *Logger, cfg *AdaptiveLevelConfig) (*adaptiveLevelController, error) {
+	c := AdaptiveLevelConfig{}
+	if cfg != nil {
+		c = *cfg
+	}
+	if c.CPUThreshold <= 0 && c.QueueThreshold <= 0 && c.WriteLatencyThreshold <= 0 {
+		return nil, fmt.Errorf("adaptive level: at least one threshold is required")
+	}
+	if c.QueueThreshold > 0 && c.QueueDepth == nil {
+		return nil, fmt.Errorf("adaptive level: queue threshold requires a QueueDepth func")
+	}
+	if c.ReducedLevel == logrus.PanicLevel {
+		c.ReducedLevel = logrus.InfoLevel
+	}
+	if c.CPUUsage == nil {
+		c.CPUUsage = newCPUSampler()
+	}
+	if c.Interval <= 0 {
+		c.Interval = DefaultAdaptiveInterval
+	}
+	if c.RecoverRatio <= 0 || c.RecoverRatio > 1 {
+		c.RecoverRatio = DefaultAdaptiveRecoverRatio
+	}
+	if c.RecoverSamples <= 0 {
+		c.RecoverSamples = DefaultAdaptiveRecoverSamples
+	}
+
+	ctrl := &adaptiveLevelController{
+		cfg:    c,
+		logger: l.Entry.Logger,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	if c.WriteLatencyThreshold > 0 {
+		ctrl.latency = &latencyWriter{w: l.Entry.Logger.Out}
+		l.Entry.Logger.SetOutput(ctrl.latency)
+	}
+	go ctrl.run()
+	return ctrl, nil
+}
+
+// WithAdaptiveLevel reduces the verbosity of the logger while CPU usage, queue
+// depth or write latency exceed the configured thresholds. The controller is
+// stopped when the logger is closed.
+func WithAdaptiveLevel(cfg *AdaptiveLevelConfig) Option {
+	return func(l *Logger) error {
+		ctrl, err := NewAdaptiveLevelController(l, cfg)
+		if err != nil {
+			return err
+		}
+		l.Entry.Logger.AddHook(ctrl)
+		return nil
+	}
+}
+
+// Reduced reports whether the controller has currently reduced the level
+func (c *adaptiveLevelController) Reduced() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.reduced
+}
+
+// Stop stops sampling and restores the original level if it was reduced
+func (c *adaptiveLevelController) Stop() {
+	c.stopSampling(true)
+}
+
+// Close stops the controller like Stop, without logging the restored level as
+// the sinks are being closed
+func (c *adaptiveLevelController) Close() error {
+	c.stopSampling(false)
+	return nil
+}
+
+// stopSampling stops the sampling goroutine and restores the original level,
+// logging the transition when notify is set
+func (c *adaptiveLevelController) stopSampling(notify bool) {
+	c.once.Do(func() {
+		close(c.stop)
+		<-c.done
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if !c.reduced {
+			return
+		}
+		if notify {
+			c.restore(adaptiveSample{}, "controller stopped")
+			return
+		}
+		c.reduced = false
+		c.logger.SetLevel(c.normal)
+	})
+}
+
+func (c *adaptiveLevelController) Levels() []logrus.Level {
+	return []logrus.Level{logrus.PanicLevel}
+}
+
+func (c *adaptiveLevelController) Fire(*logrus.Entry) error {
+	return nil
+}
+
+func (c *adaptiveLevelController) run() {
+	defer close(c.done)
+	ticker := time.NewTicker(c.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.evaluate(c.sample())
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// sample reads the current load signals
+func (c *adaptiveLevelController) sample() adaptiveSample {
+	var s adaptiveSample
+	if c.cfg.CPUThreshold > 0 {
+		s.cpu = c.cfg.CPUUsage()
+	}
+	if c.cfg.QueueThreshold > 0 {
+		s.queueDepth = c.cfg.QueueDepth()
+	}
+	if c.latency != nil {
+		s.writeLatency = c.latency.average()
+	}
+	return s
+}
+
+// evaluate applies a sample, reducing the level when any signal is over its
+// threshold and restoring it after enough calm samples
+func (c *adaptiveLevelController) evaluate(s adaptiveSample) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if reasons := c.pressure(s, 1); len(reasons) > 0 {
+		c.calm = 0
+		if !c.reduced {
+			c.reduce(s, strings.Join(reasons, ", "))
+		}
+		return
+	}
+	if !c.reduced {
+		return
+	}
+	if len(c.pressure(s, c.cfg.RecoverRatio)) > 0 {
+		c.calm = 0
+		return
+	}
+	c.calm++
+	if c.calm >= c.cfg.RecoverSamples {
+		c.restore(s, "pressure subsided")
+	}
+}
+
+// pressure returns the signals exceeding ratio times their threshold
+func (c *adaptiveLevelController) pressure(s adaptiveSample, ratio float64) []string {
+	var reasons []string
+	if c.cfg.CPUThreshold > 0 && s.cpu > c.cfg.CPUThreshold*ratio {
+		reasons = append(reasons, "cpu")
+	}
+	if c.cfg.QueueThreshold > 0 && float64(s.queueDepth) > float64(c.cfg.QueueThreshold)*ratio {
+		reasons = append(reasons, "queue_depth")
+	}
+	if c.cfg.WriteLatencyThreshold > 0 && float64(s.writeLatency) > float64(c.cfg.WriteLatencyThreshold)*ratio {
+		reasons = append(reasons, "write_latency")
+	}
+	return reasons
+}
+
+// reduce lowers the level, unless the logger is already less verbose than the
+// reduced level. It must be called with c.mu held.
+func (c *adaptiveLevelController) reduce(s adaptiveSample, reason string) {
+	current := c.logger.GetLevel()
+	if current <= c.cfg.ReducedLevel {
+		return
+	}
+	c.reduced = true
+	c.normal = current
+	c.calm = 0
+	// notify before switching so the entry isn't filtered by the reduced level
+	c.notify(logrus.WarnLevel, s, reason, current, c.cfg.ReducedLevel)
+	c.logger.SetLevel(c.cfg.ReducedLevel)
+}
+
+// restore sets the level back to the one in use before it was reduced. It must
+// be called with c.mu held.
+func (c *adaptiveLevelController) restore(s adaptiveSample, reason string) {
+	c.reduced = false
+	c.calm = 0
+	from := c.logger.GetLevel()
+	c.logger.SetLevel(c.normal)
+	c.notify(logrus.InfoLevel, s, reason, from, c.normal)
+}
+
+// notify logs a transition entry
+func (c *adaptiveLevelController) notify(level logrus.Level, s adaptiveSample, reason string, from, to logrus.Level) {
+	c.logger.WithFields(logrus.Fields{
+		adaptiveLevelKey: true,
+		"reason":         reason,
+		"from":           from.String(),
+		"to":             to.String(),
+		"cpu":            fmt.Sprintf("%.2f", s.cpu),
+		"queue_depth":    s.queueDepth,
+		"write_latency":  s.writeLatency.String(),
+	}).Log(level, fmt.Sprintf("log level changed from %s to %s", from, to))
+}
+
+// latencyWriter measures the average latency of the writes to w since the last
+// call to average
+type latencyWriter struct {
+	w     io.Writer
+	total atomic.Int64
+	count atomic.Int64
+}
+
+func (w *latencyWriter) Write(p []byte) (int, error) {
+	start := time.Now()
+	n, err := w.w.Write(p)
+	w.total.Add(int64(time.Since(start)))
+	w.count.Add(1)
+	return n, err
+}
+
+// average returns the average write latency and resets the measurements
+func (w *latencyWriter) average() time.Duration {
+	total := w.total.Swap(0)
+	count := w.count.Swap(0)
+	if count == 0 {
+		return 0
+	}
+	return time.Duration(total / count)
+}
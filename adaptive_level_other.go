@@ -0,0 +1,9 @@
+//go:build !unix
+
+package logger
+
+// newCPUSampler returns a sampler reporting CPU usage as unavailable on this
+// platform
+func newCPUSampler() func() float64 {
+	return func() float64 { return -1 }
+}
@@ -0,0 +1,119 @@
+package logger
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdaptiveLevel_ReduceAndRestore(t *testing.T) {
+	logger, err := NewLogger(WithNullOutput(), WithLevel("debug"), WithLastEntriesCapture(10))
+	require.NoError(t, err)
+
+	var depth atomic.Int64
+	ctrl, err := NewAdaptiveLevelController(logger, &AdaptiveLevelConfig{
+		QueueDepth:     func() int { return int(depth.Load()) },
+		QueueThreshold: 100,
+		Interval:       time.Hour,
+		RecoverSamples: 2,
+	})
+	require.NoError(t, err)
+	defer ctrl.Stop()
+
+	depth.Store(150)
+	ctrl.evaluate(ctrl.sample())
+	assert.True(t, ctrl.Reduced())
+	assert.Equal(t, logrus.InfoLevel, logger.Logger.GetLevel())
+
+	// below the threshold but inside the hysteresis band: stays reduced
+	depth.Store(90)
+	ctrl.evaluate(ctrl.sample())
+	ctrl.evaluate(ctrl.sample())
+	assert.True(t, ctrl.Reduced())
+
+	depth.Store(10)
+	ctrl.evaluate(ctrl.sample())
+	assert.True(t, ctrl.Reduced())
+	ctrl.evaluate(ctrl.sample())
+	assert.False(t, ctrl.Reduced())
+	assert.Equal(t, logrus.DebugLevel, logger.Logger.GetLevel())
+
+	crumbs := logger.Breadcrumbs(0)
+	require.Len(t, crumbs, 2)
+	assert.Equal(t, "log level changed from debug to info", crumbs[0].Message)
+	assert.Equal(t, "queue_depth", crumbs[0].Data["reason"])
+	assert.Equal(t, "log level changed from info to debug", crumbs[1].Message)
+}
+
+func TestAdaptiveLevel_CPUAndLatency(t *testing.T) {
+	logger, err := NewLogger(WithOutput(writerFunc(func(p []byte) (int, error) {
+		time.Sleep(5 * time.Millisecond)
+		return len(p), nil
+	})), WithLevel("debug"))
+	require.NoError(t, err)
+
+	cpu := 0.1
+	ctrl, err := NewAdaptiveLevelController(logger, &AdaptiveLevelConfig{
+		CPUThreshold:          0.9,
+		CPUUsage:              func() float64 { return cpu },
+		WriteLatencyThreshold: time.Millisecond,
+		Interval:              time.Hour,
+	})
+	require.NoError(t, err)
+
+	ctrl.evaluate(ctrl.sample())
+	assert.False(t, ctrl.Reduced())
+
+	logger.Debug("slow write")
+	ctrl.evaluate(ctrl.sample())
+	assert.True(t, ctrl.Reduced())
+
+	ctrl.Stop()
+	assert.False(t, ctrl.Reduced())
+	assert.Equal(t, logrus.DebugLevel, logger.Logger.GetLevel())
+
+	cpu = 0.95
+	s := ctrl.sample()
+	assert.Contains(t, ctrl.pressure(s, 1), "cpu")
+}
+
+func TestWithAdaptiveLevel_StoppedOnClose(t *testing.T) {
+	logger, err := NewLogger(WithNullOutput(), WithLevel("debug"), WithAdaptiveLevel(&AdaptiveLevelConfig{
+		QueueDepth:     func() int { return 500 },
+		QueueThreshold: 100,
+		Interval:       time.Hour,
+	}))
+	require.NoError(t, err)
+
+	ctrl, ok := findHook[*adaptiveLevelController](logger.Entry.Logger)
+	require.True(t, ok)
+	ctrl.evaluate(ctrl.sample())
+	require.True(t, ctrl.Reduced())
+
+	require.NoError(t, logger.Close())
+	select {
+	case <-ctrl.done:
+	default:
+		t.Fatal("sampling goroutine still running after Close")
+	}
+	assert.False(t, ctrl.Reduced())
+	assert.Equal(t, logrus.DebugLevel, logger.Logger.GetLevel())
+}
+
+func TestAdaptiveLevel_Validation(t *testing.T) {
+	logger, err := NewLogger(WithNullOutput())
+	require.NoError(t, err)
+
+	_, err = NewAdaptiveLevelController(logger, nil)
+	assert.Error(t, err)
+	_, err = NewAdaptiveLevelController(logger, &AdaptiveLevelConfig{QueueThreshold: 10})
+	assert.Error(t, err)
+}
+
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) { return f(p) }
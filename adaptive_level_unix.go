@@ -0,0 +1,41 @@
+//go:build unix
+
+package logger
+
+import (
+	"runtime"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// newCPUSampler returns a func reporting the process CPU usage since its
+// previous call, as a fraction of the available cores
+func newCPUSampler() func() float64 {
+	var (
+		mu       sync.Mutex
+		lastCPU  time.Duration
+		lastWall time.Time
+	)
+	return func() float64 {
+		var ru syscall.Rusage
+		if err := syscall.Getrusage(syscall.RUSAGE_SELF, &ru); err != nil {
+			return -1
+		}
+		cpu := time.Duration(ru.Utime.Nano() + ru.Stime.Nano())
+		now := time.Now()
+
+		mu.Lock()
+		defer mu.Unlock()
+		prevCPU, prevWall := lastCPU, lastWall
+		lastCPU, lastWall = cpu, now
+		if prevWall.IsZero() {
+			return 0
+		}
+		wall := now.Sub(prevWall)
+		if wall <= 0 {
+			return 0
+		}
+		return float64(cpu-prevCPU) / float64(wall) / float64(runtime.NumCPU())
+	}
+}
@@ -0,0 +1,94 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// ansiStripWriter removes ANSI escape sequences from the records written
+// through it. Each Write call is treated as one record, which is how logrus
+// writes entries.
+type ansiStripWriter struct {
+	w   io.Writer
+	buf []byte
+	mu  sync.Mutex
+}
+
+// NewANSIStripWriter returns a writer removing the ANSI escape sequences, such
+// as the colors forced by ColorFormatter, from the entries written to w, e.g.
+// to pass a file or pipe to WithOutput
+func NewANSIStripWriter(w io.Writer) io.Writer {
+	return &ansiStripWriter{w: w}
+}
+
+func (s *ansiStripWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.buf = appendStripped(s.buf[:0], p)
+	if _, err := s.w.Write(s.buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (s *ansiStripWriter) Flush() error {
+	if flush := outputFlusher(s.w); flush != nil {
+		return flush()
+	}
+	return nil
+}
+
+func (s *ansiStripWriter) Reopen() error {
+	return reopenWriter(s.w)
+}
+
+func (s *ansiStripWriter) Close() error {
+	return closeOutput(s.w)
+}
+
+// appendStripped appends p to dst without its ANSI escape sequences: CSI
+// sequences such as colors and cursor moves, OSC sequences such as hyperlinks
+// and titles, and two byte escapes. A sequence cut at the end of p is dropped.
+func appendStripped(dst, p []byte) []byte {
+	const esc, bel = 0x1b, 0x07
+	for i := 0; i < len(p); i++ {
+		if p[i] != esc {
+			dst = append(dst, p[i])
+			continue
+		}
+		if i+1 >= len(p) {
+			break
+		}
+		switch p[i+1] {
+		case '[':
+			// parameter and intermediate bytes up to a final byte in 0x40-0x7e
+			i += 2
+			for i < len(p) && (p[i] < 0x40 || p[i] > 0x7e) {
+				i++
+			}
+		case ']':
+			// up to BEL or ESC \
+			i += 2
+			for i < len(p) && p[i] != bel && !(p[i] == esc && i+1 < len(p) && p[i+1] == '\\') {
+				i++
+			}
+			if i < len(p) && p[i] == esc {
+				i++
+			}
+		default:
+			i++
+		}
+	}
+	return dst
+}
+
+// stripANSI returns line without its ANSI escape sequences, line itself when it
+// has none
+func stripANSI(line []byte) []byte {
+	if bytes.IndexByte(line, 0x1b) < 0 {
+		return line
+	}
+	return appendStripped(make([]byte, 0, len(line)), line)
+}
@@ -0,0 +1,61 @@
+package logger
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStripANSI(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "plain", in: "INFO plain\n", want: "INFO plain\n"},
+		{name: "colors", in: "\x1b[36mINFO\x1b[0m \x1b[1;31mred\x1b[0m\n", want: "INFO red\n"},
+		{name: "hyperlink", in: "\x1b]8;;https://example.com\x07link\x1b]8;;\x1b\\\n", want: "link\n"},
+		{name: "two byte escape", in: "\x1bcreset\n", want: "reset\n"},
+		{name: "cut sequence", in: "INFO\x1b[3", want: "INFO"},
+		{name: "trailing escape", in: "INFO\x1b", want: "INFO"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, string(stripANSI([]byte(tt.in))))
+		})
+	}
+}
+
+func TestNewANSIStripWriter(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := NewLogger(WithOutput(NewANSIStripWriter(&buf)), WithFormatter(&ColorFormatter{}))
+	require.NoError(t, err)
+
+	logger.Info("\x1b[1mpiped\x1b[0m")
+	assert.NotContains(t, buf.String(), "\x1b")
+	assert.Contains(t, buf.String(), "piped")
+}
+
+func TestStripANSI_FileDestinations(t *testing.T) {
+	dir := t.TempDir()
+	uriPath := filepath.Join(dir, "uri.log")
+	hookPath := filepath.Join(dir, "hook.log")
+	hook, err := newRotatingFileHook(&RotatingFileConfig{Filename: hookPath, Formatter: &ColorFormatter{}, StripANSI: true})
+	require.NoError(t, err)
+
+	logger, err := NewLogger(WithOutputURIs("file://"+uriPath+"?format=color&strip_ansi=true"), WithHooks(hook))
+	require.NoError(t, err)
+	logger.Info("\x1b[32mgreen\x1b[0m message")
+	require.NoError(t, logger.Close())
+
+	for _, path := range []string{uriPath, hookPath} {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "\x1b")
+		assert.Contains(t, string(data), "green message")
+	}
+}
@@ -0,0 +1,435 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultAsyncQueueSize is the number of entries queued by WithAsync when the
+// queue size isn't set
+const DefaultAsyncQueueSize = 10000
+
+// Block makes a full queue block the logging goroutine until there is room
+const Block DropPolicy = DropNewest + 1
+
+// asyncDispatcher moves hook firing, formatting and writing to a background
+// worker. The logger keeps its level check and the hooks capturing call site
+// state; its formatter queues a copy of every entry, which the worker delivers
+// through a shadow logger holding the real hooks, formatter and output.
+type asyncDispatcher struct {
+	policy DropPolicy
+	queue  chan *logrus.Entry
+	shadow *logrus.Logger
+	spill  *asyncSpill // set by WithAsyncSpill
+
+	// shadowMu guards the formatter and output of the shadow logger, replaced
+	// by setFormatter and setOutput while the worker delivers entries
+	shadowMu sync.RWMutex
+
+	// priority is the lane of the entries at priorityLevel or more severe, set
+	// by WithAsyncPriority
+	priority      chan *logrus.Entry
+	priorityLevel logrus.Level
+
+	// mu guards sending on the queue against closing it
+	mu      sync.RWMutex
+	closed  bool
+	pending sync.WaitGroup
+	done    chan struct{}
+	dropped atomic.Uint64
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// WithAsync delivers entries on a background worker with a queue of queueSize
+// entries (DefaultAsyncQueueSize when not positive), so slow hooks and outputs
+// never block the logging goroutine. policy selects what happens when the
+// queue is full. Fatal and panic entries are delivered before the call
+// returns, and closing the logger's sinks drains the queue. The hooks,
+// formatter and output configured when the logger is created are moved to the
+// worker; hooks added later run synchronously, while a formatter or output set
+// later, e.g. by SetLevel or SetOutput, is used by the worker.
+func WithAsync(queueSize int, policy DropPolicy) Option {
+	return func(l *Logger) error {
+		if policy != Block && policy != DropNewest && policy != DropOldest {
+			return fmt.Errorf("async: unknown drop policy %d", policy)
+		}
+		if queueSize <= 0 {
+			queueSize = DefaultAsyncQueueSize
+		}
+		if _, ok := findHook[*asyncDispatcher](l.Entry.Logger); ok {
+			return nil
+		}
+		l.Entry.Logger.AddHook(&asyncDispatcher{
+			policy: policy,
+			queue:  make(chan *logrus.Entry, queueSize),
+			done:   make(chan struct{}),
+		})
+		return nil
+	}
+}
+
+// WithAsyncPriority gives the entries at level or more severe, e.g. error, a
+// lane of size entries (DefaultAsyncQueueSize when not positive) delivered
+// ahead of the queue, so they reach the sinks first when the pipeline is
+// congested. Entries overflowing the lane join the queue. Entries of different
+// lanes are no longer delivered in order. Apply it after WithAsync.
+func WithAsyncPriority(level logrus.Level, size int) Option {
+	return func(l *Logger) error {
+		d, ok := findHook[*asyncDispatcher](l.Entry.Logger)
+		if !ok {
+			return errors.New("async priority: WithAsync is required")
+		}
+		if size <= 0 {
+			size = DefaultAsyncQueueSize
+		}
+		if d.priority == nil {
+			d.priority = make(chan *logrus.Entry, size)
+		}
+		d.priorityLevel = level
+		return nil
+	}
+}
+
+// startAsync moves the hooks, formatter and output of l to the async worker
+// when WithAsync was used. It runs once the options have been applied.
+func startAsync(l *logrus.Logger) {
+	d, ok := findHook[*asyncDispatcher](l)
+	if !ok || d.shadow != nil {
+		return
+	}
+
+	shadow := logrus.New()
+	shadow.Out = l.Out
+	shadow.Formatter = l.Formatter
+	shadow.Level = l.Level
+	shadow.ReportCaller = l.ReportCaller
+	shadow.ExitFunc = l.ExitFunc
+	shadow.Hooks = make(logrus.LevelHooks)
+
+	hooks := make(logrus.LevelHooks)
+	for level, levelHooks := range l.Hooks {
+		for _, hook := range levelHooks {
+			switch unwrapHook(hook).(type) {
+			case *asyncDispatcher, *fieldEncoderHook, *runtimeContextHook, *determinismHook, *contextExtractorHook, *statsHook, *fatalExit, *levelRegistry, *verbosityThreshold:
+				// these need the logging goroutine or the original entry, or hold
+				// configuration
+				hooks[level] = append(hooks[level], hook)
+			default:
+				shadow.Hooks[level] = append(shadow.Hooks[level], hook)
+			}
+		}
+	}
+	d.shadow = shadow
+	l.ReplaceHooks(hooks)
+	l.SetFormatter(&asyncFormatter{d: d})
+	l.SetOutput(io.Discard)
+
+	go d.run()
+}
+
+func (d *asyncDispatcher) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Enriches implements EnrichingHook, it hands the entry to the worker ahead of the sinks
+func (d *asyncDispatcher) Enriches() bool {
+	return true
+}
+
+// Fire does nothing, entries are queued by the formatter once every
+// synchronous hook has run
+func (d *asyncDispatcher) Fire(*logrus.Entry) error {
+	return nil
+}
+
+// Dropped returns the number of entries discarded because the queue was full
+func (d *asyncDispatcher) Dropped() uint64 {
+	return d.dropped.Load()
+}
+
+// Close delivers the queued entries, stops the worker and closes the hooks and
+// output it writes to
+func (d *asyncDispatcher) Close() error {
+	d.closeOnce.Do(func() {
+		d.mu.Lock()
+		d.closed = true
+		close(d.queue)
+		if d.priority != nil {
+			close(d.priority)
+		}
+		d.mu.Unlock()
+		if d.shadow == nil {
+			return
+		}
+		<-d.done
+
+		var errs []error
+		if d.spill != nil {
+			errs = append(errs, d.spill.close())
+		}
+		for _, closer := range sinkClosers(d.shadow) {
+			errs = append(errs, closer())
+		}
+		if flush := outputFlusher(d.shadow.Out); flush != nil {
+			errs = append(errs, flush())
+		}
+		d.closeErr = errors.Join(errs...)
+	})
+	return d.closeErr
+}
+
+// enqueue queues a copy of entry, applying the drop policy when the queue is
+// full. Fatal and panic entries are waited for.
+func (d *asyncDispatcher) enqueue(entry *logrus.Entry) {
+	e := cloneEntry(entry)
+	e.Logger = d.shadow
+
+	d.mu.RLock()
+	if d.closed {
+		d.mu.RUnlock()
+		// the worker is gone, deliver in place
+		d.deliver(e)
+		return
+	}
+	d.pending.Add(1)
+	d.send(e)
+	d.mu.RUnlock()
+
+	if entry.Level <= logrus.FatalLevel {
+		d.pending.Wait()
+	}
+}
+
+// send puts e on the queue according to the policy, or on the spill file once
+// the queue is full when there is one. Entries with a priority go to the
+// priority lane while it has room.
+func (d *asyncDispatcher) send(e *logrus.Entry) {
+	if d.priority != nil && e.Level <= d.priorityLevel {
+		select {
+		case d.priority <- e:
+			return
+		default:
+		}
+	}
+	if d.spill != nil {
+		d.sendOrSpill(e)
+		return
+	}
+	if d.policy == Block || e.Level <= logrus.FatalLevel {
+		d.queue <- e
+		return
+	}
+	for {
+		select {
+		case d.queue <- e:
+			return
+		default:
+		}
+		if d.policy == DropNewest {
+			d.drop()
+			return
+		}
+		select {
+		case <-d.queue:
+			d.drop()
+		default:
+		}
+	}
+}
+
+// sendOrSpill puts e on the queue, unless the queue is full or entries are
+// being spilled. Fatal and panic entries not fitting in the spill file wait for
+// room on the queue.
+func (d *asyncDispatcher) sendOrSpill(e *logrus.Entry) {
+	handled, dropped := d.spill.add(e, false)
+	if !handled {
+		select {
+		case d.queue <- e:
+			return
+		default:
+		}
+		_, dropped = d.spill.add(e, true)
+	}
+	if !dropped {
+		return
+	}
+	if e.Level <= logrus.FatalLevel {
+		d.queue <- e
+		return
+	}
+	d.drop()
+}
+
+func (d *asyncDispatcher) drop() {
+	d.dropped.Add(1)
+	d.pending.Done()
+}
+
+func (d *asyncDispatcher) run() {
+	defer close(d.done)
+	if d.spill == nil && d.priority == nil {
+		for e := range d.queue {
+			d.deliver(e)
+			d.pending.Done()
+		}
+		return
+	}
+
+	// priority entries go first; queued entries are older than spilled ones
+	priority := d.priority
+	var spilled chan struct{}
+	if d.spill != nil {
+		spilled = d.spill.notify
+	}
+	for {
+		select {
+		case e, ok := <-priority:
+			if ok {
+				d.received(e)
+			} else {
+				// closed, the rest is delivered with the queue
+				priority = nil
+			}
+			continue
+		default:
+		}
+		select {
+		case e, ok := <-d.queue:
+			if !ok {
+				d.drain()
+				return
+			}
+			d.received(e)
+			continue
+		default:
+		}
+		if d.spill != nil && d.deliverSpilled() {
+			continue
+		}
+		select {
+		case e, ok := <-priority:
+			if ok {
+				d.received(e)
+			} else {
+				priority = nil
+			}
+		case e, ok := <-d.queue:
+			if !ok {
+				d.drain()
+				return
+			}
+			d.received(e)
+		case <-spilled:
+		}
+	}
+}
+
+// received delivers an entry taken from a queue
+func (d *asyncDispatcher) received(e *logrus.Entry) {
+	d.deliver(e)
+	d.pending.Done()
+}
+
+// drain delivers the entries left once the queues are closed
+func (d *asyncDispatcher) drain() {
+	if d.priority != nil {
+		for e := range d.priority {
+			d.received(e)
+		}
+	}
+	if d.spill != nil {
+		for d.deliverSpilled() {
+		}
+	}
+}
+
+// deliverSpilled delivers the next batch of spilled entries and reports whether
+// there was any
+func (d *asyncDispatcher) deliverSpilled() bool {
+	entries := d.spill.next(d.shadow)
+	for _, e := range entries {
+		// entries that couldn't be read back are only accounted for
+		if e != nil {
+			d.deliver(e)
+		}
+		d.pending.Done()
+	}
+	return len(entries) > 0
+}
+
+// deliver fires the hooks and writes the formatted entry, reporting failures
+// on stderr like logrus does
+func (d *asyncDispatcher) deliver(e *logrus.Entry) {
+	for _, hook := range d.shadow.Hooks[e.Level] {
+		if err := hook.Fire(e); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to fire hook: %v\n", err)
+		}
+	}
+	d.shadowMu.RLock()
+	formatter, out := d.shadow.Formatter, d.shadow.Out
+	d.shadowMu.RUnlock()
+	line, err := formatter.Format(e)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to obtain reader, %v\n", err)
+		return
+	}
+	if _, err := out.Write(line); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write to log, %v\n", err)
+	}
+}
+
+// startedAsync returns the dispatcher of l once its worker delivers the
+// entries, the formatter and output of l being replaced by then
+func startedAsync(l *logrus.Logger) (*asyncDispatcher, bool) {
+	d, ok := findHook[*asyncDispatcher](l)
+	return d, ok && d.shadow != nil
+}
+
+// setOutput sets the output of l, or the output the async worker writes to
+// once it was started
+func setOutput(l *logrus.Logger, out io.Writer) {
+	d, ok := startedAsync(l)
+	if !ok {
+		l.SetOutput(out)
+		return
+	}
+	d.shadowMu.Lock()
+	defer d.shadowMu.Unlock()
+	d.shadow.Out = out
+}
+
+// cloneEntry returns a copy of entry with its own fields, which can outlive the
+// logging call
+func cloneEntry(entry *logrus.Entry) *logrus.Entry {
+	data := make(logrus.Fields, len(entry.Data))
+	for k, v := range entry.Data {
+		data[k] = v
+	}
+	return &logrus.Entry{
+		Logger:  entry.Logger,
+		Data:    data,
+		Time:    entry.Time,
+		Level:   entry.Level,
+		Caller:  entry.Caller,
+		Message: entry.Message,
+		Context: entry.Context,
+	}
+}
+
+// asyncFormatter queues entries on the dispatcher instead of formatting them
+type asyncFormatter struct {
+	d *asyncDispatcher
+}
+
+func (f *asyncFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	f.d.enqueue(entry)
+	return nil, nil
+}
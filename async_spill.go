@@ -0,0 +1,183 @@
+package logger
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultAsyncSpillMaxBytes  = 256 << 20 // 256MB
+	DefaultAsyncSpillBatchSize = 100
+)
+
+// AsyncSpillConfig holds configuration for spilling the async queue to disk
+type AsyncSpillConfig struct {
+	// Dir holds the spill file, a temporary directory removed on close when
+	// empty
+	Dir string
+	// MaxBytes bounds the size of the spill file, defaults to
+	// DefaultAsyncSpillMaxBytes. Entries overflowing it are dropped.
+	MaxBytes  int64
+	BatchSize int // entries read back per delivery round
+}
+
+// asyncSpill is the temporary disk segment taking the entries overflowing the
+// async queue. Once an entry is spilled, the following ones are spilled too
+// until the worker has read the file back, so entries keep their order. The
+// entries lose their context and caller on the way.
+type asyncSpill struct {
+	cfg     AsyncSpillConfig
+	path    string
+	tempDir string // removed on close
+
+	mu      sync.Mutex
+	f       *os.File
+	active  bool  // entries go to the file rather than the queue
+	readOff int64 // offset of the first entry not read back
+	size    int64
+	entries int // entries not read back
+
+	notify  chan struct{}
+	spilled atomic.Uint64
+}
+
+// WithAsyncSpill spills the entries overflowing the async queue to a temporary
+// file instead of applying the drop policy, and delivers them once the worker
+// catches up. Entries overflowing the disk budget are dropped and counted like
+// the entries dropped from the queue. Apply it after WithAsync.
+func WithAsyncSpill(cfg *AsyncSpillConfig) Option {
+	return func(l *Logger) error {
+		d, ok := findHook[*asyncDispatcher](l.Entry.Logger)
+		if !ok {
+			return errors.New("async spill: WithAsync is required")
+		}
+		if d.spill != nil {
+			return nil
+		}
+		spill, err := newAsyncSpill(cfg)
+		if err != nil {
+			return err
+		}
+		d.spill = spill
+		return nil
+	}
+}
+
+func newAsyncSpill(cfg *AsyncSpillConfig) (*asyncSpill, error) {
+	c := AsyncSpillConfig{}
+	if cfg != nil {
+		c = *cfg
+	}
+	if c.MaxBytes <= 0 {
+		c.MaxBytes = DefaultAsyncSpillMaxBytes
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = DefaultAsyncSpillBatchSize
+	}
+
+	s := &asyncSpill{cfg: c, notify: make(chan struct{}, 1)}
+	dir := c.Dir
+	if dir == "" {
+		tmp, err := os.MkdirTemp("", "logger-spill-")
+		if err != nil {
+			return nil, fmt.Errorf("async spill: %w", err)
+		}
+		dir, s.tempDir = tmp, tmp
+	} else if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("async spill: %w", err)
+	}
+	f, err := os.CreateTemp(dir, "async-*.spill")
+	if err != nil {
+		return nil, fmt.Errorf("async spill: %w", err)
+	}
+	s.f, s.path = f, f.Name()
+	return s, nil
+}
+
+// add spills e when the file is in use, or when force is set because the
+// queue is full. It reports whether e was handled, dropping it when it
+// doesn't fit in the budget.
+func (s *asyncSpill) add(e *logrus.Entry, force bool) (handled, dropped bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.active && !force {
+		return false, false
+	}
+	if isSuppressed(e) {
+		// suppression is carried by the context, which isn't spilled
+		return true, true
+	}
+	line, err := encodeSpoolRecord(e)
+	if err != nil || s.size+int64(len(line)) > s.cfg.MaxBytes {
+		return true, true
+	}
+	if _, err := s.f.WriteAt(line, s.size); err != nil {
+		return true, true
+	}
+	s.active = true
+	s.size += int64(len(line))
+	s.entries++
+	s.spilled.Add(1)
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+	return true, false
+}
+
+// next reads back the next batch of entries. The file is reset once read back
+// entirely, and the queue used again.
+func (s *asyncSpill) next(logger *logrus.Logger) []*logrus.Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.readOff >= s.size {
+		return nil
+	}
+
+	r := bufio.NewReader(io.NewSectionReader(s.f, s.readOff, s.size-s.readOff))
+	var entries []*logrus.Entry
+	for len(entries) < s.cfg.BatchSize {
+		line, err := r.ReadBytes('\n')
+		if len(line) == 0 || err != nil && err != io.EOF {
+			break
+		}
+		s.readOff += int64(len(line))
+		s.entries--
+		if entry, err := decodeSpoolRecord(line, logger); err == nil {
+			entries = append(entries, entry)
+		} else {
+			// still accounted for, so pending callers are released
+			entries = append(entries, nil)
+		}
+	}
+	if s.readOff >= s.size {
+		s.f.Truncate(0)
+		s.readOff, s.size, s.entries = 0, 0, 0
+		s.active = false
+	}
+	return entries
+}
+
+// Spilled returns the number of entries written to the spill file
+func (s *asyncSpill) Spilled() uint64 {
+	return s.spilled.Load()
+}
+
+// close removes the spill file
+func (s *asyncSpill) close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.f.Close()
+	os.Remove(s.path)
+	if s.tempDir != "" {
+		os.Remove(s.tempDir)
+	}
+	return err
+}
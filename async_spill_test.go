@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithAsyncSpill(t *testing.T) {
+	out := newGatedWriter()
+	dir := t.TempDir()
+	sink := &flakyHook{}
+	logger, d := newAsyncLogger(t, out, 2, DropNewest, WithHooks(sink), WithAsyncSpill(&AsyncSpillConfig{Dir: dir, BatchSize: 3}))
+
+	var want []string
+	for i := 0; i < 20; i++ {
+		logger.WithField("n", i).Info("entry")
+		want = append(want, fmt.Sprintf("level=info msg=entry n=%d", i))
+	}
+	assert.Zero(t, d.Dropped())
+	assert.Greater(t, d.spill.Spilled(), uint64(0))
+
+	close(out.gate)
+	require.NoError(t, d.Close())
+	assert.Equal(t, want, out.written(), "spilled entries keep their order")
+	assert.Len(t, sink.received(), 20)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.spill"))
+	require.NoError(t, err)
+	assert.Empty(t, files, "the spill file is removed on close")
+}
+
+func TestWithAsyncSpill_ResumesQueue(t *testing.T) {
+	out := newGatedWriter()
+	logger, d := newAsyncLogger(t, out, 1, DropNewest, WithAsyncSpill(nil))
+	tempDir := filepath.Dir(d.spill.path)
+
+	for i := 0; i < 5; i++ {
+		logger.Info("burst")
+	}
+	close(out.gate)
+	assert.Eventually(t, func() bool { return len(out.written()) == 5 }, time.Second, 5*time.Millisecond)
+
+	d.spill.mu.Lock()
+	active := d.spill.active
+	d.spill.mu.Unlock()
+	assert.False(t, active, "the queue is used again once the spill file is read back")
+
+	logger.Info("after")
+	require.NoError(t, d.Close())
+	assert.Len(t, out.written(), 6)
+	_, err := os.Stat(tempDir)
+	assert.True(t, os.IsNotExist(err), "the temporary directory is removed")
+}
+
+func TestWithAsyncSpill_Budget(t *testing.T) {
+	out := newGatedWriter()
+	logger, d := newAsyncLogger(t, out, 1, Block, WithAsyncSpill(&AsyncSpillConfig{Dir: t.TempDir(), MaxBytes: 300}))
+
+	for i := 0; i < 20; i++ {
+		logger.Info("overflow")
+	}
+	assert.Greater(t, d.Dropped(), uint64(0), "entries over the budget are dropped")
+
+	close(out.gate)
+	require.NoError(t, d.Close())
+	assert.Equal(t, 20, len(out.written())+int(d.Dropped()))
+}
+
+func TestWithAsyncSpill_RequiresAsync(t *testing.T) {
+	_, err := NewLogger(WithAsyncSpill(nil))
+	assert.Error(t, err)
+}
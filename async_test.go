@@ -0,0 +1,194 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gatedWriter blocks writes until opened and records them
+type gatedWriter struct {
+	gate  chan struct{}
+	mu    sync.Mutex
+	lines []string
+}
+
+func newGatedWriter() *gatedWriter {
+	return &gatedWriter{gate: make(chan struct{})}
+}
+
+func (w *gatedWriter) Write(p []byte) (int, error) {
+	<-w.gate
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.lines = append(w.lines, strings.TrimSpace(string(p)))
+	return len(p), nil
+}
+
+func (w *gatedWriter) written() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]string(nil), w.lines...)
+}
+
+func newAsyncLogger(t *testing.T, out *gatedWriter, queueSize int, policy DropPolicy, opts ...Option) (*Logger, *asyncDispatcher) {
+	t.Helper()
+	opts = append([]Option{
+		WithOutput(out),
+		WithFormatter(&logrus.TextFormatter{DisableTimestamp: true, DisableQuote: true}),
+		WithAsync(queueSize, policy),
+	}, opts...)
+	logger, err := NewLogger(opts...)
+	require.NoError(t, err)
+	d, ok := findHook[*asyncDispatcher](logger.Logger)
+	require.True(t, ok)
+	return logger, d
+}
+
+func TestWithAsync_DoesNotBlock(t *testing.T) {
+	out := newGatedWriter()
+	sink := &flakyHook{}
+	logger, d := newAsyncLogger(t, out, 10, Block, func(l *Logger) error {
+		l.Logger.AddHook(sink)
+		return nil
+	})
+	_, onWorker := findHook[*flakyHook](d.shadow)
+	assert.True(t, onWorker)
+
+	done := make(chan struct{})
+	go func() {
+		logger.Info("one")
+		logger.WithField("n", 2).Info("two")
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("logging blocked on a slow output")
+	}
+	assert.Empty(t, out.written())
+
+	close(out.gate)
+	require.NoError(t, d.Close())
+	assert.Equal(t, []string{"level=info msg=one", "level=info msg=two n=2"}, out.written())
+	assert.Equal(t, []string{"one", "two"}, sink.received())
+}
+
+func TestWithAsync_DropPolicies(t *testing.T) {
+	for _, policy := range []DropPolicy{DropNewest, DropOldest} {
+		t.Run(map[DropPolicy]string{DropOldest: "oldest", DropNewest: "newest"}[policy], func(t *testing.T) {
+			out := newGatedWriter()
+			logger, d := newAsyncLogger(t, out, 2, policy)
+			for i := 0; i < 10; i++ {
+				logger.Infof("entry %d", i)
+			}
+			close(out.gate)
+			require.NoError(t, d.Close())
+
+			written := out.written()
+			assert.GreaterOrEqual(t, d.Dropped(), uint64(7))
+			assert.Equal(t, 10, len(written)+int(d.Dropped()))
+			if policy == DropOldest {
+				assert.Equal(t, "level=info msg=entry 9", written[len(written)-1])
+			} else {
+				assert.Equal(t, "level=info msg=entry 0", written[0])
+			}
+		})
+	}
+}
+
+func TestWithAsync_FatalDrains(t *testing.T) {
+	var buf bytes.Buffer
+	var atExit string
+	logger, err := NewLogger(
+		WithOutput(&buf),
+		WithFormatter(&logrus.TextFormatter{DisableTimestamp: true}),
+		WithAsync(10, DropNewest),
+		func(l *Logger) error {
+			l.Logger.ExitFunc = func(int) { atExit = buf.String() }
+			return nil
+		},
+	)
+	require.NoError(t, err)
+
+	logger.Info("starting")
+	logger.Fatal("cannot continue")
+	assert.Contains(t, atExit, "msg=starting")
+	assert.Contains(t, atExit, `msg="cannot continue"`)
+}
+
+func TestWithAsync_SetLevel(t *testing.T) {
+	previous := Log
+	defer func() { Log = previous }()
+	out := newGatedWriter()
+	close(out.gate)
+	Log, _ = newAsyncLogger(t, out, 10, Block)
+
+	Info("one")
+	SetLevel("debug")
+	Debug("two")
+	require.NoError(t, Close())
+
+	written := out.written()
+	require.Len(t, written, 2)
+	// the formatter may have been replaced before the first entry was delivered
+	assert.Contains(t, written[0], "one")
+	assert.Contains(t, string(stripANSI([]byte(written[1]))), "two")
+}
+
+func TestWithAsync_UnknownPolicy(t *testing.T) {
+	_, err := NewLogger(WithAsync(10, DropPolicy(42)))
+	assert.EqualError(t, err, "async: unknown drop policy 42")
+}
+
+func TestWithAsyncPriority(t *testing.T) {
+	out := newGatedWriter()
+	debug := func(l *Logger) error {
+		l.Logger.SetLevel(logrus.DebugLevel)
+		return nil
+	}
+	logger, d := newAsyncLogger(t, out, 100, Block, debug, WithAsyncPriority(logrus.ErrorLevel, 10))
+
+	logger.Debug("d1")
+	// the worker is blocked writing d1
+	assert.Eventually(t, func() bool { return len(d.queue) == 0 }, time.Second, time.Millisecond)
+	logger.Debug("d2")
+	logger.Debug("d3")
+	logger.Error("e1")
+	logger.Debug("d4")
+
+	close(out.gate)
+	require.NoError(t, d.Close())
+	assert.Equal(t, []string{
+		"level=debug msg=d1",
+		"level=error msg=e1",
+		"level=debug msg=d2",
+		"level=debug msg=d3",
+		"level=debug msg=d4",
+	}, out.written())
+}
+
+func TestWithAsyncPriority_Overflow(t *testing.T) {
+	out := newGatedWriter()
+	logger, d := newAsyncLogger(t, out, 100, Block, WithAsyncPriority(logrus.WarnLevel, 1))
+
+	for i := 0; i < 5; i++ {
+		logger.Warn("w")
+	}
+	assert.Zero(t, d.Dropped(), "entries overflowing the lane join the queue")
+
+	close(out.gate)
+	require.NoError(t, d.Close())
+	assert.Len(t, out.written(), 5)
+}
+
+func TestWithAsyncPriority_RequiresAsync(t *testing.T) {
+	_, err := NewLogger(WithAsyncPriority(logrus.ErrorLevel, 0))
+	assert.Error(t, err)
+}
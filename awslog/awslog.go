@@ -0,0 +1,72 @@
+// Package awslog adapts go-logger to the logging interface of the AWS SDK for
+// Go v2, so SDK retry and request logs are structured like every other entry.
+package awslog
+
+import (
+	"context"
+
+	logger "github.com/alejoacosta74/go-logger"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go/logging"
+	"github.com/sirupsen/logrus"
+)
+
+// Field keys of the AWS call an entry was logged for
+const (
+	ServiceKey   = "aws_service"
+	OperationKey = "aws_operation"
+	RegionKey    = "aws_region"
+)
+
+// Logger implements logging.Logger and logging.ContextLogger
+type Logger struct {
+	logger *logger.Logger
+	ctx    context.Context
+}
+
+var (
+	_ logging.Logger        = (*Logger)(nil)
+	_ logging.ContextLogger = (*Logger)(nil)
+)
+
+// New returns an AWS SDK logger logging on l (the global Log when nil), for
+// aws.Config.Logger, along with aws.Config.ClientLogMode to select what the SDK
+// logs. Entries are logged at debug level, except warnings.
+func New(l *logger.Logger) *Logger {
+	return &Logger{logger: l}
+}
+
+// WithContext returns a logger adding the service, operation and region of the
+// AWS call ctx belongs to as fields. The SDK calls it for every operation.
+func (a *Logger) WithContext(ctx context.Context) logging.Logger {
+	return &Logger{logger: a.logger, ctx: ctx}
+}
+
+// Logf logs the message at warn level for the Warn classification and at debug
+// level otherwise
+func (a *Logger) Logf(classification logging.Classification, format string, v ...interface{}) {
+	l := a.logger
+	if l == nil {
+		l = logger.Log
+	}
+	entry := l.Entry
+	if a.ctx != nil {
+		fields := logger.Fields{}
+		if service := awsmiddleware.GetServiceID(a.ctx); service != "" {
+			fields[ServiceKey] = service
+		}
+		if operation := awsmiddleware.GetOperationName(a.ctx); operation != "" {
+			fields[OperationKey] = operation
+		}
+		if region := awsmiddleware.GetRegion(a.ctx); region != "" {
+			fields[RegionKey] = region
+		}
+		entry = entry.WithContext(a.ctx).WithFields(fields)
+	}
+
+	level := logrus.DebugLevel
+	if classification == logging.Warn {
+		level = logrus.WarnLevel
+	}
+	entry.Logf(level, format, v...)
+}
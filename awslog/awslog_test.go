@@ -0,0 +1,39 @@
+package awslog
+
+import (
+	"context"
+	"testing"
+
+	logger "github.com/alejoacosta74/go-logger"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go/logging"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger(t *testing.T) {
+	l, err := logger.NewLogger(logger.WithNullOutput(), logger.WithLevel("debug"), logger.WithLastEntriesCapture(10))
+	require.NoError(t, err)
+	sdkLogger := New(l)
+
+	metadata := &awsmiddleware.RegisterServiceMetadata{ServiceID: "S3", OperationName: "GetObject", Region: "eu-west-1"}
+	_, _, err = metadata.HandleInitialize(context.Background(), middleware.InitializeInput{},
+		middleware.InitializeHandlerFunc(func(ctx context.Context, in middleware.InitializeInput) (middleware.InitializeOutput, middleware.Metadata, error) {
+			logging.WithContext(ctx, sdkLogger).Logf(logging.Debug, "retrying request, attempt %d", 2)
+			return middleware.InitializeOutput{}, middleware.Metadata{}, nil
+		}))
+	require.NoError(t, err)
+	sdkLogger.Logf(logging.Warn, "endpoint resolution deprecated")
+
+	crumbs := l.Breadcrumbs(0)
+	require.Len(t, crumbs, 2)
+	assert.Equal(t, logrus.DebugLevel, crumbs[0].Level)
+	assert.Equal(t, "retrying request, attempt 2", crumbs[0].Message)
+	assert.Equal(t, "S3", crumbs[0].Data[ServiceKey])
+	assert.Equal(t, "GetObject", crumbs[0].Data[OperationKey])
+	assert.Equal(t, "eu-west-1", crumbs[0].Data[RegionKey])
+	assert.Equal(t, logrus.WarnLevel, crumbs[1].Level)
+	assert.NotContains(t, crumbs[1].Data, ServiceKey)
+}
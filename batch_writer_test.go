@@ -0,0 +1,129 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingWriter records the writes it receives
+type countingWriter struct {
+	mu     sync.Mutex
+	writes int
+	buf    bytes.Buffer
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writes++
+	return w.buf.Write(p)
+}
+
+func (w *countingWriter) stats() (int, string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.writes, w.buf.String()
+}
+
+func TestBatchWriter_FlushesBySize(t *testing.T) {
+	out := &countingWriter{}
+	w := NewBatchWriter(out, 3, time.Hour)
+	for _, line := range []string{"a\n", "b\n", "c\n", "d\n"} {
+		w.Write([]byte(line))
+	}
+	writes, content := out.stats()
+	assert.Equal(t, 1, writes)
+	assert.Equal(t, "a\nb\nc\n", content)
+
+	require.NoError(t, w.Close())
+	writes, content = out.stats()
+	assert.Equal(t, 2, writes)
+	assert.Equal(t, "a\nb\nc\nd\n", content)
+}
+
+func TestBatchWriter_FlushesByInterval(t *testing.T) {
+	out := &countingWriter{}
+	logger, err := NewLogger(WithOutput(out), WithBatchedOutput(100, 10*time.Millisecond))
+	require.NoError(t, err)
+	logger.Info("one")
+	logger.Info("two")
+
+	assert.Eventually(t, func() bool {
+		_, content := out.stats()
+		return bytes.Count([]byte(content), []byte("\n")) == 2
+	}, time.Second, 5*time.Millisecond)
+	writes, _ := out.stats()
+	assert.Equal(t, 1, writes)
+}
+
+func TestRotatingFileHook_Batching(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "batched.log")
+	hook, err := newRotatingFileHook(&RotatingFileConfig{Filename: filename, BatchSize: 100, FlushInterval: time.Hour, Checksum: true})
+	require.NoError(t, err)
+
+	logger, err := NewLogger(WithNullOutput())
+	require.NoError(t, err)
+	logger.Logger.AddHook(hook)
+	for i := 0; i < 10; i++ {
+		logger.Infof("entry %d", i)
+	}
+	_, err = os.Stat(filename)
+	assert.True(t, os.IsNotExist(err), "entries written before the batch is full")
+
+	require.NoError(t, hook.Close())
+	f, err := os.Open(filename)
+	require.NoError(t, err)
+	defer f.Close()
+	report, err := VerifyChecksums(f)
+	require.NoError(t, err)
+	assert.Equal(t, 10, report.Records)
+	assert.Empty(t, report.Corrupt)
+}
+
+func TestBufferedOutput(t *testing.T) {
+	out := &countingWriter{}
+	logger, err := NewLogger(WithOutput(out), WithFormatter(&PlainFormatter{}), WithBufferedOutput(64, time.Hour))
+	require.NoError(t, err)
+
+	logger.Info("first")
+	logger.Info("second")
+	writes, _ := out.stats()
+	assert.Zero(t, writes, "small writes are buffered")
+
+	logger.Error("failed")
+	writes, content := out.stats()
+	assert.Equal(t, 1, writes, "error entries flush the buffer")
+	assert.Equal(t, "INFO first\nINFO second\nERROR failed\n", content)
+
+	// 4 entries of 24 bytes overflow the 64 bytes buffer once
+	for i := 0; i < 4; i++ {
+		logger.Info("0123456789 padding")
+	}
+	writes, _ = out.stats()
+	assert.Equal(t, 2, writes)
+	require.NoError(t, logger.Shutdown(context.Background()))
+	writes, content = out.stats()
+	assert.Equal(t, 3, writes)
+	assert.Equal(t, 7, strings.Count(content, "\n"))
+}
+
+func TestBufferedOutput_Interval(t *testing.T) {
+	out := &countingWriter{}
+	logger, err := NewLogger(WithOutput(out), WithBufferedOutput(0, 10*time.Millisecond))
+	require.NoError(t, err)
+
+	logger.Info("eventually written")
+	assert.Eventually(t, func() bool {
+		_, content := out.stats()
+		return strings.Contains(content, "eventually written")
+	}, time.Second, 5*time.Millisecond)
+}
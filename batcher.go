@@ -0,0 +1,146 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Batcher accumulates items on a bounded queue and hands them to flush in
+// batches, by size or when the wait interval elapses. It is shared by the hooks
+// shipping entries to remote services, including those of the backend packages.
+type Batcher[T any] struct {
+	queue chan T
+	syncs chan chan struct{}
+	done  chan struct{}
+	size  int
+	wait  time.Duration
+	block bool
+	flush func([]T)
+
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+	dropped   atomic.Uint64
+
+	// closeMu guards queueing items against closing the batcher
+	closeMu sync.RWMutex
+	closed  bool
+}
+
+// NewBatcher starts a batcher. When block is false, items added while the queue
+// is full are dropped and counted.
+func NewBatcher[T any](size int, wait time.Duration, queueSize int, block bool, flush func([]T)) *Batcher[T] {
+	b := &Batcher[T]{
+		queue: make(chan T, queueSize),
+		syncs: make(chan chan struct{}),
+		done:  make(chan struct{}),
+		size:  size,
+		wait:  wait,
+		block: block,
+		flush: flush,
+	}
+	b.wg.Add(1)
+	go b.run()
+	return b
+}
+
+// Add queues an item, applying the backpressure policy when the queue is full.
+// Items added once the batcher is closed are dropped and counted.
+func (b *Batcher[T]) Add(item T) {
+	b.closeMu.RLock()
+	defer b.closeMu.RUnlock()
+	if b.closed {
+		b.dropped.Add(1)
+		return
+	}
+	if b.block {
+		b.queue <- item
+		return
+	}
+	select {
+	case b.queue <- item:
+	default:
+		b.dropped.Add(1)
+	}
+}
+
+// Dropped returns the number of items discarded because the queue was full or
+// the batcher was closed
+func (b *Batcher[T]) Dropped() uint64 {
+	return b.dropped.Load()
+}
+
+// Close flushes pending items and stops the worker
+func (b *Batcher[T]) Close() {
+	b.closeOnce.Do(func() {
+		b.closeMu.Lock()
+		b.closed = true
+		b.closeMu.Unlock()
+		close(b.done)
+	})
+	b.wg.Wait()
+}
+
+// Sync hands the items queued so far to flush, returning once they were
+// flushed or the batcher is closed
+func (b *Batcher[T]) Sync() {
+	synced := make(chan struct{})
+	select {
+	case b.syncs <- synced:
+		<-synced
+	case <-b.done:
+	}
+}
+
+// Closing returns a channel closed when Close has been called, so flush can
+// stop waiting, e.g. between delivery attempts
+func (b *Batcher[T]) Closing() <-chan struct{} {
+	return b.done
+}
+
+func (b *Batcher[T]) run() {
+	defer b.wg.Done()
+
+	ticker := time.NewTicker(b.wait)
+	defer ticker.Stop()
+
+	batch := make([]T, 0, b.size)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		b.flush(batch)
+		batch = make([]T, 0, b.size)
+	}
+	add := func(item T) {
+		batch = append(batch, item)
+		if len(batch) >= b.size {
+			flush()
+		}
+	}
+
+	for {
+		select {
+		case item := <-b.queue:
+			add(item)
+		case <-ticker.C:
+			flush()
+		case synced := <-b.syncs:
+			for pending := len(b.queue); pending > 0; pending-- {
+				add(<-b.queue)
+			}
+			flush()
+			close(synced)
+		case <-b.done:
+			for {
+				select {
+				case item := <-b.queue:
+					add(item)
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,436 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/sirupsen/logrus"
+)
+
+// The binary log format is a compact encoding for high-volume logging to disk. A
+// stream starts with a header (magic plus flags) followed by length-prefixed
+// records, compressed with zstd when the compression flag is set. Field keys
+// are dictionary-encoded: the first use of a key emits a definition record
+// assigning it an id and entries refer to keys by id afterwards.
+
+var binaryMagic = [4]byte{'G', 'L', 'B', '1'}
+
+// MaxBinaryRecordSize bounds the size of a record of a binary log. Larger
+// entries can't be written, and BinaryReader rejects larger length prefixes
+// rather than allocating for them.
+const MaxBinaryRecordSize = 16 << 20
+
+const (
+	binaryFlagZstd byte = 1 << 0
+
+	binaryRecordKey   byte = 0
+	binaryRecordEntry byte = 1
+
+	binaryValueString byte = 0
+	binaryValueInt    byte = 1
+	binaryValueFloat  byte = 2
+	binaryValueBool   byte = 3
+	binaryValueNil    byte = 4
+	binaryValueUint   byte = 5
+)
+
+// BinaryRecord is a decoded entry of a binary log
+type BinaryRecord struct {
+	Time    time.Time
+	Level   logrus.Level
+	Message string
+	Data    Fields
+}
+
+// BinaryWriter encodes entries in the binary log format
+type BinaryWriter struct {
+	w    io.Writer
+	zw   *zstd.Encoder
+	keys map[string]uint64
+	buf  []byte
+	mu   sync.Mutex
+}
+
+// NewBinaryWriter writes the stream header to w and returns a writer encoding
+// entries into it, zstd-compressed when compress is set
+func NewBinaryWriter(w io.Writer, compress bool) (*BinaryWriter, error) {
+	var flags byte
+	if compress {
+		flags |= binaryFlagZstd
+	}
+	header := append(binaryMagic[:], flags)
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	bw := &BinaryWriter{w: w, keys: make(map[string]uint64)}
+	if compress {
+		zw, err := zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.SpeedFastest))
+		if err != nil {
+			return nil, err
+		}
+		bw.zw = zw
+		bw.w = zw
+	}
+	return bw, nil
+}
+
+// WriteEntry encodes a single entry
+func (bw *BinaryWriter) WriteEntry(entry *logrus.Entry) error {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+
+	keys := make([]string, 0, len(entry.Data))
+	for k := range entry.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	// define keys seen for the first time
+	for _, k := range keys {
+		if _, ok := bw.keys[k]; ok {
+			continue
+		}
+		id := uint64(len(bw.keys))
+		bw.keys[k] = id
+		rec := []byte{binaryRecordKey}
+		rec = binary.AppendUvarint(rec, id)
+		rec = appendBinaryString(rec, k)
+		if err := bw.writeRecord(rec); err != nil {
+			return err
+		}
+	}
+
+	rec := append(bw.buf[:0], binaryRecordEntry)
+	rec = binary.AppendVarint(rec, entry.Time.UnixNano())
+	rec = append(rec, byte(entry.Level))
+	rec = appendBinaryString(rec, entry.Message)
+	rec = binary.AppendUvarint(rec, uint64(len(keys)))
+	for _, k := range keys {
+		rec = binary.AppendUvarint(rec, bw.keys[k])
+		rec = appendBinaryValue(rec, entry.Data[k])
+	}
+	bw.buf = rec
+	return bw.writeRecord(rec)
+}
+
+func (bw *BinaryWriter) writeRecord(rec []byte) error {
+	if len(rec) > MaxBinaryRecordSize {
+		return fmt.Errorf("binary log: record of %d bytes exceeds %d", len(rec), MaxBinaryRecordSize)
+	}
+	var length [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(length[:], uint64(len(rec)))
+	if _, err := bw.w.Write(length[:n]); err != nil {
+		return err
+	}
+	_, err := bw.w.Write(rec)
+	return err
+}
+
+// Flush writes buffered compressed data to the underlying writer
+func (bw *BinaryWriter) Flush() error {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+	if bw.zw != nil {
+		return bw.zw.Flush()
+	}
+	return nil
+}
+
+// Close flushes the stream. It does not close the underlying writer.
+func (bw *BinaryWriter) Close() error {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+	if bw.zw != nil {
+		return bw.zw.Close()
+	}
+	return nil
+}
+
+func appendBinaryString(b []byte, s string) []byte {
+	b = binary.AppendUvarint(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+func appendBinaryValue(b []byte, v interface{}) []byte {
+	switch val := v.(type) {
+	case nil:
+		return append(b, binaryValueNil)
+	case string:
+		return appendBinaryString(append(b, binaryValueString), val)
+	case bool:
+		if val {
+			return append(b, binaryValueBool, 1)
+		}
+		return append(b, binaryValueBool, 0)
+	case int:
+		return binary.AppendVarint(append(b, binaryValueInt), int64(val))
+	case int8:
+		return binary.AppendVarint(append(b, binaryValueInt), int64(val))
+	case int16:
+		return binary.AppendVarint(append(b, binaryValueInt), int64(val))
+	case int32:
+		return binary.AppendVarint(append(b, binaryValueInt), int64(val))
+	case int64:
+		return binary.AppendVarint(append(b, binaryValueInt), val)
+	case uint:
+		return binary.AppendUvarint(append(b, binaryValueUint), uint64(val))
+	case uint8:
+		return binary.AppendUvarint(append(b, binaryValueUint), uint64(val))
+	case uint16:
+		return binary.AppendUvarint(append(b, binaryValueUint), uint64(val))
+	case uint32:
+		return binary.AppendUvarint(append(b, binaryValueUint), uint64(val))
+	case uint64:
+		return binary.AppendUvarint(append(b, binaryValueUint), val)
+	case float32:
+		return binary.LittleEndian.AppendUint64(append(b, binaryValueFloat), math.Float64bits(float64(val)))
+	case float64:
+		return binary.LittleEndian.AppendUint64(append(b, binaryValueFloat), math.Float64bits(val))
+	case time.Duration:
+		return appendBinaryString(append(b, binaryValueString), val.String())
+	case error:
+		return appendBinaryString(append(b, binaryValueString), val.Error())
+	default:
+		return appendBinaryString(append(b, binaryValueString), fmt.Sprint(val))
+	}
+}
+
+// BinaryReader decodes a binary log stream
+type BinaryReader struct {
+	r    *bufio.Reader
+	zr   *zstd.Decoder
+	keys []string
+}
+
+// NewBinaryReader reads the stream header from r and returns a reader decoding
+// the records that follow
+func NewBinaryReader(r io.Reader) (*BinaryReader, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, fmt.Errorf("binary log: reading header: %w", err)
+	}
+	if [4]byte(header[:4]) != binaryMagic {
+		return nil, errors.New("binary log: invalid magic")
+	}
+
+	br := &BinaryReader{}
+	if header[4]&binaryFlagZstd != 0 {
+		zr, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		br.zr = zr
+		r = zr
+	}
+	br.r = bufio.NewReader(r)
+	return br, nil
+}
+
+// Next returns the next entry, or io.EOF at the end of the stream
+func (br *BinaryReader) Next() (*BinaryRecord, error) {
+	for {
+		length, err := binary.ReadUvarint(br.r)
+		if err != nil {
+			return nil, err
+		}
+		if length > MaxBinaryRecordSize {
+			return nil, fmt.Errorf("binary log: record of %d bytes exceeds %d", length, MaxBinaryRecordSize)
+		}
+		rec := make([]byte, length)
+		if _, err := io.ReadFull(br.r, rec); err != nil {
+			return nil, io.ErrUnexpectedEOF
+		}
+		if len(rec) == 0 {
+			return nil, errors.New("binary log: empty record")
+		}
+
+		d := &binaryDecoder{b: rec[1:]}
+		switch rec[0] {
+		case binaryRecordKey:
+			id := d.uvarint()
+			name := d.string()
+			if d.err != nil {
+				return nil, d.err
+			}
+			if id != uint64(len(br.keys)) {
+				return nil, fmt.Errorf("binary log: unexpected key id %d", id)
+			}
+			br.keys = append(br.keys, name)
+		case binaryRecordEntry:
+			return br.decodeEntry(d)
+		default:
+			return nil, fmt.Errorf("binary log: unknown record type %d", rec[0])
+		}
+	}
+}
+
+func (br *BinaryReader) decodeEntry(d *binaryDecoder) (*BinaryRecord, error) {
+	record := &BinaryRecord{}
+	record.Time = time.Unix(0, d.varint())
+	record.Level = logrus.Level(d.byte())
+	record.Message = d.string()
+
+	n := d.uvarint()
+	record.Data = make(Fields, n)
+	for i := uint64(0); i < n && d.err == nil; i++ {
+		id := d.uvarint()
+		if id >= uint64(len(br.keys)) {
+			return nil, fmt.Errorf("binary log: undefined key id %d", id)
+		}
+		record.Data[br.keys[id]] = d.value()
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	return record, nil
+}
+
+// Close releases the decompressor
+func (br *BinaryReader) Close() {
+	if br.zr != nil {
+		br.zr.Close()
+	}
+}
+
+// binaryDecoder reads primitive values from a record, keeping the first error
+type binaryDecoder struct {
+	b   []byte
+	err error
+}
+
+var errBinaryShort = errors.New("binary log: truncated record")
+
+func (d *binaryDecoder) byte() byte {
+	if d.err != nil || len(d.b) < 1 {
+		d.err = errBinaryShort
+		return 0
+	}
+	v := d.b[0]
+	d.b = d.b[1:]
+	return v
+}
+
+func (d *binaryDecoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.b)
+	if n <= 0 {
+		d.err = errBinaryShort
+		return 0
+	}
+	d.b = d.b[n:]
+	return v
+}
+
+func (d *binaryDecoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(d.b)
+	if n <= 0 {
+		d.err = errBinaryShort
+		return 0
+	}
+	d.b = d.b[n:]
+	return v
+}
+
+func (d *binaryDecoder) string() string {
+	n := d.uvarint()
+	if d.err != nil || uint64(len(d.b)) < n {
+		d.err = errBinaryShort
+		return ""
+	}
+	s := string(d.b[:n])
+	d.b = d.b[n:]
+	return s
+}
+
+func (d *binaryDecoder) value() interface{} {
+	switch d.byte() {
+	case binaryValueString:
+		return d.string()
+	case binaryValueInt:
+		return d.varint()
+	case binaryValueUint:
+		return d.uvarint()
+	case binaryValueFloat:
+		if d.err != nil || len(d.b) < 8 {
+			d.err = errBinaryShort
+			return nil
+		}
+		v := math.Float64frombits(binary.LittleEndian.Uint64(d.b))
+		d.b = d.b[8:]
+		return v
+	case binaryValueBool:
+		return d.byte() == 1
+	case binaryValueNil:
+		return nil
+	default:
+		if d.err == nil {
+			d.err = errors.New("binary log: unknown value type")
+		}
+		return nil
+	}
+}
+
+// binaryFileHook implements logrus.Hook writing entries to a binary log file
+type binaryFileHook struct {
+	file   *os.File
+	writer *BinaryWriter
+	levels []logrus.Level
+}
+
+// WithBinaryFileOutput adds a hook writing entries to path in the compact binary
+// format, zstd-compressed when compress is set. Use cmd/logview to decode it.
+func WithBinaryFileOutput(path string, compress bool, levels ...logrus.Level) Option {
+	return func(l *Logger) error {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+		if err != nil {
+			return err
+		}
+		w, err := NewBinaryWriter(f, compress)
+		if err != nil {
+			f.Close()
+			return err
+		}
+		if len(levels) == 0 {
+			levels = logrus.AllLevels
+		}
+		l.Entry.Logger.AddHook(&binaryFileHook{file: f, writer: w, levels: levels})
+		return nil
+	}
+}
+
+func (h *binaryFileHook) Levels() []logrus.Level {
+	return h.levels
+}
+
+func (h *binaryFileHook) Fire(entry *logrus.Entry) error {
+	return h.writer.WriteEntry(entry)
+}
+
+// Flush writes the compressed data buffered so far to the file
+func (h *binaryFileHook) Flush() error {
+	return h.writer.Flush()
+}
+
+// Close flushes the stream and closes the file
+func (h *binaryFileHook) Close() error {
+	err := h.writer.Close()
+	if cerr := h.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
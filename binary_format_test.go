@@ -0,0 +1,141 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBinaryFormat_RoundTrip(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		t.Run(map[bool]string{false: "raw", true: "zstd"}[compress], func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewBinaryWriter(&buf, compress)
+			require.NoError(t, err)
+
+			now := time.Now()
+			l := logrus.New()
+			entries := []*logrus.Entry{
+				l.WithFields(logrus.Fields{"user": "alice", "attempt": 3, "ok": true}).WithTime(now),
+				l.WithFields(logrus.Fields{"user": "bob", "latency": 1.5, "error": errors.New("timeout")}).WithTime(now.Add(time.Second)),
+				l.WithTime(now.Add(2 * time.Second)),
+			}
+			messages := []string{"login", "request failed", "no fields"}
+			levels := []logrus.Level{logrus.InfoLevel, logrus.ErrorLevel, logrus.TraceLevel}
+			for i, e := range entries {
+				e.Message = messages[i]
+				e.Level = levels[i]
+				require.NoError(t, w.WriteEntry(e))
+			}
+			require.NoError(t, w.Close())
+
+			r, err := NewBinaryReader(&buf)
+			require.NoError(t, err)
+			defer r.Close()
+
+			var records []*BinaryRecord
+			for {
+				rec, err := r.Next()
+				if err == io.EOF {
+					break
+				}
+				require.NoError(t, err)
+				records = append(records, rec)
+			}
+
+			require.Len(t, records, 3)
+			assert.Equal(t, "login", records[0].Message)
+			assert.Equal(t, logrus.InfoLevel, records[0].Level)
+			assert.Equal(t, now.UnixNano(), records[0].Time.UnixNano())
+			assert.Equal(t, Fields{"user": "alice", "attempt": int64(3), "ok": true}, records[0].Data)
+			assert.Equal(t, Fields{"user": "bob", "latency": 1.5, "error": "timeout"}, records[1].Data)
+			assert.Equal(t, logrus.TraceLevel, records[2].Level)
+			assert.Empty(t, records[2].Data)
+		})
+	}
+}
+
+func TestBinaryFormat_KeysAreDictionaryEncoded(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewBinaryWriter(&buf, false)
+	require.NoError(t, err)
+
+	entry := logrus.New().WithField("a_rather_long_field_name", 1)
+	require.NoError(t, w.WriteEntry(entry))
+	first := buf.Len()
+	require.NoError(t, w.WriteEntry(entry))
+
+	assert.Less(t, buf.Len()-first, first)
+	assert.Equal(t, 1, bytes.Count(buf.Bytes(), []byte("a_rather_long_field_name")))
+}
+
+func TestWithBinaryFileOutput(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "binary_file_output")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	path := filepath.Join(tmpDir, "trace.glb")
+	logger, err := NewLogger(WithNullOutput(), WithLevel("trace"), WithBinaryFileOutput(path, true))
+	require.NoError(t, err)
+
+	logger.WithField("step", 1).Trace("tracing")
+	hook, ok := findHook[*binaryFileHook](logger.Entry.Logger)
+	require.True(t, ok)
+	require.NoError(t, hook.Close())
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	r, err := NewBinaryReader(f)
+	require.NoError(t, err)
+	defer r.Close()
+	rec, err := r.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "tracing", rec.Message)
+	assert.Equal(t, int64(1), rec.Data["step"])
+}
+
+func TestNewBinaryReader_InvalidMagic(t *testing.T) {
+	_, err := NewBinaryReader(bytes.NewReader([]byte("hello world")))
+	assert.Error(t, err)
+}
+
+func TestWithBinaryFileOutput_Flush(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trace.glb")
+	logger, err := NewLogger(WithNullOutput(), WithBinaryFileOutput(path, true))
+	require.NoError(t, err)
+	defer logger.Close()
+
+	logger.Info("buffered")
+	require.NoError(t, flushHooks(logger.Entry.Logger, time.Second))
+
+	// the stream isn't closed, but the entry can be read already
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	r, err := NewBinaryReader(f)
+	require.NoError(t, err)
+	defer r.Close()
+	rec, err := r.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "buffered", rec.Message)
+}
+
+func TestBinaryReader_RecordTooLarge(t *testing.T) {
+	stream := append(binaryMagic[:], 0)
+	stream = binary.AppendUvarint(stream, 1<<40)
+	r, err := NewBinaryReader(bytes.NewReader(stream))
+	require.NoError(t, err)
+	_, err = r.Next()
+	assert.ErrorContains(t, err, "exceeds")
+}
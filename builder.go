@@ -0,0 +1,149 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LoggerBuilder configures a logger step by step, as an alternative to the
+// options of NewLogger, see Builder
+type LoggerBuilder struct {
+	opts []Option
+	errs []error
+}
+
+// Builder returns a builder configuring a logger with chained calls, e.g.
+//
+//	logger, err := logger.Builder().
+//		Level("debug").
+//		JSON().
+//		File("app.log", &logger.RotatingFileConfig{MaxSize: 50}).
+//		AddHook(hook).
+//		Build()
+//
+// Configuration errors don't stop the chain: Build reports all of them at once.
+func Builder() *LoggerBuilder {
+	return &LoggerBuilder{}
+}
+
+// Level sets the logging level, see WithLevel
+func (b *LoggerBuilder) Level(level string) *LoggerBuilder {
+	if _, err := logrus.ParseLevel(level); err != nil {
+		return b.fail(fmt.Errorf("level: %w", err))
+	}
+	return b.With(WithLevel(level))
+}
+
+// JSON renders the entries as JSON
+func (b *LoggerBuilder) JSON() *LoggerBuilder {
+	return b.Formatter(&logrus.JSONFormatter{})
+}
+
+// Text renders the entries as plain text, see PlainFormatter
+func (b *LoggerBuilder) Text() *LoggerBuilder {
+	return b.Formatter(&PlainFormatter{})
+}
+
+// Color renders the entries as colored text, see ColorFormatter
+func (b *LoggerBuilder) Color() *LoggerBuilder {
+	return b.Formatter(&ColorFormatter{})
+}
+
+// Formatter sets the formatter of the logger, see WithFormatter
+func (b *LoggerBuilder) Formatter(formatter logrus.Formatter) *LoggerBuilder {
+	if formatter == nil {
+		return b.fail(errors.New("formatter: nil formatter"))
+	}
+	return b.With(WithFormatter(formatter))
+}
+
+// Output sets the output destination of the logger, see WithOutput
+func (b *LoggerBuilder) Output(w io.Writer) *LoggerBuilder {
+	if w == nil {
+		return b.fail(errors.New("output: nil writer"))
+	}
+	return b.With(WithOutput(w))
+}
+
+// File also writes the entries to the file at path, rotated as configured by
+// rotation (the defaults of RotatingFileConfig when nil)
+func (b *LoggerBuilder) File(path string, rotation *RotatingFileConfig) *LoggerBuilder {
+	if path == "" {
+		return b.fail(errors.New("file: empty path"))
+	}
+	cfg := RotatingFileConfig{}
+	if rotation != nil {
+		cfg = *rotation
+	}
+	cfg.Filename = path
+	return b.With(func(l *Logger) error {
+		hook, err := newRotatingFileHook(&cfg)
+		if err != nil {
+			return fmt.Errorf("file %s: %w", path, err)
+		}
+		l.Entry.Logger.AddHook(hook)
+		return nil
+	})
+}
+
+// AddHook registers a hook on the logger, see WithHooks
+func (b *LoggerBuilder) AddHook(hook logrus.Hook) *LoggerBuilder {
+	if hook == nil {
+		return b.fail(errors.New("hook: nil hook"))
+	}
+	return b.With(WithHooks(hook))
+}
+
+// Field adds a field to every entry of the logger
+func (b *LoggerBuilder) Field(key string, value interface{}) *LoggerBuilder {
+	if key == "" {
+		return b.fail(errors.New("field: empty key"))
+	}
+	return b.With(func(l *Logger) error {
+		l.Entry = l.Entry.WithField(key, value)
+		return nil
+	})
+}
+
+// Named names the logger, see WithComponent
+func (b *LoggerBuilder) Named(name string) *LoggerBuilder {
+	return b.With(WithComponent(name))
+}
+
+// With applies options, in order with the other builder calls, for the
+// configuration without a dedicated builder method
+func (b *LoggerBuilder) With(opts ...Option) *LoggerBuilder {
+	b.opts = append(b.opts, opts...)
+	return b
+}
+
+func (b *LoggerBuilder) fail(err error) *LoggerBuilder {
+	b.errs = append(b.errs, err)
+	return b
+}
+
+// Build creates the logger and makes it the global logger, as NewLogger does.
+// It applies every option even when some fail and returns all the
+// configuration errors joined, in which case the global logger is left as is.
+func (b *LoggerBuilder) Build() (*Logger, error) {
+	errs := append([]error(nil), b.errs...)
+	opts := make([]Option, len(b.opts))
+	for i, opt := range b.opts {
+		opt := opt
+		opts[i] = func(l *Logger) error {
+			if err := opt(l); err != nil {
+				errs = append(errs, err)
+			}
+			return nil
+		}
+	}
+	logger, err := createNewLogger(opts...)
+	if err = errors.Join(append(errs, err)...); err != nil {
+		return nil, err
+	}
+	Log = logger
+	return logger, nil
+}
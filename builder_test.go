@@ -0,0 +1,63 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder(t *testing.T) {
+	var buf bytes.Buffer
+	hook := new(test.Hook)
+	path := filepath.Join(t.TempDir(), "app.log")
+	l, err := Builder().
+		Level("warn").
+		JSON().
+		Output(&buf).
+		File(path, &RotatingFileConfig{MaxSize: 1}).
+		AddHook(hook).
+		Field("service", "billing").
+		Named("api").
+		Build()
+	require.NoError(t, err)
+	assert.Same(t, Log, l)
+	assert.Equal(t, logrus.WarnLevel, l.Entry.Logger.GetLevel())
+
+	l.Info("hidden")
+	l.Warn("slow charge")
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "slow charge", entry["msg"])
+	assert.Equal(t, "billing", entry["service"])
+	assert.Equal(t, "api", entry[ComponentKey])
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "slow charge")
+	require.NotNil(t, hook.LastEntry())
+	assert.Equal(t, "slow charge", hook.LastEntry().Message)
+	require.NoError(t, l.Close())
+}
+
+func TestBuilderErrors(t *testing.T) {
+	previous := Log
+	_, err := Builder().
+		Level("loud").
+		Output(nil).
+		File("", nil).
+		With(WithFieldsFromStruct(42)).
+		Text().
+		Build()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "level: ")
+	assert.Contains(t, err.Error(), "output: nil writer")
+	assert.Contains(t, err.Error(), "file: empty path")
+	assert.Contains(t, err.Error(), "struct")
+	assert.Same(t, previous, Log)
+}
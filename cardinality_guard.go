@@ -0,0 +1,167 @@
+package logger
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultCardinalityMaxValues   = 1000
+	DefaultCardinalityHashBuckets = 64
+)
+
+// cardinalityGuardKey marks the warning entries logged by the guard
+const cardinalityGuardKey = "cardinality_guard"
+
+// CardinalityAction is what the cardinality guard does with values of a field
+// once it exceeds its cardinality limit
+type CardinalityAction int
+
+const (
+	// CardinalityWarn logs a warning the first time a field exceeds the limit and
+	// leaves values untouched
+	CardinalityWarn CardinalityAction = iota
+	// CardinalityHash replaces unseen values with one of HashBuckets stable
+	// hash buckets, bounding the cardinality of the field
+	CardinalityHash
+	// CardinalityDrop removes the field from entries with unseen values
+	CardinalityDrop
+)
+
+// CardinalityConfig holds configuration for the cardinality guard
+type CardinalityConfig struct {
+	// Fields lists the field keys to guard, all fields are guarded when empty
+	Fields    []string
+	MaxValues int               // distinct values allowed per field, defaults to DefaultCardinalityMaxValues
+	Limits    map[string]int    // per-field overrides of MaxValues
+	Action    CardinalityAction // applied once a field exceeds its limit, a warning is always logged
+	// HashBuckets is the number of buckets used by CardinalityHash, defaults to
+	// DefaultCardinalityHashBuckets
+	HashBuckets int
+}
+
+// cardinalityGuard implements Rule tracking the distinct values logged under
+// each field key, protecting label based backends such as Loki from
+// cardinality blowups
+type cardinalityGuard struct {
+	cfg    CardinalityConfig
+	fields map[string]bool
+	mu     sync.Mutex
+	values map[string]map[string]struct{}
+	// exceeded holds the fields over their limit
+	exceeded map[string]bool
+}
+
+// NewCardinalityGuard creates a rule tracking field cardinality. It never
+// suppresses entries but rewrites values according to the configured action.
+func NewCardinalityGuard(cfg *CardinalityConfig) *cardinalityGuard {
+	c := CardinalityConfig{}
+	if cfg != nil {
+		c = *cfg
+	}
+	if c.MaxValues <= 0 {
+		c.MaxValues = DefaultCardinalityMaxValues
+	}
+	if c.HashBuckets <= 0 {
+		c.HashBuckets = DefaultCardinalityHashBuckets
+	}
+	g := &cardinalityGuard{
+		cfg:      c,
+		values:   make(map[string]map[string]struct{}),
+		exceeded: make(map[string]bool),
+	}
+	if len(c.Fields) > 0 {
+		g.fields = make(map[string]bool, len(c.Fields))
+		for _, f := range c.Fields {
+			g.fields[f] = true
+		}
+	}
+	return g
+}
+
+// WithCardinalityGuard warns about, hashes or drops fields whose number of
+// distinct values exceeds a limit
+func WithCardinalityGuard(cfg *CardinalityConfig) Option {
+	return WithRules(NewCardinalityGuard(cfg))
+}
+
+// Cardinality returns the number of distinct values tracked for key. Tracking
+// stops once the field exceeds its limit.
+func (g *cardinalityGuard) Cardinality(key string) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.values[key])
+}
+
+// Exceeded reports whether key has exceeded its cardinality limit
+func (g *cardinalityGuard) Exceeded(key string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.exceeded[key]
+}
+
+// Apply tracks the entry's field values, never suppressing the entry
+func (g *cardinalityGuard) Apply(entry *logrus.Entry) bool {
+	if _, ok := entry.Data[cardinalityGuardKey]; ok {
+		return false
+	}
+
+	var warn []string
+	g.mu.Lock()
+	for key, v := range entry.Data {
+		if g.fields != nil && !g.fields[key] {
+			continue
+		}
+		value := fmt.Sprint(v)
+		seen, ok := g.values[key]
+		if !ok {
+			seen = make(map[string]struct{})
+			g.values[key] = seen
+		}
+		if _, ok := seen[value]; ok {
+			continue
+		}
+		if len(seen) < g.limit(key) {
+			seen[value] = struct{}{}
+			continue
+		}
+		if !g.exceeded[key] {
+			g.exceeded[key] = true
+			warn = append(warn, key)
+		}
+		switch g.cfg.Action {
+		case CardinalityHash:
+			entry.Data[key] = g.bucket(value)
+		case CardinalityDrop:
+			delete(entry.Data, key)
+		}
+	}
+	g.mu.Unlock()
+
+	for _, key := range warn {
+		entry.Logger.WithFields(logrus.Fields{
+			cardinalityGuardKey: true,
+			"field":             key,
+			"limit":             g.limit(key),
+		}).Warnf("field %q exceeded %d distinct values", key, g.limit(key))
+	}
+	return false
+}
+
+// limit returns the cardinality limit of key
+func (g *cardinalityGuard) limit(key string) int {
+	if n, ok := g.cfg.Limits[key]; ok && n > 0 {
+		return n
+	}
+	return g.cfg.MaxValues
+}
+
+// bucket maps value to a stable hash bucket label
+func (g *cardinalityGuard) bucket(value string) string {
+	h := fnv.New32a()
+	h.Write([]byte(value))
+	return fmt.Sprintf("hash:%x", h.Sum32()%uint32(g.cfg.HashBuckets))
+}
@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCardinalityGuard_Warn(t *testing.T) {
+	logger, err := NewLogger(WithNullOutput(), WithLastEntriesCapture(20),
+		WithCardinalityGuard(&CardinalityConfig{Fields: []string{"label"}, MaxValues: 3}))
+	require.NoError(t, err)
+
+	for i := 0; i < 6; i++ {
+		logger.WithFields(Fields{"label": fmt.Sprintf("id-%d", i), "other": i}).Info("request")
+	}
+
+	var warnings, requests int
+	for _, crumb := range logger.Breadcrumbs(0) {
+		if crumb.Data[cardinalityGuardKey] == true {
+			warnings++
+			assert.Equal(t, "label", crumb.Data["field"])
+			continue
+		}
+		requests++
+		assert.True(t, strings.HasPrefix(crumb.Data["label"].(string), "id-"))
+	}
+	assert.Equal(t, 1, warnings)
+	assert.Equal(t, 6, requests)
+}
+
+func TestCardinalityGuard_HashAndDrop(t *testing.T) {
+	guard := NewCardinalityGuard(&CardinalityConfig{
+		MaxValues:   2,
+		Action:      CardinalityHash,
+		HashBuckets: 4,
+	})
+	logger, err := NewLogger(WithNullOutput(), WithLastEntriesCapture(20), WithRules(guard))
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		logger.WithField("label", fmt.Sprintf("v%d", i)).Info("hashed")
+	}
+	assert.True(t, guard.Exceeded("label"))
+	assert.Equal(t, 2, guard.Cardinality("label"))
+
+	labels := map[interface{}]bool{}
+	for _, crumb := range logger.Breadcrumbs(0) {
+		if crumb.Message == "hashed" {
+			labels[crumb.Data["label"]] = true
+		}
+	}
+	// two raw values plus at most four buckets
+	assert.LessOrEqual(t, len(labels), 6)
+	assert.True(t, labels["v0"])
+	assert.False(t, labels["v9"])
+
+	// values seen before the limit was hit are kept as is
+	logger.WithField("label", "v1").Info("seen")
+	crumbs := logger.Breadcrumbs(1)
+	assert.Equal(t, "v1", crumbs[0].Data["label"])
+
+	drop := NewCardinalityGuard(&CardinalityConfig{MaxValues: 1, Action: CardinalityDrop})
+	logger, err = NewLogger(WithNullOutput(), WithLastEntriesCapture(20), WithRules(drop))
+	require.NoError(t, err)
+	logger.WithField("user", "a").Info("one")
+	logger.WithField("user", "b").Info("two")
+	crumbs = logger.Breadcrumbs(1)
+	assert.Equal(t, "two", crumbs[0].Message)
+	assert.NotContains(t, crumbs[0].Data, "user")
+}
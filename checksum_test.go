@@ -0,0 +1,66 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecksumWriter(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := NewLogger(WithOutput(&buf), WithFormatter(&ColorFormatter{}), WithChecksums())
+	require.NoError(t, err)
+
+	logger.Info("first")
+	logger.Error("multi\nline")
+	logger.Warn("third")
+
+	report, err := VerifyChecksums(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	assert.True(t, report.Valid())
+	assert.Equal(t, 3, report.Records)
+
+	// flip a byte in the second record
+	corrupted := strings.Replace(buf.String(), "multi", "mult1", 1)
+	report, err = VerifyChecksums(strings.NewReader(corrupted))
+	require.NoError(t, err)
+	assert.False(t, report.Valid())
+	assert.Equal(t, []int{3}, report.Corrupt)
+	assert.Equal(t, 2, report.Records)
+
+	// torn final write
+	torn := buf.String()[:buf.Len()-5]
+	report, err = VerifyChecksums(strings.NewReader(torn))
+	require.NoError(t, err)
+	assert.True(t, report.Truncated)
+}
+
+func TestRotatingFileHook_Checksum(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "rotating_file_hook_checksum")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	filename := filepath.Join(tmpDir, "checksum.log")
+	hook, err := newRotatingFileHook(&RotatingFileConfig{Filename: filename, Checksum: true})
+	require.NoError(t, err)
+
+	logger := logrus.New()
+	logger.AddHook(hook)
+	logger.SetOutput(io.Discard)
+	for i := 0; i < 5; i++ {
+		logger.WithField("n", i).Info("entry")
+	}
+	require.NoError(t, hook.Close())
+
+	report, err := VerifyLogFile(filename)
+	require.NoError(t, err)
+	assert.True(t, report.Valid())
+	assert.Equal(t, 5, report.Records)
+}
@@ -0,0 +1,19 @@
+// Package chilog adds the route patterns matched by chi routers to go-logger
+// access entries.
+package chilog
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// RoutePattern returns the chi route pattern matched by r (e.g. /users/{id}),
+// for logger.HTTPMiddlewareOptions.RoutePattern. The middleware must be
+// registered on the chi router with Use so the pattern is known.
+func RoutePattern(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		return rctx.RoutePattern()
+	}
+	return ""
+}
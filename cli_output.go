@@ -0,0 +1,100 @@
+package logger
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// cliEventKey carries the event name of entries logged with Emit
+const cliEventKey = "cli_event"
+
+// cliEventRule implements Rule routing events logged with Emit to stdout as JSON
+// lines, and suppressing them from the human readable output
+type cliEventRule struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// WithCLIMode configures the logger for command line tools: human readable
+// entries go to stderr and events logged with Emit or Result go to stdout as
+// JSON lines, so scripts can safely pipe stdout
+func WithCLIMode() Option {
+	return WithCLIStreams(os.Stdout, os.Stderr)
+}
+
+// WithCLIStreams is WithCLIMode with explicit machine (stdout) and human
+// (stderr) writers
+func WithCLIStreams(stdout, stderr io.Writer) Option {
+	return func(l *Logger) error {
+		l.Entry.Logger.SetOutput(stderr)
+		setFormatter(l.Entry.Logger, &PlainFormatter{})
+		return WithRules(&cliEventRule{out: stdout})(l)
+	}
+}
+
+// Emit logs a machine readable event. In CLI mode it is written to stdout as a
+// JSON object holding the logger fields, fields and the event name, whatever
+// the logger level; otherwise it is logged at info level like any other entry.
+func (l *Logger) Emit(event string, fields Fields) {
+	entry := l.Entry.WithFields(fields).WithField(cliEventKey, event)
+	if rule, ok := findCLIEventRule(l.Entry.Logger); ok {
+		entry.Time = time.Now()
+		rule.Apply(entry)
+		return
+	}
+	entry.Info(event)
+}
+
+// Result emits the final "result" event of a command
+func (l *Logger) Result(fields Fields) {
+	l.Emit("result", fields)
+}
+
+// findCLIEventRule returns the CLI event rule registered on l
+func findCLIEventRule(l *logrus.Logger) (*cliEventRule, bool) {
+	hook, ok := findHook[*rulesHook](l)
+	if !ok {
+		return nil, false
+	}
+	hook.mu.RLock()
+	defer hook.mu.RUnlock()
+	for _, rule := range hook.rules {
+		if r, ok := rule.(*cliEventRule); ok {
+			return r, true
+		}
+	}
+	return nil, false
+}
+
+// Apply writes events to stdout and suppresses them from every other sink
+func (r *cliEventRule) Apply(entry *logrus.Entry) bool {
+	event, ok := entry.Data[cliEventKey]
+	if !ok {
+		return false
+	}
+
+	record := make(map[string]interface{}, len(entry.Data)+1)
+	for k, v := range entry.Data {
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+		record[k] = v
+	}
+	delete(record, cliEventKey)
+	record["event"] = event
+	record["time"] = entry.Time.Format(time.RFC3339Nano)
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return false
+	}
+	r.mu.Lock()
+	r.out.Write(append(line, '\n'))
+	r.mu.Unlock()
+	return true
+}
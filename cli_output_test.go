@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCLIStreams(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	logger, err := NewLogger(WithCLIStreams(&stdout, &stderr))
+	require.NoError(t, err)
+
+	cmd := &Logger{Entry: logger.WithField("command", "sync")}
+	cmd.Info("syncing 3 files")
+	cmd.Emit("file_synced", Fields{"path": "a.txt", "bytes": 12})
+	cmd.Warn("skipped b.txt")
+	cmd.Result(Fields{"synced": 2, "skipped": 1})
+
+	assert.Equal(t, "INFO syncing 3 files command=sync\nWARNING skipped b.txt command=sync\n", stderr.String())
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var event, result map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &event))
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &result))
+	assert.Equal(t, "file_synced", event["event"])
+	assert.Equal(t, "a.txt", event["path"])
+	assert.Equal(t, "sync", event["command"])
+	assert.NotContains(t, event, cliEventKey)
+	assert.Equal(t, "result", result["event"])
+	assert.Equal(t, float64(2), result["synced"])
+}
+
+func TestEmit_WithoutCLIMode(t *testing.T) {
+	logger, err := NewLogger(WithNullOutput(), WithLastEntriesCapture(5))
+	require.NoError(t, err)
+
+	logger.Emit("done", Fields{"count": 1})
+	crumbs := logger.Breadcrumbs(0)
+	require.Len(t, crumbs, 1)
+	assert.Equal(t, "done", crumbs[0].Message)
+}
+
+func TestCLIStreams_ResultIgnoresLevel(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	logger, err := NewLogger(WithCLIStreams(&stdout, &stderr), WithLevel("error"))
+	require.NoError(t, err)
+
+	logger.Info("quiet")
+	logger.Result(Fields{"ok": true})
+	assert.Empty(t, stderr.String())
+	assert.Contains(t, stdout.String(), `"event":"result"`)
+}
@@ -0,0 +1,98 @@
+// Command logview decodes logs written in the binary log format back to text or
+// JSON.
+//
+// Usage:
+//
+//	logview [-format text|json] [file ...]
+//
+// Files are read from standard input when none are given.
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	logger "github.com/alejoacosta74/go-logger"
+	"github.com/sirupsen/logrus"
+)
+
+func main() {
+	format := flag.String("format", "text", "output format: text or json")
+	flag.Parse()
+
+	var formatter logrus.Formatter
+	switch *format {
+	case "text":
+		formatter = &logrus.TextFormatter{DisableColors: true, FullTimestamp: true}
+	case "json":
+		formatter = &logrus.JSONFormatter{}
+	default:
+		fmt.Fprintf(os.Stderr, "logview: unknown format %q\n", *format)
+		os.Exit(2)
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+
+	if flag.NArg() == 0 {
+		if err := decode(os.Stdin, out, formatter); err != nil {
+			fail(out, err)
+		}
+		return
+	}
+	for _, path := range flag.Args() {
+		f, err := os.Open(path)
+		if err != nil {
+			fail(out, err)
+		}
+		err = decode(f, out, formatter)
+		f.Close()
+		if err != nil {
+			fail(out, fmt.Errorf("%s: %w", path, err))
+		}
+	}
+}
+
+// decode writes every record of a binary log to w using formatter
+func decode(r io.Reader, w io.Writer, formatter logrus.Formatter) error {
+	reader, err := logger.NewBinaryReader(r)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	l := logrus.New()
+	for {
+		record, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		entry := logrus.NewEntry(l)
+		entry.Time = record.Time
+		entry.Level = record.Level
+		entry.Message = record.Message
+		entry.Data = record.Data
+
+		line, err := formatter.Format(entry)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(line); err != nil {
+			return err
+		}
+	}
+}
+
+func fail(out *bufio.Writer, err error) {
+	out.Flush()
+	fmt.Fprintf(os.Stderr, "logview: %v\n", err)
+	os.Exit(1)
+}
@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+)
+
+// CohortKey is the field holding the cohort of a logger, see WithCohort
+const CohortKey = "cohort"
+
+// CohortOf returns the stable cohort bucket (0-99) of seed
+func CohortOf(seed string) int {
+	h := fnv.New32a()
+	h.Write([]byte(seed))
+	return int(h.Sum32() % 100)
+}
+
+// WithCohort places the logger in the cohort bucket (0-99) derived from seed,
+// such as a pod or deployment name, and logs it on every entry under CohortKey.
+// The hostname is used when seed is empty. The same seed always lands in the
+// same bucket, so a log line can be traced back to the configuration of its
+// cohort.
+func WithCohort(seed string) Option {
+	return func(l *Logger) error {
+		if seed == "" {
+			host, err := os.Hostname()
+			if err != nil {
+				return fmt.Errorf("cohort: %w", err)
+			}
+			seed = host
+		}
+		l.Entry = l.Entry.WithField(CohortKey, CohortOf(seed))
+		return nil
+	}
+}
+
+// WithCohortLevel sets level (see WithLevel) when the logger belongs to the
+// first percent cohorts, e.g. WithCohortLevel(5, "debug") enables debug logging
+// for 5% of the pods. Apply it after WithCohort and WithLevel.
+func WithCohortLevel(percent int, level string) Option {
+	return func(l *Logger) error {
+		if _, ok := l.Cohort(); !ok {
+			return errors.New("cohort: WithCohortLevel requires WithCohort")
+		}
+		if !l.InCohort(percent) {
+			return nil
+		}
+		return WithLevel(level)(l)
+	}
+}
+
+// Cohort returns the cohort bucket of the logger, false when WithCohort wasn't
+// used
+func (l *Logger) Cohort() (int, bool) {
+	cohort, ok := l.Entry.Data[CohortKey].(int)
+	return cohort, ok
+}
+
+// InCohort reports whether the logger belongs to the first percent cohorts, so
+// applications can sample expensive logging consistently with the cohort field.
+// It is false when WithCohort wasn't used.
+func (l *Logger) InCohort(percent int) bool {
+	cohort, ok := l.Cohort()
+	return ok && cohort < percent
+}
@@ -0,0 +1,64 @@
+package logger
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCohortOf(t *testing.T) {
+	assert.Equal(t, CohortOf("api-7d9f-x2k4q"), CohortOf("api-7d9f-x2k4q"))
+
+	// seeds spread over the buckets
+	seen := make(map[int]bool)
+	for i := 0; i < 1000; i++ {
+		cohort := CohortOf(fmt.Sprintf("pod-%d", i))
+		require.True(t, cohort >= 0 && cohort < 100)
+		seen[cohort] = true
+	}
+	assert.Greater(t, len(seen), 90)
+}
+
+func TestWithCohort(t *testing.T) {
+	logger, err := NewLogger(WithNullOutput(), WithLastEntriesCapture(5), WithCohort("api-1"))
+	require.NoError(t, err)
+
+	cohort, ok := logger.Cohort()
+	require.True(t, ok)
+	assert.Equal(t, CohortOf("api-1"), cohort)
+	assert.True(t, logger.InCohort(cohort+1))
+	assert.False(t, logger.InCohort(cohort))
+
+	logger.Info("hello")
+	crumbs := logger.Breadcrumbs(0)
+	require.Len(t, crumbs, 1)
+	assert.Equal(t, cohort, crumbs[0].Data[CohortKey])
+}
+
+func TestWithCohortLevel(t *testing.T) {
+	seed := "api-1"
+	cohort := CohortOf(seed)
+
+	in, err := NewLogger(WithNullOutput(), WithLevel("info"), WithCohort(seed), WithCohortLevel(cohort+1, "debug"))
+	require.NoError(t, err)
+	assert.Equal(t, logrus.DebugLevel, in.Logger.GetLevel())
+
+	out, err := NewLogger(WithNullOutput(), WithLevel("info"), WithCohort(seed), WithCohortLevel(cohort, "debug"))
+	require.NoError(t, err)
+	assert.Equal(t, logrus.InfoLevel, out.Logger.GetLevel())
+
+	_, err = NewLogger(WithCohortLevel(5, "debug"))
+	assert.Error(t, err)
+}
+
+func TestWithoutCohort(t *testing.T) {
+	logger, err := NewLogger(WithNullOutput())
+	require.NoError(t, err)
+
+	_, ok := logger.Cohort()
+	assert.False(t, ok)
+	assert.False(t, logger.InCohort(100))
+}
@@ -3,6 +3,7 @@ package logger
 import (
 	"bytes"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/fatih/color"
@@ -42,12 +43,28 @@ func (f *ColorFormatter) Format(entry *logrus.Entry) ([]byte, error) {
 	timestamp := timestampColor.Sprint(entry.Time.Format(time.StampMilli))
 	level := levelColor.Sprintf("[%s]", entry.Level.String())
 	message := messageColor.Sprintf("%s", entry.Message)
+	component := entryComponent(entry.Data)
+	if component != "" {
+		message = color.New(color.FgHiCyan).Sprintf("[%s]", component) + " " + message
+	}
 
 	// Write main log line
 	b.WriteString(fmt.Sprintf("%s %s %s", timestamp, level, message))
 
+	keys := make([]string, 0, len(entry.Data))
+	for key := range entry.Data {
+		if key == ComponentKey && component != "" {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	if !f.DisableSorting {
+		sort.Strings(keys)
+	}
+
 	// add a differet color for custom fields
-	for key, value := range entry.Data {
+	for _, key := range keys {
+		value := entry.Data[key]
 		if key != "func" && key != "src" {
 			fieldColor := color.New(color.FgHiYellow)
 			fieldKey := fieldColor.Sprint(key)
@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"errors"
+	"io"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Field keys of the entries logged for the output of a command
+const (
+	CommandKey = "command"
+	StreamKey  = "stream"
+)
+
+// CommandOutput sets the Stdout and Stderr of cmd to writers logging every line
+// the command writes as an entry on l (the global Log when nil) at level, with
+// the command name and the stream (stdout or stderr) as fields. Close the
+// returned closer once cmd.Wait returned to log the last lines when the process
+// exited without terminating them.
+func CommandOutput(l *Logger, level logrus.Level, cmd *exec.Cmd) io.Closer {
+	if l == nil {
+		l = globalLogger()
+	}
+	name := filepath.Base(cmd.Path)
+	if len(cmd.Args) > 0 {
+		name = filepath.Base(cmd.Args[0])
+	}
+	writer := func(stream string) io.WriteCloser {
+		return (&Logger{Entry: l.Entry.WithFields(Fields{CommandKey: name, StreamKey: stream})}).WriterLevel(level)
+	}
+	out := &commandOutput{stdout: writer("stdout"), stderr: writer("stderr")}
+	cmd.Stdout = out.stdout
+	cmd.Stderr = out.stderr
+	return out
+}
+
+// commandOutput implements io.Closer closing the writers of a command
+type commandOutput struct {
+	stdout io.WriteCloser
+	stderr io.WriteCloser
+}
+
+func (c *commandOutput) Close() error {
+	return errors.Join(c.stdout.Close(), c.stderr.Close())
+}
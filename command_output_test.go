@@ -0,0 +1,35 @@
+package logger
+
+import (
+	"os/exec"
+	"runtime"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+	logger, err := NewLogger(WithNullOutput(), WithLastEntriesCapture(10))
+	require.NoError(t, err)
+
+	cmd := exec.Command("sh", "-c", `echo "migrating"; echo "deprecated flag" >&2; printf "done"`)
+	output := CommandOutput(logger, logrus.InfoLevel, cmd)
+	require.NoError(t, cmd.Run())
+	require.NoError(t, output.Close())
+
+	crumbs := logger.Breadcrumbs(0)
+	require.Len(t, crumbs, 3)
+	messages := map[string]Fields{}
+	for _, crumb := range crumbs {
+		assert.Equal(t, logrus.InfoLevel, crumb.Level)
+		messages[crumb.Message] = crumb.Data
+	}
+	assert.Equal(t, Fields{CommandKey: "sh", StreamKey: "stdout"}, messages["migrating"])
+	assert.Equal(t, Fields{CommandKey: "sh", StreamKey: "stderr"}, messages["deprecated flag"])
+	assert.Equal(t, Fields{CommandKey: "sh", StreamKey: "stdout"}, messages["done"])
+}
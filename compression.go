@@ -0,0 +1,351 @@
+package logger
+
+import (
+	"compress/gzip"
+	"crypto/cipher"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Compressor compresses rotated log files
+type Compressor interface {
+	// Extension is appended to the name of compressed files, e.g. ".gz"
+	Extension() string
+	Compress(dst io.Writer, src io.Reader) error
+}
+
+// archiveExtensions are the extensions of the archives written by the built-in
+// compressors and the encryption, longest first
+var archiveExtensions = []string{".gz" + EncryptedExtension, ".zst" + EncryptedExtension, EncryptedExtension, ".gz", ".zst"}
+
+type gzipCompressor struct {
+	level int
+}
+
+// GzipCompressor returns a gzip compressor using level, from gzip.HuffmanOnly
+// to gzip.BestCompression; 0 selects gzip.DefaultCompression
+func GzipCompressor(level int) (Compressor, error) {
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	if _, err := gzip.NewWriterLevel(io.Discard, level); err != nil {
+		return nil, fmt.Errorf("gzip compressor: %w", err)
+	}
+	return &gzipCompressor{level: level}, nil
+}
+
+func (c *gzipCompressor) Extension() string {
+	return ".gz"
+}
+
+func (c *gzipCompressor) Compress(dst io.Writer, src io.Reader) error {
+	zw, err := gzip.NewWriterLevel(dst, c.level)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(zw, src); err != nil {
+		zw.Close()
+		return err
+	}
+	return zw.Close()
+}
+
+type zstdCompressor struct {
+	level zstd.EncoderLevel
+}
+
+// ZstdCompressor returns a zstd compressor using level, mapped like the zstd
+// command line levels (1 fastest, 3 default, 19 and above best); 0 selects the
+// default level
+func ZstdCompressor(level int) (Compressor, error) {
+	if level < 0 {
+		return nil, fmt.Errorf("zstd compressor: invalid level %d", level)
+	}
+	l := zstd.SpeedDefault
+	if level > 0 {
+		l = zstd.EncoderLevelFromZstd(level)
+	}
+	return &zstdCompressor{level: l}, nil
+}
+
+func (c *zstdCompressor) Extension() string {
+	return ".zst"
+}
+
+func (c *zstdCompressor) Compress(dst io.Writer, src io.Reader) error {
+	zw, err := zstd.NewWriter(dst, zstd.WithEncoderLevel(c.level))
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(zw, src); err != nil {
+		zw.Close()
+		return err
+	}
+	return zw.Close()
+}
+
+// archiver compresses, encrypts, records the checksums of and uploads the
+// backups of a lumberjack logger on a background worker, so rotation doesn't
+// stall logging, and enforces
+// MaxBackups and MaxAge on the archives lumberjack doesn't recognize, as well as
+// the total size budget
+type archiver struct {
+	file       *lumberjack.Logger
+	compressor Compressor  // nil to leave backups uncompressed
+	aead       cipher.AEAD // nil to leave backups unencrypted
+	uploader   *uploader   // nil to keep backups local only
+	manifest   *manifest   // nil to leave the checksums of the archives unrecorded
+	maxBackups int
+	maxAge     time.Duration
+	maxTotal   int64 // bytes, the current file counted at its max size
+	onError    func(error)
+
+	notify chan struct{}
+	done   chan struct{}
+	wg     sync.WaitGroup
+	once   sync.Once
+}
+
+// start starts archiving the backups of file
+func (a *archiver) start(file *lumberjack.Logger) *archiver {
+	a.file = file
+	a.maxBackups = file.MaxBackups
+	a.maxAge = time.Duration(file.MaxAge) * 24 * time.Hour
+	a.notify = make(chan struct{}, 1)
+	a.done = make(chan struct{})
+	a.wg.Add(1)
+	go a.run()
+	// backups left by a previous run
+	a.rotated()
+	return a
+}
+
+// rotated schedules the compression of the new backup
+func (a *archiver) rotated() {
+	select {
+	case a.notify <- struct{}{}:
+	default:
+	}
+}
+
+// close compresses the pending backups and stops the worker
+func (a *archiver) close() {
+	a.once.Do(func() {
+		close(a.done)
+		a.wg.Wait()
+	})
+}
+
+func (a *archiver) run() {
+	defer a.wg.Done()
+	for {
+		select {
+		case <-a.notify:
+			a.archive()
+		case <-a.done:
+			a.archive()
+			return
+		}
+	}
+}
+
+// archive compresses and encrypts the new backups and removes the ones
+// exceeding the retention, oldest first
+func (a *archiver) archive() {
+	backups := listBackups(a.file)
+	for i, b := range backups {
+		if b.compressed || !a.transforms() {
+			continue
+		}
+		name, err := a.compress(b.path)
+		if err != nil {
+			a.report(err)
+			continue
+		}
+		backups[i].path, backups[i].compressed = name, true
+		if info, err := os.Stat(name); err == nil {
+			backups[i].size = info.Size()
+		}
+	}
+	if a.manifest != nil {
+		for _, b := range backups {
+			// archives failing to compress are recorded once compressed
+			if b.compressed || !a.transforms() {
+				if err := a.manifest.record(b.path); err != nil {
+					a.report(err)
+				}
+			}
+		}
+	}
+
+	// newest first
+	sort.Slice(backups, func(i, j int) bool { return backups[i].time.After(backups[j].time) })
+	if a.uploader != nil {
+		backups = a.upload(backups)
+	}
+	total := int64(a.file.MaxSize) * 1024 * 1024
+	for i, b := range backups {
+		total += b.size
+		expired := a.maxAge > 0 && time.Since(b.time) > a.maxAge
+		overBudget := a.maxTotal > 0 && total > a.maxTotal
+		if i >= a.maxBackups && a.maxBackups > 0 || expired || overBudget {
+			os.Remove(b.path)
+			if a.uploader != nil {
+				delete(a.uploader.uploaded, b.path)
+			}
+		}
+	}
+}
+
+// upload uploads the archives, oldest first, returning the ones left locally
+func (a *archiver) upload(backups []backup) []backup {
+	kept := make([]backup, 0, len(backups))
+	for i := len(backups) - 1; i >= 0; i-- {
+		b := backups[i]
+		// archives failing to compress are retried on the next rotation
+		if a.transforms() && !b.compressed {
+			kept = append(kept, b)
+			continue
+		}
+		deleted, err := a.uploader.upload(b, a.done)
+		if err != nil {
+			a.report(err)
+		}
+		if !deleted {
+			kept = append(kept, b)
+		}
+	}
+	// newest first again
+	for i, j := 0, len(kept)-1; i < j; i, j = i+1, j-1 {
+		kept[i], kept[j] = kept[j], kept[i]
+	}
+	return kept
+}
+
+func (a *archiver) report(err error) {
+	if a.onError != nil {
+		a.onError(err)
+	}
+}
+
+// transforms reports whether backups are compressed or encrypted
+func (a *archiver) transforms() bool {
+	return a.compressor != nil || a.aead != nil
+}
+
+// compress compresses and encrypts path into path+extensions and removes path.
+// The archive is written under a temporary name first, so a crash never leaves
+// a torn one.
+func (a *archiver) compress(path string) (string, error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	name := path
+	if a.compressor != nil {
+		name += a.compressor.Extension()
+	}
+	if a.aead != nil {
+		name += EncryptedExtension
+	}
+	tmp := name + ".tmp"
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return "", err
+	}
+	if err := a.write(dst, src); err != nil {
+		dst.Close()
+		os.Remove(tmp)
+		return "", fmt.Errorf("compress %s: %w", path, err)
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+	if err := os.Rename(tmp, name); err != nil {
+		return "", err
+	}
+	return name, os.Remove(path)
+}
+
+// write compresses then encrypts src into dst
+func (a *archiver) write(dst io.Writer, src io.Reader) error {
+	switch {
+	case a.aead == nil:
+		return a.compressor.Compress(dst, src)
+	case a.compressor == nil:
+		return encryptArchive(dst, src, a.aead)
+	}
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(a.compressor.Compress(pw, src))
+	}()
+	err := encryptArchive(dst, pr, a.aead)
+	// unblocks the compressor when encryption failed
+	pr.CloseWithError(io.ErrClosedPipe)
+	return err
+}
+
+// backup is a rotated file of a lumberjack logger
+type backup struct {
+	path       string
+	time       time.Time
+	size       int64
+	compressed bool
+}
+
+// listBackups returns the backups of l. lumberjack names them
+// <name>-<timestamp><ext>, compressed ones with an extra extension.
+func listBackups(l *lumberjack.Logger) []backup {
+	dir := filepath.Dir(l.Filename)
+	base := filepath.Base(l.Filename)
+	ext := filepath.Ext(base)
+	prefix := strings.TrimSuffix(base, ext) + "-"
+	loc := time.UTC
+	if l.LocalTime {
+		loc = time.Local
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var backups []backup
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		stamp, compressed := name[len(prefix):], false
+		for _, c := range archiveExtensions {
+			if strings.HasSuffix(stamp, c) {
+				stamp, compressed = strings.TrimSuffix(stamp, c), true
+				break
+			}
+		}
+		if !strings.HasSuffix(stamp, ext) {
+			continue
+		}
+		t, err := time.ParseInLocation(backupTimeFormat, strings.TrimSuffix(stamp, ext), loc)
+		if err != nil {
+			continue
+		}
+		var size int64
+		if info, err := e.Info(); err == nil {
+			size = info.Size()
+		}
+		backups = append(backups, backup{path: filepath.Join(dir, name), time: t, size: size, compressed: compressed})
+	}
+	return backups
+}
@@ -0,0 +1,156 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressors(t *testing.T) {
+	data := strings.Repeat("level=info msg=hello\n", 100)
+
+	gz, err := GzipCompressor(gzip.BestCompression)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, gz.Compress(&buf, strings.NewReader(data)))
+	zr, err := gzip.NewReader(&buf)
+	require.NoError(t, err)
+	out, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	assert.Equal(t, data, string(out))
+	assert.Equal(t, ".gz", gz.Extension())
+
+	zs, err := ZstdCompressor(19)
+	require.NoError(t, err)
+	buf.Reset()
+	require.NoError(t, zs.Compress(&buf, strings.NewReader(data)))
+	dec, err := zstd.NewReader(&buf)
+	require.NoError(t, err)
+	defer dec.Close()
+	out, err = io.ReadAll(dec)
+	require.NoError(t, err)
+	assert.Equal(t, data, string(out))
+	assert.Equal(t, ".zst", zs.Extension())
+
+	_, err = GzipCompressor(42)
+	assert.Error(t, err)
+	_, err = ZstdCompressor(-1)
+	assert.Error(t, err)
+}
+
+func newCompressedFileHook(t *testing.T, cfg *RotatingFileConfig) (*Logger, *rotatingFileHook) {
+	t.Helper()
+	hook, err := newRotatingFileHook(cfg)
+	require.NoError(t, err)
+	logger, err := NewLogger(WithNullOutput(), WithHooks(hook), WithStats())
+	require.NoError(t, err)
+	return logger, hook
+}
+
+func TestRotatingFileHook_Compressor(t *testing.T) {
+	dir := t.TempDir()
+	zs, err := ZstdCompressor(3)
+	require.NoError(t, err)
+	logger, hook := newCompressedFileHook(t, &RotatingFileConfig{Filename: filepath.Join(dir, "app.log"), Compressor: zs})
+
+	logger.Info("archived entry")
+	require.NoError(t, logger.ForceRotateAll())
+	logger.Info("current entry")
+	require.NoError(t, hook.Close())
+
+	archives, err := filepath.Glob(filepath.Join(dir, "app-*.log.zst"))
+	require.NoError(t, err)
+	require.Len(t, archives, 1)
+	f, err := os.Open(archives[0])
+	require.NoError(t, err)
+	defer f.Close()
+	dec, err := zstd.NewReader(f)
+	require.NoError(t, err)
+	defer dec.Close()
+	content, err := io.ReadAll(dec)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "archived entry")
+
+	plain, err := filepath.Glob(filepath.Join(dir, "app-*.log"))
+	require.NoError(t, err)
+	assert.Empty(t, plain, "uncompressed backups are removed")
+
+	stats, _ := logger.Stats()
+	assert.Equal(t, 1, stats.Files[0].Backups)
+	assert.Zero(t, stats.Files[0].CompressionBacklog)
+}
+
+func TestRotatingFileHook_CompressorDetectsRotation(t *testing.T) {
+	dir := t.TempDir()
+	gz, err := GzipCompressor(gzip.BestSpeed)
+	require.NoError(t, err)
+	_, hook := newCompressedFileHook(t, &RotatingFileConfig{Filename: filepath.Join(dir, "app.log"), MaxSize: 1, Compressor: gz})
+
+	entry := &logrus.Entry{Logger: logrus.New(), Level: logrus.InfoLevel, Message: strings.Repeat("x", 1024), Data: logrus.Fields{}}
+	for i := 0; i < 1200; i++ {
+		require.NoError(t, hook.Fire(entry))
+	}
+	assert.Eventually(t, func() bool {
+		archives, _ := filepath.Glob(filepath.Join(dir, "app-*.log.gz"))
+		return len(archives) == 1
+	}, 5*time.Second, 10*time.Millisecond, "backups are compressed in the background")
+	require.NoError(t, hook.Close())
+}
+
+func TestArchiver_Retention(t *testing.T) {
+	dir := t.TempDir()
+	gz, err := GzipCompressor(0)
+	require.NoError(t, err)
+	logger, hook := newCompressedFileHook(t, &RotatingFileConfig{Filename: filepath.Join(dir, "app.log"), MaxBackups: 2, Compressor: gz})
+
+	for i := 0; i < 4; i++ {
+		logger.Info("entry")
+		require.NoError(t, logger.ForceRotateAll())
+		// backups are named after the rotation time, in milliseconds
+		time.Sleep(5 * time.Millisecond)
+	}
+	require.NoError(t, hook.Close())
+
+	archives, err := filepath.Glob(filepath.Join(dir, "app-*.log.gz"))
+	require.NoError(t, err)
+	assert.Len(t, archives, 2)
+}
+
+func TestRotatingFileHook_MaxTotalSize(t *testing.T) {
+	dir := t.TempDir()
+	message := strings.Repeat("x", 1000)
+	// the current file counts at MaxSize, leaving room for two backups
+	logger, hook := newCompressedFileHook(t, &RotatingFileConfig{
+		Filename:     filepath.Join(dir, "app.log"),
+		MaxSize:      1,
+		MaxBackups:   10,
+		MaxTotalSize: 1<<20 + 2500,
+	})
+
+	for i := 0; i < 5; i++ {
+		logger.Info(message)
+		require.NoError(t, logger.ForceRotateAll())
+		time.Sleep(5 * time.Millisecond)
+	}
+	require.NoError(t, hook.Close())
+
+	backups, err := filepath.Glob(filepath.Join(dir, "app-*.log"))
+	require.NoError(t, err)
+	assert.Len(t, backups, 2)
+	stats, _ := logger.Stats()
+	require.Len(t, stats.Files, 1)
+	assert.Zero(t, stats.Files[0].CompressionBacklog)
+
+	_, err = newRotatingFileHook(&RotatingFileConfig{Filename: filepath.Join(dir, "small.log"), MaxSize: 1, MaxTotalSize: 1000})
+	assert.Error(t, err)
+}
@@ -0,0 +1,109 @@
+package logger
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+// loggerKey is the context key of the logger stored by NewContext
+type loggerKey struct{}
+
+// WithContext returns a logger attaching ctx to every entry, so hooks can read
+// the values it carries
+func (l *Logger) WithContext(ctx context.Context) *Logger {
+	return &Logger{Entry: l.Entry.WithContext(ctx)}
+}
+
+// NewContext returns a copy of ctx carrying l, e.g. a request-scoped logger with
+// request_id and user_id fields, so it can be retrieved down the call stack
+// with FromContext instead of being passed explicitly
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// FromContext returns the logger stored in ctx by NewContext, the global Log
+// when there is none, with ctx attached to its entries
+func FromContext(ctx context.Context) *Logger {
+	l, ok := ctx.Value(loggerKey{}).(*Logger)
+	if !ok || l == nil {
+		l = globalLogger()
+	}
+	return l.WithContext(ctx)
+}
+
+// ContextExtractor returns the fields to attach to an entry logged with a
+// context, e.g. a trace id, tenant id or auth subject it carries
+type ContextExtractor func(ctx context.Context) Fields
+
+// WithContextExtractor attaches the fields returned by extract to every entry
+// logged with a context, see Logger.WithContext and FromContext. Extractors
+// compose, running in the order they were added. Fields set on the entry take
+// precedence, then the fields of the earlier extractors.
+func WithContextExtractor(extract ContextExtractor) Option {
+	return func(l *Logger) error {
+		if extract == nil {
+			return nil
+		}
+		if h, ok := findHook[*contextExtractorHook](l.Entry.Logger); ok {
+			h.extractors = append(h.extractors, extract)
+			return nil
+		}
+		// extracted fields are encoded and seen by every other hook
+		prependHook(l.Entry.Logger, &contextExtractorHook{extractors: []ContextExtractor{extract}})
+		return nil
+	}
+}
+
+// contextExtractorHook implements logrus.Hook adding the fields extracted from
+// the entry context
+type contextExtractorHook struct {
+	extractors []ContextExtractor
+}
+
+func (h *contextExtractorHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Enriches implements EnrichingHook, it adds the context fields
+func (h *contextExtractorHook) Enriches() bool {
+	return true
+}
+
+func (h *contextExtractorHook) Fire(entry *logrus.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+	for _, extract := range h.extractors {
+		for key, value := range extract(entry.Context) {
+			if _, exists := entry.Data[key]; !exists {
+				entry.Data[key] = value
+			}
+		}
+	}
+	return nil
+}
+
+// metadataKey is the context key of the metadata set by ContextWithMetadata
+type metadataKey struct{}
+
+// ContextWithMetadata returns a copy of ctx carrying md merged over the metadata
+// ctx already carries, e.g. a tenant or feature flag set by middleware. Selected
+// keys are added as fields with WithBaggageFields.
+func ContextWithMetadata(ctx context.Context, md map[string]string) context.Context {
+	merged := make(map[string]string, len(md))
+	for k, v := range MetadataFromContext(ctx) {
+		merged[k] = v
+	}
+	for k, v := range md {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, metadataKey{}, merged)
+}
+
+// MetadataFromContext returns the metadata carried by ctx, nil when there is
+// none. The returned map must not be modified.
+func MetadataFromContext(ctx context.Context) map[string]string {
+	md, _ := ctx.Value(metadataKey{}).(map[string]string)
+	return md
+}
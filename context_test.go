@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tenantKey struct{}
+
+func TestNewContext_FromContext(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := NewLogger(WithOutput(&buf), WithFormatter(&PlainFormatter{}))
+	require.NoError(t, err)
+
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	requestLogger := logger.WithFields(Fields{"request_id": "r-1"}).(*Logger)
+	ctx = NewContext(ctx, requestLogger)
+
+	l := FromContext(ctx)
+	l.Info("handled")
+	assert.Contains(t, buf.String(), "request_id=r-1")
+	// the entries carry the context for hooks
+	assert.Equal(t, ctx, l.Entry.Context)
+	assert.Equal(t, "acme", l.Entry.Context.Value(tenantKey{}))
+}
+
+func TestFromContext_Global(t *testing.T) {
+	prev := Log
+	t.Cleanup(func() { Log = prev })
+	logger, err := NewLogger(WithNullOutput())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	l := FromContext(ctx)
+	assert.Equal(t, logger.Entry.Logger, l.Entry.Logger)
+	assert.Equal(t, ctx, l.WithContext(ctx).Entry.Context)
+}
+
+type subjectKey struct{}
+
+func TestWithContextExtractor(t *testing.T) {
+	var buf bytes.Buffer
+	tenant := func(ctx context.Context) Fields {
+		if v, ok := ctx.Value(tenantKey{}).(string); ok {
+			return Fields{"tenant": v}
+		}
+		return nil
+	}
+	subject := func(ctx context.Context) Fields {
+		return Fields{"subject": ctx.Value(subjectKey{}), "tenant": "ignored"}
+	}
+	logger, err := NewLogger(
+		WithOutput(&buf),
+		WithFormatter(&logrus.JSONFormatter{}),
+		WithContextExtractor(tenant),
+		WithContextExtractor(subject),
+		WithAsync(16, Block),
+	)
+	require.NoError(t, err)
+
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	ctx = context.WithValue(ctx, subjectKey{}, "user-1")
+	logger.WithContext(ctx).Info("with context")
+	logger.WithContext(ctx).WithField("subject", "explicit").Info("explicit field")
+	logger.Info("without context")
+	require.NoError(t, logger.Shutdown(context.Background()))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+	var entries []map[string]interface{}
+	for _, line := range lines {
+		var e map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &e))
+		entries = append(entries, e)
+	}
+	assert.Equal(t, "acme", entries[0]["tenant"])
+	assert.Equal(t, "user-1", entries[0]["subject"])
+	assert.Equal(t, "explicit", entries[1]["subject"])
+	assert.NotContains(t, entries[2], "tenant")
+}
@@ -0,0 +1,295 @@
+package logger
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultDatadogSite       = "datadoghq.com"
+	DefaultDatadogSource     = "go"
+	DefaultDatadogBatchSize  = 500 // the intake accepts at most 1000 entries per request
+	DefaultDatadogBatchWait  = 2 * time.Second
+	DefaultDatadogQueueSize  = 10000
+	DefaultDatadogMaxRetries = 3
+	DefaultDatadogTimeout    = 10 * time.Second
+)
+
+// DatadogConfig holds configuration for the Datadog hook. Entries are sent to the
+// HTTP logs intake unless AgentAddress is set, in which case they are written as
+// JSON lines to a local agent TCP listener.
+type DatadogConfig struct {
+	APIKey       string // required for the HTTP intake
+	Site         string // e.g. datadoghq.eu, defaults to DefaultDatadogSite
+	URL          string // overrides the intake URL derived from Site
+	AgentAddress string // host:port of an agent TCP logs listener
+	Service      string
+	Source       string // ddsource, defaults to DefaultDatadogSource
+	Hostname     string // defaults to os.Hostname()
+	Tags         []string
+	BatchSize    int
+	BatchWait    time.Duration
+	QueueSize    int
+	Block        bool // block logging when the queue is full instead of dropping
+	MaxRetries   int
+	Retry        *RetryPolicy // overrides MaxRetries
+	TLS          *TLSConfig   // used for the intake and the agent connection
+	Timeout      time.Duration
+	Levels       []logrus.Level
+}
+
+// datadogHook implements logrus.Hook shipping entries to Datadog
+type datadogHook struct {
+	cfg     DatadogConfig
+	url     string
+	client  *http.Client
+	tls     *tls.Config
+	ddtags  string
+	retry   RetryPolicy
+	batcher *Batcher[[]byte]
+
+	// agent connection
+	conn net.Conn
+	mu   sync.Mutex
+}
+
+// datadogStatus maps logrus levels to Datadog statuses
+var datadogStatus = map[logrus.Level]string{
+	logrus.PanicLevel: "emergency",
+	logrus.FatalLevel: "critical",
+	logrus.ErrorLevel: "error",
+	logrus.WarnLevel:  "warn",
+	logrus.InfoLevel:  "info",
+	logrus.DebugLevel: "debug",
+	logrus.TraceLevel: "debug",
+}
+
+// NewDatadogHook creates a new hook shipping entries to Datadog
+func NewDatadogHook(cfg *DatadogConfig) (*datadogHook, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("datadog: config is required")
+	}
+	c := *cfg
+	if c.AgentAddress == "" && c.APIKey == "" {
+		return nil, fmt.Errorf("datadog: an API key or agent address is required")
+	}
+	// Set default values if not specified
+	if c.Site == "" {
+		c.Site = DefaultDatadogSite
+	}
+	if c.Source == "" {
+		c.Source = DefaultDatadogSource
+	}
+	if c.Hostname == "" {
+		c.Hostname, _ = os.Hostname()
+	}
+	if c.BatchSize <= 0 || c.BatchSize > 1000 {
+		c.BatchSize = DefaultDatadogBatchSize
+	}
+	if c.BatchWait <= 0 {
+		c.BatchWait = DefaultDatadogBatchWait
+	}
+	if c.QueueSize <= 0 {
+		c.QueueSize = DefaultDatadogQueueSize
+	}
+	if c.MaxRetries < 0 {
+		c.MaxRetries = 0
+	} else if c.MaxRetries == 0 {
+		c.MaxRetries = DefaultDatadogMaxRetries
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultDatadogTimeout
+	}
+	if len(c.Levels) == 0 {
+		c.Levels = logrus.AllLevels
+	}
+
+	url := c.URL
+	if url == "" {
+		url = fmt.Sprintf("https://http-intake.logs.%s/api/v2/logs", c.Site)
+	}
+
+	tlsConfig, err := c.TLS.build()
+	if err != nil {
+		return nil, fmt.Errorf("datadog: %w", err)
+	}
+
+	hook := &datadogHook{
+		cfg:    c,
+		url:    url,
+		client: newHTTPClient(c.Timeout, tlsConfig),
+		tls:    tlsConfig,
+		ddtags: strings.Join(c.Tags, ","),
+		retry:  retryPolicy(c.Retry, RetryPolicy{MaxAttempts: c.MaxRetries + 1}),
+	}
+	hook.batcher = NewBatcher(c.BatchSize, c.BatchWait, c.QueueSize, c.Block, hook.ship)
+	return hook, nil
+}
+
+// WithDatadog adds a hook that ships entries to Datadog
+func WithDatadog(cfg *DatadogConfig) Option {
+	return func(l *Logger) error {
+		hook, err := NewDatadogHook(cfg)
+		if err != nil {
+			return err
+		}
+		l.Entry.Logger.AddHook(hook)
+		return nil
+	}
+}
+
+func (h *datadogHook) Levels() []logrus.Level {
+	return h.cfg.Levels
+}
+
+// Fire queues the entry for the next batch
+func (h *datadogHook) Fire(entry *logrus.Entry) error {
+	record, err := json.Marshal(h.record(entry))
+	if err != nil {
+		return err
+	}
+	h.batcher.Add(record)
+	return nil
+}
+
+// Dropped returns the number of entries discarded because the queue was full
+func (h *datadogHook) Dropped() uint64 {
+	return h.batcher.Dropped()
+}
+
+// Flush ships the queued entries without stopping the background worker
+func (h *datadogHook) Flush() error {
+	h.batcher.Sync()
+	return nil
+}
+
+// Close ships pending entries and stops the background worker
+func (h *datadogHook) Close() error {
+	h.batcher.Close()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.conn != nil {
+		h.conn.Close()
+		h.conn = nil
+	}
+	return nil
+}
+
+// record builds the Datadog log record for an entry
+func (h *datadogHook) record(entry *logrus.Entry) map[string]interface{} {
+	record := make(map[string]interface{}, len(entry.Data)+8)
+	for k, v := range entry.Data {
+		switch k {
+		case "trace_id":
+			k = "dd.trace_id"
+		case "span_id":
+			k = "dd.span_id"
+		}
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+		record[k] = v
+	}
+	record["message"] = entry.Message
+	record["status"] = datadogStatus[entry.Level]
+	record["timestamp"] = entry.Time.UnixMilli()
+	record["ddsource"] = h.cfg.Source
+	if h.cfg.Service != "" {
+		record["service"] = h.cfg.Service
+	}
+	if h.cfg.Hostname != "" {
+		record["hostname"] = h.cfg.Hostname
+	}
+	if h.ddtags != "" {
+		record["ddtags"] = h.ddtags
+	}
+	return record
+}
+
+// ship delivers a batch of encoded records
+func (h *datadogHook) ship(records [][]byte) {
+	if h.cfg.AgentAddress != "" {
+		h.writeAgent(records)
+		return
+	}
+
+	body := bytes.NewBuffer(make([]byte, 0, 256*len(records)))
+	body.WriteByte('[')
+	for i, r := range records {
+		if i > 0 {
+			body.WriteByte(',')
+		}
+		body.Write(r)
+	}
+	body.WriteByte(']')
+
+	h.retry.Deliver("datadog", len(records), body.Bytes(), h.batcher.Closing(), h.send)
+}
+
+// send performs a single intake request and reports whether it should be retried
+func (h *datadogHook) send(body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("DD-API-KEY", h.cfg.APIKey)
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode/100 == 2 {
+		return false, nil
+	}
+	err = fmt.Errorf("datadog: intake failed with status %d", resp.StatusCode)
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500, err
+}
+
+// writeAgent writes records as JSON lines to the agent, reconnecting and
+// retrying according to the retry policy
+func (h *datadogHook) writeAgent(records [][]byte) {
+	var buf bytes.Buffer
+	for _, r := range records {
+		buf.Write(r)
+		buf.WriteByte('\n')
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.retry.Deliver("datadog", len(records), buf.Bytes(), h.batcher.Closing(), h.sendAgent)
+}
+
+// sendAgent performs a single write to the agent, dialing first if needed
+func (h *datadogHook) sendAgent(lines []byte) (bool, error) {
+	if h.conn == nil {
+		conn, err := dialTimeout("tcp", h.cfg.AgentAddress, h.cfg.Timeout, h.tls)
+		if err != nil {
+			return true, err
+		}
+		h.conn = conn
+	}
+	h.conn.SetWriteDeadline(time.Now().Add(h.cfg.Timeout))
+	if _, err := h.conn.Write(lines); err != nil {
+		h.conn.Close()
+		h.conn = nil
+		return true, err
+	}
+	return false, nil
+}
@@ -0,0 +1,143 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatadogHook_HTTP(t *testing.T) {
+	var (
+		records []map[string]interface{}
+		apiKey  string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiKey = r.Header.Get("DD-API-KEY")
+		var batch []map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&batch))
+		records = append(records, batch...)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	hook, err := NewDatadogHook(&DatadogConfig{
+		APIKey:    "secret",
+		URL:       server.URL,
+		Service:   "checkout",
+		Hostname:  "web-1",
+		Tags:      []string{"env:prod", "team:payments"},
+		BatchWait: time.Hour,
+	})
+	require.NoError(t, err)
+
+	logger := logrus.New()
+	logger.AddHook(hook)
+	logger.SetOutput(io.Discard)
+
+	logger.WithFields(logrus.Fields{"trace_id": "123", "order": "A1"}).Error("payment failed")
+	logger.Info("order created")
+
+	require.NoError(t, hook.Close())
+
+	assert.Equal(t, "secret", apiKey)
+	require.Len(t, records, 2)
+	assert.Equal(t, "payment failed", records[0]["message"])
+	assert.Equal(t, "error", records[0]["status"])
+	assert.Equal(t, "go", records[0]["ddsource"])
+	assert.Equal(t, "checkout", records[0]["service"])
+	assert.Equal(t, "web-1", records[0]["hostname"])
+	assert.Equal(t, "env:prod,team:payments", records[0]["ddtags"])
+	assert.Equal(t, "123", records[0]["dd.trace_id"])
+	assert.Equal(t, "A1", records[0]["order"])
+	assert.Equal(t, "info", records[1]["status"])
+}
+
+func TestDatadogHook_Agent(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	lines := make(chan string, 10)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	hook, err := NewDatadogHook(&DatadogConfig{
+		AgentAddress: listener.Addr().String(),
+		Service:      "worker",
+		BatchSize:    1,
+	})
+	require.NoError(t, err)
+	defer hook.Close()
+
+	logger := logrus.New()
+	logger.AddHook(hook)
+	logger.SetOutput(io.Discard)
+	logger.Warn("queue lagging")
+
+	select {
+	case line := <-lines:
+		var record map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		assert.Equal(t, "queue lagging", record["message"])
+		assert.Equal(t, "warn", record["status"])
+		assert.Equal(t, "worker", record["service"])
+	case <-time.After(2 * time.Second):
+		t.Fatal("agent did not receive the entry")
+	}
+}
+
+func TestDatadogHook_AgentFailure(t *testing.T) {
+	// reserve a port with nothing listening on it
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+
+	var failed []FailedDelivery
+	hook, err := NewDatadogHook(&DatadogConfig{
+		AgentAddress: addr,
+		Retry: &RetryPolicy{
+			MaxAttempts: 2,
+			MinBackoff:  time.Millisecond,
+			OnFailure:   func(f FailedDelivery) { failed = append(failed, f) },
+		},
+	})
+	require.NoError(t, err)
+
+	logger := logrus.New()
+	logger.AddHook(hook)
+	logger.SetOutput(io.Discard)
+	logger.Error("agent down")
+	require.NoError(t, hook.Close())
+
+	require.Len(t, failed, 1)
+	assert.Equal(t, "datadog", failed[0].Sink)
+	assert.Equal(t, 2, failed[0].Attempts)
+	assert.Contains(t, string(failed[0].Payload), "agent down")
+}
+
+func TestNewDatadogHook_Validation(t *testing.T) {
+	_, err := NewDatadogHook(nil)
+	assert.Error(t, err)
+
+	_, err = NewDatadogHook(&DatadogConfig{Service: "api"})
+	assert.Error(t, err)
+}
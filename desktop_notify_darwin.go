@@ -0,0 +1,14 @@
+package logger
+
+import "os/exec"
+
+// desktopNotify raises a notification through AppleScript. The title and
+// message are passed as arguments to avoid quoting issues.
+func desktopNotify(title, message string) error {
+	return exec.Command("osascript",
+		"-e", "on run argv",
+		"-e", "display notification (item 2 of argv) with title (item 1 of argv)",
+		"-e", "end run",
+		title, message,
+	).Run()
+}
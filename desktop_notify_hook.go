@@ -0,0 +1,111 @@
+package logger
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/mattn/go-isatty"
+	"github.com/sirupsen/logrus"
+)
+
+const DefaultDesktopNotifyInterval = 5 * time.Second
+
+// DesktopNotifyConfig holds configuration for the desktop notification hook
+type DesktopNotifyConfig struct {
+	Levels []logrus.Level // defaults to error, fatal and panic
+	Title  string         // notification title, defaults to the program name
+	// DisableBell and DisableNotification turn off the terminal bell and the OS
+	// notification respectively
+	DisableBell         bool
+	DisableNotification bool
+	// MinInterval is the minimum time between two notifications, defaults to
+	// DefaultDesktopNotifyInterval
+	MinInterval time.Duration
+	// Force enables the hook even when stderr isn't a terminal or the CI
+	// environment variable is set
+	Force bool
+}
+
+// desktopNotifyHook implements logrus.Hook ringing the terminal bell and raising
+// an OS notification for error entries, meant for local development
+type desktopNotifyHook struct {
+	cfg     DesktopNotifyConfig
+	enabled bool
+	bell    io.Writer
+	notify  func(title, message string) error
+	mu      sync.Mutex
+	last    time.Time
+}
+
+// NewDesktopNotifyHook creates a new desktop notification hook. Unless Force is
+// set, the hook does nothing outside an interactive terminal, so it can stay
+// enabled in code that also runs in CI or production.
+func NewDesktopNotifyHook(cfg *DesktopNotifyConfig) *desktopNotifyHook {
+	c := DesktopNotifyConfig{}
+	if cfg != nil {
+		c = *cfg
+	}
+	if len(c.Levels) == 0 {
+		c.Levels = []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel}
+	}
+	if c.Title == "" {
+		c.Title = filepath.Base(os.Args[0])
+	}
+	if c.MinInterval <= 0 {
+		c.MinInterval = DefaultDesktopNotifyInterval
+	}
+	interactive := isatty.IsTerminal(os.Stderr.Fd()) && os.Getenv("CI") == ""
+	return &desktopNotifyHook{
+		cfg:     c,
+		enabled: c.Force || interactive,
+		bell:    os.Stderr,
+		notify:  desktopNotify,
+	}
+}
+
+// WithDesktopNotifications rings the terminal bell and raises an OS
+// notification when an entry at the given levels (error and above when empty)
+// is logged during local development
+func WithDesktopNotifications(levels ...logrus.Level) Option {
+	return func(l *Logger) error {
+		l.Entry.Logger.AddHook(NewDesktopNotifyHook(&DesktopNotifyConfig{Levels: levels}))
+		return nil
+	}
+}
+
+func (h *desktopNotifyHook) Levels() []logrus.Level {
+	return h.cfg.Levels
+}
+
+// Fire notifies about the entry unless a notification was raised less than
+// MinInterval ago. Fatal and panic entries are notified synchronously since the
+// process is about to stop.
+func (h *desktopNotifyHook) Fire(entry *logrus.Entry) error {
+	if !h.enabled {
+		return nil
+	}
+	h.mu.Lock()
+	now := time.Now()
+	if !h.last.IsZero() && now.Sub(h.last) < h.cfg.MinInterval {
+		h.mu.Unlock()
+		return nil
+	}
+	h.last = now
+	h.mu.Unlock()
+
+	if !h.cfg.DisableBell {
+		h.bell.Write([]byte{'\a'})
+	}
+	if h.cfg.DisableNotification {
+		return nil
+	}
+	message := entry.Level.String() + ": " + entry.Message
+	if entry.Level <= logrus.FatalLevel {
+		return h.notify(h.cfg.Title, message)
+	}
+	go h.notify(h.cfg.Title, message)
+	return nil
+}
@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDesktopNotifyHook(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		messages []string
+		bell     bytes.Buffer
+	)
+	hook := NewDesktopNotifyHook(&DesktopNotifyConfig{Title: "tests", Force: true, MinInterval: time.Hour})
+	hook.bell = &bell
+	hook.notify = func(title, message string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, "tests", title)
+		messages = append(messages, message)
+		return nil
+	}
+
+	logger, err := NewLogger(WithNullOutput())
+	require.NoError(t, err)
+	logger.Logger.AddHook(hook)
+
+	logger.Info("not notified")
+	logger.Error("database down")
+	logger.Error("rate limited")
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(messages) == 1
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, "error: database down", messages[0])
+	assert.Equal(t, "\a", bell.String())
+}
+
+func TestDesktopNotifyHook_Disabled(t *testing.T) {
+	hook := NewDesktopNotifyHook(&DesktopNotifyConfig{Levels: []logrus.Level{logrus.WarnLevel}})
+	hook.enabled = false
+	hook.notify = func(title, message string) error {
+		t.Fatal("notified while disabled")
+		return nil
+	}
+	assert.Equal(t, []logrus.Level{logrus.WarnLevel}, hook.Levels())
+	require.NoError(t, hook.Fire(logrus.NewEntry(logrus.New())))
+}
@@ -0,0 +1,14 @@
+//go:build !darwin && !windows
+
+package logger
+
+import "os/exec"
+
+// desktopNotify raises a notification with notify-send where available
+func desktopNotify(title, message string) error {
+	path, err := exec.LookPath("notify-send")
+	if err != nil {
+		return nil
+	}
+	return exec.Command(path, "--urgency=critical", title, message).Run()
+}
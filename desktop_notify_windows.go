@@ -0,0 +1,31 @@
+package logger
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// desktopNotifyScript shows a balloon tip from the notification area
+const desktopNotifyScript = `Add-Type -AssemblyName System.Windows.Forms
+$n = New-Object System.Windows.Forms.NotifyIcon
+$n.Icon = [System.Drawing.SystemIcons]::Error
+$n.Visible = $true
+$n.ShowBalloonTip(5000, $env:LOGGER_NOTIFY_TITLE, $env:LOGGER_NOTIFY_MESSAGE, 'Error')
+Start-Sleep -Seconds 5
+$n.Dispose()`
+
+// desktopNotify raises a notification through PowerShell. The title and message
+// are passed in the environment to avoid quoting issues.
+func desktopNotify(title, message string) error {
+	cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", desktopNotifyScript)
+	cmd.Env = append(cmd.Environ(),
+		"LOGGER_NOTIFY_TITLE="+title,
+		"LOGGER_NOTIFY_MESSAGE="+strings.ReplaceAll(message, "\n", " "),
+	)
+	// the script outlives the call to keep the balloon visible
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
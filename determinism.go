@@ -0,0 +1,151 @@
+package logger
+
+import (
+	"encoding/binary"
+	"io"
+	mathrand "math/rand"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DeterminismConfig holds configuration for deterministic mode. Zero values
+// select the defaults.
+type DeterminismConfig struct {
+	// Start is the time of the first entry, defaults to 2000-01-01 UTC
+	Start time.Time
+	// Step is added to the clock for every entry, defaults to one millisecond
+	Step time.Duration
+	// Seed seeds the generator of the IDField ULIDs, defaults to 1
+	Seed int64
+	// SeqField receives a sequence number starting at 1, defaults to "seq"
+	SeqField string
+	// IDField receives a ULID generated from the clock and seed, no ID is added
+	// when empty
+	IDField string
+	// StripFields are removed from every entry, defaults to fields that vary
+	// between runs such as goroutine_id and pid
+	StripFields []string
+}
+
+// DefaultDeterministicStripFields are the fields removed in deterministic mode
+// unless StripFields is set
+var DefaultDeterministicStripFields = []string{"goroutine_id", "pid"}
+
+// determinismHook implements logrus.Hook replacing the sources of run to run
+// variation in entries (time, IDs, ordering) so output is byte identical
+type determinismHook struct {
+	cfg DeterminismConfig
+	mu  sync.Mutex
+	seq uint64
+	ids *ulidGenerator
+}
+
+// WithDeterminism makes the output of the logger byte identical across runs,
+// for golden files, replay and audit verification: entries get a fixed clock
+// advancing by Step, a sequence number and optionally a seeded ULID, run
+// specific fields are stripped and fields are rendered sorted.
+func WithDeterminism(cfg *DeterminismConfig) Option {
+	return func(l *Logger) error {
+		c := DeterminismConfig{}
+		if cfg != nil {
+			c = *cfg
+		}
+		if c.Start.IsZero() {
+			c.Start = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+		}
+		if c.Step <= 0 {
+			c.Step = time.Millisecond
+		}
+		if c.Seed == 0 {
+			c.Seed = 1
+		}
+		if c.SeqField == "" {
+			c.SeqField = "seq"
+		}
+		if c.StripFields == nil {
+			c.StripFields = DefaultDeterministicStripFields
+		}
+		hook := &determinismHook{
+			cfg: c,
+			ids: newULIDGenerator(mathrand.New(mathrand.NewSource(c.Seed))),
+		}
+		// runs first so every other hook sees the deterministic values
+		prependHook(l.Entry.Logger, hook)
+		if tf, ok := l.Entry.Logger.Formatter.(*logrus.TextFormatter); ok {
+			tf.DisableSorting = false
+		}
+		return nil
+	}
+}
+
+func (h *determinismHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Enriches implements EnrichingHook, it adds the sequence and id fields
+func (h *determinismHook) Enriches() bool {
+	return true
+}
+
+// Fire stamps the entry with the next clock value, sequence number and ID
+func (h *determinismHook) Fire(entry *logrus.Entry) error {
+	h.mu.Lock()
+	h.seq++
+	seq := h.seq
+	entry.Time = h.cfg.Start.Add(time.Duration(seq-1) * h.cfg.Step)
+	var id string
+	if h.cfg.IDField != "" {
+		id = h.ids.next(entry.Time)
+	}
+	h.mu.Unlock()
+
+	for _, key := range h.cfg.StripFields {
+		delete(entry.Data, key)
+	}
+	entry.Data[h.cfg.SeqField] = seq
+	if id != "" {
+		entry.Data[h.cfg.IDField] = id
+	}
+	return nil
+}
+
+// crockford is the ULID base32 alphabet
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ulidGenerator generates ULIDs (48 bit millisecond timestamp followed by 80
+// random bits) from a source of randomness
+type ulidGenerator struct {
+	mu      sync.Mutex
+	entropy io.Reader
+}
+
+func newULIDGenerator(entropy io.Reader) *ulidGenerator {
+	return &ulidGenerator{entropy: entropy}
+}
+
+// next returns a ULID for time t
+func (g *ulidGenerator) next(t time.Time) string {
+	var id [16]byte
+	var ms [8]byte
+	binary.BigEndian.PutUint64(ms[:], uint64(t.UnixMilli()))
+	copy(id[:6], ms[2:])
+	g.mu.Lock()
+	io.ReadFull(g.entropy, id[6:])
+	g.mu.Unlock()
+	return encodeULID(id)
+}
+
+// encodeULID renders the 128 bits of id as 26 Crockford base32 characters
+func encodeULID(id [16]byte) string {
+	hi := binary.BigEndian.Uint64(id[:8])
+	lo := binary.BigEndian.Uint64(id[8:])
+	var out [26]byte
+	for i := 25; i >= 0; i-- {
+		out[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
@@ -0,0 +1,58 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithDeterminism_ByteIdentical(t *testing.T) {
+	run := func() string {
+		var buf bytes.Buffer
+		logger, err := NewLogger(
+			WithOutput(&buf),
+			WithFormatter(&logrus.JSONFormatter{TimestampFormat: time.RFC3339Nano}),
+			WithDeterminism(&DeterminismConfig{IDField: "id"}),
+		)
+		require.NoError(t, err)
+		logger.WithFields(Fields{"b": 2, "a": 1, "pid": 1234}).Info("first")
+		logger.WithField("goroutine_id", 7).Warn("second")
+		return buf.String()
+	}
+
+	first := run()
+	assert.Equal(t, first, run())
+
+	lines := strings.Split(strings.TrimSpace(first), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"seq":1`)
+	assert.Contains(t, lines[0], `"time":"2000-01-01T00:00:00Z"`)
+	assert.NotContains(t, lines[0], "pid")
+	assert.Contains(t, lines[1], `"seq":2`)
+	assert.Contains(t, lines[1], `"time":"2000-01-01T00:00:00.001Z"`)
+	assert.NotContains(t, lines[1], "goroutine_id")
+}
+
+func TestColorFormatter_SortedFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := NewLogger(WithOutput(&buf), WithFormatter(&ColorFormatter{}), WithDeterminism(nil))
+	require.NoError(t, err)
+	logger.WithFields(Fields{"zeta": 1, "alpha": 2, "mid": 3}).Info("sorted")
+
+	out := buf.String()
+	alpha, mid, zeta := strings.Index(out, "alpha"), strings.Index(out, "mid"), strings.Index(out, "zeta")
+	assert.True(t, alpha < mid && mid < zeta, out)
+}
+
+func TestULID(t *testing.T) {
+	g := newULIDGenerator(bytes.NewReader(make([]byte, 10)))
+	id := g.next(time.UnixMilli(1469918176385))
+	assert.Len(t, id, 26)
+	assert.Equal(t, "01ARYZ6S41", id[:10])
+	assert.Equal(t, "0000000000000000", id[10:])
+}
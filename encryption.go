@@ -0,0 +1,188 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// EncryptedExtension is appended to the name of encrypted archives
+const EncryptedExtension = ".enc"
+
+// Encrypted archives start with encryptionMagic and a random nonce prefix,
+// followed by chunks of at most encryptionChunkSize plaintext bytes, each sealed
+// with AES-GCM and preceded by its length. The nonce of a chunk is the prefix,
+// the chunk number and a flag set on the last chunk, so reordered, dropped or
+// truncated chunks fail to decrypt.
+const (
+	encryptionMagic       = "LGE1"
+	encryptionPrefixSize  = 7
+	encryptionChunkSize   = 64 << 10
+	encryptionLastChunk   = 1
+	encryptionNonceLength = 12
+)
+
+// ErrArchiveCorrupt is returned when an encrypted archive can't be decrypted:
+// wrong key, tampered or truncated file
+var ErrArchiveCorrupt = errors.New("encrypted archive is corrupt or the key is wrong")
+
+// newArchiveCipher returns the AES-GCM cipher for key, 16, 24 or 32 bytes long
+// for AES-128, AES-192 or AES-256
+func newArchiveCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("archive encryption: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// EncryptArchive encrypts src into dst with AES-GCM using key, 16, 24 or 32
+// bytes long. DecryptArchive reverses it.
+func EncryptArchive(dst io.Writer, src io.Reader, key []byte) error {
+	aead, err := newArchiveCipher(key)
+	if err != nil {
+		return err
+	}
+	return encryptArchive(dst, src, aead)
+}
+
+func encryptArchive(dst io.Writer, src io.Reader, aead cipher.AEAD) error {
+	header := make([]byte, len(encryptionMagic)+encryptionPrefixSize)
+	copy(header, encryptionMagic)
+	if _, err := rand.Read(header[len(encryptionMagic):]); err != nil {
+		return err
+	}
+	if _, err := dst.Write(header); err != nil {
+		return err
+	}
+	prefix := header[len(encryptionMagic):]
+
+	// one chunk is read ahead to tell which one is the last
+	cur, next := make([]byte, encryptionChunkSize), make([]byte, encryptionChunkSize)
+	n, err := io.ReadFull(src, cur)
+	out := make([]byte, 4, 4+encryptionChunkSize+aead.Overhead())
+	for chunk := uint32(0); ; chunk++ {
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return err
+		}
+		last := err != nil
+		var m int
+		if !last {
+			m, err = io.ReadFull(src, next)
+			// a full chunk followed by nothing is the last one
+			last = m == 0 && err == io.EOF
+		}
+
+		out = aead.Seal(out[:4], archiveNonce(prefix, chunk, last), cur[:n], nil)
+		binary.BigEndian.PutUint32(out, uint32(len(out)-4))
+		if _, werr := dst.Write(out); werr != nil {
+			return werr
+		}
+		if last {
+			return nil
+		}
+		cur, next, n = next, cur, m
+	}
+}
+
+// DecryptArchive decrypts src, encrypted by EncryptArchive, into dst
+func DecryptArchive(dst io.Writer, src io.Reader, key []byte) error {
+	aead, err := newArchiveCipher(key)
+	if err != nil {
+		return err
+	}
+	header := make([]byte, len(encryptionMagic)+encryptionPrefixSize)
+	if _, err := io.ReadFull(src, header); err != nil || string(header[:len(encryptionMagic)]) != encryptionMagic {
+		return ErrArchiveCorrupt
+	}
+	prefix := header[len(encryptionMagic):]
+
+	var size [4]byte
+	sealed := make([]byte, encryptionChunkSize+aead.Overhead())
+	var plain []byte
+	for chunk := uint32(0); ; chunk++ {
+		if _, err := io.ReadFull(src, size[:]); err != nil {
+			// the last chunk was never seen
+			return ErrArchiveCorrupt
+		}
+		n := binary.BigEndian.Uint32(size[:])
+		if int(n) > len(sealed) {
+			return ErrArchiveCorrupt
+		}
+		if _, err := io.ReadFull(src, sealed[:n]); err != nil {
+			return ErrArchiveCorrupt
+		}
+		last := false
+		var err error
+		plain, err = aead.Open(plain[:0], archiveNonce(prefix, chunk, false), sealed[:n], nil)
+		if err != nil {
+			plain, err = aead.Open(plain[:0], archiveNonce(prefix, chunk, true), sealed[:n], nil)
+			last = true
+		}
+		if err != nil {
+			return ErrArchiveCorrupt
+		}
+		if _, err := dst.Write(plain); err != nil {
+			return err
+		}
+		if last {
+			return nil
+		}
+	}
+}
+
+func archiveNonce(prefix []byte, chunk uint32, last bool) []byte {
+	nonce := make([]byte, encryptionNonceLength)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[encryptionPrefixSize:], chunk)
+	if last {
+		nonce[encryptionNonceLength-1] = encryptionLastChunk
+	}
+	return nonce
+}
+
+// ReadArchive returns the content of a rotated file, decrypting it with key
+// when its name ends with EncryptedExtension and decompressing it when it ends
+// with ".gz" or ".zst"
+func ReadArchive(path string, key []byte) ([]byte, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	name := path
+	if strings.HasSuffix(name, EncryptedExtension) {
+		var plain bytes.Buffer
+		if err := DecryptArchive(&plain, bytes.NewReader(content), key); err != nil {
+			return nil, fmt.Errorf("read archive %s: %w", path, err)
+		}
+		content, name = plain.Bytes(), strings.TrimSuffix(name, EncryptedExtension)
+	}
+
+	switch {
+	case strings.HasSuffix(name, ".gz"):
+		zr, err := gzip.NewReader(bytes.NewReader(content))
+		if err != nil {
+			return nil, fmt.Errorf("read archive %s: %w", path, err)
+		}
+		defer zr.Close()
+		return io.ReadAll(zr)
+	case strings.HasSuffix(name, ".zst"):
+		zr, err := zstd.NewReader(bytes.NewReader(content))
+		if err != nil {
+			return nil, fmt.Errorf("read archive %s: %w", path, err)
+		}
+		defer zr.Close()
+		return io.ReadAll(zr)
+	}
+	return content, nil
+}
@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptArchive(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	for _, size := range []int{0, 10, encryptionChunkSize, 3*encryptionChunkSize + 5} {
+		data := bytes.Repeat([]byte("a"), size)
+		var sealed bytes.Buffer
+		require.NoError(t, EncryptArchive(&sealed, bytes.NewReader(data), key))
+		assert.NotContains(t, sealed.String(), "aaaaaaaa")
+
+		var plain bytes.Buffer
+		require.NoError(t, DecryptArchive(&plain, bytes.NewReader(sealed.Bytes()), key), size)
+		assert.Equal(t, string(data), plain.String())
+
+		// a truncated archive is detected
+		truncated := sealed.Bytes()[:sealed.Len()-1]
+		assert.ErrorIs(t, DecryptArchive(&plain, bytes.NewReader(truncated), key), ErrArchiveCorrupt)
+	}
+
+	var sealed bytes.Buffer
+	require.NoError(t, EncryptArchive(&sealed, strings.NewReader("secret"), key))
+	wrong := bytes.Repeat([]byte{8}, 32)
+	assert.ErrorIs(t, DecryptArchive(&bytes.Buffer{}, &sealed, wrong), ErrArchiveCorrupt)
+	assert.Error(t, EncryptArchive(&sealed, strings.NewReader("secret"), []byte("short")))
+}
+
+func TestRotatingFileHook_EncryptionKey(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 16)
+	for name, cfg := range map[string]RotatingFileConfig{
+		"plain":      {EncryptionKey: key},
+		"compressed": {EncryptionKey: key, Compress: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			cfg.Filename = filepath.Join(dir, "app.log")
+			logger, hook := newCompressedFileHook(t, &cfg)
+
+			logger.Info("card 4111")
+			require.NoError(t, logger.ForceRotateAll())
+			require.NoError(t, hook.Close())
+
+			archives, err := filepath.Glob(filepath.Join(dir, "app-*"+EncryptedExtension))
+			require.NoError(t, err)
+			require.Len(t, archives, 1)
+			if cfg.Compress {
+				assert.True(t, strings.HasSuffix(archives[0], ".log.gz.enc"))
+			}
+			raw, err := os.ReadFile(archives[0])
+			require.NoError(t, err)
+			assert.NotContains(t, string(raw), "card 4111")
+
+			content, err := ReadArchive(archives[0], key)
+			require.NoError(t, err)
+			assert.Contains(t, string(content), "card 4111")
+
+			stats, _ := logger.Stats()
+			require.Len(t, stats.Files, 1)
+			assert.Equal(t, 1, stats.Files[0].Backups)
+			assert.Zero(t, stats.Files[0].CompressionBacklog)
+		})
+	}
+
+	_, err := newRotatingFileHook(&RotatingFileConfig{Filename: filepath.Join(t.TempDir(), "app.log"), EncryptionKey: []byte("short")})
+	assert.Error(t, err)
+}
@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Field keys added by WithError besides the error itself
+const (
+	// ErrorCauseKey prefixes the messages of the errors wrapped by the error,
+	// numbered from the outermost: error.cause.1, error.cause.2, ...
+	ErrorCauseKey = "error.cause"
+	// ErrorStackKey holds the stack trace carried by the error, if any
+	ErrorStackKey = "error.stack"
+
+	// maxErrorCauses bounds the wrapped errors added as fields
+	maxErrorCauses = 10
+)
+
+// WithError returns a logger adding err under the error field to every entry,
+// along with the messages of the errors it wraps as error.cause.N fields and,
+// when an error of the chain carries a stack trace (e.g. one created with
+// github.com/pkg/errors), the deepest one in the error.stack field
+func (l *Logger) WithError(err error) Interface {
+	return &Logger{Entry: l.Entry.WithFields(errorFields(err))}
+}
+
+// WithError returns the global logger adding err and its causes to every entry,
+// see Logger.WithError
+func WithError(err error) *Logger {
+	return &Logger{Entry: global().WithFields(errorFields(err))}
+}
+
+// errorFields returns the fields describing err
+func errorFields(err error) Fields {
+	f := Fields{logrus.ErrorKey: err}
+	var stack string
+	n := 0
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if e != err && n < maxErrorCauses {
+			n++
+			f[ErrorCauseKey+"."+strconv.Itoa(n)] = e.Error()
+		}
+		if s, ok := errorStack(e); ok {
+			stack = s
+		}
+	}
+	if stack != "" {
+		f[ErrorStackKey] = stack
+	}
+	return f
+}
+
+// errorStack renders the stack trace of err when it has a StackTrace method
+// returning program counters, as the errors of github.com/pkg/errors do
+func errorStack(err error) (string, bool) {
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() {
+		return "", false
+	}
+	t := m.Type()
+	if t.NumIn() != 0 || t.NumOut() != 1 || t.Out(0).Kind() != reflect.Slice || t.Out(0).Elem().Kind() != reflect.Uintptr {
+		return "", false
+	}
+	frames := m.Call(nil)[0]
+	pcs := make([]uintptr, frames.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(frames.Index(i).Uint())
+	}
+	if len(pcs) == 0 {
+		return "", false
+	}
+	return formatStack(pcs), true
+}
@@ -0,0 +1,52 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stackFrame and stackError mimic the errors of github.com/pkg/errors
+type stackFrame uintptr
+
+type stackError struct {
+	msg   string
+	stack []stackFrame
+}
+
+func newStackError(msg string) *stackError {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	e := &stackError{msg: msg}
+	for _, pc := range pcs[:n] {
+		e.stack = append(e.stack, stackFrame(pc))
+	}
+	return e
+}
+
+func (e *stackError) Error() string            { return e.msg }
+func (e *stackError) StackTrace() []stackFrame { return e.stack }
+
+func TestWithError(t *testing.T) {
+	logger, err := NewLogger(WithNullOutput(), WithLastEntriesCapture(10))
+	require.NoError(t, err)
+
+	root := newStackError("connection refused")
+	wrapped := fmt.Errorf("charge card: %w", fmt.Errorf("call gateway: %w", root))
+	logger.WithError(wrapped).Error("payment failed")
+	WithError(errors.New("plain")).Warn("global")
+
+	crumbs := logger.Breadcrumbs(0)
+	require.Len(t, crumbs, 2)
+	data := crumbs[0].Data
+	assert.Equal(t, wrapped, data[logrus.ErrorKey])
+	assert.Equal(t, "call gateway: connection refused", data[ErrorCauseKey+".1"])
+	assert.Equal(t, "connection refused", data[ErrorCauseKey+".2"])
+	assert.Contains(t, data[ErrorStackKey], "logger.TestWithError\n\t")
+	assert.Equal(t, Fields{logrus.ErrorKey: errors.New("plain")}, crumbs[1].Data)
+}
@@ -0,0 +1,197 @@
+package logger
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultFailoverMaxFailures   = 3
+	DefaultFailoverProbeInterval = 30 * time.Second
+)
+
+// FailoverConfig holds configuration for the failover wrappers
+type FailoverConfig struct {
+	// MaxFailures is the number of consecutive primary failures after which
+	// entries are routed to the secondary, defaults to DefaultFailoverMaxFailures
+	MaxFailures int
+	// ProbeInterval is how often an entry is tried on the primary again while
+	// failed over, defaults to DefaultFailoverProbeInterval
+	ProbeInterval time.Duration
+	// OnSwitch is called when entries start going to the secondary (failover is
+	// true) or back to the primary
+	OnSwitch func(failover bool, err error)
+}
+
+// failoverState tracks consecutive primary failures and when to probe the
+// primary again. It is shared by the hook and writer wrappers.
+type failoverState struct {
+	cfg        FailoverConfig
+	mu         sync.Mutex
+	failures   int
+	failedOver bool
+	nextProbe  time.Time
+	switches   atomic.Uint64
+}
+
+func newFailoverState(cfg *FailoverConfig) *failoverState {
+	c := FailoverConfig{}
+	if cfg != nil {
+		c = *cfg
+	}
+	if c.MaxFailures <= 0 {
+		c.MaxFailures = DefaultFailoverMaxFailures
+	}
+	if c.ProbeInterval <= 0 {
+		c.ProbeInterval = DefaultFailoverProbeInterval
+	}
+	return &failoverState{cfg: c}
+}
+
+// usePrimary reports whether the next entry should be sent to the primary,
+// either because it is healthy or because a recovery probe is due
+func (s *failoverState) usePrimary() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.failedOver {
+		return true
+	}
+	if time.Now().Before(s.nextProbe) {
+		return false
+	}
+	s.nextProbe = time.Now().Add(s.cfg.ProbeInterval)
+	return true
+}
+
+// result records the outcome of sending an entry to the primary
+func (s *failoverState) result(err error) {
+	s.mu.Lock()
+	var switched, failover bool
+	if err == nil {
+		s.failures = 0
+		if s.failedOver {
+			s.failedOver = false
+			switched = true
+		}
+	} else {
+		s.failures++
+		if !s.failedOver && s.failures >= s.cfg.MaxFailures {
+			s.failedOver = true
+			s.nextProbe = time.Now().Add(s.cfg.ProbeInterval)
+			switched, failover = true, true
+		}
+	}
+	s.mu.Unlock()
+
+	if switched {
+		s.switches.Add(1)
+		if s.cfg.OnSwitch != nil {
+			s.cfg.OnSwitch(failover, err)
+		}
+	}
+}
+
+// FailedOver reports whether entries are currently routed to the secondary
+func (s *failoverState) FailedOver() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.failedOver
+}
+
+// Switches returns the number of switches between the primary and secondary
+func (s *failoverState) Switches() uint64 {
+	return s.switches.Load()
+}
+
+// failoverHook implements logrus.Hook sending entries to a primary hook and
+// failing over to a secondary hook when the primary errors repeatedly. Entries
+// the primary fails to handle are sent to the secondary, so none are lost.
+type failoverHook struct {
+	*failoverState
+	primary   logrus.Hook
+	secondary logrus.Hook
+}
+
+// NewFailoverHook wraps primary so its entries go to secondary (e.g. a local
+// file hook) while primary is failing
+func NewFailoverHook(primary, secondary logrus.Hook, cfg *FailoverConfig) *failoverHook {
+	return &failoverHook{failoverState: newFailoverState(cfg), primary: primary, secondary: secondary}
+}
+
+// WithFailover adds primary as a hook failing over to secondary
+func WithFailover(primary, secondary logrus.Hook, cfg *FailoverConfig) Option {
+	return func(l *Logger) error {
+		l.Entry.Logger.AddHook(NewFailoverHook(primary, secondary, cfg))
+		return nil
+	}
+}
+
+// Levels returns the levels of the primary hook
+func (h *failoverHook) Levels() []logrus.Level {
+	return h.primary.Levels()
+}
+
+func (h *failoverHook) Fire(entry *logrus.Entry) error {
+	if h.usePrimary() {
+		err := h.primary.Fire(entry)
+		h.result(err)
+		if err == nil {
+			return nil
+		}
+	}
+	return h.secondary.Fire(entry)
+}
+
+// Close closes both hooks
+func (h *failoverHook) Close() error {
+	return closeBoth(h.primary, h.secondary)
+}
+
+// failoverWriter is an io.Writer writing to a primary writer and failing over to
+// a secondary writer when the primary errors repeatedly
+type failoverWriter struct {
+	*failoverState
+	primary   io.Writer
+	secondary io.Writer
+}
+
+// NewFailoverWriter wraps primary so writes go to secondary while primary is
+// failing, e.g. to use with WithOutput
+func NewFailoverWriter(primary, secondary io.Writer, cfg *FailoverConfig) *failoverWriter {
+	return &failoverWriter{failoverState: newFailoverState(cfg), primary: primary, secondary: secondary}
+}
+
+func (w *failoverWriter) Write(p []byte) (int, error) {
+	if w.usePrimary() {
+		n, err := w.primary.Write(p)
+		if err == nil && n < len(p) {
+			err = io.ErrShortWrite
+		}
+		w.result(err)
+		if err == nil {
+			return n, nil
+		}
+	}
+	return w.secondary.Write(p)
+}
+
+// Close closes both writers when they implement io.Closer
+func (w *failoverWriter) Close() error {
+	return closeBoth(w.primary, w.secondary)
+}
+
+// closeBoth closes a and b when they implement io.Closer
+func closeBoth(a, b interface{}) error {
+	var errs []error
+	for _, v := range []interface{}{a, b} {
+		if c, ok := v.(io.Closer); ok {
+			errs = append(errs, c.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
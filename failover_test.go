@@ -0,0 +1,92 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyHook is a hook failing while broken is set
+type flakyHook struct {
+	mu       sync.Mutex
+	broken   bool
+	messages []string
+}
+
+func (h *flakyHook) Levels() []logrus.Level { return logrus.AllLevels }
+
+func (h *flakyHook) Fire(entry *logrus.Entry) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.broken {
+		return errors.New("unavailable")
+	}
+	h.messages = append(h.messages, entry.Message)
+	return nil
+}
+
+func (h *flakyHook) setBroken(broken bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.broken = broken
+}
+
+func TestFailoverHook(t *testing.T) {
+	primary := &flakyHook{}
+	secondary := &flakyHook{}
+	var switches []bool
+	hook := NewFailoverHook(primary, secondary, &FailoverConfig{
+		MaxFailures:   2,
+		ProbeInterval: 20 * time.Millisecond,
+		OnSwitch:      func(failover bool, err error) { switches = append(switches, failover) },
+	})
+
+	logger, err := NewLogger(WithNullOutput())
+	require.NoError(t, err)
+	logger.Logger.AddHook(hook)
+
+	logger.Info("1")
+	primary.setBroken(true)
+	logger.Info("2")
+	assert.False(t, hook.FailedOver())
+	logger.Info("3")
+	assert.True(t, hook.FailedOver())
+	logger.Info("4")
+
+	// primary recovers and is probed again after the interval
+	primary.setBroken(false)
+	logger.Info("5")
+	time.Sleep(30 * time.Millisecond)
+	logger.Info("6")
+	assert.False(t, hook.FailedOver())
+
+	assert.Equal(t, []string{"1", "6"}, primary.messages)
+	assert.Equal(t, []string{"2", "3", "4", "5"}, secondary.messages)
+	assert.Equal(t, []bool{true, false}, switches)
+	assert.Equal(t, uint64(2), hook.Switches())
+}
+
+// brokenWriter fails every write
+type brokenWriter struct{}
+
+func (brokenWriter) Write(p []byte) (int, error) { return 0, errors.New("broken pipe") }
+
+func TestFailoverWriter(t *testing.T) {
+	var fallback bytes.Buffer
+	w := NewFailoverWriter(brokenWriter{}, &fallback, &FailoverConfig{MaxFailures: 1, ProbeInterval: time.Hour})
+
+	logger, err := NewLogger(WithOutput(w), WithFormatter(&PlainFormatter{}))
+	require.NoError(t, err)
+	logger.Info("first")
+	logger.Info("second")
+
+	assert.Equal(t, "INFO first\nINFO second\n", fallback.String())
+	assert.True(t, w.FailedOver())
+	require.NoError(t, w.Close())
+}
@@ -0,0 +1,279 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+)
+
+// DefaultFatalFlushTimeout bounds the time spent flushing sinks before a fatal
+// entry exits the process
+const DefaultFatalFlushTimeout = 5 * time.Second
+
+// WithExitFunc calls exit instead of os.Exit when Fatal terminates the process,
+// once the exit handlers ran and the sinks were flushed, so libraries and tests
+// can intercept Fatal. Fatal returns when exit does, so the sinks are flushed
+// but left open.
+func WithExitFunc(exit func(code int)) Option {
+	return func(l *Logger) error {
+		l.Entry.Logger.ExitFunc = exit
+		return nil
+	}
+}
+
+// WithFatalExitCode makes Fatal exit the process with code rather than 1
+func WithFatalExitCode(code int) Option {
+	return func(l *Logger) error {
+		if code < 0 || code > 255 {
+			return fmt.Errorf("fatal exit code: %d out of range 0-255", code)
+		}
+		useFatalExit(l.Entry.Logger).code = code
+		return nil
+	}
+}
+
+// fatalExit holds the exit code set with WithFatalExitCode. It registers like a
+// hook for the fatal level so it can be found, but firing it does nothing.
+type fatalExit struct {
+	code int
+}
+
+// useFatalExit returns the fatal exit registered on l, registering one first
+// when needed
+func useFatalExit(l *logrus.Logger) *fatalExit {
+	if h, ok := findHook[*fatalExit](l); ok {
+		return h
+	}
+	h := &fatalExit{code: 1}
+	l.AddHook(h)
+	return h
+}
+
+func (h *fatalExit) Levels() []logrus.Level {
+	return []logrus.Level{logrus.FatalLevel}
+}
+
+func (h *fatalExit) Fire(*logrus.Entry) error {
+	return nil
+}
+
+// fatalExitFunc returns the exit func of l, exiting with the code set with
+// WithFatalExitCode when Fatal exits with 1
+func fatalExitFunc(l *logrus.Logger) func(int) {
+	exit := l.ExitFunc
+	if exit == nil {
+		exit = os.Exit
+	}
+	h, ok := findHook[*fatalExit](l)
+	if !ok {
+		return exit
+	}
+	return func(code int) {
+		if code == 1 {
+			code = h.code
+		}
+		exit(code)
+	}
+}
+
+var (
+	exitHandlersMu sync.Mutex
+	exitHandlers   []func()
+)
+
+// RegisterExitHandler registers handler to run when Fatal exits the process,
+// before the sinks are flushed, e.g. to release resources or log a last entry.
+// Handlers run in registration order, for DefaultFatalFlushTimeout at most, and
+// a panicking handler doesn't prevent the exit.
+func RegisterExitHandler(handler func()) {
+	exitHandlersMu.Lock()
+	defer exitHandlersMu.Unlock()
+	exitHandlers = append(exitHandlers, handler)
+}
+
+// runExitHandlers runs the registered exit handlers, waiting at most timeout
+func runExitHandlers(timeout time.Duration) {
+	exitHandlersMu.Lock()
+	handlers := append([]func(){}, exitHandlers...)
+	exitHandlersMu.Unlock()
+	if len(handlers) == 0 {
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, handler := range handlers {
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						fmt.Fprintf(os.Stderr, "logger: exit handler panicked: %v\n", r)
+					}
+				}()
+				handler()
+			}()
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		fmt.Fprintf(os.Stderr, "logger: exit handlers timed out after %s\n", timeout)
+	}
+}
+
+// flushOnExit returns an exit function that runs the exit handlers and flushes
+// the sinks of l before calling exit, so entries buffered by hooks and the
+// output (including the fatal entry itself) aren't lost when Fatal terminates
+// the process. The sinks are only closed when exiting with os.Exit: a custom
+// exit func, set with WithExitFunc, may return and the logger keep being used.
+func flushOnExit(l *logrus.Logger, exit func(int), custom bool, timeout time.Duration) func(int) {
+	if exit == nil {
+		exit = os.Exit
+	}
+	flush := flushSinks
+	if custom {
+		flush = flushHooks
+	}
+	return func(code int) {
+		runExitHandlers(timeout)
+		if err := flush(l, timeout); err != nil {
+			fmt.Fprintf(os.Stderr, "logger: flushing sinks on exit: %v\n", err)
+		}
+		exit(code)
+	}
+}
+
+// flushOnPanic flushes the sinks of l without closing them when the caller
+// panics, then panics again, so the panic entry reaches remote sinks even when
+// the process crashes. It must be deferred.
+func flushOnPanic(l *logrus.Logger) {
+	if r := recover(); r != nil {
+		if err := flushHooks(l, DefaultFatalFlushTimeout); err != nil {
+			fmt.Fprintf(os.Stderr, "logger: flushing sinks on panic: %v\n", err)
+		}
+		panic(r)
+	}
+}
+
+// Panic logs a message at the panic level, flushes the sinks without closing
+// them and panics
+func (l *Logger) Panic(args ...interface{}) {
+	defer flushOnPanic(l.Entry.Logger)
+	l.Entry.Panic(args...)
+}
+
+// Panicf logs a formatted message at the panic level, flushes the sinks without
+// closing them and panics
+func (l *Logger) Panicf(format string, args ...interface{}) {
+	defer flushOnPanic(l.Entry.Logger)
+	l.Entry.Panicf(format, args...)
+}
+
+// Panicln logs a message at the panic level, flushes the sinks without closing
+// them and panics
+func (l *Logger) Panicln(args ...interface{}) {
+	defer flushOnPanic(l.Entry.Logger)
+	l.Entry.Panicln(args...)
+}
+
+// flushSinks closes every hook of l that can be closed and flushes its output,
+// in parallel, waiting at most timeout
+func flushSinks(l *logrus.Logger, timeout time.Duration) error {
+	flushRules(l)
+	funcs := sinkClosers(l)
+	if flush := outputFlusher(l.Out); flush != nil {
+		funcs = append(funcs, flush)
+	}
+	return runWithin(timeout, funcs)
+}
+
+// flushHooks flushes the hooks of l buffering entries and its output, in
+// parallel and without closing them, waiting at most timeout
+func flushHooks(l *logrus.Logger, timeout time.Duration) error {
+	outputs := []io.Writer{l.Out}
+	if d, ok := findHook[*asyncDispatcher](l); ok && d.shadow != nil {
+		outputs = append(outputs, d.shadow.Out)
+	}
+	flushRules(l)
+	var funcs []func() error
+	for _, hook := range allHooks(l) {
+		if _, ok := hook.(*rulesHook); ok {
+			continue
+		}
+		if f, ok := hook.(interface{ Flush() error }); ok {
+			funcs = append(funcs, f.Flush)
+		}
+	}
+	for _, out := range outputs {
+		if c, ok := out.(*countingSink); ok {
+			// countingSink closes the writers it can't flush
+			out = c.w
+		}
+		switch w := out.(type) {
+		case interface{ Flush() error }:
+			funcs = append(funcs, w.Flush)
+		case *os.File:
+			funcs = append(funcs, func() error {
+				w.Sync()
+				return nil
+			})
+		}
+	}
+	return runWithin(timeout, funcs)
+}
+
+// runWithin runs funcs in parallel, waiting at most timeout. A failing func
+// doesn't cancel the others, and funcs still running at the deadline are left
+// behind.
+func runWithin(timeout time.Duration, funcs []func() error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	var g errgroup.Group
+	for _, fn := range funcs {
+		g.Go(fn)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- g.Wait() }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("timed out after %s", timeout)
+	}
+}
+
+// sinkClosers returns the Close methods of the distinct hooks registered on l
+func sinkClosers(l *logrus.Logger) []func() error {
+	var closers []func() error
+	for _, hook := range registeredHooks(l) {
+		if c, ok := hook.(io.Closer); ok {
+			closers = append(closers, c.Close)
+		}
+	}
+	return closers
+}
+
+// outputFlusher returns a func flushing w, or nil when w doesn't buffer. The
+// standard streams are synced but never closed.
+func outputFlusher(w io.Writer) func() error {
+	switch out := w.(type) {
+	case interface{ Flush() error }:
+		return out.Flush
+	case *os.File:
+		return func() error {
+			// syncing a pipe or terminal fails harmlessly
+			out.Sync()
+			return nil
+		}
+	case io.Closer:
+		return out.Close
+	}
+	return nil
+}
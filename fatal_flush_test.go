@@ -0,0 +1,181 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// closeRecorder is a hook recording when it is closed
+type closeRecorder struct {
+	delay  time.Duration
+	closed atomic.Bool
+}
+
+func (h *closeRecorder) Levels() []logrus.Level         { return logrus.AllLevels }
+func (h *closeRecorder) Fire(entry *logrus.Entry) error { return nil }
+func (h *closeRecorder) Close() error {
+	time.Sleep(h.delay)
+	h.closed.Store(true)
+	return nil
+}
+
+func TestFatal_FlushesSinksBeforeExit(t *testing.T) {
+	var buf bytes.Buffer
+	out := bufio.NewWriter(&buf)
+	hooks := []*flushRecorder{{closeRecorder: closeRecorder{delay: 50 * time.Millisecond}}, {closeRecorder: closeRecorder{delay: 50 * time.Millisecond}}}
+
+	exitCode := -1
+	var flushedAtExit []bool
+	logger, err := NewLogger(WithOutput(out), WithFormatter(&PlainFormatter{}), WithExitFunc(func(code int) {
+		exitCode = code
+		for _, h := range hooks {
+			flushedAtExit = append(flushedAtExit, h.flushed.Load())
+		}
+	}))
+	require.NoError(t, err)
+	for _, h := range hooks {
+		logger.Logger.AddHook(h)
+	}
+
+	start := time.Now()
+	logger.Fatal("shutting down")
+
+	assert.Equal(t, 1, exitCode)
+	assert.Equal(t, []bool{true, true}, flushedAtExit)
+	assert.Equal(t, "FATAL shutting down\n", buf.String())
+	// hooks are flushed in parallel
+	assert.Less(t, time.Since(start), 90*time.Millisecond)
+	// the exit func returned, the sinks are left open
+	for _, h := range hooks {
+		assert.False(t, h.closed.Load())
+	}
+}
+
+func TestFlushOnExit_ClosesSinksWithoutCustomExit(t *testing.T) {
+	l := logrus.New()
+	hook := &flushRecorder{}
+	l.AddHook(hook)
+
+	exitCode := -1
+	flushOnExit(l, func(code int) { exitCode = code }, false, time.Second)(1)
+	assert.Equal(t, 1, exitCode)
+	assert.True(t, hook.closed.Load())
+}
+
+func TestFatal_FlushIsBounded(t *testing.T) {
+	slow := &closeRecorder{delay: time.Second}
+	err := func() error {
+		l := logrus.New()
+		l.AddHook(slow)
+		return flushSinks(l, 10*time.Millisecond)
+	}()
+	assert.Error(t, err)
+	assert.False(t, slow.closed.Load())
+}
+
+// failingCloser is a hook failing to close
+type failingCloser struct{}
+
+func (failingCloser) Levels() []logrus.Level   { return logrus.AllLevels }
+func (failingCloser) Fire(*logrus.Entry) error { return nil }
+func (failingCloser) Close() error             { return errors.New("close failed") }
+
+func TestFatal_FlushIsBoundedWhenASinkFails(t *testing.T) {
+	l := logrus.New()
+	l.AddHook(failingCloser{})
+	l.AddHook(&closeRecorder{delay: time.Second})
+
+	start := time.Now()
+	err := flushSinks(l, 20*time.Millisecond)
+	assert.ErrorContains(t, err, "timed out")
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
+
+// flushRecorder is a hook recording when it is flushed
+type flushRecorder struct {
+	closeRecorder
+	flushed atomic.Bool
+}
+
+func (h *flushRecorder) Flush() error {
+	time.Sleep(h.delay)
+	h.flushed.Store(true)
+	return nil
+}
+
+func TestFatal_RunsExitHandlers(t *testing.T) {
+	prev := exitHandlers
+	t.Cleanup(func() { exitHandlers = prev })
+	exitHandlers = nil
+
+	hook := &flushRecorder{}
+	var order []string
+	var flushedInHandler bool
+	RegisterExitHandler(func() { order = append(order, "first") })
+	RegisterExitHandler(func() { panic("broken handler") })
+	RegisterExitHandler(func() {
+		order = append(order, "last")
+		flushedInHandler = hook.flushed.Load()
+	})
+
+	exitCode := -1
+	logger, err := NewLogger(WithNullOutput(), WithHooks(hook), WithExitFunc(func(code int) { exitCode = code }))
+	require.NoError(t, err)
+	logger.Fatal("shutting down")
+
+	assert.Equal(t, 1, exitCode)
+	assert.Equal(t, []string{"first", "last"}, order)
+	// handlers run before the sinks are flushed
+	assert.False(t, flushedInHandler)
+	assert.True(t, hook.flushed.Load())
+}
+
+func TestPanic_FlushesSinks(t *testing.T) {
+	hook := &flushRecorder{}
+	logger, err := NewLogger(WithNullOutput(), WithHooks(hook))
+	require.NoError(t, err)
+
+	assert.Panics(t, func() { logger.Panic("crashing") })
+	assert.True(t, hook.flushed.Load())
+	// the process may recover, the sinks stay open
+	assert.False(t, hook.closed.Load())
+
+	hook.flushed.Store(false)
+	assert.Panics(t, func() { logger.Panicf("crashing %d", 2) })
+	assert.True(t, hook.flushed.Load())
+}
+
+func TestWithFatalExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		want int
+	}{
+		{name: "default", want: 1},
+		{name: "code", opts: []Option{WithFatalExitCode(3)}, want: 3},
+		{name: "async", opts: []Option{WithFatalExitCode(4), WithAsync(16, Block)}, want: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exitCode := -1
+			// the exit func can be set after the code
+			opts := append(append([]Option{WithNullOutput()}, tt.opts...), WithExitFunc(func(code int) { exitCode = code }))
+			logger, err := NewLogger(opts...)
+			require.NoError(t, err)
+
+			logger.Fatal("shutting down")
+			assert.Equal(t, tt.want, exitCode)
+		})
+	}
+
+	_, err := NewLogger(WithFatalExitCode(256))
+	assert.Error(t, err)
+}
@@ -0,0 +1,121 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// FieldEncoder converts a field value before it reaches hooks and formatters
+type FieldEncoder func(interface{}) interface{}
+
+var (
+	fieldEncodersMu sync.RWMutex
+	// fieldEncoders holds encoders registered for concrete types
+	fieldEncoders = map[reflect.Type]FieldEncoder{}
+	// interfaceEncoders holds encoders registered for interface types, in
+	// registration order
+	interfaceEncoders []interfaceEncoder
+)
+
+type interfaceEncoder struct {
+	typ reflect.Type
+	enc FieldEncoder
+}
+
+// RegisterFieldEncoder registers enc for field values of type typ, so domain types
+// are encoded consistently regardless of the formatter in use. typ may be an
+// interface type (e.g. fmt.Stringer), in which case enc applies to every value
+// implementing it that has no encoder registered for its concrete type.
+// Registering a nil encoder removes the encoder for typ.
+func RegisterFieldEncoder(typ reflect.Type, enc FieldEncoder) {
+	fieldEncodersMu.Lock()
+	defer fieldEncodersMu.Unlock()
+
+	if typ.Kind() != reflect.Interface {
+		if enc == nil {
+			delete(fieldEncoders, typ)
+			return
+		}
+		fieldEncoders[typ] = enc
+		return
+	}
+
+	for i, ie := range interfaceEncoders {
+		if ie.typ == typ {
+			if enc == nil {
+				interfaceEncoders = append(interfaceEncoders[:i], interfaceEncoders[i+1:]...)
+			} else {
+				interfaceEncoders[i].enc = enc
+			}
+			return
+		}
+	}
+	if enc != nil {
+		interfaceEncoders = append(interfaceEncoders, interfaceEncoder{typ: typ, enc: enc})
+	}
+}
+
+// StringerEncoder encodes values implementing fmt.Stringer using their String method
+func StringerEncoder(v interface{}) interface{} {
+	if s, ok := v.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return v
+}
+
+// JSONEncoder encodes values as their JSON representation
+func JSONEncoder(v interface{}) interface{} {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("!JSON(%v)", err)
+	}
+	return string(b)
+}
+
+// encodeFieldValue applies the registered encoder for v, if any
+func encodeFieldValue(v interface{}) interface{} {
+	if v == nil {
+		return v
+	}
+	typ := reflect.TypeOf(v)
+	if enc, ok := fieldEncoders[typ]; ok {
+		return enc(v)
+	}
+	for _, ie := range interfaceEncoders {
+		if typ.Implements(ie.typ) {
+			return ie.enc(v)
+		}
+	}
+	return v
+}
+
+// fieldEncoderHook implements logrus.Hook applying registered field encoders
+type fieldEncoderHook struct{}
+
+func (h *fieldEncoderHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Enriches implements EnrichingHook, it encodes the field values in place
+func (h *fieldEncoderHook) Enriches() bool {
+	return true
+}
+
+// Fire encodes the entry fields in place. logrus hands hooks a copy of the
+// entry data, so the logger's own fields are left untouched.
+func (h *fieldEncoderHook) Fire(entry *logrus.Entry) error {
+	fieldEncodersMu.RLock()
+	defer fieldEncodersMu.RUnlock()
+
+	if len(fieldEncoders) == 0 && len(interfaceEncoders) == 0 {
+		return nil
+	}
+	for key, value := range entry.Data {
+		entry.Data[key] = encodeFieldValue(value)
+	}
+	return nil
+}
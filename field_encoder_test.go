@@ -0,0 +1,89 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testUserID int
+
+type testMoney struct {
+	Cents    int64
+	Currency string
+}
+
+func (m testMoney) String() string {
+	return fmt.Sprintf("%d.%02d %s", m.Cents/100, m.Cents%100, m.Currency)
+}
+
+func TestRegisterFieldEncoder(t *testing.T) {
+	ipType := reflect.TypeOf(net.IP{})
+	userType := reflect.TypeOf(testUserID(0))
+	stringerType := reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+	t.Cleanup(func() {
+		RegisterFieldEncoder(ipType, nil)
+		RegisterFieldEncoder(userType, nil)
+		RegisterFieldEncoder(stringerType, nil)
+	})
+
+	// mask the last octet of IPv4 addresses
+	RegisterFieldEncoder(ipType, func(v interface{}) interface{} {
+		ip := v.(net.IP).To4()
+		if ip == nil {
+			return v
+		}
+		return fmt.Sprintf("%d.%d.%d.x", ip[0], ip[1], ip[2])
+	})
+	RegisterFieldEncoder(userType, func(v interface{}) interface{} {
+		return fmt.Sprintf("user-%d", v)
+	})
+	RegisterFieldEncoder(stringerType, StringerEncoder)
+
+	formatters := map[string]logrus.Formatter{
+		"text":  &logrus.TextFormatter{DisableColors: true},
+		"json":  &logrus.JSONFormatter{},
+		"plain": &PlainFormatter{},
+	}
+
+	for name, formatter := range formatters {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger, err := NewLogger(WithOutput(&buf), WithFormatter(formatter))
+			require.NoError(t, err)
+
+			logger.WithFields(logrus.Fields{
+				"client_ip": net.ParseIP("192.168.1.42"),
+				"user":      testUserID(7),
+				"amount":    testMoney{Cents: 1250, Currency: "EUR"},
+			}).Info("payment accepted")
+
+			output := buf.String()
+			assert.Contains(t, output, "192.168.1.x")
+			assert.NotContains(t, output, "192.168.1.42")
+			assert.Contains(t, output, "user-7")
+			assert.Contains(t, output, "12.50 EUR")
+		})
+	}
+}
+
+func TestFieldEncoderHook_PreservesLoggerFields(t *testing.T) {
+	userType := reflect.TypeOf(testUserID(0))
+	t.Cleanup(func() { RegisterFieldEncoder(userType, nil) })
+	RegisterFieldEncoder(userType, JSONEncoder)
+
+	var buf bytes.Buffer
+	logger, err := NewLogger(WithOutput(&buf))
+	require.NoError(t, err)
+
+	child := logger.WithField("user", testUserID(3))
+	child.Info("first")
+
+	assert.Equal(t, testUserID(3), child.Data["user"])
+}
@@ -0,0 +1,188 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultFloodWindow = 10 * time.Second
+)
+
+// DefaultCancellationPatterns are the error messages collapsed by the
+// cancellation flood rule
+var DefaultCancellationPatterns = []string{
+	"context canceled",
+	"context deadline exceeded",
+	"connection reset by peer",
+	"broken pipe",
+}
+
+// floodSummaryKey marks summary entries so the rule never collapses them
+const floodSummaryKey = "flood_summary"
+
+// CancellationFloodConfig holds configuration for the cancellation flood rule
+type CancellationFloodConfig struct {
+	Patterns []string      // substrings matched against the message and error, defaults to DefaultCancellationPatterns
+	Window   time.Duration // collapse window, defaults to DefaultFloodWindow
+	// Burst is the number of matching entries let through per window before the
+	// rest are collapsed, defaults to 1
+	Burst int
+	// SummaryLevel is the level of the summary entry, defaults to the level of
+	// the first collapsed entry. Summaries are logged at error level at most, so
+	// closing a window never exits or panics.
+	SummaryLevel *logrus.Level
+}
+
+// cancellationFloodRule implements Rule collapsing bursts of cancellation errors,
+// typical during shutdowns or client disconnects, into one summary per window
+type cancellationFloodRule struct {
+	cfg     CancellationFloodConfig
+	mu      sync.Mutex
+	windows map[string]*floodWindow
+	closed  bool // no window is opened once closed
+}
+
+// floodWindow tracks matching entries for a single pattern
+type floodWindow struct {
+	seen       int
+	suppressed int
+	level      logrus.Level
+	logger     *logrus.Logger
+	timer      *time.Timer
+}
+
+// NewCancellationFloodRule creates a rule that lets through the first Burst
+// entries matching a cancellation pattern in each window and collapses the rest
+// into a single summary entry logged when the window closes
+func NewCancellationFloodRule(cfg *CancellationFloodConfig) *cancellationFloodRule {
+	c := CancellationFloodConfig{}
+	if cfg != nil {
+		c = *cfg
+	}
+	if len(c.Patterns) == 0 {
+		c.Patterns = DefaultCancellationPatterns
+	}
+	if c.Window <= 0 {
+		c.Window = DefaultFloodWindow
+	}
+	if c.Burst <= 0 {
+		c.Burst = 1
+	}
+	return &cancellationFloodRule{cfg: c, windows: make(map[string]*floodWindow)}
+}
+
+// WithCancellationFloodControl collapses floods of context cancellation and
+// connection reset errors into one summary entry per window
+func WithCancellationFloodControl(cfg *CancellationFloodConfig) Option {
+	return WithRules(NewCancellationFloodRule(cfg))
+}
+
+// Apply reports whether the entry is part of a flood that should be collapsed
+func (r *cancellationFloodRule) Apply(entry *logrus.Entry) bool {
+	if _, ok := entry.Data[floodSummaryKey]; ok {
+		return false
+	}
+	pattern, ok := r.match(entry)
+	if !ok {
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return false
+	}
+
+	w, ok := r.windows[pattern]
+	if !ok {
+		w = &floodWindow{level: entry.Level, logger: entry.Logger}
+		r.windows[pattern] = w
+		w.timer = time.AfterFunc(r.cfg.Window, func() { r.closeWindow(pattern) })
+	}
+	w.seen++
+	if w.seen <= r.cfg.Burst {
+		return false
+	}
+	w.suppressed++
+	return true
+}
+
+// match returns the first pattern found in the entry message or error
+func (r *cancellationFloodRule) match(entry *logrus.Entry) (string, bool) {
+	var errText string
+	if v, ok := entry.Data[logrus.ErrorKey]; ok {
+		if err, ok := v.(error); ok {
+			if errors.Is(err, context.Canceled) {
+				return "context canceled", true
+			}
+			errText = strings.ToLower(err.Error())
+		} else {
+			errText = strings.ToLower(fmt.Sprint(v))
+		}
+	}
+	msg := strings.ToLower(entry.Message)
+	for _, pattern := range r.cfg.Patterns {
+		p := strings.ToLower(pattern)
+		if strings.Contains(msg, p) || strings.Contains(errText, p) {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+// closeWindow ends the window for pattern and logs a summary of what was collapsed
+func (r *cancellationFloodRule) closeWindow(pattern string) {
+	r.mu.Lock()
+	w := r.windows[pattern]
+	delete(r.windows, pattern)
+	r.mu.Unlock()
+
+	if w == nil || w.suppressed == 0 {
+		return
+	}
+	level := w.level
+	if r.cfg.SummaryLevel != nil {
+		level = *r.cfg.SummaryLevel
+	}
+	if level < logrus.ErrorLevel {
+		level = logrus.ErrorLevel
+	}
+	w.logger.WithFields(logrus.Fields{
+		floodSummaryKey: true,
+		"pattern":       pattern,
+		"suppressed":    w.suppressed,
+		"window":        r.cfg.Window.String(),
+	}).Log(level, fmt.Sprintf("suppressed %d entries matching %q", w.suppressed, pattern))
+}
+
+// Flush closes all open windows immediately, logging their summaries
+func (r *cancellationFloodRule) Flush() {
+	r.mu.Lock()
+	patterns := make([]string, 0, len(r.windows))
+	for pattern, w := range r.windows {
+		w.timer.Stop()
+		patterns = append(patterns, pattern)
+	}
+	r.mu.Unlock()
+
+	for _, pattern := range patterns {
+		r.closeWindow(pattern)
+	}
+}
+
+// Close logs the summaries of the open windows and stops opening new ones, so
+// no timer logs through the logger once its sinks are closed
+func (r *cancellationFloodRule) Close() error {
+	r.mu.Lock()
+	r.closed = true
+	r.mu.Unlock()
+	r.Flush()
+	return nil
+}
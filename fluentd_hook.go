@@ -0,0 +1,292 @@
+package logger
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+const (
+	DefaultFluentdTag        = "app"
+	DefaultFluentdTimeout    = 3 * time.Second
+	DefaultFluentdMaxBackoff = 30 * time.Second
+	DefaultFluentdQueueSize  = 10000
+)
+
+// FluentdConfig holds configuration for the Fluentd forward hook
+type FluentdConfig struct {
+	Address string // host:port of the fluentd/fluent-bit forward input
+	// Tag is the event tag. Placeholders in braces are replaced with entry fields,
+	// e.g. "app.{service}.{level}"; missing fields render as "unknown".
+	Tag        string
+	RequireAck bool          // wait for the server to acknowledge every event
+	Timeout    time.Duration // dial, write and ack timeout
+	MaxBackoff time.Duration // maximum delay between reconnection attempts
+	TLS        *TLSConfig    // connect over TLS, e.g. to a secure forward input
+	QueueSize  int           // events queued for the background sender
+	Block      bool          // block logging when the queue is full instead of dropping
+	// Retry retries events failing to send, by default once right after
+	// reconnecting. Events given up on are passed to its OnFailure.
+	Retry  *RetryPolicy
+	Levels []logrus.Level
+}
+
+// fluentdHook implements logrus.Hook sending entries with the Fluentd forward
+// protocol. Entries are queued and sent by a background worker, the only user of
+// the connection.
+type fluentdHook struct {
+	cfg     FluentdConfig
+	tls     *tls.Config
+	conn    net.Conn
+	reader  *bufio.Reader
+	retry   RetryPolicy
+	batcher *Batcher[fluentdMessage]
+
+	// reconnection state
+	backoff   time.Duration
+	nextRetry time.Time
+
+	// err is the error of the last delivery, nil once one succeeds
+	errMu sync.Mutex
+	err   error
+}
+
+// fluentdMessage is an encoded event and the chunk id its ack must carry
+type fluentdMessage struct {
+	msg   []byte
+	chunk string
+}
+
+// fluentdEventTime is the forward protocol EventTime extension (type 0)
+type fluentdEventTime time.Time
+
+func (t fluentdEventTime) EncodeMsgpack(enc *msgpack.Encoder) error {
+	if err := enc.EncodeExtHeader(0, 8); err != nil {
+		return err
+	}
+	tm := time.Time(t)
+	var b [8]byte
+	binary.BigEndian.PutUint32(b[:4], uint32(tm.Unix()))
+	binary.BigEndian.PutUint32(b[4:], uint32(tm.Nanosecond()))
+	_, err := enc.Writer().Write(b[:])
+	return err
+}
+
+// NewFluentdHook creates a new hook forwarding entries to fluentd
+func NewFluentdHook(cfg *FluentdConfig) (*fluentdHook, error) {
+	if cfg == nil || cfg.Address == "" {
+		return nil, fmt.Errorf("fluentd: address is required")
+	}
+	c := *cfg
+	if c.Tag == "" {
+		c.Tag = DefaultFluentdTag
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultFluentdTimeout
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = DefaultFluentdMaxBackoff
+	}
+	if c.QueueSize <= 0 {
+		c.QueueSize = DefaultFluentdQueueSize
+	}
+	if len(c.Levels) == 0 {
+		c.Levels = logrus.AllLevels
+	}
+
+	tlsConfig, err := c.TLS.build()
+	if err != nil {
+		return nil, fmt.Errorf("fluentd: %w", err)
+	}
+
+	hook := &fluentdHook{
+		cfg:   c,
+		tls:   tlsConfig,
+		retry: retryPolicy(c.Retry, RetryPolicy{MaxAttempts: 2, MinBackoff: time.Millisecond}),
+	}
+	// fail early on a bad address, later failures reconnect
+	if err := hook.connect(); err != nil {
+		return nil, err
+	}
+	hook.batcher = NewBatcher(1, time.Second, c.QueueSize, c.Block, hook.ship)
+	return hook, nil
+}
+
+// WithFluentd adds a hook that ships entries to fluentd/fluent-bit over the
+// forward protocol
+func WithFluentd(cfg *FluentdConfig) Option {
+	return func(l *Logger) error {
+		hook, err := NewFluentdHook(cfg)
+		if err != nil {
+			return err
+		}
+		l.Entry.Logger.AddHook(hook)
+		return nil
+	}
+}
+
+func (h *fluentdHook) Levels() []logrus.Level {
+	return h.cfg.Levels
+}
+
+// Fire queues the entry for the background worker. While the server can't be
+// reached it also returns the error of the last delivery, so a failover hook
+// can switch to a secondary sink.
+func (h *fluentdHook) Fire(entry *logrus.Entry) error {
+	msg, chunk, err := h.encode(entry)
+	if err != nil {
+		return err
+	}
+	h.batcher.Add(fluentdMessage{msg: msg, chunk: chunk})
+
+	h.errMu.Lock()
+	defer h.errMu.Unlock()
+	return h.err
+}
+
+// Dropped returns the number of entries discarded because the queue was full
+func (h *fluentdHook) Dropped() uint64 {
+	return h.batcher.Dropped()
+}
+
+// Flush sends the queued entries without stopping the background worker
+func (h *fluentdHook) Flush() error {
+	h.batcher.Sync()
+	return nil
+}
+
+// Close sends pending entries, stops the background worker and closes the
+// connection
+func (h *fluentdHook) Close() error {
+	h.batcher.Close()
+	if h.conn == nil {
+		return nil
+	}
+	err := h.conn.Close()
+	h.conn = nil
+	return err
+}
+
+// ship sends queued messages, reconnecting and retrying according to the retry
+// policy if the connection is broken
+func (h *fluentdHook) ship(messages []fluentdMessage) {
+	for _, m := range messages {
+		err := h.retry.Deliver("fluentd", 1, m.msg, h.batcher.Closing(), func(msg []byte) (bool, error) {
+			if err := h.send(msg, m.chunk); err != nil {
+				h.disconnect()
+				return true, err
+			}
+			return false, nil
+		})
+		h.errMu.Lock()
+		h.err = err
+		h.errMu.Unlock()
+	}
+}
+
+// encode serializes the entry in forward protocol message mode:
+// [tag, time, record, option]
+func (h *fluentdHook) encode(entry *logrus.Entry) ([]byte, string, error) {
+	record := make(map[string]interface{}, len(entry.Data)+2)
+	for k, v := range entry.Data {
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+		record[k] = v
+	}
+	record["message"] = entry.Message
+	record["level"] = entry.Level.String()
+
+	option := map[string]interface{}{}
+	var chunk string
+	if h.cfg.RequireAck {
+		var id [16]byte
+		if _, err := rand.Read(id[:]); err != nil {
+			return nil, "", err
+		}
+		chunk = base64.StdEncoding.EncodeToString(id[:])
+		option["chunk"] = chunk
+	}
+
+	msg, err := msgpack.Marshal([]interface{}{
+		ExpandFields(h.cfg.Tag, entry, nil),
+		fluentdEventTime(entry.Time),
+		record,
+		option,
+	})
+	return msg, chunk, err
+}
+
+// send writes a message and waits for its ack when required
+func (h *fluentdHook) send(msg []byte, chunk string) error {
+	if err := h.ensureConnected(); err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(h.cfg.Timeout)
+	h.conn.SetDeadline(deadline)
+	if _, err := h.conn.Write(msg); err != nil {
+		return err
+	}
+	if chunk == "" {
+		return nil
+	}
+
+	var resp struct {
+		Ack string `msgpack:"ack"`
+	}
+	if err := msgpack.NewDecoder(h.reader).Decode(&resp); err != nil {
+		return err
+	}
+	if resp.Ack != chunk {
+		return fmt.Errorf("fluentd: unexpected ack %q", resp.Ack)
+	}
+	return nil
+}
+
+// ensureConnected dials the server if needed, honoring the reconnection backoff
+func (h *fluentdHook) ensureConnected() error {
+	if h.conn != nil {
+		return nil
+	}
+	if time.Now().Before(h.nextRetry) {
+		return fmt.Errorf("fluentd: reconnecting to %s", h.cfg.Address)
+	}
+	if err := h.connect(); err != nil {
+		if h.backoff == 0 {
+			h.backoff = 100 * time.Millisecond
+		} else if h.backoff *= 2; h.backoff > h.cfg.MaxBackoff {
+			h.backoff = h.cfg.MaxBackoff
+		}
+		h.nextRetry = time.Now().Add(h.backoff)
+		return err
+	}
+	h.backoff = 0
+	return nil
+}
+
+func (h *fluentdHook) connect() error {
+	conn, err := dialTimeout("tcp", h.cfg.Address, h.cfg.Timeout, h.tls)
+	if err != nil {
+		return fmt.Errorf("fluentd: %w", err)
+	}
+	h.conn = conn
+	h.reader = bufio.NewReader(conn)
+	return nil
+}
+
+func (h *fluentdHook) disconnect() {
+	if h.conn != nil {
+		h.conn.Close()
+		h.conn = nil
+	}
+}
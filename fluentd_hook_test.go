@@ -0,0 +1,212 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+type fluentdEvent struct {
+	tag    string
+	time   time.Time
+	record map[string]interface{}
+	option map[string]interface{}
+}
+
+// fluentdTestServer accepts forward protocol messages, acknowledging chunks. When
+// dropFirst is set the first connection is closed after reading one message
+// without acknowledging it.
+type fluentdTestServer struct {
+	listener  net.Listener
+	dropFirst bool
+	mu        sync.Mutex
+	events    []fluentdEvent
+	conns     int
+}
+
+func newFluentdTestServer(t *testing.T, dropFirst bool) *fluentdTestServer {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &fluentdTestServer{listener: l, dropFirst: dropFirst}
+	go s.serve()
+	t.Cleanup(func() { l.Close() })
+	return s
+}
+
+func (s *fluentdTestServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns++
+		drop := s.dropFirst && s.conns == 1
+		s.mu.Unlock()
+		go s.handle(conn, drop)
+	}
+}
+
+func (s *fluentdTestServer) handle(conn net.Conn, drop bool) {
+	defer conn.Close()
+	dec := msgpack.NewDecoder(bufio.NewReader(conn))
+	enc := msgpack.NewEncoder(conn)
+	for {
+		event, err := decodeFluentdEvent(dec)
+		if err != nil {
+			return
+		}
+		if drop {
+			return
+		}
+		s.mu.Lock()
+		s.events = append(s.events, event)
+		s.mu.Unlock()
+		if chunk, ok := event.option["chunk"]; ok {
+			enc.Encode(map[string]interface{}{"ack": chunk})
+		}
+	}
+}
+
+func (s *fluentdTestServer) received() []fluentdEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]fluentdEvent(nil), s.events...)
+}
+
+func decodeFluentdEvent(dec *msgpack.Decoder) (fluentdEvent, error) {
+	var event fluentdEvent
+	if _, err := dec.DecodeArrayLen(); err != nil {
+		return event, err
+	}
+	tag, err := dec.DecodeString()
+	if err != nil {
+		return event, err
+	}
+	event.tag = tag
+
+	if _, _, err := dec.DecodeExtHeader(); err != nil {
+		return event, err
+	}
+	var b [8]byte
+	if err := dec.ReadFull(b[:]); err != nil {
+		return event, err
+	}
+	event.time = time.Unix(int64(binary.BigEndian.Uint32(b[:4])), int64(binary.BigEndian.Uint32(b[4:])))
+
+	if event.record, err = dec.DecodeMap(); err != nil {
+		return event, err
+	}
+	event.option, err = dec.DecodeMap()
+	return event, err
+}
+
+func TestFluentdHook(t *testing.T) {
+	server := newFluentdTestServer(t, false)
+
+	hook, err := NewFluentdHook(&FluentdConfig{
+		Address:    server.listener.Addr().String(),
+		Tag:        "app.{service}.{level}",
+		RequireAck: true,
+	})
+	require.NoError(t, err)
+	defer hook.Close()
+
+	logger := logrus.New()
+	logger.AddHook(hook)
+	logger.SetOutput(io.Discard)
+
+	now := time.Now()
+	logger.WithField("service", "billing").WithTime(now).Info("invoice sent")
+	logger.Warn("no service")
+	require.NoError(t, hook.Flush())
+
+	events := server.received()
+	require.Len(t, events, 2)
+	assert.Equal(t, "app.billing.info", events[0].tag)
+	assert.Equal(t, "invoice sent", events[0].record["message"])
+	assert.Equal(t, "billing", events[0].record["service"])
+	assert.Equal(t, now.UnixNano(), events[0].time.UnixNano())
+	assert.Equal(t, "app.unknown.warning", events[1].tag)
+}
+
+func TestFluentdHook_Reconnect(t *testing.T) {
+	server := newFluentdTestServer(t, true)
+
+	hook, err := NewFluentdHook(&FluentdConfig{
+		Address:    server.listener.Addr().String(),
+		RequireAck: true,
+		Timeout:    time.Second,
+	})
+	require.NoError(t, err)
+	defer hook.Close()
+
+	logger := logrus.New()
+	logger.AddHook(hook)
+	logger.SetOutput(io.Discard)
+
+	logger.Info("delivered after reconnect")
+	require.NoError(t, hook.Flush())
+
+	events := server.received()
+	require.Len(t, events, 1)
+	assert.Equal(t, "delivered after reconnect", events[0].record["message"])
+}
+
+func TestFluentdHook_DoesNotBlock(t *testing.T) {
+	// the server accepts connections but never acknowledges
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go io.Copy(io.Discard, conn)
+		}
+	}()
+
+	failed := make(chan FailedDelivery, 1)
+	hook, err := NewFluentdHook(&FluentdConfig{
+		Address:    listener.Addr().String(),
+		RequireAck: true,
+		Timeout:    200 * time.Millisecond,
+		Retry:      &RetryPolicy{MaxAttempts: 1, OnFailure: func(f FailedDelivery) { failed <- f }},
+	})
+	require.NoError(t, err)
+
+	start := time.Now()
+	assert.NoError(t, hook.Fire(logrus.NewEntry(logrus.New())))
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+
+	select {
+	case f := <-failed:
+		assert.Equal(t, "fluentd", f.Sink)
+	case <-time.After(2 * time.Second):
+		t.Fatal("the unacknowledged entry was not reported")
+	}
+	// the last delivery failed, let a failover hook know
+	assert.Eventually(t, func() bool {
+		return hook.Fire(logrus.NewEntry(logrus.New())) != nil
+	}, time.Second, 10*time.Millisecond)
+	require.NoError(t, hook.Close())
+}
+
+func TestFluentdHook_InvalidAddress(t *testing.T) {
+	_, err := NewFluentdHook(&FluentdConfig{Address: "127.0.0.1:1", Timeout: 100 * time.Millisecond})
+	assert.Error(t, err)
+
+	_, err = NewFluentdHook(nil)
+	assert.Error(t, err)
+}
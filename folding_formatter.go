@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"bytes"
+
+	"github.com/sirupsen/logrus"
+)
+
+// FoldingFormatter keeps every entry on a single line so line-splitting log
+// collectors (Docker, Kubernetes) don't break multi-line stacks into separate
+// entries. Line breaks inside a rendered entry are replaced with Marker; when
+// Marker is empty, entries spanning several lines are encoded as a single JSON
+// object instead.
+type FoldingFormatter struct {
+	Formatter logrus.Formatter // defaults to a TextFormatter
+	Marker    string
+
+	json logrus.JSONFormatter
+}
+
+func (f *FoldingFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	formatter := f.Formatter
+	if formatter == nil {
+		formatter = &logrus.TextFormatter{}
+	}
+	out, err := formatter.Format(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	body := bytes.TrimSuffix(out, []byte("\n"))
+	if !bytes.ContainsAny(body, "\r\n") {
+		return out, nil
+	}
+	if f.Marker == "" {
+		return f.json.Format(entry)
+	}
+
+	body = bytes.ReplaceAll(body, []byte("\r\n"), []byte("\n"))
+	folded := bytes.ReplaceAll(body, []byte("\n"), []byte(f.Marker))
+	return append(folded, '\n'), nil
+}
+
+// WithMultilineFolding wraps the current formatter in a FoldingFormatter using
+// marker as the continuation marker (JSON encoding when empty). Apply it after
+// WithFormatter.
+func WithMultilineFolding(marker string) Option {
+	return func(l *Logger) error {
+		current := l.Entry.Logger.Formatter
+		if s, ok := current.(*suppressingFormatter); ok {
+			current = s.Formatter
+		}
+		setFormatter(l.Entry.Logger, &FoldingFormatter{Formatter: current, Marker: marker})
+		return nil
+	}
+}
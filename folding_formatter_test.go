@@ -0,0 +1,52 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testStack = "goroutine 1 [running]:\nmain.main()\n\t/app/main.go:12 +0x1d"
+
+func TestFoldingFormatter_Marker(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := NewLogger(
+		WithOutput(&buf),
+		WithFormatter(&ColorFormatter{}),
+		WithMultilineFolding(" | "),
+	)
+	require.NoError(t, err)
+
+	logger.Error("panic: boom\n" + testStack)
+	logger.Info("single line")
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], "panic: boom | goroutine 1 [running]: | main.main() | \t/app/main.go:12 +0x1d")
+	assert.Contains(t, lines[1], "single line")
+}
+
+func TestFoldingFormatter_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := NewLogger(
+		WithOutput(&buf),
+		WithFormatter(&FoldingFormatter{Formatter: &ColorFormatter{}}),
+	)
+	require.NoError(t, err)
+
+	logger.WithField("stack", testStack).Error("recovered")
+	logger.Info("plain line")
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 2)
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+	assert.Equal(t, "recovered", record["msg"])
+	assert.Equal(t, testStack, record["stack"])
+	assert.Contains(t, lines[1], "plain line")
+}
@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// DefaultMaxFrameSize bounds the frames accepted by a FrameReader when its
+// limit isn't set
+const DefaultMaxFrameSize = 16 << 20 // 16MB
+
+// Framing selects how entries are delimited on a stream
+type Framing int
+
+const (
+	// FramingNewline writes entries as newline delimited records
+	FramingNewline Framing = iota
+	// FramingLengthPrefixed writes every entry as a frame made of its length,
+	// a big endian uint32, followed by the entry without its trailing newline
+	FramingLengthPrefixed
+)
+
+// parseFraming parses the framing query parameter of output URIs
+func parseFraming(s string) (Framing, error) {
+	switch s {
+	case "", "newline":
+		return FramingNewline, nil
+	case "length":
+		return FramingLengthPrefixed, nil
+	}
+	return 0, fmt.Errorf("invalid framing %q", s)
+}
+
+// appendFrame appends the length-prefixed frame of the entry p to dst
+func appendFrame(dst, p []byte) []byte {
+	p = bytes.TrimSuffix(p, []byte("\n"))
+	dst = binary.BigEndian.AppendUint32(dst, uint32(len(p)))
+	return append(dst, p...)
+}
+
+// framedWriter writes every Write to w as a length-prefixed frame
+type framedWriter struct {
+	w io.Writer
+}
+
+// NewFramedWriter returns a writer turning every entry written to it into a
+// length-prefixed frame written to w, see FramingLengthPrefixed
+func NewFramedWriter(w io.Writer) io.Writer {
+	return &framedWriter{w: w}
+}
+
+func (f *framedWriter) Write(p []byte) (int, error) {
+	if _, err := f.w.Write(appendFrame(nil, p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// FrameReader reads the entries of a length-prefixed stream
+type FrameReader struct {
+	r io.Reader
+	// MaxSize bounds the size of a frame, DefaultMaxFrameSize when not
+	// positive, so a corrupted length doesn't exhaust memory
+	MaxSize int
+}
+
+// NewFrameReader returns a reader of the frames written to r with
+// FramingLengthPrefixed
+func NewFrameReader(r io.Reader) *FrameReader {
+	return &FrameReader{r: r}
+}
+
+// Next returns the next entry, io.EOF at the end of the stream and
+// io.ErrUnexpectedEOF when it ends within a frame
+func (f *FrameReader) Next() ([]byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(f.r, header[:]); err != nil {
+		return nil, err
+	}
+	max := f.MaxSize
+	if max <= 0 {
+		max = DefaultMaxFrameSize
+	}
+	n := binary.BigEndian.Uint32(header[:])
+	if uint64(n) > uint64(max) {
+		return nil, fmt.Errorf("frame of %d bytes exceeds the %d bytes limit", n, max)
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(f.r, payload); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return payload, nil
+}
@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFramedWriter_RoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := NewLogger(WithOutput(NewFramedWriter(&buf)), WithFormatter(&PlainFormatter{}))
+	require.NoError(t, err)
+
+	logger.Info("first")
+	// frames may hold line breaks, e.g. from a multi-line formatter
+	NewFramedWriter(&buf).Write([]byte("multi\nline\n"))
+
+	assert.Equal(t, uint32(len("INFO first")), binary.BigEndian.Uint32(buf.Bytes()[:4]))
+
+	r := NewFrameReader(&buf)
+	frame, err := r.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "INFO first", string(frame))
+	frame, err = r.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "multi\nline", string(frame))
+	_, err = r.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestFrameReader_Errors(t *testing.T) {
+	truncated := appendFrame(nil, []byte("hello\n"))
+	_, err := NewFrameReader(bytes.NewReader(truncated[:6])).Next()
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+
+	r := NewFrameReader(bytes.NewReader(appendFrame(nil, bytes.Repeat([]byte("x"), 100))))
+	r.MaxSize = 10
+	_, err = r.Next()
+	assert.Error(t, err)
+}
+
+func TestNetworkWriter_LengthPrefixed(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	sink, err := NewSink("tcp://" + ln.Addr().String() + "?framing=length")
+	require.NoError(t, err)
+	defer sink.Close()
+
+	logger, err := NewLogger(WithOutput(sink), WithFormatter(&PlainFormatter{}))
+	require.NoError(t, err)
+	logger.Info("one")
+	logger.Info("two")
+
+	conn, err := ln.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+	r := NewFrameReader(conn)
+	for _, want := range []string{"INFO one", "INFO two"} {
+		frame, err := r.Next()
+		require.NoError(t, err)
+		assert.Equal(t, want, string(frame))
+	}
+}
+
+func TestNewSink_InvalidFraming(t *testing.T) {
+	_, err := NewSink("tcp://127.0.0.1:9?framing=cobs")
+	assert.Error(t, err)
+}
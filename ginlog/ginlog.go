@@ -0,0 +1,108 @@
+// Package ginlog adapts go-logger to Gin, replacing its default logger and
+// recovery middleware with structured entries.
+package ginlog
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	logger "github.com/alejoacosta74/go-logger"
+	"github.com/gin-gonic/gin"
+)
+
+// New returns a gin engine without middleware but Logger and Recovery, logging
+// on l (the global Log when nil), in place of gin.Default
+func New(l *logger.Logger, opts *logger.HTTPMiddlewareOptions) *gin.Engine {
+	engine := gin.New()
+	engine.Use(Logger(l, opts), Recovery(l))
+	return engine
+}
+
+// Logger returns gin middleware logging one access entry per request on l (the
+// global Log when nil), with the same fields as logger.HTTPMiddleware. The route
+// field holds the matched route (e.g. /users/:id) unless opts sets RoutePattern.
+// Errors attached to the gin context are logged in the error field.
+func Logger(l *logger.Logger, opts *logger.HTTPMiddlewareOptions) gin.HandlerFunc {
+	o := logger.HTTPMiddlewareOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		reqOpts := o
+		if reqOpts.RoutePattern == nil {
+			route := c.FullPath()
+			reqOpts.RoutePattern = func(*http.Request) string { return route }
+		}
+		log := l
+		if log == nil {
+			log = logger.Log
+		}
+		if errs := c.Errors.ByType(gin.ErrorTypePrivate); len(errs) > 0 {
+			log = &logger.Logger{Entry: log.Entry.WithField("error", errs.String())}
+		}
+		logger.LogHTTPAccess(log, c.Request, c.Writer.Status(), c.Writer.Size(), time.Since(start), &reqOpts)
+	}
+}
+
+// Recovery returns gin middleware recovering from panics in handlers, logging
+// them at error level on l (the global Log when nil) with the panic value and
+// the stack, see logger.Stack, and responding 500. Panics caused by a client
+// closing the connection are logged without a stack and get no response.
+func Recovery(l *logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+			log := l
+			if log == nil {
+				log = logger.Log
+			}
+			entry := log.Entry.WithContext(c.Request.Context()).WithFields(logger.Fields{
+				"panic":  fmt.Sprint(r),
+				"method": c.Request.Method,
+				"path":   c.Request.URL.Path,
+			})
+			if err, ok := r.(error); ok {
+				entry = entry.WithError(err)
+			}
+			if brokenPipe(r) {
+				entry.Warn("client closed the connection")
+				c.Error(r.(error))
+				c.Abort()
+				return
+			}
+			entry.WithField("stack", logger.Stack(1)).Error("recovered from panic in handler")
+			c.AbortWithStatus(http.StatusInternalServerError)
+		}()
+		c.Next()
+	}
+}
+
+// brokenPipe reports whether the panic value is a write error on a connection
+// closed by the client
+func brokenPipe(r interface{}) bool {
+	err, ok := r.(error)
+	if !ok {
+		return false
+	}
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) {
+		return false
+	}
+	var syscallErr *os.SyscallError
+	if !errors.As(opErr, &syscallErr) {
+		return false
+	}
+	msg := strings.ToLower(syscallErr.Error())
+	return strings.Contains(msg, "broken pipe") || strings.Contains(msg, "connection reset by peer")
+}
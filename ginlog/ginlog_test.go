@@ -0,0 +1,53 @@
+package ginlog
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	logger "github.com/alejoacosta74/go-logger"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	l, err := logger.NewLogger(logger.WithNullOutput(), logger.WithLastEntriesCapture(10))
+	require.NoError(t, err)
+
+	engine := New(l, nil)
+	engine.GET("/users/:id", func(c *gin.Context) {
+		c.String(http.StatusOK, "user")
+	})
+	engine.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	crumbs := l.Breadcrumbs(0)
+	require.Len(t, crumbs, 3)
+
+	assert.Equal(t, "request completed", crumbs[0].Message)
+	assert.Equal(t, "/users/:id", crumbs[0].Data["route"])
+	assert.Equal(t, "/users/42", crumbs[0].Data["path"])
+	assert.Equal(t, http.StatusOK, crumbs[0].Data["status"])
+	assert.Equal(t, 4, crumbs[0].Data["bytes"])
+
+	assert.Equal(t, logrus.ErrorLevel, crumbs[1].Level)
+	assert.Equal(t, "recovered from panic in handler", crumbs[1].Message)
+	assert.Equal(t, "boom", crumbs[1].Data["panic"])
+	assert.Contains(t, crumbs[1].Data["stack"], "ginlog.TestNew")
+
+	assert.Equal(t, logrus.ErrorLevel, crumbs[2].Level)
+	assert.Equal(t, http.StatusInternalServerError, crumbs[2].Data["status"])
+	assert.Equal(t, "server_error", crumbs[2].Data["outcome"])
+}
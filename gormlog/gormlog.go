@@ -0,0 +1,153 @@
+// Package gormlog adapts go-logger to GORM, so database logs are structured
+// like every other entry.
+package gormlog
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	logger "github.com/alejoacosta74/go-logger"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// DefaultSlowThreshold is the duration above which queries are logged as slow
+// when no threshold is configured
+const DefaultSlowThreshold = 200 * time.Millisecond
+
+// Config holds configuration for the GORM logger
+type Config struct {
+	// LogLevel selects what GORM logs: Silent, Error (failed queries), Warn
+	// (also slow queries) or Info (every query). Defaults to Warn.
+	LogLevel gormlogger.LogLevel
+	// SlowThreshold is the duration above which queries are logged as slow,
+	// defaults to DefaultSlowThreshold. A negative threshold disables it.
+	SlowThreshold time.Duration
+	// IgnoreRecordNotFoundError doesn't log gorm.ErrRecordNotFound as a failure
+	IgnoreRecordNotFoundError bool
+	// RedactParams logs the SQL with placeholders instead of the query params
+	RedactParams bool
+}
+
+// gormLogger implements gormlogger.Interface logging through a Logger
+type gormLogger struct {
+	logger *logger.Logger
+	cfg    Config
+}
+
+// New returns a GORM logger logging on l (the global Log when nil). Failed
+// queries are logged at error level, slow queries at warn level and, at the
+// Info log level, the other queries at debug level, with the sql, rows,
+// latency_ms and caller (the file and line running the query) fields. Messages
+// GORM logs itself keep their info, warn and error level.
+func New(l *logger.Logger, cfg *Config) gormlogger.Interface {
+	c := Config{}
+	if cfg != nil {
+		c = *cfg
+	}
+	if c.LogLevel == 0 {
+		c.LogLevel = gormlogger.Warn
+	}
+	if c.SlowThreshold == 0 {
+		c.SlowThreshold = DefaultSlowThreshold
+	}
+	return &gormLogger{logger: l, cfg: c}
+}
+
+func (g *gormLogger) log() *logger.Logger {
+	if g.logger == nil {
+		return logger.Log
+	}
+	return g.logger
+}
+
+// LogMode returns a copy of the logger with level as its log level
+func (g *gormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	c := *g
+	c.cfg.LogLevel = level
+	return &c
+}
+
+func (g *gormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if g.cfg.LogLevel >= gormlogger.Info {
+		g.log().Entry.WithContext(ctx).WithField("caller", caller()).Infof(msg, data...)
+	}
+}
+
+func (g *gormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if g.cfg.LogLevel >= gormlogger.Warn {
+		g.log().Entry.WithContext(ctx).WithField("caller", caller()).Warnf(msg, data...)
+	}
+}
+
+func (g *gormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if g.cfg.LogLevel >= gormlogger.Error {
+		g.log().Entry.WithContext(ctx).WithField("caller", caller()).Errorf(msg, data...)
+	}
+}
+
+// Trace logs a query once it has run
+func (g *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if g.cfg.LogLevel <= gormlogger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+	failed := err != nil && !(g.cfg.IgnoreRecordNotFoundError && errors.Is(err, gorm.ErrRecordNotFound))
+	slow := g.cfg.SlowThreshold > 0 && elapsed > g.cfg.SlowThreshold
+
+	switch {
+	case failed && g.cfg.LogLevel >= gormlogger.Error:
+		g.entry(ctx, elapsed, fc).WithError(err).Error("query failed")
+	case slow && g.cfg.LogLevel >= gormlogger.Warn:
+		g.entry(ctx, elapsed, fc).WithField("slow_threshold", g.cfg.SlowThreshold.String()).Warn("slow query")
+	case g.cfg.LogLevel >= gormlogger.Info:
+		g.entry(ctx, elapsed, fc).Debug("query")
+	}
+}
+
+func (g *gormLogger) entry(ctx context.Context, elapsed time.Duration, fc func() (string, int64)) *logger.Logger {
+	sql, rows := fc()
+	fields := logger.Fields{
+		"sql":        sql,
+		"latency_ms": float64(elapsed) / float64(time.Millisecond),
+		"caller":     caller(),
+	}
+	if rows >= 0 {
+		fields["rows"] = rows
+	}
+	return &logger.Logger{Entry: g.log().Entry.WithContext(ctx).WithFields(fields)}
+}
+
+// ParamsFilter implements gorm.ParamsFilter, leaving the params out of the
+// logged SQL when RedactParams is set
+func (g *gormLogger) ParamsFilter(_ context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	if g.cfg.RedactParams {
+		return sql, nil
+	}
+	return sql, params
+}
+
+var _ gorm.ParamsFilter = (*gormLogger)(nil)
+
+// caller returns the file and line of the code running the query, skipping the
+// frames of GORM and of this package
+func caller() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		internal := strings.HasPrefix(frame.Function, "gorm.io/") ||
+			strings.HasPrefix(frame.Function, "github.com/alejoacosta74/go-logger/gormlog.")
+		if !internal || strings.HasSuffix(frame.File, "_test.go") {
+			return frame.File + ":" + strconv.Itoa(frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
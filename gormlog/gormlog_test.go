@@ -0,0 +1,58 @@
+package gormlog
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	logger "github.com/alejoacosta74/go-logger"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func TestTrace(t *testing.T) {
+	l, err := logger.NewLogger(logger.WithNullOutput(), logger.WithLevel("debug"), logger.WithLastEntriesCapture(10))
+	require.NoError(t, err)
+	query := func() (string, int64) { return "SELECT * FROM users WHERE id = 42", 1 }
+
+	g := New(l, &Config{SlowThreshold: time.Second, IgnoreRecordNotFoundError: true})
+	ctx := context.Background()
+	g.Trace(ctx, time.Now(), query, nil)
+	g.Trace(ctx, time.Now(), query, gorm.ErrRecordNotFound)
+	g.Trace(ctx, time.Now(), query, errors.New("connection refused"))
+	g.Trace(ctx, time.Now().Add(-2*time.Second), query, nil)
+	g.LogMode(gormlogger.Info).Trace(ctx, time.Now(), func() (string, int64) { return "BEGIN", -1 }, nil)
+	g.LogMode(gormlogger.Silent).Trace(ctx, time.Now(), query, errors.New("not logged"))
+
+	crumbs := l.Breadcrumbs(0)
+	require.Len(t, crumbs, 3)
+
+	assert.Equal(t, logrus.ErrorLevel, crumbs[0].Level)
+	assert.Equal(t, "query failed", crumbs[0].Message)
+	assert.Equal(t, "SELECT * FROM users WHERE id = 42", crumbs[0].Data["sql"])
+	assert.Equal(t, int64(1), crumbs[0].Data["rows"])
+	assert.Contains(t, crumbs[0].Data["caller"], "gormlog_test.go:")
+
+	assert.Equal(t, logrus.WarnLevel, crumbs[1].Level)
+	assert.Equal(t, "slow query", crumbs[1].Message)
+	assert.Equal(t, "1s", crumbs[1].Data["slow_threshold"])
+
+	assert.Equal(t, logrus.DebugLevel, crumbs[2].Level)
+	assert.Equal(t, "BEGIN", crumbs[2].Data["sql"])
+	assert.NotContains(t, crumbs[2].Data, "rows")
+}
+
+func TestParamsFilter(t *testing.T) {
+	filter := New(nil, &Config{RedactParams: true}).(gorm.ParamsFilter)
+	sql, params := filter.ParamsFilter(context.Background(), "SELECT * FROM users WHERE email = ?", "jane@example.com")
+	assert.Equal(t, "SELECT * FROM users WHERE email = ?", sql)
+	assert.Empty(t, params)
+
+	filter = New(nil, nil).(gorm.ParamsFilter)
+	_, params = filter.ParamsFilter(context.Background(), "SELECT * FROM users WHERE email = ?", "jane@example.com")
+	assert.Equal(t, []interface{}{"jane@example.com"}, params)
+}
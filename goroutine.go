@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"fmt"
+	"runtime/debug"
+	"time"
+)
+
+// GoOption configures goroutines launched with Go
+type GoOption func(*goConfig)
+
+type goConfig struct {
+	name        string
+	maxRestarts int
+	backoff     time.Duration
+}
+
+// WithGoroutineName sets the name logged in the goroutine field of panic entries
+func WithGoroutineName(name string) GoOption {
+	return func(c *goConfig) {
+		c.name = name
+	}
+}
+
+// WithRestarts restarts the goroutine after a panic, up to max times (a negative
+// max restarts forever), waiting backoff between attempts
+func WithRestarts(max int, backoff time.Duration) GoOption {
+	return func(c *goConfig) {
+		c.maxRestarts = max
+		c.backoff = backoff
+	}
+}
+
+// Go launches fn in a new goroutine that recovers from panics and logs them at
+// error level on l (the global Log when nil), with the panic value and stack
+// trace as fields. The returned channel is closed once fn returns normally or
+// the restart policy is exhausted.
+func Go(l *Logger, fn func(), opts ...GoOption) <-chan struct{} {
+	cfg := &goConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for restarts := 0; ; restarts++ {
+			if !runRecovered(l, fn, cfg, restarts) {
+				return
+			}
+			if cfg.maxRestarts >= 0 && restarts >= cfg.maxRestarts {
+				return
+			}
+			if cfg.backoff > 0 {
+				time.Sleep(cfg.backoff)
+			}
+		}
+	}()
+	return done
+}
+
+// runRecovered runs fn and reports whether it panicked
+func runRecovered(l *Logger, fn func(), cfg *goConfig, restarts int) (panicked bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			panicked = true
+
+			logger := l
+			if logger == nil {
+				logger = globalLogger()
+			}
+			entry := logger.Entry.WithFields(Fields{
+				"panic":    fmt.Sprint(r),
+				"stack":    string(debug.Stack()),
+				"restarts": restarts,
+			})
+			if cfg.name != "" {
+				entry = entry.WithField("goroutine", cfg.name)
+			}
+			if err, ok := r.(error); ok {
+				entry = entry.WithError(err)
+			}
+			entry.Error("recovered from panic in goroutine")
+		}
+	}()
+	fn()
+	return false
+}
@@ -0,0 +1,57 @@
+package logger
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGo_RecoversPanic(t *testing.T) {
+	logger, err := NewLogger(WithNullOutput(), WithLastEntriesCapture(10))
+	require.NoError(t, err)
+
+	<-Go(logger, func() {
+		panic(errors.New("worker exploded"))
+	}, WithGoroutineName("worker-1"))
+
+	crumbs := logger.Breadcrumbs(0)
+	require.Len(t, crumbs, 1)
+	assert.Equal(t, "recovered from panic in goroutine", crumbs[0].Message)
+	assert.Equal(t, "worker exploded", crumbs[0].Data["panic"])
+	assert.Equal(t, "worker-1", crumbs[0].Data["goroutine"])
+	assert.Contains(t, crumbs[0].Data["stack"], "goroutine_test.go")
+	assert.EqualError(t, crumbs[0].Data["error"].(error), "worker exploded")
+}
+
+func TestGo_RestartPolicy(t *testing.T) {
+	logger, err := NewLogger(WithNullOutput(), WithLastEntriesCapture(10))
+	require.NoError(t, err)
+
+	var runs atomic.Int32
+	<-Go(logger, func() {
+		runs.Add(1)
+		panic("boom")
+	}, WithRestarts(2, time.Millisecond))
+
+	assert.Equal(t, int32(3), runs.Load())
+	assert.Len(t, logger.Breadcrumbs(0), 3)
+}
+
+func TestGo_StopsAfterNormalReturn(t *testing.T) {
+	logger, err := NewLogger(WithNullOutput(), WithLastEntriesCapture(10))
+	require.NoError(t, err)
+
+	var runs atomic.Int32
+	<-Go(logger, func() {
+		if runs.Add(1) == 1 {
+			panic("first run fails")
+		}
+	}, WithRestarts(-1, 0))
+
+	assert.Equal(t, int32(2), runs.Load())
+	assert.Len(t, logger.Breadcrumbs(0), 1)
+}
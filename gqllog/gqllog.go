@@ -0,0 +1,135 @@
+// Package gqllog logs gqlgen GraphQL operations and resolver calls with
+// go-logger.
+package gqllog
+
+import (
+	"context"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	logger "github.com/alejoacosta74/go-logger"
+	"github.com/sirupsen/logrus"
+)
+
+// Options holds configuration for the gqlgen instrumentation
+type Options struct {
+	LatencyBuckets []time.Duration // defaults to logger.DefaultLatencyBuckets
+	Message        string          // operation entry message, defaults to "operation completed"
+	// Resolvers adds a debug entry per resolver call with its path and duration;
+	// failing resolvers are logged at error
+	Resolvers bool
+}
+
+// loggingExtension implements the gqlgen handler extension interfaces logging
+// operations and resolvers
+type loggingExtension struct {
+	logger *logger.Logger
+	opts   Options
+}
+
+var (
+	_ graphql.HandlerExtension    = (*loggingExtension)(nil)
+	_ graphql.ResponseInterceptor = (*loggingExtension)(nil)
+	_ graphql.FieldInterceptor    = (*loggingExtension)(nil)
+)
+
+// Extension returns a gqlgen extension logging one entry per operation response
+// on l (the global logger.Log when nil), with the operation name and type,
+// complexity (when the complexity limit extension is used), error count,
+// outcome and latency fields:
+//
+//	srv := handler.NewDefaultServer(schema)
+//	srv.Use(gqllog.Extension(logger, nil))
+func Extension(l *logger.Logger, opts *Options) *loggingExtension {
+	o := Options{}
+	if opts != nil {
+		o = *opts
+	}
+	if len(o.LatencyBuckets) == 0 {
+		o.LatencyBuckets = logger.DefaultLatencyBuckets
+	}
+	if o.Message == "" {
+		o.Message = "operation completed"
+	}
+	return &loggingExtension{logger: l, opts: o}
+}
+
+func (e *loggingExtension) ExtensionName() string {
+	return "Logger"
+}
+
+func (e *loggingExtension) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptResponse logs each operation response, once per event for
+// subscriptions
+func (e *loggingExtension) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	resp := next(ctx)
+	if resp == nil || !graphql.HasOperationContext(ctx) {
+		return resp
+	}
+	oc := graphql.GetOperationContext(ctx)
+	latency := time.Since(oc.Stats.OperationStart)
+
+	name := oc.OperationName
+	if name == "" {
+		name = "anonymous"
+	}
+	outcome := logger.OutcomeSuccess
+	if len(resp.Errors) > 0 {
+		outcome = logger.OutcomeServerError
+	}
+	fields := logger.Fields{
+		"graphql_operation": name,
+		"latency_ms":        float64(latency) / float64(time.Millisecond),
+		"latency_bucket":    logger.LatencyBucket(latency, e.opts.LatencyBuckets),
+		"outcome":           string(outcome),
+	}
+	if oc.Operation != nil {
+		fields["graphql_type"] = string(oc.Operation.Operation)
+	}
+	if stats := extension.GetComplexityStats(ctx); stats != nil {
+		fields["graphql_complexity"] = stats.Complexity
+	}
+	if len(resp.Errors) > 0 {
+		fields["graphql_errors"] = len(resp.Errors)
+		fields[logrus.ErrorKey] = resp.Errors[0].Message
+	}
+	e.log().Entry.WithContext(ctx).WithFields(fields).Log(outcome.Level(), e.opts.Message)
+	return resp
+}
+
+// InterceptField logs resolver calls when enabled
+func (e *loggingExtension) InterceptField(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+	if !e.opts.Resolvers {
+		return next(ctx)
+	}
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil || !fc.IsResolver {
+		return next(ctx)
+	}
+	start := time.Now()
+	res, err := next(ctx)
+	latency := time.Since(start)
+
+	entry := e.log().Entry.WithContext(ctx).WithFields(logger.Fields{
+		"graphql_path":  fc.Path().String(),
+		"graphql_field": fc.Object + "." + fc.Field.Name,
+		"latency_ms":    float64(latency) / float64(time.Millisecond),
+	})
+	if err != nil {
+		entry.WithError(err).Error("resolver failed")
+	} else {
+		entry.Debug("resolver completed")
+	}
+	return res, err
+}
+
+func (e *loggingExtension) log() *logger.Logger {
+	if e.logger == nil {
+		return logger.Log
+	}
+	return e.logger
+}
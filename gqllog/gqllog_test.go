@@ -0,0 +1,68 @@
+package gqllog
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/testserver"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	logger "github.com/alejoacosta74/go-logger"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func TestExtension_Operation(t *testing.T) {
+	log, err := logger.NewLogger(logger.WithNullOutput(), logger.WithLastEntriesCapture(10))
+	require.NoError(t, err)
+
+	srv := testserver.New()
+	srv.AddTransport(transport.POST{})
+	srv.Use(extension.FixedComplexityLimit(100))
+	srv.SetCalculatedComplexity(7)
+	srv.Use(Extension(log, nil))
+
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query":"query GetName { name }","operationName":"GetName"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	crumbs := log.Breadcrumbs(0)
+	require.Len(t, crumbs, 1)
+	assert.Equal(t, "operation completed", crumbs[0].Message)
+	assert.Equal(t, "GetName", crumbs[0].Data["graphql_operation"])
+	assert.Equal(t, "query", crumbs[0].Data["graphql_type"])
+	assert.Equal(t, 7, crumbs[0].Data["graphql_complexity"])
+	assert.Equal(t, "success", crumbs[0].Data["outcome"])
+	assert.Contains(t, crumbs[0].Data, "latency_ms")
+}
+
+func TestExtension_Resolvers(t *testing.T) {
+	log, err := logger.NewLogger(logger.WithNullOutput(), logger.WithLevel("debug"), logger.WithLastEntriesCapture(10))
+	require.NoError(t, err)
+	ext := Extension(log, &Options{Resolvers: true})
+
+	ctx := graphql.WithFieldContext(context.Background(), &graphql.FieldContext{
+		Object:     "Query",
+		Field:      graphql.CollectedField{Field: &ast.Field{Name: "user", Alias: "user"}},
+		IsResolver: true,
+	})
+	ext.InterceptField(ctx, func(context.Context) (interface{}, error) { return "alice", nil })
+	ext.InterceptField(ctx, func(context.Context) (interface{}, error) { return nil, errors.New("not found") })
+
+	crumbs := log.Breadcrumbs(0)
+	require.Len(t, crumbs, 2)
+	assert.Equal(t, logrus.DebugLevel, crumbs[0].Level)
+	assert.Equal(t, "user", crumbs[0].Data["graphql_path"])
+	assert.Equal(t, "Query.user", crumbs[0].Data["graphql_field"])
+	assert.Equal(t, "resolver failed", crumbs[1].Message)
+	assert.Equal(t, logrus.ErrorLevel, crumbs[1].Level)
+}
@@ -0,0 +1,115 @@
+// Package grpclog logs one go-logger access entry per gRPC call, with the
+// outcome and latency_bucket fields used for log based SLO computation.
+package grpclog
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	logger "github.com/alejoacosta74/go-logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// idempotencyMetadataKey is the metadata key copied to the idempotency_key field
+	idempotencyMetadataKey = "idempotency-key"
+	// previousAttemptsMetadataKey is set by grpc-go transparent retries
+	previousAttemptsMetadataKey = "grpc-previous-rpc-attempts"
+)
+
+// Options holds configuration for the gRPC access log interceptors
+type Options struct {
+	LatencyBuckets []time.Duration // defaults to logger.DefaultLatencyBuckets
+	Message        string          // access entry message, defaults to "call completed"
+}
+
+func (o *Options) withDefaults() Options {
+	opts := Options{}
+	if o != nil {
+		opts = *o
+	}
+	if len(opts.LatencyBuckets) == 0 {
+		opts.LatencyBuckets = logger.DefaultLatencyBuckets
+	}
+	if opts.Message == "" {
+		opts.Message = "call completed"
+	}
+	return opts
+}
+
+// ClassifyOutcome classifies a gRPC call from its status code
+func ClassifyOutcome(code codes.Code) logger.Outcome {
+	switch code {
+	case codes.OK:
+		return logger.OutcomeSuccess
+	case codes.Canceled:
+		return logger.OutcomeCanceled
+	case codes.DeadlineExceeded:
+		return logger.OutcomeTimeout
+	case codes.InvalidArgument, codes.NotFound, codes.AlreadyExists, codes.PermissionDenied,
+		codes.Unauthenticated, codes.FailedPrecondition, codes.OutOfRange, codes.Unimplemented,
+		codes.ResourceExhausted:
+		return logger.OutcomeClientError
+	default:
+		return logger.OutcomeServerError
+	}
+}
+
+// UnaryServerInterceptor returns a gRPC interceptor logging one access entry per
+// unary call on l (the global Log when nil), with outcome and latency_bucket fields
+func UnaryServerInterceptor(l *logger.Logger, opts *Options) grpc.UnaryServerInterceptor {
+	o := opts.withDefaults()
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logCall(ctx, l, &o, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a gRPC interceptor logging one access entry per
+// stream on l (the global Log when nil), with outcome and latency_bucket fields
+func StreamServerInterceptor(l *logger.Logger, opts *Options) grpc.StreamServerInterceptor {
+	o := opts.withDefaults()
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logCall(ss.Context(), l, &o, info.FullMethod, start, err)
+		return err
+	}
+}
+
+func logCall(ctx context.Context, l *logger.Logger, o *Options, method string, start time.Time, err error) {
+	if l == nil {
+		l = logger.Log
+	}
+	latency := time.Since(start)
+	code := status.Code(err)
+	outcome := ClassifyOutcome(code)
+
+	entry := l.Entry.WithFields(logger.Fields{
+		"grpc_method":    method,
+		"grpc_code":      code.String(),
+		"latency_ms":     float64(latency) / float64(time.Millisecond),
+		"latency_bucket": logger.LatencyBucket(latency, o.LatencyBuckets),
+		"outcome":        string(outcome),
+	})
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if keys := md.Get(idempotencyMetadataKey); len(keys) > 0 {
+			entry = entry.WithField(logger.IdempotencyKeyKey, keys[0])
+		}
+		if prev := md.Get(previousAttemptsMetadataKey); len(prev) > 0 {
+			if n, err := strconv.Atoi(prev[0]); err == nil {
+				entry = entry.WithField(logger.AttemptKey, n+1)
+			}
+		}
+	}
+	if err != nil {
+		entry = entry.WithError(err)
+	}
+	entry.Log(outcome.Level(), o.Message)
+}
@@ -0,0 +1,72 @@
+package grpclog
+
+import (
+	"context"
+	"testing"
+
+	logger "github.com/alejoacosta74/go-logger"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestClassifyOutcome(t *testing.T) {
+	assert.Equal(t, logger.OutcomeSuccess, ClassifyOutcome(codes.OK))
+	assert.Equal(t, logger.OutcomeClientError, ClassifyOutcome(codes.NotFound))
+	assert.Equal(t, logger.OutcomeServerError, ClassifyOutcome(codes.Unavailable))
+	assert.Equal(t, logger.OutcomeTimeout, ClassifyOutcome(codes.DeadlineExceeded))
+	assert.Equal(t, logger.OutcomeCanceled, ClassifyOutcome(codes.Canceled))
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	l, err := logger.NewLogger(logger.WithNullOutput(), logger.WithLastEntriesCapture(10))
+	require.NoError(t, err)
+
+	interceptor := UnaryServerInterceptor(l, nil)
+	info := &grpc.UnaryServerInfo{FullMethod: "/orders.v1.Orders/Get"}
+
+	_, err = interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	})
+	require.NoError(t, err)
+
+	_, err = interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.Internal, "database unavailable")
+	})
+	require.Error(t, err)
+
+	crumbs := l.Breadcrumbs(0)
+	require.Len(t, crumbs, 2)
+	assert.Equal(t, "/orders.v1.Orders/Get", crumbs[0].Data["grpc_method"])
+	assert.Equal(t, "success", crumbs[0].Data["outcome"])
+	assert.Equal(t, "OK", crumbs[0].Data["grpc_code"])
+
+	assert.Equal(t, "server_error", crumbs[1].Data["outcome"])
+	assert.Equal(t, "Internal", crumbs[1].Data["grpc_code"])
+	assert.Equal(t, logrus.ErrorLevel, crumbs[1].Level)
+}
+
+func TestUnaryServerInterceptor_RetryFields(t *testing.T) {
+	l, err := logger.NewLogger(logger.WithNullOutput(), logger.WithLastEntriesCapture(10))
+	require.NoError(t, err)
+
+	interceptor := UnaryServerInterceptor(l, nil)
+	info := &grpc.UnaryServerInfo{FullMethod: "/orders.v1.Orders/Create"}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		"idempotency-key", "order-42",
+		"grpc-previous-rpc-attempts", "2",
+	))
+	_, err = interceptor(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	})
+	require.NoError(t, err)
+
+	crumbs := l.Breadcrumbs(0)
+	require.Len(t, crumbs, 1)
+	assert.Equal(t, "order-42", crumbs[0].Data[logger.IdempotencyKeyKey])
+	assert.Equal(t, 3, crumbs[0].Data[logger.AttemptKey])
+}
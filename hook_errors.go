@@ -0,0 +1,172 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DeadLetterHookKey  = "dead_letter_hook"
+	DeadLetterErrorKey = "dead_letter_error"
+)
+
+// HookErrorHandler is called with the hook that failed to fire, the entry it
+// was given and the error it returned. It may be called concurrently and the
+// entry must not be retained.
+type HookErrorHandler func(hook logrus.Hook, entry *logrus.Entry, err error)
+
+// hookErrorGuard holds the hook error handling configured on a logger. It
+// registers like a hook so it can be found, but firing it does nothing: the
+// hooks registered when the logger is created are wrapped in guardedHooks
+// reporting their errors to it.
+type hookErrorGuard struct {
+	handler HookErrorHandler
+
+	mu        sync.Mutex
+	dead      io.Writer
+	formatter logrus.JSONFormatter
+}
+
+// WithHookErrorHandler calls handler whenever a hook fails to fire, instead of
+// logrus reporting the error on stderr. It applies to the hooks registered when
+// the logger is created.
+func WithHookErrorHandler(handler HookErrorHandler) Option {
+	return func(l *Logger) error {
+		useHookErrorGuard(l.Entry.Logger).handler = handler
+		return nil
+	}
+}
+
+// WithDeadLetter writes the entries a hook failed to deliver to w as JSON lines,
+// with the hook type and the error under DeadLetterHookKey and
+// DeadLetterErrorKey, so they can be inspected or replayed. It applies to the
+// hooks registered when the logger is created and can be combined with
+// WithHookErrorHandler.
+func WithDeadLetter(w io.Writer) Option {
+	return func(l *Logger) error {
+		useHookErrorGuard(l.Entry.Logger).dead = w
+		return nil
+	}
+}
+
+// useHookErrorGuard returns the guard registered on l, registering one first
+// when needed
+func useHookErrorGuard(l *logrus.Logger) *hookErrorGuard {
+	if g, ok := findHook[*hookErrorGuard](l); ok {
+		return g
+	}
+	g := &hookErrorGuard{}
+	l.AddHook(g)
+	return g
+}
+
+// guardHooks replaces the hooks of l with guarded ones when a guard is
+// registered, wrapping a hook registered for several levels once. It runs once
+// the options have been applied, before the hooks move to the async worker.
+func guardHooks(l *logrus.Logger) {
+	g, ok := findHook[*hookErrorGuard](l)
+	if !ok {
+		return
+	}
+	// a slice rather than a map, as hooks may not be comparable
+	var guarded []*guardedHook
+	wrap := func(hook logrus.Hook) *guardedHook {
+		for _, gh := range guarded {
+			if containsHook([]logrus.Hook{gh.Hook}, hook) {
+				return gh
+			}
+		}
+		gh := &guardedHook{Hook: hook, guard: g}
+		guarded = append(guarded, gh)
+		return gh
+	}
+	hooks := make(logrus.LevelHooks, len(l.Hooks))
+	for level, levelHooks := range l.Hooks {
+		for _, hook := range levelHooks {
+			switch hook.(type) {
+			case *hookErrorGuard, *asyncDispatcher, *guardedHook:
+			default:
+				hook = wrap(hook)
+			}
+			hooks[level] = append(hooks[level], hook)
+		}
+	}
+	l.ReplaceHooks(hooks)
+}
+
+func (g *hookErrorGuard) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Enriches implements EnrichingHook, it inspects entries ahead of the sinks
+func (g *hookErrorGuard) Enriches() bool {
+	return true
+}
+
+func (g *hookErrorGuard) Fire(*logrus.Entry) error {
+	return nil
+}
+
+// report hands a hook failure to the handler and the dead-letter writer
+func (g *hookErrorGuard) report(hook logrus.Hook, entry *logrus.Entry, err error) {
+	if g.handler != nil {
+		g.handler(hook, entry, err)
+	}
+	if g.dead == nil {
+		return
+	}
+
+	data := make(logrus.Fields, len(entry.Data)+2)
+	for k, v := range entry.Data {
+		data[k] = v
+	}
+	data[DeadLetterHookKey] = fmt.Sprintf("%T", hook)
+	data[DeadLetterErrorKey] = err.Error()
+	line, ferr := g.formatter.Format(&logrus.Entry{
+		Logger:  entry.Logger,
+		Data:    data,
+		Time:    entry.Time,
+		Level:   entry.Level,
+		Message: entry.Message,
+	})
+	if ferr != nil {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.dead.Write(line)
+}
+
+// guardedHook reports the errors of the hook it wraps to a hookErrorGuard
+// instead of returning them to logrus
+type guardedHook struct {
+	logrus.Hook
+	guard *hookErrorGuard
+}
+
+func (h *guardedHook) Fire(entry *logrus.Entry) error {
+	if err := h.Hook.Fire(entry); err != nil {
+		h.guard.report(h.Hook, entry, err)
+	}
+	return nil
+}
+
+// Unwrap returns the wrapped hook
+func (h *guardedHook) Unwrap() logrus.Hook {
+	return h.Hook
+}
+
+// unwrapHook returns the hook wrapped by a guardedHook, hook otherwise. The
+// suppressibleHook wrapping either is looked through.
+func unwrapHook(hook logrus.Hook) logrus.Hook {
+	if s, ok := hook.(*suppressibleHook); ok {
+		hook = s.Hook
+	}
+	if w, ok := hook.(interface{ Unwrap() logrus.Hook }); ok {
+		return w.Unwrap()
+	}
+	return hook
+}
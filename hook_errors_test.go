@@ -0,0 +1,100 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// closingHook records whether it was closed
+type closingHook struct {
+	flakyHook
+	closed bool
+}
+
+func (h *closingHook) Close() error {
+	h.closed = true
+	return nil
+}
+
+func TestWithHookErrorHandler(t *testing.T) {
+	hook := &flakyHook{broken: true}
+	var failures []string
+	logger, err := NewLogger(
+		WithNullOutput(),
+		WithHooks(hook),
+		WithHookErrorHandler(func(h logrus.Hook, entry *logrus.Entry, err error) {
+			assert.Same(t, hook, h)
+			failures = append(failures, entry.Message+": "+err.Error())
+		}),
+	)
+	require.NoError(t, err)
+
+	logger.Info("lost")
+	hook.setBroken(false)
+	logger.Info("delivered")
+
+	assert.Equal(t, []string{"lost: unavailable"}, failures)
+	assert.Equal(t, []string{"delivered"}, hook.messages)
+}
+
+func TestWithDeadLetter(t *testing.T) {
+	var dead bytes.Buffer
+	logger, err := NewLogger(
+		WithNullOutput(),
+		WithHooks(&flakyHook{broken: true}),
+		WithDeadLetter(&dead),
+	)
+	require.NoError(t, err)
+
+	logger.WithField("order", 42).Error("payment failed")
+
+	var line map[string]interface{}
+	require.NoError(t, json.Unmarshal(dead.Bytes(), &line))
+	assert.Equal(t, "payment failed", line["msg"])
+	assert.Equal(t, "error", line["level"])
+	assert.Equal(t, float64(42), line["order"])
+	assert.Equal(t, "*logger.flakyHook", line[DeadLetterHookKey])
+	assert.Equal(t, "unavailable", line[DeadLetterErrorKey])
+}
+
+func TestWithDeadLetter_Async(t *testing.T) {
+	var dead bytes.Buffer
+	hook := &closingHook{flakyHook: flakyHook{broken: true}}
+	logger, err := NewLogger(
+		WithNullOutput(),
+		WithHooks(hook),
+		WithAsync(10, Block),
+		WithDeadLetter(&dead),
+	)
+	require.NoError(t, err)
+
+	logger.Info("first")
+	logger.Info("second")
+	d, ok := findHook[*asyncDispatcher](logger.Entry.Logger)
+	require.True(t, ok)
+	require.NoError(t, d.Close())
+
+	assert.Equal(t, 2, strings.Count(dead.String(), "\n"))
+	assert.True(t, hook.closed, "guarded hooks are still closed")
+	_, ok = findHook[*closingHook](d.shadow)
+	assert.True(t, ok, "guarded hooks are still found")
+}
+
+func TestWithHookErrorHandler_NonComparableHook(t *testing.T) {
+	fired := 0
+	logger, err := NewLogger(
+		WithNullOutput(),
+		WithHooks(valueHook{levels: logrus.AllLevels, fired: &fired}),
+		WithHookErrorHandler(func(logrus.Hook, *logrus.Entry, error) {}),
+	)
+	require.NoError(t, err)
+
+	logger.Info("hello")
+	assert.Equal(t, 1, fired)
+}
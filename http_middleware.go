@@ -0,0 +1,177 @@
+package logger
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	DefaultIdempotencyHeader = "Idempotency-Key"
+	DefaultAttemptHeader     = "X-Retry-Attempt"
+)
+
+// HTTPMiddlewareOptions holds configuration for the HTTP access log middleware
+type HTTPMiddlewareOptions struct {
+	LatencyBuckets []time.Duration // defaults to DefaultLatencyBuckets
+	Message        string          // access entry message, defaults to "request completed"
+	// IdempotencyHeader and AttemptHeader name the request headers copied to the
+	// idempotency_key and attempt fields, defaulting to DefaultIdempotencyHeader
+	// and DefaultAttemptHeader
+	IdempotencyHeader string
+	AttemptHeader     string
+	// RoutePattern returns the route matched by the request (e.g. /users/{id}),
+	// logged as the route field so entries can be grouped per endpoint. See the
+	// chilog and muxlog packages.
+	RoutePattern func(*http.Request) string
+}
+
+func (o *HTTPMiddlewareOptions) withDefaults() HTTPMiddlewareOptions {
+	opts := HTTPMiddlewareOptions{}
+	if o != nil {
+		opts = *o
+	}
+	if len(opts.LatencyBuckets) == 0 {
+		opts.LatencyBuckets = DefaultLatencyBuckets
+	}
+	if opts.Message == "" {
+		opts.Message = "request completed"
+	}
+	if opts.IdempotencyHeader == "" {
+		opts.IdempotencyHeader = DefaultIdempotencyHeader
+	}
+	if opts.AttemptHeader == "" {
+		opts.AttemptHeader = DefaultAttemptHeader
+	}
+	return opts
+}
+
+// HTTPMiddleware returns net/http middleware logging one access entry per request
+// on l (the global Log when nil). Entries carry an outcome field (success,
+// client_error, server_error, timeout, canceled) and a latency_bucket field so
+// availability and latency SLOs can be computed from the logs.
+func HTTPMiddleware(l *Logger, opts *HTTPMiddlewareOptions) func(http.Handler) http.Handler {
+	o := opts.withDefaults()
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rw := &responseWriter{ResponseWriter: w}
+			// a RequestIDMiddleware wrapped by this one reports its id here
+			r = r.WithContext(context.WithValue(r.Context(), requestIDSlotKey{}, &requestIDSlot{}))
+
+			next.ServeHTTP(rw, r)
+
+			logHTTPAccess(l, r, rw.statusCode(), rw.bytes, time.Since(start), &o)
+		})
+	}
+}
+
+// LogHTTPAccess logs the access entry of a request served in latency on l (the
+// global Log when nil), as HTTPMiddleware does, for adapters of other routers
+func LogHTTPAccess(l *Logger, r *http.Request, status, bytes int, latency time.Duration, opts *HTTPMiddlewareOptions) {
+	o := opts.withDefaults()
+	logHTTPAccess(l, r, status, bytes, latency, &o)
+}
+
+func logHTTPAccess(l *Logger, r *http.Request, status, bytes int, latency time.Duration, o *HTTPMiddlewareOptions) {
+	logger := l
+	if logger == nil {
+		logger = globalLogger()
+	}
+	outcome := ClassifyHTTPOutcome(status, r.Context().Err())
+
+	fields := Fields{
+		"method":         r.Method,
+		"path":           r.URL.Path,
+		"status":         status,
+		"bytes":          bytes,
+		"remote_addr":    r.RemoteAddr,
+		"user_agent":     r.UserAgent(),
+		"latency_ms":     durationMillis(latency),
+		"latency_bucket": LatencyBucket(latency, o.LatencyBuckets),
+		"outcome":        string(outcome),
+	}
+	if o.RoutePattern != nil {
+		if route := o.RoutePattern(r); route != "" {
+			fields["route"] = route
+		}
+	}
+	if id := requestIDOf(r); id != "" {
+		fields[RequestIDKey] = id
+	}
+	if key := r.Header.Get(o.IdempotencyHeader); key != "" {
+		fields[IdempotencyKeyKey] = key
+	}
+	if attempt, err := strconv.Atoi(r.Header.Get(o.AttemptHeader)); err == nil {
+		fields[AttemptKey] = attempt
+	}
+	logger.Entry.WithFields(fields).Log(outcome.Level(), o.Message)
+}
+
+// responseWriter records the status code and bytes written by a handler
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += n
+	return n, err
+}
+
+// Flush implements http.Flusher when the underlying writer supports it
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker when the underlying writer supports it, so
+// websocket upgrades work behind the middleware. The response is logged with
+// the 101 Switching Protocols status unless one was written.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	conn, rw, err := h.Hijack()
+	if err == nil && w.status == 0 {
+		w.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+// Push implements http.Pusher when the underlying writer supports it
+func (w *responseWriter) Push(target string, opts *http.PushOptions) error {
+	if p, ok := w.ResponseWriter.(http.Pusher); ok {
+		return p.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *responseWriter) statusCode() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
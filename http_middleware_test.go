@@ -0,0 +1,145 @@
+package logger
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyHTTPOutcome(t *testing.T) {
+	tests := []struct {
+		status int
+		ctxErr error
+		want   Outcome
+	}{
+		{status: http.StatusOK, want: OutcomeSuccess},
+		{status: http.StatusFound, want: OutcomeSuccess},
+		{status: http.StatusNotFound, want: OutcomeClientError},
+		{status: http.StatusInternalServerError, want: OutcomeServerError},
+		{status: http.StatusGatewayTimeout, want: OutcomeTimeout},
+		{status: http.StatusOK, ctxErr: context.DeadlineExceeded, want: OutcomeTimeout},
+		{status: http.StatusOK, ctxErr: context.Canceled, want: OutcomeCanceled},
+		{status: 499, want: OutcomeCanceled},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ClassifyHTTPOutcome(tt.status, tt.ctxErr), "status %d, err %v", tt.status, tt.ctxErr)
+	}
+}
+
+func TestLatencyBucket(t *testing.T) {
+	assert.Equal(t, "5ms", LatencyBucket(time.Millisecond, DefaultLatencyBuckets))
+	assert.Equal(t, "250ms", LatencyBucket(120*time.Millisecond, DefaultLatencyBuckets))
+	assert.Equal(t, "+Inf", LatencyBucket(time.Minute, DefaultLatencyBuckets))
+}
+
+func TestHTTPMiddleware(t *testing.T) {
+	logger, err := NewLogger(WithNullOutput(), WithLastEntriesCapture(10))
+	require.NoError(t, err)
+
+	handler := HTTPMiddleware(logger, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			http.NotFound(w, r)
+		case "/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.Write([]byte("hello"))
+		}
+	}))
+
+	for _, path := range []string{"/hello", "/missing", "/broken"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	crumbs := logger.Breadcrumbs(0)
+	require.Len(t, crumbs, 3)
+
+	assert.Equal(t, logrus.InfoLevel, crumbs[0].Level)
+	assert.Equal(t, "success", crumbs[0].Data["outcome"])
+	assert.Equal(t, 200, crumbs[0].Data["status"])
+	assert.Equal(t, 5, crumbs[0].Data["bytes"])
+	assert.Equal(t, "5ms", crumbs[0].Data["latency_bucket"])
+
+	assert.Equal(t, "client_error", crumbs[1].Data["outcome"])
+	assert.Equal(t, logrus.InfoLevel, crumbs[1].Level)
+
+	assert.Equal(t, "server_error", crumbs[2].Data["outcome"])
+	assert.Equal(t, logrus.ErrorLevel, crumbs[2].Level)
+}
+
+func TestHTTPMiddleware_Canceled(t *testing.T) {
+	logger, err := NewLogger(WithNullOutput(), WithLastEntriesCapture(10))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	handler := HTTPMiddleware(logger, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cancel()
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow", nil).WithContext(ctx))
+
+	crumbs := logger.Breadcrumbs(0)
+	require.Len(t, crumbs, 1)
+	assert.Equal(t, "canceled", crumbs[0].Data["outcome"])
+	assert.Equal(t, logrus.WarnLevel, crumbs[0].Level)
+}
+
+func TestHTTPMiddleware_Hijack(t *testing.T) {
+	logger, err := NewLogger(WithNullOutput(), WithLastEntriesCapture(10))
+	require.NoError(t, err)
+
+	handler := HTTPMiddleware(logger, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := http.NewResponseController(w).Hijack()
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+		rw.Flush()
+	}))
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("GET /ws HTTP/1.1\r\nHost: test\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n"))
+	require.NoError(t, err)
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+
+	require.Eventually(t, func() bool { return len(logger.Breadcrumbs(0)) == 1 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, 101, logger.Breadcrumbs(0)[0].Data["status"])
+
+	// writers without hijacking or push support report it
+	rw := &responseWriter{ResponseWriter: httptest.NewRecorder()}
+	_, _, err = rw.Hijack()
+	assert.ErrorIs(t, err, http.ErrNotSupported)
+	assert.ErrorIs(t, rw.Push("/style.css", nil), http.ErrNotSupported)
+}
+
+func TestHTTPMiddleware_RetryFields(t *testing.T) {
+	logger, err := NewLogger(WithNullOutput(), WithLastEntriesCapture(10))
+	require.NoError(t, err)
+
+	handler := HTTPMiddleware(logger, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	req := httptest.NewRequest(http.MethodPost, "/payments", nil)
+	req.Header.Set("Idempotency-Key", "pay-123")
+	req.Header.Set("X-Retry-Attempt", "2")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	crumbs := logger.Breadcrumbs(0)
+	require.Len(t, crumbs, 2)
+	assert.Equal(t, "pay-123", crumbs[0].Data[IdempotencyKeyKey])
+	assert.Equal(t, 2, crumbs[0].Data[AttemptKey])
+	assert.NotContains(t, crumbs[1].Data, AttemptKey)
+}
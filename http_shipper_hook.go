@@ -0,0 +1,236 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultHTTPShipperBatchSize     = 500
+	DefaultHTTPShipperFlushInterval = 2 * time.Second
+	DefaultHTTPShipperQueueSize     = 10000
+	DefaultHTTPShipperMaxRetries    = 3
+	DefaultHTTPShipperTimeout       = 10 * time.Second
+)
+
+// HTTPShipperEncoding selects how a batch is framed in the request body
+type HTTPShipperEncoding int
+
+const (
+	// HTTPShipperNDJSON sends one formatted entry per line
+	HTTPShipperNDJSON HTTPShipperEncoding = iota
+	// HTTPShipperJSONArray sends the batch as a JSON array; the formatter must
+	// produce JSON objects
+	HTTPShipperJSONArray
+)
+
+// HTTPShipperConfig holds configuration for the generic HTTP shipper hook
+type HTTPShipperConfig struct {
+	URL       string
+	Method    string // defaults to POST
+	Headers   map[string]string
+	Encoding  HTTPShipperEncoding
+	Gzip      bool             // gzip the request body
+	Formatter logrus.Formatter // defaults to logrus.JSONFormatter
+	// BatchSize and FlushInterval bound the entries sent per request and the time
+	// an entry waits for its batch
+	BatchSize     int
+	FlushInterval time.Duration
+	QueueSize     int
+	Block         bool // block logging when the queue is full instead of dropping
+	// MaxRetries is the number of retries for requests failing with a network
+	// error, 429 or 5xx status
+	MaxRetries int
+	Retry      *RetryPolicy // overrides MaxRetries
+	TLS        *TLSConfig
+	Timeout    time.Duration
+	Levels     []logrus.Level
+}
+
+// httpShipperHook implements logrus.Hook posting batches of formatted entries
+// to an HTTP endpoint
+type httpShipperHook struct {
+	cfg     HTTPShipperConfig
+	client  *http.Client
+	retry   RetryPolicy
+	batcher *Batcher[[]byte]
+	mu      sync.Mutex
+}
+
+// NewHTTPShipperHook creates a new hook posting batches of entries to an
+// arbitrary HTTP endpoint
+func NewHTTPShipperHook(cfg *HTTPShipperConfig) (*httpShipperHook, error) {
+	if cfg == nil || cfg.URL == "" {
+		return nil, fmt.Errorf("http shipper: url is required")
+	}
+	c := *cfg
+	if c.Method == "" {
+		c.Method = http.MethodPost
+	}
+	if c.Formatter == nil {
+		c.Formatter = &logrus.JSONFormatter{}
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = DefaultHTTPShipperBatchSize
+	}
+	if c.FlushInterval <= 0 {
+		c.FlushInterval = DefaultHTTPShipperFlushInterval
+	}
+	if c.QueueSize <= 0 {
+		c.QueueSize = DefaultHTTPShipperQueueSize
+	}
+	if c.MaxRetries < 0 {
+		c.MaxRetries = 0
+	} else if c.MaxRetries == 0 {
+		c.MaxRetries = DefaultHTTPShipperMaxRetries
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultHTTPShipperTimeout
+	}
+	if len(c.Levels) == 0 {
+		c.Levels = logrus.AllLevels
+	}
+
+	tlsConfig, err := c.TLS.build()
+	if err != nil {
+		return nil, fmt.Errorf("http shipper: %w", err)
+	}
+
+	hook := &httpShipperHook{
+		cfg:    c,
+		client: newHTTPClient(c.Timeout, tlsConfig),
+		retry:  retryPolicy(c.Retry, RetryPolicy{MaxAttempts: c.MaxRetries + 1}),
+	}
+	hook.batcher = NewBatcher(c.BatchSize, c.FlushInterval, c.QueueSize, c.Block, hook.ship)
+	return hook, nil
+}
+
+// WithHTTPShipper adds a hook posting batches of entries to an HTTP endpoint
+func WithHTTPShipper(cfg *HTTPShipperConfig) Option {
+	return func(l *Logger) error {
+		hook, err := NewHTTPShipperHook(cfg)
+		if err != nil {
+			return err
+		}
+		l.Entry.Logger.AddHook(hook)
+		return nil
+	}
+}
+
+func (h *httpShipperHook) Levels() []logrus.Level {
+	return h.cfg.Levels
+}
+
+// Fire formats the entry and queues it for the next batch
+func (h *httpShipperHook) Fire(entry *logrus.Entry) error {
+	h.mu.Lock()
+	line, err := h.cfg.Formatter.Format(entry)
+	h.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	h.batcher.Add(bytes.TrimRight(line, "\n"))
+	return nil
+}
+
+// Dropped returns the number of entries discarded because the queue was full
+func (h *httpShipperHook) Dropped() uint64 {
+	return h.batcher.Dropped()
+}
+
+// Flush ships the queued entries without stopping the background worker
+func (h *httpShipperHook) Flush() error {
+	h.batcher.Sync()
+	return nil
+}
+
+// Close ships pending entries and stops the background worker
+func (h *httpShipperHook) Close() error {
+	h.batcher.Close()
+	return nil
+}
+
+// ship encodes a batch and posts it, retrying transient failures
+func (h *httpShipperHook) ship(lines [][]byte) {
+	body, err := h.encode(lines)
+	if err != nil {
+		return
+	}
+
+	h.retry.Deliver("http", len(lines), body, h.batcher.Closing(), h.send)
+}
+
+// encode frames the batch according to the configured encoding, gzipping it
+// if enabled
+func (h *httpShipperHook) encode(lines [][]byte) ([]byte, error) {
+	var buf bytes.Buffer
+	var w io.Writer = &buf
+	var zw *gzip.Writer
+	if h.cfg.Gzip {
+		zw = gzip.NewWriter(&buf)
+		w = zw
+	}
+
+	switch h.cfg.Encoding {
+	case HTTPShipperJSONArray:
+		w.Write([]byte{'['})
+		for i, line := range lines {
+			if i > 0 {
+				w.Write([]byte{','})
+			}
+			w.Write(line)
+		}
+		w.Write([]byte{']'})
+	default:
+		for _, line := range lines {
+			w.Write(line)
+			w.Write([]byte{'\n'})
+		}
+	}
+
+	if zw != nil {
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// send performs a single request and reports whether it should be retried
+func (h *httpShipperHook) send(body []byte) (bool, error) {
+	req, err := http.NewRequest(h.cfg.Method, h.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	if h.cfg.Encoding == HTTPShipperJSONArray {
+		req.Header.Set("Content-Type", "application/json")
+	} else {
+		req.Header.Set("Content-Type", "application/x-ndjson")
+	}
+	if h.cfg.Gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	for k, v := range h.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode/100 == 2 {
+		return false, nil
+	}
+	err = fmt.Errorf("http shipper: request failed with status %d", resp.StatusCode)
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500, err
+}
@@ -0,0 +1,118 @@
+package logger
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPShipperHook_NDJSONGzip(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		bodies []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+		assert.Equal(t, "application/x-ndjson", r.Header.Get("Content-Type"))
+		assert.Equal(t, "secret", r.Header.Get("Authorization"))
+		zr, err := gzip.NewReader(r.Body)
+		require.NoError(t, err)
+		body, err := io.ReadAll(zr)
+		require.NoError(t, err)
+		mu.Lock()
+		bodies = append(bodies, string(body))
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	hook, err := NewHTTPShipperHook(&HTTPShipperConfig{
+		URL:           srv.URL,
+		Headers:       map[string]string{"Authorization": "secret"},
+		Gzip:          true,
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+	})
+	require.NoError(t, err)
+
+	logger, err := NewLogger(WithNullOutput())
+	require.NoError(t, err)
+	logger.Logger.AddHook(hook)
+
+	logger.Info("one")
+	logger.Info("two")
+	logger.Info("three")
+	require.NoError(t, hook.Close())
+
+	require.Len(t, bodies, 2)
+	lines := strings.Split(strings.TrimSuffix(bodies[0], "\n"), "\n")
+	require.Len(t, lines, 2)
+	var first map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+	assert.Equal(t, "one", first["msg"])
+}
+
+func TestHTTPShipperHook_JSONArrayRetry(t *testing.T) {
+	var calls atomic.Int32
+	var body []interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+	}))
+	defer srv.Close()
+
+	hook, err := NewHTTPShipperHook(&HTTPShipperConfig{URL: srv.URL, Encoding: HTTPShipperJSONArray})
+	require.NoError(t, err)
+
+	logger, err := NewLogger(WithNullOutput())
+	require.NoError(t, err)
+	logger.Logger.AddHook(hook)
+
+	logger.Warn("a")
+	logger.Warn("b")
+	require.NoError(t, hook.Close())
+
+	assert.Equal(t, int32(2), calls.Load())
+	assert.Len(t, body, 2)
+}
+
+func TestHTTPShipperHook_FlushOnPanic(t *testing.T) {
+	var posts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posts.Add(1)
+	}))
+	defer srv.Close()
+
+	hook, err := NewHTTPShipperHook(&HTTPShipperConfig{URL: srv.URL, BatchSize: 100, FlushInterval: time.Hour})
+	require.NoError(t, err)
+	defer hook.Close()
+
+	logger, err := NewLogger(WithNullOutput(), WithHooks(hook))
+	require.NoError(t, err)
+
+	logger.Info("before the crash")
+	assert.Panics(t, func() { logger.Panic("crashing") })
+	assert.Equal(t, int32(1), posts.Load())
+
+	// the hook keeps shipping after a recovered panic
+	logger.Info("recovered")
+	require.NoError(t, hook.Close())
+	assert.Equal(t, int32(2), posts.Load())
+}
+
+func TestNewHTTPShipperHook_Validation(t *testing.T) {
+	_, err := NewHTTPShipperHook(nil)
+	assert.Error(t, err)
+}
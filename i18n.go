@@ -0,0 +1,124 @@
+package logger
+
+import (
+	"os"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// MessageCodeKey is the field holding the stable code of a message, looked up
+// in catalogs before the message itself
+const MessageCodeKey = "msg_code"
+
+// Catalog maps message codes or canonical (English) message templates to
+// localized templates. Localized templates may reference entry fields as
+// {field} and the level as {level}.
+type Catalog map[string]string
+
+// Catalogs holds the catalog of every supported locale, keyed by locale such as
+// "fr" or "pt_BR"
+type Catalogs map[string]Catalog
+
+// Lookup returns the catalog of locale, falling back from "pt_BR.UTF-8" to
+// "pt_BR" and then "pt". It returns nil when no catalog matches.
+func (c Catalogs) Lookup(locale string) Catalog {
+	locale, _, _ = strings.Cut(locale, ".")
+	locale, _, _ = strings.Cut(locale, "@")
+	locale = strings.ReplaceAll(locale, "-", "_")
+	if catalog, ok := c[locale]; ok {
+		return catalog
+	}
+	if lang, _, ok := strings.Cut(locale, "_"); ok {
+		return c[lang]
+	}
+	return nil
+}
+
+// LocaleFromEnv returns the locale of the user from LC_ALL, LC_MESSAGES or LANG,
+// in that order
+func LocaleFromEnv() string {
+	for _, key := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if locale := os.Getenv(key); locale != "" {
+			return locale
+		}
+	}
+	return ""
+}
+
+// localize returns the localized message of entry, looking up its message code
+// first, and false when the catalog has no translation for it
+func (c Catalog) localize(entry *logrus.Entry) (string, bool) {
+	tmpl, ok := "", false
+	if code, isString := entry.Data[MessageCodeKey].(string); isString {
+		tmpl, ok = c[code]
+	}
+	if !ok {
+		tmpl, ok = c[entry.Message]
+	}
+	if !ok {
+		return "", false
+	}
+	return ExpandFields(tmpl, entry, nil), true
+}
+
+// LocalizingFormatter renders entries with their message translated by
+// Catalog, for output shown to end users. Only the rendered copy is
+// translated: hooks and machine sinks keep the canonical message. The message
+// code field is left out of the rendered entry; entries without a translation
+// are rendered unchanged.
+type LocalizingFormatter struct {
+	Formatter logrus.Formatter // defaults to a TextFormatter
+	Catalog   Catalog
+}
+
+func (f *LocalizingFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	formatter := f.Formatter
+	if formatter == nil {
+		formatter = &logrus.TextFormatter{}
+	}
+	message, translated := f.Catalog.localize(entry)
+	_, coded := entry.Data[MessageCodeKey]
+	if !translated && !coded {
+		return formatter.Format(entry)
+	}
+
+	localized := *entry
+	if translated {
+		localized.Message = message
+	}
+	if coded {
+		localized.Data = make(logrus.Fields, len(entry.Data))
+		for k, v := range entry.Data {
+			if k != MessageCodeKey {
+				localized.Data[k] = v
+			}
+		}
+	}
+	return formatter.Format(&localized)
+}
+
+// WithMessageCatalog translates the messages written to the output with
+// catalog, see LocalizingFormatter. Hooks keep receiving the canonical
+// messages. Apply it after the options setting the formatter, such as
+// WithFormatter or WithCLIMode.
+func WithMessageCatalog(catalog Catalog) Option {
+	return func(l *Logger) error {
+		current := l.Entry.Logger.Formatter
+		if s, ok := current.(*suppressingFormatter); ok {
+			current = s.Formatter
+		}
+		setFormatter(l.Entry.Logger, &LocalizingFormatter{Formatter: current, Catalog: catalog})
+		return nil
+	}
+}
+
+// WithLocale is WithMessageCatalog using the catalog of locale, the locale of
+// the user (see LocaleFromEnv) when empty. Messages are left untranslated when
+// catalogs has no catalog for the locale.
+func WithLocale(catalogs Catalogs, locale string) Option {
+	if locale == "" {
+		locale = LocaleFromEnv()
+	}
+	return WithMessageCatalog(catalogs.Lookup(locale))
+}
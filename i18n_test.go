@@ -0,0 +1,64 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithMessageCatalog(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	logger, err := NewLogger(
+		WithCLIStreams(&stdout, &stderr),
+		WithLastEntriesCapture(5),
+		WithMessageCatalog(Catalog{
+			"disk_full":    "disque plein sur {mount}",
+			"sync started": "synchronisation démarrée",
+		}),
+	)
+	require.NoError(t, err)
+
+	logger.Info("sync started")
+	logger.WithFields(Fields{MessageCodeKey: "disk_full", "mount": "/data"}).Warn("disk full on /data")
+	logger.Info("not translated")
+
+	assert.Equal(t, "INFO synchronisation démarrée\nWARNING disque plein sur /data mount=/data\nINFO not translated\n", stderr.String())
+
+	crumbs := logger.Breadcrumbs(0)
+	require.Len(t, crumbs, 3)
+	assert.Equal(t, "sync started", crumbs[0].Message)
+	assert.Equal(t, "disk full on /data", crumbs[1].Message)
+	assert.Equal(t, "disk_full", crumbs[1].Data[MessageCodeKey])
+}
+
+func TestCatalogsLookup(t *testing.T) {
+	catalogs := Catalogs{
+		"pt":    {"hello": "olá"},
+		"pt_BR": {"hello": "oi"},
+	}
+
+	assert.Equal(t, "oi", catalogs.Lookup("pt_BR.UTF-8")["hello"])
+	assert.Equal(t, "oi", catalogs.Lookup("pt-BR")["hello"])
+	assert.Equal(t, "olá", catalogs.Lookup("pt_PT")["hello"])
+	assert.Nil(t, catalogs.Lookup("de_DE"))
+	assert.Nil(t, catalogs.Lookup(""))
+}
+
+func TestWithLocale_FromEnv(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "fr_FR.UTF-8")
+
+	var buf bytes.Buffer
+	logger, err := NewLogger(
+		WithOutput(&buf),
+		WithFormatter(&PlainFormatter{}),
+		WithLocale(Catalogs{"fr": {"ready": "prêt"}}, ""),
+	)
+	require.NoError(t, err)
+
+	logger.Info("ready")
+	assert.Equal(t, "INFO prêt\n", buf.String())
+}
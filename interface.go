@@ -0,0 +1,33 @@
+package logger
+
+//go:generate mockery --name Interface --structname Logger --output mocks --outpkg mocks
+
+// Interface is the core logging API implemented by *Logger. Code depending on
+// Interface rather than *Logger can be unit tested with the mock in the mocks
+// package, asserting logging calls without capturing output.
+type Interface interface {
+	Trace(args ...interface{})
+	Tracef(format string, args ...interface{})
+	Debug(args ...interface{})
+	Debugf(format string, args ...interface{})
+	Info(args ...interface{})
+	Infof(format string, args ...interface{})
+	Warn(args ...interface{})
+	Warnf(format string, args ...interface{})
+	Error(args ...interface{})
+	Errorf(format string, args ...interface{})
+	Fatal(args ...interface{})
+	Fatalf(format string, args ...interface{})
+	Panic(args ...interface{})
+	Panicf(format string, args ...interface{})
+
+	WithFields(fields Fields) Interface
+	WithError(err error) Interface
+}
+
+var _ Interface = (*Logger)(nil)
+
+// WithFields returns a logger adding fields to every entry
+func (l *Logger) WithFields(fields Fields) Interface {
+	return &Logger{Entry: l.Entry.WithFields(fields)}
+}
@@ -0,0 +1,210 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultJournaldSocket is the path of the journald native protocol socket
+	DefaultJournaldSocket = "/run/systemd/journal/socket"
+)
+
+// JournaldConfig holds configuration for the journald hook
+type JournaldConfig struct {
+	SocketPath string // defaults to DefaultJournaldSocket
+	Identifier string // SYSLOG_IDENTIFIER, defaults to the program name
+	Levels     []logrus.Level
+}
+
+// journaldHook implements logrus.Hook writing entries via the journald native protocol
+type journaldHook struct {
+	conn       *net.UnixConn
+	identifier string
+	levels     []logrus.Level
+	mu         sync.Mutex
+}
+
+// journaldPriorities maps logrus levels to syslog priorities
+var journaldPriorities = map[logrus.Level]int{
+	logrus.PanicLevel: 2, // crit
+	logrus.FatalLevel: 2, // crit
+	logrus.ErrorLevel: 3, // err
+	logrus.WarnLevel:  4, // warning
+	logrus.InfoLevel:  6, // info
+	logrus.DebugLevel: 7, // debug
+	logrus.TraceLevel: 7, // debug
+}
+
+// NewJournaldHook creates a new hook that sends entries to the systemd journal
+func NewJournaldHook(cfg *JournaldConfig) (*journaldHook, error) {
+	if cfg == nil {
+		cfg = &JournaldConfig{}
+	}
+	if cfg.SocketPath == "" {
+		cfg.SocketPath = DefaultJournaldSocket
+	}
+	if cfg.Identifier == "" {
+		cfg.Identifier = filepath.Base(os.Args[0])
+	}
+	if len(cfg.Levels) == 0 {
+		cfg.Levels = logrus.AllLevels
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: cfg.SocketPath, Net: "unixgram"})
+	if err != nil {
+		return nil, fmt.Errorf("journald: %w", err)
+	}
+
+	return &journaldHook{
+		conn:       conn,
+		identifier: cfg.Identifier,
+		levels:     cfg.Levels,
+	}, nil
+}
+
+// WithJournald adds a hook that sends entries to the systemd journal
+func WithJournald(cfg *JournaldConfig) Option {
+	return func(l *Logger) error {
+		hook, err := NewJournaldHook(cfg)
+		if err != nil {
+			return err
+		}
+		l.Entry.Logger.AddHook(hook)
+		return nil
+	}
+}
+
+// Fire sends the log entry to the journal
+func (h *journaldHook) Fire(entry *logrus.Entry) error {
+	msg := encodeJournaldEntry(entry, h.identifier)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	_, err := h.conn.Write(msg)
+	if err == nil {
+		return nil
+	}
+	// entries too large for a single datagram are passed as a file descriptor
+	if errors.Is(err, syscall.EMSGSIZE) || errors.Is(err, syscall.ENOBUFS) {
+		return h.sendViaFile(msg)
+	}
+	return err
+}
+
+// Levels returns the levels this hook should be fired for
+func (h *journaldHook) Levels() []logrus.Level {
+	return h.levels
+}
+
+// Close implements io.Closer
+func (h *journaldHook) Close() error {
+	return h.conn.Close()
+}
+
+// encodeJournaldEntry serializes an entry into the journald native protocol format
+func encodeJournaldEntry(entry *logrus.Entry, identifier string) []byte {
+	var b bytes.Buffer
+
+	writeJournaldField(&b, "MESSAGE", entry.Message)
+	writeJournaldField(&b, "PRIORITY", strconv.Itoa(journaldPriorities[entry.Level]))
+	writeJournaldField(&b, "SYSLOG_IDENTIFIER", identifier)
+
+	if entry.HasCaller() {
+		writeJournaldField(&b, "CODE_FILE", entry.Caller.File)
+		writeJournaldField(&b, "CODE_LINE", strconv.Itoa(entry.Caller.Line))
+		writeJournaldField(&b, "CODE_FUNC", entry.Caller.Function)
+	}
+
+	for key, value := range entry.Data {
+		switch key {
+		case "src":
+			// set by the runtime context hook as file:line
+			src := fmt.Sprint(value)
+			if i := strings.LastIndex(src, ":"); i != -1 {
+				writeJournaldField(&b, "CODE_FILE", src[:i])
+				writeJournaldField(&b, "CODE_LINE", src[i+1:])
+				continue
+			}
+			writeJournaldField(&b, "CODE_FILE", src)
+		case "func":
+			writeJournaldField(&b, "CODE_FUNC", fmt.Sprint(value))
+		case logrus.ErrorKey:
+			if err, ok := value.(error); ok {
+				writeJournaldField(&b, "ERROR", err.Error())
+				continue
+			}
+			writeJournaldField(&b, "ERROR", fmt.Sprint(value))
+		default:
+			writeJournaldField(&b, journaldFieldName(key), fmt.Sprint(value))
+		}
+	}
+	return b.Bytes()
+}
+
+// writeJournaldField appends a single field. Values containing newlines use the
+// binary length-prefixed form of the protocol.
+func writeJournaldField(b *bytes.Buffer, name, value string) {
+	if !strings.Contains(value, "\n") {
+		b.WriteString(name)
+		b.WriteByte('=')
+		b.WriteString(value)
+		b.WriteByte('\n')
+		return
+	}
+	b.WriteString(name)
+	b.WriteByte('\n')
+	binary.Write(b, binary.LittleEndian, uint64(len(value)))
+	b.WriteString(value)
+	b.WriteByte('\n')
+}
+
+// journaldReserved lists the fields written by the hook itself, which user
+// fields must not duplicate
+var journaldReserved = map[string]bool{
+	"MESSAGE":           true,
+	"PRIORITY":          true,
+	"SYSLOG_IDENTIFIER": true,
+	"CODE_FILE":         true,
+	"CODE_LINE":         true,
+	"CODE_FUNC":         true,
+	"ERROR":             true,
+}
+
+// journaldFieldName converts a logrus field key into a valid journal field name:
+// uppercase letters, digits and underscores, not starting with an underscore or
+// digit. Names of the fields written by the hook get a FIELD_ prefix.
+func journaldFieldName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case 'a' <= r && r <= 'z':
+			return r - 'a' + 'A'
+		case 'A' <= r && r <= 'Z', '0' <= r && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+	name = strings.TrimLeft(name, "_")
+	if name == "" || ('0' <= name[0] && name[0] <= '9') {
+		name = "F_" + name
+	} else if journaldReserved[name] {
+		name = "FIELD_" + name
+	}
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return name
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package logger
+
+import "syscall"
+
+// sendViaFile is not supported on this platform
+func (h *journaldHook) sendViaFile(msg []byte) error {
+	return syscall.EMSGSIZE
+}
@@ -0,0 +1,98 @@
+//go:build unix
+
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournaldHook(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "journald_hook_test")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	socket := filepath.Join(tmpDir, "journal.sock")
+	server, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	require.NoError(t, err)
+	defer server.Close()
+
+	hook, err := NewJournaldHook(&JournaldConfig{SocketPath: socket, Identifier: "test-app"})
+	require.NoError(t, err)
+	defer hook.Close()
+
+	logger := logrus.New()
+	logger.AddHook(hook)
+	logger.SetOutput(&bytes.Buffer{})
+
+	logger.WithFields(logrus.Fields{
+		"request-id": "abc",
+		"src":        "app/main.go:42",
+		"func":       "main.run",
+		"trace":      "line1\nline2",
+	}).Warn("disk almost full")
+
+	buf := make([]byte, 4096)
+	n, err := server.Read(buf)
+	require.NoError(t, err)
+	msg := string(buf[:n])
+
+	assert.Contains(t, msg, "MESSAGE=disk almost full\n")
+	assert.Contains(t, msg, "PRIORITY=4\n")
+	assert.Contains(t, msg, "SYSLOG_IDENTIFIER=test-app\n")
+	assert.Contains(t, msg, "REQUEST_ID=abc\n")
+	assert.Contains(t, msg, "CODE_FILE=app/main.go\n")
+	assert.Contains(t, msg, "CODE_LINE=42\n")
+	assert.Contains(t, msg, "CODE_FUNC=main.run\n")
+
+	var multiline bytes.Buffer
+	multiline.WriteString("TRACE\n")
+	binary.Write(&multiline, binary.LittleEndian, uint64(len("line1\nline2")))
+	multiline.WriteString("line1\nline2\n")
+	assert.Contains(t, msg, multiline.String())
+}
+
+func TestJournaldHook_MissingSocket(t *testing.T) {
+	_, err := NewJournaldHook(&JournaldConfig{SocketPath: filepath.Join(os.TempDir(), "missing-journal.sock")})
+	assert.Error(t, err)
+}
+
+func TestEncodeJournaldEntry_ReservedFields(t *testing.T) {
+	entry := logrus.NewEntry(logrus.New()).WithFields(logrus.Fields{
+		"message":  "from the user",
+		"priority": "high",
+	})
+	entry.Message = "disk almost full"
+	entry.Level = logrus.WarnLevel
+	msg := string(encodeJournaldEntry(entry, "test-app"))
+
+	assert.Equal(t, 1, strings.Count(msg, "MESSAGE=disk almost full\n"))
+	assert.NotContains(t, msg, "\nMESSAGE=from the user")
+	assert.Contains(t, msg, "FIELD_MESSAGE=from the user\n")
+	assert.Equal(t, 1, strings.Count(msg, "\nPRIORITY="))
+	assert.Contains(t, msg, "FIELD_PRIORITY=high\n")
+}
+
+func TestJournaldFieldName(t *testing.T) {
+	tests := map[string]string{
+		"request_id":        "REQUEST_ID",
+		"user.name":         "USER_NAME",
+		"_private":          "PRIVATE",
+		"1st":               "F_1ST",
+		"message":           "FIELD_MESSAGE",
+		"priority":          "FIELD_PRIORITY",
+		"Syslog-Identifier": "FIELD_SYSLOG_IDENTIFIER",
+	}
+	for key, want := range tests {
+		assert.Equal(t, want, journaldFieldName(key), key)
+	}
+}
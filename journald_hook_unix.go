@@ -0,0 +1,30 @@
+//go:build unix
+
+package logger
+
+import (
+	"os"
+	"syscall"
+)
+
+// sendViaFile writes msg to an unlinked temporary file and passes its descriptor
+// to journald, which is how the protocol handles entries exceeding the datagram size
+func (h *journaldHook) sendViaFile(msg []byte) error {
+	f, err := os.CreateTemp("/dev/shm", "journald-")
+	if err != nil {
+		f, err = os.CreateTemp("", "journald-")
+		if err != nil {
+			return err
+		}
+	}
+	defer f.Close()
+	os.Remove(f.Name())
+
+	if _, err := f.Write(msg); err != nil {
+		return err
+	}
+
+	rights := syscall.UnixRights(int(f.Fd()))
+	_, _, err = h.conn.WriteMsgUnix(nil, rights, nil)
+	return err
+}
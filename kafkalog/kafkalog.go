@@ -0,0 +1,316 @@
+// Package kafkalog produces go-logger entries to Kafka. Importing it registers
+// the kafka scheme with logger.RegisterSink, so kafka://broker:9092/topic URLs
+// can be used with logger.WithOutputURIs and logger.NewSink.
+package kafkalog
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/IBM/sarama"
+	logger "github.com/alejoacosta74/go-logger"
+	"github.com/sirupsen/logrus"
+)
+
+// errClosed is reported for entries logged once the hook is closed
+var errClosed = errors.New("kafka: producer is closed")
+
+func init() {
+	if err := logger.RegisterSink("kafka", newSink); err != nil {
+		panic(err)
+	}
+}
+
+// Options holds configuration for the Kafka hook
+type Options struct {
+	// KeyField selects the entry field used as the message key (e.g. request_id),
+	// so related entries land on the same partition. Messages have no key when
+	// empty or when the field is missing.
+	KeyField string
+	// OnError is called for every message the async producer fails to deliver
+	OnError func(err error, line []byte)
+	// Retry bounds the attempts to deliver a message once the producer retries
+	// (Config.Producer.Retry) are exhausted, a single one by default. Messages
+	// given up on are passed to its OnFailure, then to OnError.
+	Retry     *logger.RetryPolicy
+	Formatter logrus.Formatter
+	Levels    []logrus.Level
+	// Config is passed to the sarama producer; sarama defaults are used when nil
+	Config *sarama.Config
+}
+
+// hook implements logrus.Hook producing formatted entries to a Kafka topic
+type hook struct {
+	producer  sarama.AsyncProducer
+	topic     string
+	keyField  string
+	onError   func(err error, line []byte)
+	retry     logger.RetryPolicy
+	formatter logrus.Formatter
+	levels    []logrus.Level
+	mu        sync.Mutex
+	wg        sync.WaitGroup
+
+	// closeMu guards sending on the producer against closing it
+	closeMu   sync.RWMutex
+	closed    bool
+	closeOnce sync.Once
+	// stop is closed on Close so retries stop waiting
+	stop chan struct{}
+}
+
+// pending receives the outcome of a message sent again by the retry policy, it
+// is set as the message metadata
+type pending chan error
+
+// NewHook creates a new hook producing entries to topic on the given brokers
+func NewHook(brokers []string, topic string, opts *Options) (*hook, error) {
+	if len(brokers) == 0 {
+		return nil, fmt.Errorf("kafka: at least one broker is required")
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	// copied, so the caller's config is left as is
+	config := sarama.NewConfig()
+	if opts.Config != nil {
+		c := *opts.Config
+		config = &c
+	}
+	// errors are always reported through OnError, successes tell retries apart
+	config.Producer.Return.Errors = true
+	config.Producer.Return.Successes = true
+
+	producer, err := sarama.NewAsyncProducer(brokers, config)
+	if err != nil {
+		return nil, fmt.Errorf("kafka: %w", err)
+	}
+	return newHook(producer, topic, opts)
+}
+
+func newHook(producer sarama.AsyncProducer, topic string, opts *Options) (*hook, error) {
+	if topic == "" {
+		return nil, fmt.Errorf("kafka: topic is required")
+	}
+	formatter := opts.Formatter
+	if formatter == nil {
+		formatter = &logrus.JSONFormatter{}
+	}
+	levels := opts.Levels
+	if len(levels) == 0 {
+		levels = logrus.AllLevels
+	}
+
+	h := &hook{
+		producer:  producer,
+		topic:     topic,
+		keyField:  opts.KeyField,
+		onError:   opts.OnError,
+		formatter: formatter,
+		levels:    levels,
+		stop:      make(chan struct{}),
+	}
+	if opts.Retry != nil {
+		h.retry = *opts.Retry
+	}
+
+	h.wg.Add(1)
+	go h.handleResults()
+
+	return h, nil
+}
+
+// WithOutput adds a hook that produces entries to a Kafka topic
+func WithOutput(brokers []string, topic string, opts *Options) logger.Option {
+	return func(l *logger.Logger) error {
+		h, err := NewHook(brokers, topic, opts)
+		if err != nil {
+			return err
+		}
+		l.Entry.Logger.AddHook(h)
+		return nil
+	}
+}
+
+// Fire queues the formatted entry on the async producer
+func (h *hook) Fire(entry *logrus.Entry) error {
+	h.mu.Lock()
+	line, err := h.formatter.Format(entry)
+	h.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	msg := &sarama.ProducerMessage{
+		Topic:     h.topic,
+		Value:     sarama.ByteEncoder(line),
+		Timestamp: entry.Time,
+	}
+	if h.keyField != "" {
+		if key, ok := entry.Data[h.keyField]; ok {
+			msg.Key = sarama.StringEncoder(fmt.Sprint(key))
+		}
+	}
+
+	h.closeMu.RLock()
+	defer h.closeMu.RUnlock()
+	if h.closed {
+		if h.onError != nil {
+			h.onError(errClosed, line)
+		}
+		return errClosed
+	}
+	h.producer.Input() <- msg
+	return nil
+}
+
+// Levels returns the levels this hook should be fired for
+func (h *hook) Levels() []logrus.Level {
+	return h.levels
+}
+
+// Close flushes buffered messages and shuts the producer down. Entries logged
+// afterwards are reported through OnError.
+func (h *hook) Close() error {
+	h.closeOnce.Do(func() {
+		h.closeMu.Lock()
+		h.closed = true
+		h.closeMu.Unlock()
+		close(h.stop)
+		h.producer.AsyncClose()
+		h.wg.Wait()
+	})
+	return nil
+}
+
+// handleResults drains the producer channels until the producer is closed,
+// retrying the messages it failed to deliver
+func (h *hook) handleResults() {
+	defer h.wg.Done()
+	errs, successes := h.producer.Errors(), h.producer.Successes()
+	for errs != nil || successes != nil {
+		select {
+		case perr, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			if done, ok := perr.Msg.Metadata.(pending); ok {
+				done <- perr.Err
+				continue
+			}
+			h.wg.Add(1)
+			go h.redeliver(perr.Msg, perr.Err)
+		case msg, ok := <-successes:
+			if !ok {
+				successes = nil
+				continue
+			}
+			if done, ok := msg.Metadata.(pending); ok {
+				done <- nil
+			}
+		}
+	}
+}
+
+// redeliver produces a message the producer failed to deliver again until the
+// retry policy gives up, reporting it to OnError then
+func (h *hook) redeliver(msg *sarama.ProducerMessage, err error) {
+	defer h.wg.Done()
+	var line []byte
+	if msg.Value != nil {
+		line, _ = msg.Value.Encode()
+	}
+	attempts := 0
+	err = h.retry.Deliver("kafka", 1, line, h.stop, func([]byte) (bool, error) {
+		// the first attempt is the failed one
+		if attempts++; attempts > 1 {
+			resendErr := h.resend(msg)
+			if resendErr == errClosed {
+				return false, err
+			}
+			err = resendErr
+		}
+		return err != nil && !errors.Is(err, sarama.ErrMessageSizeTooLarge), err
+	})
+	if err != nil && h.onError != nil {
+		h.onError(err, line)
+	}
+}
+
+// resend produces a copy of msg and waits for the outcome
+func (h *hook) resend(msg *sarama.ProducerMessage) error {
+	done := make(pending, 1)
+	h.closeMu.RLock()
+	if h.closed {
+		h.closeMu.RUnlock()
+		return errClosed
+	}
+	h.producer.Input() <- &sarama.ProducerMessage{
+		Topic:     msg.Topic,
+		Key:       msg.Key,
+		Value:     msg.Value,
+		Headers:   msg.Headers,
+		Timestamp: msg.Timestamp,
+		Metadata:  done,
+	}
+	h.closeMu.RUnlock()
+	return <-done
+}
+
+// newSink opens a producer for kafka://broker:9092/topic URLs. Additional
+// brokers can be listed in the brokers query parameter, separated by semicolons.
+func newSink(u *url.URL) (logger.Sink, error) {
+	topic := strings.Trim(u.Path, "/")
+	if u.Host == "" || topic == "" {
+		return nil, fmt.Errorf("broker and topic are required, e.g. kafka://broker:9092/topic")
+	}
+	brokers := []string{u.Host}
+	if extra := u.Query().Get("brokers"); extra != "" {
+		brokers = append(brokers, strings.Split(extra, ";")...)
+	}
+
+	config := sarama.NewConfig()
+	config.Producer.Return.Errors = false
+	producer, err := sarama.NewAsyncProducer(brokers, config)
+	if err != nil {
+		return nil, err
+	}
+	return &sink{producer: producer, topic: topic}, nil
+}
+
+// sink is a logger.Sink producing each write to a Kafka topic
+type sink struct {
+	producer sarama.AsyncProducer
+	topic    string
+	mu       sync.RWMutex
+	closed   bool
+}
+
+func (s *sink) Write(p []byte) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return 0, errClosed
+	}
+	line := make([]byte, len(p))
+	copy(line, p)
+	s.producer.Input() <- &sarama.ProducerMessage{Topic: s.topic, Value: sarama.ByteEncoder(line)}
+	return len(p), nil
+}
+
+// Close flushes buffered messages and shuts the producer down
+func (s *sink) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.mu.Unlock()
+	return s.producer.Close()
+}
@@ -0,0 +1,193 @@
+package kafkalog
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	logger "github.com/alejoacosta74/go-logger"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHook(t *testing.T) {
+	config := sarama.NewConfig()
+	config.Producer.Return.Errors = true
+	producer := mocks.NewAsyncProducer(t, config)
+
+	var keys []string
+	producer.ExpectInputWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+		if msg.Topic != "logs" {
+			return errors.New("unexpected topic " + msg.Topic)
+		}
+		value, _ := msg.Value.Encode()
+		if !strings.Contains(string(value), `"msg":"request handled"`) {
+			return errors.New("unexpected value " + string(value))
+		}
+		key, _ := msg.Key.Encode()
+		keys = append(keys, string(key))
+		return nil
+	})
+	producer.ExpectInputWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+		if msg.Key != nil {
+			return errors.New("expected no key")
+		}
+		return nil
+	})
+
+	hook, err := newHook(producer, "logs", &Options{KeyField: "request_id"})
+	require.NoError(t, err)
+
+	logger := logrus.New()
+	logger.AddHook(hook)
+	logger.SetOutput(io.Discard)
+
+	logger.WithField("request_id", "abc-123").Info("request handled")
+	logger.Info("no request")
+
+	require.NoError(t, hook.Close())
+	assert.Equal(t, []string{"abc-123"}, keys)
+}
+
+func TestHook_ErrorCallback(t *testing.T) {
+	config := sarama.NewConfig()
+	config.Producer.Return.Errors = true
+	producer := mocks.NewAsyncProducer(t, config)
+	producer.ExpectInputAndFail(sarama.ErrOutOfBrokers)
+
+	var (
+		mu     sync.Mutex
+		errs   []error
+		failed []string
+	)
+	hook, err := newHook(producer, "logs", &Options{
+		OnError: func(err error, line []byte) {
+			mu.Lock()
+			defer mu.Unlock()
+			errs = append(errs, err)
+			failed = append(failed, string(line))
+		},
+	})
+	require.NoError(t, err)
+
+	logger := logrus.New()
+	logger.AddHook(hook)
+	logger.SetOutput(io.Discard)
+	logger.Error("undeliverable")
+
+	require.NoError(t, hook.Close())
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], sarama.ErrOutOfBrokers)
+	assert.Contains(t, failed[0], "undeliverable")
+}
+
+func TestHook_Retry(t *testing.T) {
+	newRetryingHook := func(t *testing.T, failed chan<- logger.FailedDelivery) (*mocks.AsyncProducer, *hook) {
+		config := sarama.NewConfig()
+		config.Producer.Return.Errors = true
+		config.Producer.Return.Successes = true
+		producer := mocks.NewAsyncProducer(t, config)
+		hook, err := newHook(producer, "logs", &Options{
+			Retry: &logger.RetryPolicy{
+				MaxAttempts: 3,
+				MinBackoff:  time.Millisecond,
+				OnFailure:   func(f logger.FailedDelivery) { failed <- f },
+			},
+		})
+		require.NoError(t, err)
+		return producer, hook
+	}
+
+	t.Run("delivers on retry", func(t *testing.T) {
+		failed := make(chan logger.FailedDelivery, 1)
+		producer, hook := newRetryingHook(t, failed)
+		producer.ExpectInputAndFail(sarama.ErrNotLeaderForPartition)
+		producer.ExpectInputAndFail(sarama.ErrNotLeaderForPartition)
+		delivered := make(chan struct{})
+		producer.ExpectInputWithMessageCheckerFunctionAndSucceed(func(*sarama.ProducerMessage) error {
+			close(delivered)
+			return nil
+		})
+
+		require.NoError(t, hook.Fire(logrus.NewEntry(logrus.New())))
+		select {
+		case <-delivered:
+		case <-time.After(2 * time.Second):
+			t.Fatal("the message was not retried")
+		}
+		require.NoError(t, hook.Close())
+		assert.Empty(t, failed)
+	})
+
+	t.Run("reports exhausted attempts", func(t *testing.T) {
+		failed := make(chan logger.FailedDelivery, 1)
+		producer, hook := newRetryingHook(t, failed)
+		for i := 0; i < 3; i++ {
+			producer.ExpectInputAndFail(sarama.ErrOutOfBrokers)
+		}
+
+		require.NoError(t, hook.Fire(logrus.NewEntry(logrus.New()).WithField("k", "v")))
+		select {
+		case f := <-failed:
+			assert.Equal(t, "kafka", f.Sink)
+			assert.Equal(t, 3, f.Attempts)
+			assert.ErrorIs(t, f.Err, sarama.ErrOutOfBrokers)
+			assert.Contains(t, string(f.Payload), `"k":"v"`)
+		case <-time.After(2 * time.Second):
+			t.Fatal("the failed message was not reported")
+		}
+		require.NoError(t, hook.Close())
+	})
+}
+
+func TestHook_FireAfterClose(t *testing.T) {
+	producer := mocks.NewAsyncProducer(t, nil)
+	var failed []string
+	hook, err := newHook(producer, "logs", &Options{
+		OnError: func(err error, line []byte) { failed = append(failed, string(line)) },
+	})
+	require.NoError(t, err)
+	require.NoError(t, hook.Close())
+
+	assert.NotPanics(t, func() {
+		assert.ErrorIs(t, hook.Fire(logrus.NewEntry(logrus.New()).WithField("k", "v")), errClosed)
+	})
+	assert.Len(t, failed, 1)
+}
+
+func TestNewHook_KeepsConfig(t *testing.T) {
+	config := sarama.NewConfig()
+	config.Producer.Return.Errors = false
+	config.Producer.Return.Successes = true
+	config.Metadata.Retry.Max = 0
+
+	// the broker is unreachable, the config is prepared before dialing
+	_, err := NewHook([]string{"127.0.0.1:1"}, "logs", &Options{Config: config})
+	assert.Error(t, err)
+	assert.False(t, config.Producer.Return.Errors)
+	assert.True(t, config.Producer.Return.Successes)
+}
+
+func TestNewHook_Validation(t *testing.T) {
+	_, err := NewHook(nil, "logs", nil)
+	assert.Error(t, err)
+
+	producer := mocks.NewAsyncProducer(t, nil)
+	defer producer.Close()
+	_, err = newHook(producer, "", &Options{})
+	assert.Error(t, err)
+}
+
+func TestNewSink(t *testing.T) {
+	_, err := logger.NewSink("kafka://broker:9092")
+	assert.ErrorContains(t, err, "topic are required")
+}
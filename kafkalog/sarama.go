@@ -0,0 +1,44 @@
+package kafkalog
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/IBM/sarama"
+	logger "github.com/alejoacosta74/go-logger"
+	"github.com/sirupsen/logrus"
+)
+
+// saramaLogger implements sarama.StdLogger logging at a fixed level
+type saramaLogger struct {
+	entry *logrus.Entry
+	level logrus.Level
+}
+
+// NewSaramaLogger returns a sarama.StdLogger logging the messages of the Kafka
+// client on l (the global Log when nil) at level, with a component=sarama field
+// so they can be filtered. Assign it to sarama.Logger, or sarama.DebugLogger for
+// the client's debug messages.
+func NewSaramaLogger(l *logger.Logger, level logrus.Level) sarama.StdLogger {
+	if l == nil {
+		l = logger.Log
+	}
+	return &saramaLogger{entry: l.Entry.WithField(logger.ComponentKey, "sarama"), level: level}
+}
+
+func (s *saramaLogger) Print(v ...interface{}) {
+	s.log(fmt.Sprint(v...))
+}
+
+func (s *saramaLogger) Printf(format string, v ...interface{}) {
+	s.log(fmt.Sprintf(format, v...))
+}
+
+func (s *saramaLogger) Println(v ...interface{}) {
+	s.log(fmt.Sprintln(v...))
+}
+
+// log logs msg without the trailing newline sarama ends most messages with
+func (s *saramaLogger) log(msg string) {
+	s.entry.Log(s.level, strings.TrimRight(msg, "\n"))
+}
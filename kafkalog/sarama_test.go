@@ -0,0 +1,30 @@
+package kafkalog
+
+import (
+	"testing"
+
+	logger "github.com/alejoacosta74/go-logger"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSaramaLogger(t *testing.T) {
+	l, err := logger.NewLogger(logger.WithNullOutput(), logger.WithLevel("debug"), logger.WithLastEntriesCapture(10))
+	require.NoError(t, err)
+
+	s := NewSaramaLogger(l, logrus.DebugLevel)
+	s.Printf("client/metadata fetching metadata for all topics from broker %s\n", "kafka:9092")
+	s.Println("Connected to broker", 1)
+	s.Print("producer/broker ", 1, " shut down")
+
+	crumbs := l.Breadcrumbs(0)
+	require.Len(t, crumbs, 3)
+	assert.Equal(t, "client/metadata fetching metadata for all topics from broker kafka:9092", crumbs[0].Message)
+	assert.Equal(t, "Connected to broker 1", crumbs[1].Message)
+	assert.Equal(t, "producer/broker 1 shut down", crumbs[2].Message)
+	for _, crumb := range crumbs {
+		assert.Equal(t, logrus.DebugLevel, crumb.Level)
+		assert.Equal(t, "sarama", crumb.Data["component"])
+	}
+}
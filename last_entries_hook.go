@@ -0,0 +1,170 @@
+package logger
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// lastEntriesHook implements logrus.Hook keeping the last N entries, both
+// rendered and structured, in a ring buffer
+type lastEntriesHook struct {
+	entries []capturedEntry
+	next    int
+	full    bool
+	mu      sync.Mutex
+}
+
+// capturedEntry is a single entry held by lastEntriesHook
+type capturedEntry struct {
+	line    string
+	time    time.Time
+	level   logrus.Level
+	message string
+	data    Fields
+}
+
+// Breadcrumb is a structured record of a recent entry, shaped after the
+// breadcrumbs accepted by error reporters such as Sentry
+type Breadcrumb struct {
+	Type      string
+	Category  string
+	Message   string
+	Data      Fields
+	Level     logrus.Level
+	Timestamp time.Time
+}
+
+func newLastEntriesHook(n int) *lastEntriesHook {
+	return &lastEntriesHook{entries: make([]capturedEntry, n)}
+}
+
+// WithLastEntriesCapture keeps the last n entries in memory so they can be
+// retrieved with Logger.LastEntries or Logger.Breadcrumbs, e.g. to attach recent
+// context to error reports
+func WithLastEntriesCapture(n int) Option {
+	return func(l *Logger) error {
+		if n <= 0 {
+			return nil
+		}
+		l.Entry.Logger.AddHook(newLastEntriesHook(n))
+		return nil
+	}
+}
+
+func (h *lastEntriesHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire renders the entry with the logger's formatter and stores it
+func (h *lastEntriesHook) Fire(entry *logrus.Entry) error {
+	line, err := entry.Logger.Formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+
+	data := make(Fields, len(entry.Data))
+	for k, v := range entry.Data {
+		data[k] = v
+	}
+	h.add(capturedEntry{
+		line:    strings.TrimSuffix(string(line), "\n"),
+		time:    entry.Time,
+		level:   entry.Level,
+		message: entry.Message,
+		data:    data,
+	})
+	return nil
+}
+
+func (h *lastEntriesHook) add(e capturedEntry) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.entries[h.next] = e
+	h.next = (h.next + 1) % len(h.entries)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// snapshot returns the captured entries, oldest first
+func (h *lastEntriesHook) snapshot() []capturedEntry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.full {
+		return append([]capturedEntry(nil), h.entries[:h.next]...)
+	}
+	out := make([]capturedEntry, 0, len(h.entries))
+	out = append(out, h.entries[h.next:]...)
+	return append(out, h.entries[:h.next]...)
+}
+
+// LastEntries returns the most recently rendered lines, oldest first. It returns
+// nil unless the logger was created with WithLastEntriesCapture.
+func (l *Logger) LastEntries() []string {
+	hook, ok := findHook[*lastEntriesHook](l.Entry.Logger)
+	if !ok {
+		return nil
+	}
+	entries := hook.snapshot()
+	lines := make([]string, len(entries))
+	for i, e := range entries {
+		lines[i] = e.line
+	}
+	return lines
+}
+
+// Breadcrumbs returns up to limit of the most recent entries as breadcrumbs,
+// oldest first. When levels are given only entries at those levels are returned.
+// A limit of zero or less returns every captured entry. It returns nil unless the
+// logger was created with WithLastEntriesCapture.
+func (l *Logger) Breadcrumbs(limit int, levels ...logrus.Level) []Breadcrumb {
+	hook, ok := findHook[*lastEntriesHook](l.Entry.Logger)
+	if !ok {
+		return nil
+	}
+	return hook.breadcrumbs(limit, levels...)
+}
+
+func (h *lastEntriesHook) breadcrumbs(limit int, levels ...logrus.Level) []Breadcrumb {
+	entries := h.snapshot()
+
+	crumbs := make([]Breadcrumb, 0, len(entries))
+	for _, e := range entries {
+		if len(levels) > 0 && !containsLevel(levels, e.level) {
+			continue
+		}
+		category := "log"
+		if component, ok := e.data[ComponentKey]; ok {
+			if s, ok := component.(string); ok && s != "" {
+				category = s
+			}
+		}
+		crumbs = append(crumbs, Breadcrumb{
+			Type:      "default",
+			Category:  category,
+			Message:   e.message,
+			Data:      e.data,
+			Level:     e.level,
+			Timestamp: e.time,
+		})
+	}
+	if limit > 0 && len(crumbs) > limit {
+		crumbs = crumbs[len(crumbs)-limit:]
+	}
+	return crumbs
+}
+
+// containsLevel reports whether level is one of levels
+func containsLevel(levels []logrus.Level, level logrus.Level) bool {
+	for _, l := range levels {
+		if l == level {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithLastEntriesCapture(t *testing.T) {
+	logger, err := NewLogger(
+		WithNullOutput(),
+		WithLevel("info"),
+		WithFormatter(&PlainFormatter{}),
+		WithLastEntriesCapture(3),
+	)
+	require.NoError(t, err)
+
+	assert.Empty(t, logger.LastEntries())
+
+	logger.Info("first")
+	logger.Debug("filtered by level")
+	assert.Equal(t, []string{"INFO first"}, logger.LastEntries())
+
+	for i := 0; i < 5; i++ {
+		logger.WithField("n", i).Warn("message")
+	}
+	assert.Equal(t, []string{
+		"WARNING message n=2",
+		"WARNING message n=3",
+		"WARNING message n=4",
+	}, logger.LastEntries())
+
+	// child loggers share the capture
+	child := &Logger{Entry: logger.WithField("child", true)}
+	child.Error("from child")
+	entries := child.LastEntries()
+	assert.Equal(t, "ERROR from child child=true", entries[len(entries)-1])
+}
+
+func TestLastEntries_NotEnabled(t *testing.T) {
+	logger, err := NewLogger(WithNullOutput())
+	require.NoError(t, err)
+
+	logger.Info("message")
+	assert.Nil(t, logger.LastEntries())
+}
+
+func BenchmarkLastEntriesHook(b *testing.B) {
+	hook := newLastEntriesHook(100)
+	entry := capturedEntry{}
+	for i := 0; i < b.N; i++ {
+		entry.line = fmt.Sprintf("line %d", i)
+		hook.add(entry)
+	}
+}
+
+func TestBreadcrumbs(t *testing.T) {
+	logger, err := NewLogger(WithNullOutput(), WithLevel("info"), WithLastEntriesCapture(10))
+	require.NoError(t, err)
+
+	logger.Info("starting")
+	logger.WithField("component", "db").Warn("slow query")
+	logger.Info("serving")
+
+	crumbs := logger.Breadcrumbs(2)
+	require.Len(t, crumbs, 2)
+	assert.Equal(t, "slow query", crumbs[0].Message)
+	assert.Equal(t, "db", crumbs[0].Category)
+	assert.Equal(t, "serving", crumbs[1].Message)
+	assert.Equal(t, "log", crumbs[1].Category)
+
+	warnings := logger.Breadcrumbs(0, logrus.WarnLevel)
+	require.Len(t, warnings, 1)
+	assert.Equal(t, "slow query", warnings[0].Message)
+}
@@ -0,0 +1,55 @@
+package logger
+
+import "github.com/sirupsen/logrus"
+
+// LevelRange bounds the levels a hook is fired for. Min is the most verbose
+// level included and Max the most severe one, e.g. LevelRange{Min:
+// logrus.DebugLevel, Max: logrus.InfoLevel} for debug and info only.
+type LevelRange struct {
+	Min logrus.Level
+	Max logrus.Level
+}
+
+// Contains reports whether level is within r
+func (r LevelRange) Contains(level logrus.Level) bool {
+	return level >= r.Max && level <= r.Min
+}
+
+// Levels returns the levels within r, ordered from panic to the most verbose,
+// to be used as the Levels of the built-in hooks' configs
+func (r LevelRange) Levels() []logrus.Level {
+	var levels []logrus.Level
+	for _, level := range logrus.AllLevels {
+		if r.Contains(level) {
+			levels = append(levels, level)
+		}
+	}
+	return levels
+}
+
+// LimitLevels returns hook fired only for the levels it was registered for
+// that are within r, so any hook can be limited without changing its config
+func LimitLevels(hook logrus.Hook, r LevelRange) logrus.Hook {
+	var levels []logrus.Level
+	for _, level := range hook.Levels() {
+		if r.Contains(level) {
+			levels = append(levels, level)
+		}
+	}
+	return &limitedHook{Hook: hook, levels: levels}
+}
+
+// limitedHook restricts the levels of the hook it wraps
+type limitedHook struct {
+	logrus.Hook
+	levels []logrus.Level
+}
+
+func (h *limitedHook) Levels() []logrus.Level {
+	return h.levels
+}
+
+// Unwrap returns the wrapped hook
+func (h *limitedHook) Unwrap() logrus.Hook {
+	return h.Hook
+}
@@ -0,0 +1,41 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLevelRange(t *testing.T) {
+	r := LevelRange{Min: logrus.DebugLevel, Max: logrus.InfoLevel}
+	assert.Equal(t, []logrus.Level{logrus.InfoLevel, logrus.DebugLevel}, r.Levels())
+	assert.True(t, r.Contains(logrus.InfoLevel))
+	assert.False(t, r.Contains(logrus.TraceLevel))
+	assert.False(t, r.Contains(logrus.WarnLevel))
+
+	errorsOnly := LevelRange{Min: logrus.ErrorLevel, Max: logrus.FatalLevel}
+	assert.Equal(t, []logrus.Level{logrus.FatalLevel, logrus.ErrorLevel}, errorsOnly.Levels())
+	assert.Empty(t, LevelRange{Min: logrus.PanicLevel, Max: logrus.ErrorLevel}.Levels())
+}
+
+func TestLimitLevels(t *testing.T) {
+	alerts := &flakyHook{}
+	logger, err := NewLogger(
+		WithNullOutput(),
+		WithHooks(LimitLevels(alerts, LevelRange{Min: logrus.ErrorLevel, Max: logrus.FatalLevel})),
+	)
+	require.NoError(t, err)
+
+	logger.Warn("not an alert")
+	logger.Error("alert")
+	assert.Panics(t, func() { logger.Panic("handled separately") })
+
+	received := alerts.received()
+	require.Len(t, received, 1)
+	assert.Equal(t, "alert", received[0])
+
+	_, ok := findHook[*flakyHook](logger.Entry.Logger)
+	assert.True(t, ok, "the limited hook is found")
+}
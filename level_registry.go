@@ -0,0 +1,210 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// levelRegistriesMu serializes the creation of level registries
+var levelRegistriesMu sync.Mutex
+
+// levelRegistry implements Rule applying per-name levels to the entries of
+// named loggers, see SetLevelFor. It registers like a hook for the panic level
+// so it can be found, but firing it does nothing.
+type levelRegistry struct {
+	logger *logrus.Logger
+	// threshold is the level of the names without an override, the logger
+	// level before the overrides raised it
+	threshold logrus.Level
+	// raised is the level the logger was raised to, a different logger level
+	// having been set since, e.g. with logrus, and becoming the threshold
+	raised    logrus.Level
+	overrides map[string]logrus.Level
+	mu        sync.RWMutex
+}
+
+// WithLevelOverrides sets per-name levels from a comma separated list of
+// name=level pairs, e.g. "db=debug,http.*=warn", see Logger.SetLevelFor
+func WithLevelOverrides(spec string) Option {
+	return func(l *Logger) error {
+		for _, pair := range strings.Split(spec, ",") {
+			pair = strings.TrimSpace(pair)
+			if pair == "" {
+				continue
+			}
+			name, level, ok := strings.Cut(pair, "=")
+			if !ok {
+				return fmt.Errorf("invalid level override %q, want name=level", pair)
+			}
+			if err := l.SetLevelFor(strings.TrimSpace(name), strings.TrimSpace(level)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// SetLevelFor sets the level of the loggers named name with Named, and of their
+// descendants, so debug can be turned on for a single subsystem at runtime.
+// name may end with ".*" to match only the descendants (http.* matches
+// http.server but not http), or be "*" to match every named logger; the most
+// specific match wins. Loggers without a matching name use the logger level.
+//
+// Levels are looked up when entries are logged, so existing named loggers follow
+// later changes. The logger level is raised to the most verbose override so its
+// entries are created, the rules suppressing the entries of other names before
+// they are formatted or reach a hook.
+func (l *Logger) SetLevelFor(name, level string) error {
+	if err := validLevelName(name); err != nil {
+		return err
+	}
+	parsedLevel, err := logrus.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	r := levelRegistryFor(l)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.overrides[name] = parsedLevel
+	r.raiseLocked()
+	return nil
+}
+
+// LevelFor returns the level applying to the logger named name
+func (l *Logger) LevelFor(name string) logrus.Level {
+	r, ok := findHook[*levelRegistry](l.Entry.Logger)
+	if !ok {
+		return l.Entry.Logger.GetLevel()
+	}
+	return r.levelFor(name)
+}
+
+// SetLevelFor sets the level of the global loggers named name, see
+// Logger.SetLevelFor
+func SetLevelFor(name, level string) error {
+	return (&Logger{Entry: global()}).SetLevelFor(name, level)
+}
+
+// validLevelName reports an error unless name is a logger name, optionally
+// ending with ".*", or "*"
+func validLevelName(name string) error {
+	if name == "*" {
+		return nil
+	}
+	base := strings.TrimSuffix(name, ".*")
+	if base == "" || strings.Contains(base, "*") {
+		return fmt.Errorf("invalid logger name %q", name)
+	}
+	for _, part := range strings.Split(base, ".") {
+		if part == "" {
+			return fmt.Errorf("invalid logger name %q", name)
+		}
+	}
+	return nil
+}
+
+// levelRegistryFor returns the level registry of the logger, adding it to the
+// logger rules on first use
+func levelRegistryFor(l *Logger) *levelRegistry {
+	levelRegistriesMu.Lock()
+	defer levelRegistriesMu.Unlock()
+
+	if r, ok := findHook[*levelRegistry](l.Entry.Logger); ok {
+		return r
+	}
+	level := l.Entry.Logger.GetLevel()
+	r := &levelRegistry{
+		logger:    l.Entry.Logger,
+		threshold: level,
+		raised:    level,
+		overrides: make(map[string]logrus.Level),
+	}
+	_ = WithRules(r)(l)
+	l.Entry.Logger.AddHook(r)
+	return r
+}
+
+// setLoggerLevel sets the level of l, which is the level of the names without
+// an override once per-name levels are set
+func setLoggerLevel(l *logrus.Logger, level logrus.Level) {
+	r, ok := findHook[*levelRegistry](l)
+	if !ok {
+		l.SetLevel(level)
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.threshold, r.raised = level, l.GetLevel()
+	r.raiseLocked()
+}
+
+// raiseLocked sets the logger level to the most verbose of the threshold and
+// the overrides, a logger level set since the last call becoming the threshold
+func (r *levelRegistry) raiseLocked() {
+	if level := r.logger.GetLevel(); level != r.raised {
+		r.threshold = level
+	}
+	lowest := r.threshold
+	for _, level := range r.overrides {
+		if level > lowest {
+			lowest = level
+		}
+	}
+	r.logger.SetLevel(lowest)
+	r.raised = lowest
+}
+
+func (r *levelRegistry) Levels() []logrus.Level {
+	return []logrus.Level{logrus.PanicLevel}
+}
+
+func (r *levelRegistry) Fire(*logrus.Entry) error {
+	return nil
+}
+
+// Apply suppresses the entries above the level of their component
+func (r *levelRegistry) Apply(entry *logrus.Entry) bool {
+	r.mu.RLock()
+	changed := r.logger.GetLevel() != r.raised
+	r.mu.RUnlock()
+	if changed {
+		r.mu.Lock()
+		r.raiseLocked()
+		r.mu.Unlock()
+	}
+	return entry.Level > r.levelFor(entryComponent(entry.Data))
+}
+
+// levelFor returns the level of the most specific override matching name: the
+// name itself, then the closest ancestor, a wildcard ranking ahead of the plain
+// ancestor name, and finally "*". Names without an override use the threshold.
+func (r *levelRegistry) levelFor(name string) logrus.Level {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	level, best := r.threshold, -1
+	for pattern, lvl := range r.overrides {
+		score := -1
+		switch {
+		case name == "":
+		case pattern == "*":
+			score = 0
+		case pattern == name:
+			score = 3*len(name) + 3
+		case strings.HasSuffix(pattern, ".*"):
+			if base := strings.TrimSuffix(pattern, "*"); strings.HasPrefix(name, base) {
+				score = 3*len(base) + 2
+			}
+		case strings.HasPrefix(name, pattern+"."):
+			score = 3*len(pattern) + 1
+		}
+		if score > best {
+			level, best = lvl, score
+		}
+	}
+	return level
+}
@@ -0,0 +1,90 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetLevelFor(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := NewLogger(WithOutput(&buf), WithFormatter(&PlainFormatter{}), WithLevelOverrides("db=debug, http.*=warn"))
+	require.NoError(t, err)
+	assert.Equal(t, logrus.DebugLevel, l.Entry.Logger.GetLevel())
+
+	l.Debug("root debug")
+	l.Named("db").Debug("db debug")
+	l.Named("db").Named("pool").Debug("pool debug")
+	l.Named("http").Info("http info")
+	l.Named("http").Named("server").Info("server info")
+	l.Named("http").Named("server").Warn("server warn")
+	assert.Equal(t, "DEBUG [db] db debug\nDEBUG [db.pool] pool debug\nINFO [http] http info\nWARNING [http.server] server warn\n", buf.String())
+
+	require.NoError(t, l.SetLevelFor("db.pool", "error"))
+	assert.Equal(t, logrus.ErrorLevel, l.LevelFor("db.pool.conn"))
+	assert.Equal(t, logrus.DebugLevel, l.LevelFor("db"))
+	assert.Equal(t, logrus.InfoLevel, l.LevelFor("cache"))
+	require.NoError(t, l.SetLevelFor("*", "trace"))
+	assert.Equal(t, logrus.TraceLevel, l.LevelFor("cache"))
+	assert.Equal(t, logrus.InfoLevel, l.LevelFor(""))
+	assert.Equal(t, logrus.TraceLevel, l.Entry.Logger.GetLevel())
+
+	assert.Error(t, l.SetLevelFor("db", "loud"))
+	for _, name := range []string{"", "http.", "*.db", "a..b"} {
+		assert.Error(t, l.SetLevelFor(name, "debug"), name)
+	}
+	_, err = NewLogger(WithLevelOverrides("db"))
+	assert.Error(t, err)
+}
+
+func TestSetLevelFor_LookedUpWhenLogging(t *testing.T) {
+	var buf bytes.Buffer
+	hook := new(test.Hook)
+	l, err := NewLogger(WithOutput(&buf), WithFormatter(&PlainFormatter{}), WithHooks(hook))
+	require.NoError(t, err)
+	db, cache := l.Named("db"), l.Named("cache")
+
+	require.NoError(t, l.SetLevelFor("db", "debug"))
+	db.Debug("db debug")
+	db.Debugw("db debugw", "n", 1)
+	cache.Debug("cache debug")
+	l.Debug("root debug")
+	assert.Equal(t, logrus.InfoLevel, l.LevelFor(""))
+	assert.Equal(t, "DEBUG [db] db debug\nDEBUG [db] db debugw n=1\n", buf.String())
+	assert.Len(t, hook.AllEntries(), 2)
+
+	// the names without an override follow the logger level changes
+	require.NoError(t, l.SetLevelFor("db", "warn"))
+	buf.Reset()
+	db.Info("db info")
+	l.Entry.Logger.SetLevel(logrus.DebugLevel)
+	cache.Debug("cache debug")
+	assert.Equal(t, "DEBUG [cache] cache debug\n", buf.String())
+	assert.Len(t, hook.AllEntries(), 3)
+}
+
+func TestSetLevelFor_Concurrent(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := NewLogger(WithOutput(&buf), WithLevelOverrides("db=debug"))
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for _, logger := range []*Logger{l, l.Named("db")} {
+		wg.Add(1)
+		go func(logger *Logger) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				logger.Debug("debug")
+				logger.Info("info")
+			}
+		}(logger)
+	}
+	wg.Wait()
+	assert.Equal(t, 300, strings.Count(buf.String(), "\n"))
+}
@@ -0,0 +1,95 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// MaxWriterLineSize bounds the lines buffered by the writers returned by
+// Logger.WriterLevel; longer lines are logged in chunks of this size
+const MaxWriterLineSize = 64 * 1024
+
+// levelWriter implements io.WriteCloser logging every line written to it as an
+// entry at a fixed level
+type levelWriter struct {
+	entry  *logrus.Entry
+	level  logrus.Level
+	buf    []byte
+	closed bool
+	mu     sync.Mutex
+}
+
+// WriterLevel returns a writer logging every line written to it as an entry at
+// level, without the trailing newline, so the standard library log.Logger,
+// exec.Cmd.Stderr or libraries only accepting writers can log through l.
+// Incomplete lines are buffered until the next newline or Close, which logs
+// them. Unlike the embedded logrus method, it starts no goroutine.
+func (l *Logger) WriterLevel(level logrus.Level) io.WriteCloser {
+	return &levelWriter{entry: l.Entry, level: level}
+}
+
+// Writer returns a writer logging every line written to it at info level, see
+// WriterLevel
+func (l *Logger) Writer() io.WriteCloser {
+	return l.WriterLevel(logrus.InfoLevel)
+}
+
+// WriterLevel returns a writer logging every line written to it on the global
+// logger at level, see Logger.WriterLevel
+func WriterLevel(level logrus.Level) io.WriteCloser {
+	return globalLogger().WriterLevel(level)
+}
+
+func (w *levelWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, io.ErrClosedPipe
+	}
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.log(w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+	for len(w.buf) >= MaxWriterLineSize {
+		w.log(w.buf[:MaxWriterLineSize])
+		w.buf = w.buf[MaxWriterLineSize:]
+	}
+	if len(w.buf) == 0 {
+		w.buf = nil
+	}
+	return len(p), nil
+}
+
+// Close logs the buffered incomplete line. Writes after Close fail.
+func (w *levelWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	w.log(w.buf)
+	w.buf = nil
+	return nil
+}
+
+func (w *levelWriter) log(line []byte) {
+	line = bytes.TrimSuffix(line, []byte("\r"))
+	for len(line) > MaxWriterLineSize {
+		w.entry.Log(w.level, string(line[:MaxWriterLineSize]))
+		line = line[MaxWriterLineSize:]
+	}
+	if len(line) == 0 {
+		return
+	}
+	w.entry.Log(w.level, string(line))
+}
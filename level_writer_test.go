@@ -0,0 +1,45 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	stdlog "log"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriterLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := NewLogger(WithOutput(&buf), WithFormatter(&PlainFormatter{}))
+	require.NoError(t, err)
+
+	w := logger.Named("legacy").WriterLevel(logrus.WarnLevel)
+	std := stdlog.New(w, "", 0)
+	std.Print("disk almost full")
+	io.WriteString(w, "partial ")
+	io.WriteString(w, "line\r\n\nunterminated")
+	assert.Equal(t, "WARNING [legacy] disk almost full\nWARNING [legacy] partial line\n", buf.String())
+
+	require.NoError(t, w.Close())
+	assert.True(t, strings.HasSuffix(buf.String(), "WARNING [legacy] unterminated\n"))
+	_, err = w.Write([]byte("late\n"))
+	assert.ErrorIs(t, err, io.ErrClosedPipe)
+}
+
+func TestWriterLevelLongLine(t *testing.T) {
+	logger, err := NewLogger(WithNullOutput(), WithLastEntriesCapture(10))
+	require.NoError(t, err)
+
+	w := logger.Writer()
+	io.WriteString(w, strings.Repeat("x", MaxWriterLineSize+10)+"\n")
+
+	crumbs := logger.Breadcrumbs(0)
+	require.Len(t, crumbs, 2)
+	assert.Len(t, crumbs[0].Message, MaxWriterLineSize)
+	assert.Len(t, crumbs[1].Message, 10)
+	assert.Equal(t, logrus.InfoLevel, crumbs[0].Level)
+}
@@ -2,6 +2,7 @@ package logger
 
 import (
 	"io"
+	"reflect"
 	"sync"
 
 	"github.com/sirupsen/logrus"
@@ -31,6 +32,8 @@ var (
 )
 
 func init() {
+	// the standard logger is used as is, only loggers created by this package
+	// get its hooks and exit func
 	entry := logrus.NewEntry(logrus.StandardLogger())
 	Log = &Logger{
 		Entry: entry,
@@ -60,6 +63,12 @@ func NewSingletonLogger(opts ...Option) (*Logger, error) {
 
 func createNewLogger(opts ...Option) (*Logger, error) {
 	l := logrus.New()
+	// field encoders run first so every other hook sees encoded values
+	l.AddHook(&fieldEncoderHook{})
+	// rules run next so suppressed entries can be kept from the hooks
+	l.AddHook(&rulesHook{})
+	// tracing probes, only registered when built with the logprobes tag
+	addProbeHook(l)
 
 	logger := &Logger{
 		Entry: logrus.NewEntry(l),
@@ -70,6 +79,21 @@ func createNewLogger(opts ...Option) (*Logger, error) {
 			return nil, err
 		}
 	}
+	// hook errors reach the handler set with WithHookErrorHandler or WithDeadLetter
+	guardHooks(l)
+	// with WithParallelHooks, sink hooks are grouped to fire concurrently
+	startParallelHooks(l)
+	// hooks never receive the entries suppressed by rules
+	filterSuppressed(l)
+	// with WithWriteFallback, failed writes on the output file are mirrored
+	startWriteFallback(l)
+	// with WithShutdownSummary, the bytes written to the outputs are counted
+	startStats(l)
+	// with WithAsync, hooks and output move to the background worker
+	startAsync(l)
+	// sinks are flushed before Fatal exits, wrapping the exit func and code set
+	// by options
+	l.ExitFunc = flushOnExit(l, fatalExitFunc(l), l.ExitFunc != nil, DefaultFatalFlushTimeout)
 	return logger, nil
 }
 
@@ -79,98 +103,168 @@ func createNewLogger(opts ...Option) (*Logger, error) {
 // This function modifies the global Log's level to trace and accepts variadic arguments
 // that will be formatted using fmt.Sprint.
 func Trace(args ...interface{}) {
-	Log.Trace(args...)
+	global().Trace(args...)
 }
 
 // Tracef logs a formatted message at the trace level using the global Log instance.
 // This function modifies the global Log's level to trace and accepts a format string
 // and variadic arguments that will be formatted using fmt.Sprintf.
 func Tracef(format string, args ...interface{}) {
-	Log.Tracef(format, args...)
+	global().Tracef(format, args...)
 }
 
 // Debug logs a message at the debug level using the global Log instance.
 // This function modifies the global Log's level to debug and accepts variadic arguments
 // that will be formatted using fmt.Sprint.
 func Debug(args ...interface{}) {
-	Log.Debug(args...)
+	global().Debug(args...)
 }
 
 // Debugf logs a formatted message at the debug level using the global Log instance.
 // This function modifies the global Log's level to debug and accepts a format string
 // and variadic arguments that will be formatted using fmt.Sprintf.
 func Debugf(format string, args ...interface{}) {
-	Log.Debugf(format, args...)
+	global().Debugf(format, args...)
 }
 
 // Info logs a message at the info level using the global Log instance.
 // This function modifies the global Log's level to info and accepts variadic arguments
 // that will be formatted using fmt.Sprint.
 func Info(args ...interface{}) {
-	Log.Info(args...)
+	global().Info(args...)
 }
 
 // Infof logs a formatted message at the info level using the global Log instance.
 // This function modifies the global Log's level to info and accepts a format string
 // and variadic arguments that will be formatted using fmt.Sprintf.
 func Infof(format string, args ...interface{}) {
-	Log.Infof(format, args...)
+	global().Infof(format, args...)
 }
 
 // Warn logs a message at the warn level using the global Log instance.
 // This function modifies the global Log's level to warn and accepts variadic arguments
 // that will be formatted using fmt.Sprint.
 func Warn(args ...interface{}) {
-	Log.Warn(args...)
+	global().Warn(args...)
 }
 
 // Warnf logs a formatted message at the warn level using the global Log instance.
 // This function modifies the global Log's level to warn and accepts a format string
 // and variadic arguments that will be formatted using fmt.Sprintf.
 func Warnf(format string, args ...interface{}) {
-	Log.Warnf(format, args...)
+	global().Warnf(format, args...)
 }
 
 // Error logs a message at the error level using the global Log instance.
 // This function modifies the global Log's level to error and accepts variadic arguments
 // that will be formatted using fmt.Sprint.
 func Error(args ...interface{}) {
-	Log.Error(args...)
+	global().Error(args...)
 }
 
 // Errorf logs a formatted message at the error level using the global Log instance.
 // This function modifies the global Log's level to error and accepts a format string
 // and variadic arguments that will be formatted using fmt.Sprintf.
 func Errorf(format string, args ...interface{}) {
-	Log.Errorf(format, args...)
+	global().Errorf(format, args...)
 }
 
 // Fatal logs a message at the fatal level using the global Log instance and then exits.
 // This function modifies the global Log's level to fatal, accepts variadic arguments
 // that will be formatted using fmt.Sprint, and terminates the program with os.Exit(1).
 func Fatal(args ...interface{}) {
-	Log.Fatal(args...)
+	global().Fatal(args...)
 }
 
 // Fatalf logs a formatted message at the fatal level using the global Log instance and then exits.
 // This function modifies the global Log's level to fatal, accepts a format string and variadic
 // arguments that will be formatted using fmt.Sprintf, and terminates the program with os.Exit(1).
 func Fatalf(format string, args ...interface{}) {
-	Log.Fatalf(format, args...)
+	global().Fatalf(format, args...)
 }
 
 // Panic logs a message at the panic level using the global Log instance and then panics.
 // This function modifies the global Log's level to panic, accepts variadic arguments
-// that will be formatted using fmt.Sprint, and calls panic() with the resulting string.
+// that will be formatted using fmt.Sprint, flushes the sinks and calls panic() with the resulting string.
 func Panic(args ...interface{}) {
-	Log.Panic(args...)
+	entry := global()
+	defer flushOnPanic(entry.Logger)
+	entry.Panic(args...)
 }
 
 // Panicf logs a formatted message at the panic level using the global Log instance and then panics.
 // This function modifies the global Log's level to panic, accepts a format string and variadic
-// arguments that will be formatted using fmt.Sprintf, and calls panic() with the resulting string.
+// arguments that will be formatted using fmt.Sprintf, flushes the sinks and calls panic() with the resulting string.
 func Panicf(format string, args ...interface{}) {
-	Log.Panicf(format, args...)
+	entry := global()
+	defer flushOnPanic(entry.Logger)
+	entry.Panicf(format, args...)
+}
+
+// Print logs a message at the info level using the global Log instance, like the
+// Print function of the standard library log package. Arguments are formatted
+// using fmt.Sprint.
+func Print(args ...interface{}) {
+	global().Print(args...)
+}
+
+// Printf logs a formatted message at the info level using the global Log instance,
+// like the Printf function of the standard library log package.
+func Printf(format string, args ...interface{}) {
+	global().Printf(format, args...)
+}
+
+// Println logs a message at the info level using the global Log instance, like the
+// Println function of the standard library log package. Arguments are formatted
+// using fmt.Sprintln, always separated by spaces, without the trailing newline.
+func Println(args ...interface{}) {
+	global().Println(args...)
+}
+
+// Traceln logs a message at the trace level using the global Log instance.
+// Arguments are formatted using fmt.Sprintln, without the trailing newline.
+func Traceln(args ...interface{}) {
+	global().Traceln(args...)
+}
+
+// Debugln logs a message at the debug level using the global Log instance.
+// Arguments are formatted using fmt.Sprintln, without the trailing newline.
+func Debugln(args ...interface{}) {
+	global().Debugln(args...)
+}
+
+// Infoln logs a message at the info level using the global Log instance.
+// Arguments are formatted using fmt.Sprintln, without the trailing newline.
+func Infoln(args ...interface{}) {
+	global().Infoln(args...)
+}
+
+// Warnln logs a message at the warn level using the global Log instance.
+// Arguments are formatted using fmt.Sprintln, without the trailing newline.
+func Warnln(args ...interface{}) {
+	global().Warnln(args...)
+}
+
+// Errorln logs a message at the error level using the global Log instance.
+// Arguments are formatted using fmt.Sprintln, without the trailing newline.
+func Errorln(args ...interface{}) {
+	global().Errorln(args...)
+}
+
+// Fatalln logs a message at the fatal level using the global Log instance and then
+// exits, as Fatal does. Arguments are formatted using fmt.Sprintln, without the
+// trailing newline.
+func Fatalln(args ...interface{}) {
+	global().Fatalln(args...)
+}
+
+// Panicln logs a message at the panic level using the global Log instance, flushes
+// the sinks and panics, as Panic does. Arguments are formatted using fmt.Sprintln,
+// without the trailing newline.
+func Panicln(args ...interface{}) {
+	entry := global()
+	defer flushOnPanic(entry.Logger)
+	entry.Panicln(args...)
 }
 
 // WithField adds a single field to the logger entry. It takes a key string and a value of any type,
@@ -178,13 +272,13 @@ func Panicf(format string, args ...interface{}) {
 // information to log entries, such as request IDs, user IDs, or any other metadata that helps
 // trace and debug issues.
 func WithField(key string, value interface{}) *Logger {
-	return &Logger{Entry: Log.Entry.WithField(key, value)}
+	return &Logger{Entry: global().WithField(key, value)}
 
 }
 
 // SetOutput sets the output destination for the global logger
 func SetOutput(output io.Writer) {
-	Log.Entry.Logger.SetOutput(output)
+	setOutput(globalLogger().Entry.Logger, output)
 }
 
 // AddFileOutputHook adds a file hook to the global logger
@@ -200,26 +294,52 @@ func AddFileOutputHook(filename string, cfg *RotatingFileConfig, levels ...logru
 	if err != nil {
 		return err
 	}
-	Log.Entry.Logger.AddHook(hook)
+	addHook(globalLogger().Entry.Logger, hook)
 	return nil
 }
 
+// SetFileFormatter sets the formatter of the file hooks of the global logger,
+// see Logger.SetFileFormatter
+func SetFileFormatter(formatter logrus.Formatter) {
+	globalLogger().SetFileFormatter(formatter)
+}
+
+// Close flushes and closes the hooks and the output of the global logger, see
+// Logger.Close
+func Close() error {
+	return globalLogger().Close()
+}
+
 // NullOutput sets the logger output to io.Discard, effectively disabling all log output.
 // This is useful for testing scenarios where log output needs to be suppressed.
 func NullOutput() {
-	Log.Entry.Logger.SetOutput(io.Discard)
+	setOutput(globalLogger().Entry.Logger, io.Discard)
 }
 
-func WithFields(fields ...string) *Logger {
-	if len(fields)%2 != 0 {
-		panic("WithFields requires an even number of arguments")
+// WithFields returns the global logger adding fields given as alternating keys
+// and values to every entry. Keys must be strings, values can be of any type.
+// Malformed arguments don't panic: keys that aren't strings are formatted, a
+// trailing value without a key is kept under !BADKEY, and a warning is logged.
+func WithFields(keysAndValues ...interface{}) *Logger {
+	f, err := sugarFields(keysAndValues)
+	entry := global()
+	if err != nil {
+		entry.WithField(badKey, err.Error()).Warn("WithFields: malformed fields")
 	}
+	return &Logger{Entry: entry.WithFields(f)}
+}
 
-	f := make(logrus.Fields)
-	for i := 0; i < len(fields); i += 2 {
-		f[fields[i]] = fields[i+1]
-	}
-	return &Logger{Entry: Log.WithFields(f)}
+// WithFieldsMap returns the global logger adding fields to every entry, for
+// callers holding a map or values other than strings, see Logger.WithFieldsMap
+func WithFieldsMap(fields Fields) *Logger {
+	return &Logger{Entry: global().WithFields(fields)}
+}
+
+// WithFieldsMap returns a logger adding fields to every entry. Unlike
+// WithFields it returns a *Logger, so the logger specific methods stay
+// available without a type assertion.
+func (l *Logger) WithFieldsMap(fields Fields) *Logger {
+	return &Logger{Entry: l.Entry.WithFields(fields)}
 }
 
 func SetLevel(level string) {
@@ -227,12 +347,13 @@ func SetLevel(level string) {
 	if err != nil {
 		panic(err)
 	}
-	Log.Entry.Logger.SetLevel(parsedLevel)
+	l := globalLogger().Entry.Logger
+	setLoggerLevel(l, parsedLevel)
 	if parsedLevel == logrus.DebugLevel || parsedLevel == logrus.TraceLevel {
 		// set the color formatter
-		Log.Entry.Logger.SetFormatter(colorFormatter)
+		setFormatter(l, colorFormatter)
 		// add the runtime context hook
-		Log.Entry.Logger.AddHook(NewRuntimeContextHook(3))
+		l.AddHook(NewRuntimeContextHook(3))
 	}
 }
 
@@ -241,3 +362,71 @@ func ResetLogger() {
 	Log = nil
 	loggerOnce = sync.Once{}
 }
+
+// findHook returns the first hook of type T registered on l, looking through
+// the hooks wrapping other hooks
+func findHook[T logrus.Hook](l *logrus.Logger) (T, bool) {
+	for _, hook := range registeredHooks(l) {
+		if h, ok := hook.(T); ok {
+			return h, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// registeredHooks returns the distinct hooks registered on l, in level order,
+// each hook wrapping other hooks (guarded or parallel hooks) followed by the
+// hooks it wraps
+func registeredHooks(l *logrus.Logger) []logrus.Hook {
+	var hooks []logrus.Hook
+	var visit func(logrus.Hook)
+	visit = func(hook logrus.Hook) {
+		if containsHook(hooks, hook) {
+			return
+		}
+		hooks = append(hooks, hook)
+		switch w := hook.(type) {
+		case interface{ Unwrap() logrus.Hook }:
+			visit(w.Unwrap())
+		case interface{ Hooks() []logrus.Hook }:
+			for _, inner := range w.Hooks() {
+				visit(inner)
+			}
+		}
+	}
+	for _, level := range logrus.AllLevels {
+		for _, hook := range l.Hooks[level] {
+			visit(hook)
+		}
+	}
+	return hooks
+}
+
+// containsHook reports whether hooks holds hook. Hooks are compared only when
+// their values are comparable, so hooks like structs with slice fields, which
+// logrus accepts, don't panic; such hooks are never seen as duplicates.
+func containsHook(hooks []logrus.Hook, hook logrus.Hook) bool {
+	if !reflect.ValueOf(hook).Comparable() {
+		return false
+	}
+	for _, h := range hooks {
+		if reflect.TypeOf(h) == reflect.TypeOf(hook) && reflect.ValueOf(h).Comparable() && h == hook {
+			return true
+		}
+	}
+	return false
+}
+
+// prependHook registers hook ahead of the hooks already registered on l. Hooks
+// enriching entries with fields use it so sinks added earlier still see them.
+func prependHook(l *logrus.Logger, hook logrus.Hook) {
+	hooks := make(logrus.LevelHooks, len(l.Hooks))
+	for level, levelHooks := range l.Hooks {
+		hooks[level] = append([]logrus.Hook(nil), levelHooks...)
+	}
+	for _, level := range hook.Levels() {
+		hooks[level] = append([]logrus.Hook{hook}, hooks[level]...)
+	}
+	l.ReplaceHooks(hooks)
+}
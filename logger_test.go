@@ -2,9 +2,11 @@ package logger
 
 import (
 	"bytes"
+	"os"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
@@ -301,3 +303,136 @@ func TestSingletonLoggerOutput(t *testing.T) {
 		})
 	}
 }
+
+// TestPrintFunctions tests the drop-in replacements of the standard library log
+// and logrus package functions
+func TestPrintFunctions(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := NewLogger(WithOutput(&buf), WithFormatter(&PlainFormatter{}))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	logger.Entry.Logger.SetLevel(logrus.TraceLevel)
+
+	Print("print ", 1)
+	Printf("printf %d", 2)
+	Println("println", 3)
+	Traceln("traceln", 4)
+	Debugln("debugln", 5)
+	Infoln("infoln", 6)
+	Warnln("warnln", 7)
+	Errorln("errorln", 8)
+
+	want := "INFO print 1\nINFO printf 2\nINFO println 3\nTRACE traceln 4\nDEBUG debugln 5\n" +
+		"INFO infoln 6\nWARNING warnln 7\nERROR errorln 8\n"
+	if got := buf.String(); got != want {
+		t.Errorf("Unexpected output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// TestWithFieldsMap tests attaching an existing map of fields
+func TestWithFieldsMap(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := NewLogger(WithOutput(&buf), WithFormatter(&PlainFormatter{}))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	logger.WithFieldsMap(Fields{"order": 42}).WithFieldsMap(logrus.Fields{"paid": true}).Info("order placed")
+	WithFieldsMap(Fields{"total": 9.5}).Info("global")
+
+	want := "INFO order placed order=42 paid=true\nINFO global total=9.5\n"
+	if got := buf.String(); got != want {
+		t.Errorf("Unexpected output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// TestWithFieldsAnyValues tests the package level WithFields with values of any
+// type and malformed arguments
+func TestWithFieldsAnyValues(t *testing.T) {
+	var buf bytes.Buffer
+	_, err := NewLogger(WithOutput(&buf), WithFormatter(&PlainFormatter{}))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	WithFields("order", 42, "took", 1500*time.Millisecond, "paid", true).Info("order placed")
+	want := "INFO order placed order=42 paid=true took=1.5s\n"
+	if got := buf.String(); got != want {
+		t.Errorf("Unexpected output:\n%s\nwant:\n%s", got, want)
+	}
+
+	buf.Reset()
+	WithFields("order", 42, "orphan").Info("malformed")
+	want = "WARNING WithFields: malformed fields !BADKEY=\"odd number of arguments, value orphan has no key\"\n" +
+		"INFO malformed !BADKEY=orphan order=42\n"
+	if got := buf.String(); got != want {
+		t.Errorf("Unexpected output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// valueHook is a non-comparable hook registered by value, which logrus accepts
+type valueHook struct {
+	levels []logrus.Level
+	fired  *int
+}
+
+func (h valueHook) Levels() []logrus.Level { return h.levels }
+
+func (h valueHook) Fire(*logrus.Entry) error {
+	*h.fired++
+	return nil
+}
+
+func TestNonComparableHook(t *testing.T) {
+	fired := 0
+	hook := valueHook{levels: logrus.AllLevels, fired: &fired}
+	for _, opts := range [][]Option{
+		{WithHooks(hook)},
+		{WithHooks(hook), WithParallelHooks(time.Second)},
+	} {
+		fired = 0
+		logger, err := NewLogger(append([]Option{WithNullOutput(), WithLastEntriesCapture(1)}, opts...)...)
+		if err != nil {
+			t.Fatalf("NewLogger() error = %v", err)
+		}
+		logger.Info("hello")
+		if fired != 1 {
+			t.Errorf("hook fired %d times, want 1", fired)
+		}
+		if got := len(logger.LastEntries()); got != 1 {
+			t.Errorf("LastEntries() returned %d entries, want 1", got)
+		}
+		if err := logger.Close(); err != nil {
+			t.Errorf("Close() error = %v", err)
+		}
+	}
+}
+
+func TestStandardLoggerUntouched(t *testing.T) {
+	if _, err := NewLogger(WithNullOutput()); err != nil {
+		t.Fatalf("NewLogger() error = %v", err)
+	}
+	for level, hooks := range logrus.StandardLogger().Hooks {
+		if len(hooks) != 0 {
+			t.Errorf("standard logger has %d hooks for %s, want none", len(hooks), level)
+		}
+	}
+}
+
+func TestPackageFunctionsAfterReset(t *testing.T) {
+	previous := Log
+	defer func() { Log = previous }()
+	ResetLogger()
+
+	logrus.SetOutput(&bytes.Buffer{})
+	defer logrus.SetOutput(os.Stderr)
+	defer func() {
+		if e, ok := recover().(*logrus.Entry); !ok || e.Message != "boom" {
+			t.Errorf("Panic() recovered %v, want the boom entry", e)
+		}
+	}()
+	SetVerbosity(0)
+	Info("after reset")
+	Panic("boom")
+}
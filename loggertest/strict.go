@@ -0,0 +1,105 @@
+// Package loggertest provides test doubles for code logging through
+// go-logger.
+package loggertest
+
+import (
+	"strings"
+	"sync"
+
+	logger "github.com/alejoacosta74/go-logger"
+	"github.com/sirupsen/logrus"
+)
+
+// TB is the part of testing.TB used by the strict logger
+type TB interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+	Cleanup(func())
+}
+
+// expectation allows entries at level whose message contains substr
+type expectation struct {
+	level   logrus.Level
+	substr  string
+	matched int
+}
+
+// StrictLogger is a logger failing its test on any warn, error, fatal or panic
+// entry that wasn't expected
+type StrictLogger struct {
+	*logger.Logger
+	t        TB
+	mu       sync.Mutex
+	expected []*expectation
+}
+
+// Strict returns a logger failing t on every warn or higher entry not allowed
+// with Expect, so tests catch unexpected warnings and errors. Output is
+// discarded unless opts set one, and Fatal doesn't exit. Expectations never
+// matched fail the test when it completes.
+func Strict(t TB, opts ...logger.Option) *StrictLogger {
+	t.Helper()
+	s := &StrictLogger{t: t}
+	opts = append([]logger.Option{logger.WithNullOutput()}, opts...)
+	opts = append(opts, func(l *logger.Logger) error {
+		l.Logger.ExitFunc = func(int) {}
+		l.Logger.AddHook(&strictHook{s: s})
+		return nil
+	})
+	l, err := logger.NewLogger(opts...)
+	if err != nil {
+		t.Errorf("loggertest: %v", err)
+		return s
+	}
+	s.Logger = l
+	t.Cleanup(s.verify)
+	return s
+}
+
+// Expect allows entries at level whose message contains substr. The test
+// fails if no such entry is logged.
+func (s *StrictLogger) Expect(level logrus.Level, substr string) *StrictLogger {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expected = append(s.expected, &expectation{level: level, substr: substr})
+	return s
+}
+
+// check matches an entry against the expectations
+func (s *StrictLogger) check(entry *logrus.Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range s.expected {
+		if e.level == entry.Level && strings.Contains(entry.Message, e.substr) {
+			e.matched++
+			return
+		}
+	}
+	s.t.Helper()
+	s.t.Errorf("loggertest: unexpected %s entry: %q %v", entry.Level, entry.Message, entry.Data)
+}
+
+// verify reports the expectations that were never matched
+func (s *StrictLogger) verify() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range s.expected {
+		if e.matched == 0 {
+			s.t.Errorf("loggertest: expected %s entry containing %q was not logged", e.level, e.substr)
+		}
+	}
+}
+
+// strictHook implements logrus.Hook checking warn and higher entries
+type strictHook struct {
+	s *StrictLogger
+}
+
+func (h *strictHook) Levels() []logrus.Level {
+	return []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel, logrus.WarnLevel}
+}
+
+func (h *strictHook) Fire(entry *logrus.Entry) error {
+	h.s.check(entry)
+	return nil
+}
@@ -0,0 +1,55 @@
+package loggertest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeT records the failures reported by the strict logger
+type fakeT struct {
+	errors   []string
+	cleanups []func()
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Errorf(format string, args ...interface{}) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeT) Cleanup(fn func()) { f.cleanups = append(f.cleanups, fn) }
+
+func (f *fakeT) finish() {
+	for i := len(f.cleanups) - 1; i >= 0; i-- {
+		f.cleanups[i]()
+	}
+}
+
+func TestStrict(t *testing.T) {
+	ft := &fakeT{}
+	l := Strict(ft).Expect(logrus.WarnLevel, "retrying")
+	l.Info("starting")
+	l.Debug("details")
+	l.Warn("retrying request")
+	l.Fatal("cannot continue")
+	ft.finish()
+
+	assert.Len(t, ft.errors, 1)
+	assert.Contains(t, ft.errors[0], `unexpected fatal entry: "cannot continue"`)
+}
+
+func TestStrict_UnmetExpectation(t *testing.T) {
+	ft := &fakeT{}
+	Strict(ft).Expect(logrus.ErrorLevel, "payment failed")
+	ft.finish()
+
+	assert.Equal(t, []string{`loggertest: expected error entry containing "payment failed" was not logged`}, ft.errors)
+}
+
+func TestStrict_Passes(t *testing.T) {
+	l := Strict(t)
+	l.WithFields(map[string]interface{}{"order": "A1"}).Info("charged")
+}
@@ -0,0 +1,300 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultLokiBatchSize  = 100
+	DefaultLokiBatchWait  = time.Second
+	DefaultLokiQueueSize  = 10000
+	DefaultLokiMaxRetries = 5
+	DefaultLokiMinBackoff = 500 * time.Millisecond
+	DefaultLokiMaxBackoff = 5 * time.Second
+	DefaultLokiTimeout    = 10 * time.Second
+)
+
+// LokiLabels selects the labels attached to every pushed stream
+type LokiLabels struct {
+	Static map[string]string // labels added to every stream (e.g. job, app)
+	Fields []string          // entry fields promoted to labels when present
+}
+
+// LokiBatchOptions holds batching, retry and backpressure settings for the Loki hook
+type LokiBatchOptions struct {
+	BatchSize  int           // entries per push
+	BatchWait  time.Duration // maximum time an entry waits before being pushed
+	QueueSize  int           // entries buffered before backpressure applies
+	Block      bool          // block logging when the queue is full instead of dropping
+	MaxRetries int           // retries for failed pushes
+	MinBackoff time.Duration // initial retry delay
+	MaxBackoff time.Duration // maximum retry delay
+	Timeout    time.Duration // HTTP request timeout
+	TenantID   string        // sent as X-Scope-OrgID when set
+	Formatter  logrus.Formatter
+	Levels     []logrus.Level
+	// Retry overrides MaxRetries, MinBackoff and MaxBackoff, and reports the
+	// batches that couldn't be pushed
+	Retry *RetryPolicy
+	TLS   *TLSConfig
+}
+
+// lokiHook implements logrus.Hook pushing batches of entries to Loki
+type lokiHook struct {
+	url       string
+	client    *http.Client
+	labels    LokiLabels
+	opts      LokiBatchOptions
+	formatter logrus.Formatter
+	retry     RetryPolicy
+	mu        sync.Mutex
+	batcher   *Batcher[lokiEntry]
+}
+
+type lokiEntry struct {
+	labels map[string]string
+	ts     time.Time
+	line   string
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+type lokiPushRequest struct {
+	Streams []*lokiStream `json:"streams"`
+}
+
+// NewLokiHook creates a new hook pushing entries to the Loki push API at url
+// (e.g. http://loki:3100/loki/api/v1/push)
+func NewLokiHook(url string, labels LokiLabels, batchOpts *LokiBatchOptions) (*lokiHook, error) {
+	if url == "" {
+		return nil, fmt.Errorf("loki: url is required")
+	}
+	if !strings.Contains(url, "/loki/api/") {
+		url = strings.TrimSuffix(url, "/") + "/loki/api/v1/push"
+	}
+
+	opts := LokiBatchOptions{}
+	if batchOpts != nil {
+		opts = *batchOpts
+	}
+	// Set default values if not specified
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultLokiBatchSize
+	}
+	if opts.BatchWait <= 0 {
+		opts.BatchWait = DefaultLokiBatchWait
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = DefaultLokiQueueSize
+	}
+	if opts.MaxRetries < 0 {
+		opts.MaxRetries = 0
+	} else if opts.MaxRetries == 0 {
+		opts.MaxRetries = DefaultLokiMaxRetries
+	}
+	if opts.MinBackoff <= 0 {
+		opts.MinBackoff = DefaultLokiMinBackoff
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = DefaultLokiMaxBackoff
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultLokiTimeout
+	}
+	if len(opts.Levels) == 0 {
+		opts.Levels = logrus.AllLevels
+	}
+
+	formatter := opts.Formatter
+	if formatter == nil {
+		formatter = &logrus.TextFormatter{
+			DisableColors:    true,
+			DisableTimestamp: true,
+		}
+	}
+
+	tlsConfig, err := opts.TLS.build()
+	if err != nil {
+		return nil, fmt.Errorf("loki: %w", err)
+	}
+
+	hook := &lokiHook{
+		url:       url,
+		client:    newHTTPClient(opts.Timeout, tlsConfig),
+		labels:    labels,
+		opts:      opts,
+		formatter: formatter,
+		retry: retryPolicy(opts.Retry, RetryPolicy{
+			MaxAttempts: opts.MaxRetries + 1,
+			MinBackoff:  opts.MinBackoff,
+			MaxBackoff:  opts.MaxBackoff,
+		}),
+	}
+	hook.batcher = NewBatcher(opts.BatchSize, opts.BatchWait, opts.QueueSize, opts.Block, hook.push)
+
+	return hook, nil
+}
+
+// WithLoki adds a hook that batches entries and pushes them to Grafana Loki
+func WithLoki(url string, labels LokiLabels, batchOpts *LokiBatchOptions) Option {
+	return func(l *Logger) error {
+		hook, err := NewLokiHook(url, labels, batchOpts)
+		if err != nil {
+			return err
+		}
+		l.Entry.Logger.AddHook(hook)
+		return nil
+	}
+}
+
+// Fire queues the log entry for the next push
+func (h *lokiHook) Fire(entry *logrus.Entry) error {
+	h.mu.Lock()
+	line, err := h.formatter.Format(entry)
+	h.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	h.batcher.Add(lokiEntry{
+		labels: h.streamLabels(entry),
+		ts:     entry.Time,
+		line:   strings.TrimSuffix(string(line), "\n"),
+	})
+	return nil
+}
+
+// Levels returns the levels this hook should be fired for
+func (h *lokiHook) Levels() []logrus.Level {
+	return h.opts.Levels
+}
+
+// Dropped returns the number of entries discarded because the queue was full
+func (h *lokiHook) Dropped() uint64 {
+	return h.batcher.Dropped()
+}
+
+// Flush pushes the queued entries without stopping the background worker
+func (h *lokiHook) Flush() error {
+	h.batcher.Sync()
+	return nil
+}
+
+// Close pushes pending entries and stops the background worker
+func (h *lokiHook) Close() error {
+	h.batcher.Close()
+	return nil
+}
+
+// streamLabels builds the label set for an entry
+func (h *lokiHook) streamLabels(entry *logrus.Entry) map[string]string {
+	labels := make(map[string]string, len(h.labels.Static)+len(h.labels.Fields)+1)
+	for k, v := range h.labels.Static {
+		labels[lokiLabelName(k)] = v
+	}
+	labels["level"] = entry.Level.String()
+	for _, field := range h.labels.Fields {
+		if v, ok := entry.Data[field]; ok {
+			labels[lokiLabelName(field)] = fmt.Sprint(v)
+		}
+	}
+	return labels
+}
+
+// push sends a batch to Loki, retrying with exponential backoff on network
+// errors, 429 and 5xx responses
+func (h *lokiHook) push(entries []lokiEntry) {
+	streams := make(map[string]*lokiStream)
+	req := lokiPushRequest{}
+	for _, e := range entries {
+		key := lokiLabelKey(e.labels)
+		stream, ok := streams[key]
+		if !ok {
+			stream = &lokiStream{Stream: e.labels}
+			streams[key] = stream
+			req.Streams = append(req.Streams, stream)
+		}
+		stream.Values = append(stream.Values, [2]string{strconv.FormatInt(e.ts.UnixNano(), 10), e.line})
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return
+	}
+
+	h.retry.Deliver("loki", len(entries), body, h.batcher.Closing(), h.send)
+}
+
+// send performs a single push request and reports whether it should be retried
+func (h *lokiHook) send(body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if h.opts.TenantID != "" {
+		req.Header.Set("X-Scope-OrgID", h.opts.TenantID)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode/100 == 2 {
+		return false, nil
+	}
+	err = fmt.Errorf("loki: push failed with status %d", resp.StatusCode)
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500, err
+}
+
+// lokiLabelKey returns a canonical key for a label set. Label names can't hold
+// separators but values can, so they are quoted to keep distinct sets apart.
+func lokiLabelKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(strconv.Quote(labels[k]))
+		b.WriteByte(',')
+	}
+	return b.String()
+}
+
+// lokiLabelName converts a field key into a valid Loki label name
+func lokiLabelName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+	if name == "" || ('0' <= name[0] && name[0] <= '9') {
+		name = "_" + name
+	}
+	return name
+}
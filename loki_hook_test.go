@@ -0,0 +1,142 @@
+package logger
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLokiHook(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []lokiPushRequest
+		tenant   string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/loki/api/v1/push", r.URL.Path)
+		var req lokiPushRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		mu.Lock()
+		requests = append(requests, req)
+		tenant = r.Header.Get("X-Scope-OrgID")
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	hook, err := NewLokiHook(server.URL,
+		LokiLabels{Static: map[string]string{"job": "api"}, Fields: []string{"service"}},
+		&LokiBatchOptions{BatchSize: 10, BatchWait: time.Hour, TenantID: "team-a"},
+	)
+	require.NoError(t, err)
+
+	logger := logrus.New()
+	logger.AddHook(hook)
+	logger.SetOutput(io.Discard)
+
+	logger.WithField("service", "auth").Info("user logged in")
+	logger.WithField("service", "auth").Warn("slow login")
+	logger.Error("no service field")
+
+	// Close flushes the pending batch
+	require.NoError(t, hook.Close())
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, requests, 1)
+	assert.Equal(t, "team-a", tenant)
+
+	streams := map[string]*lokiStream{}
+	total := 0
+	for _, s := range requests[0].Streams {
+		streams[lokiLabelKey(s.Stream)] = s
+		total += len(s.Values)
+	}
+	assert.Equal(t, 3, total)
+
+	info := streams[lokiLabelKey(map[string]string{"job": "api", "level": "info", "service": "auth"})]
+	require.NotNil(t, info)
+	assert.Contains(t, info.Values[0][1], "user logged in")
+
+	errStream := streams[lokiLabelKey(map[string]string{"job": "api", "level": "error"})]
+	require.NotNil(t, errStream)
+	assert.Contains(t, errStream.Values[0][1], "no service field")
+}
+
+func TestLokiHook_Retry(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	hook, err := NewLokiHook(server.URL, LokiLabels{}, &LokiBatchOptions{
+		BatchSize:  1,
+		MinBackoff: time.Millisecond,
+		MaxBackoff: time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	logger := logrus.New()
+	logger.AddHook(hook)
+	logger.SetOutput(io.Discard)
+	logger.Info("retried message")
+
+	require.NoError(t, hook.Close())
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestLokiHook_DropWhenFull(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	hook, err := NewLokiHook(server.URL, LokiLabels{}, &LokiBatchOptions{BatchSize: 1, QueueSize: 1})
+	require.NoError(t, err)
+
+	logger := logrus.New()
+	logger.AddHook(hook)
+	logger.SetOutput(io.Discard)
+	for i := 0; i < 10; i++ {
+		logger.Info("message")
+	}
+
+	assert.Positive(t, hook.Dropped())
+	close(release)
+	require.NoError(t, hook.Close())
+}
+
+func TestLokiLabelName(t *testing.T) {
+	assert.Equal(t, "request_id", lokiLabelName("request-id"))
+	assert.Equal(t, "_1st", lokiLabelName("1st"))
+}
+
+func TestLokiLabelKey(t *testing.T) {
+	assert.Equal(t,
+		lokiLabelKey(map[string]string{"job": "api", "level": "info"}),
+		lokiLabelKey(map[string]string{"level": "info", "job": "api"}))
+
+	// values holding the separators don't collide with other label sets
+	assert.NotEqual(t,
+		lokiLabelKey(map[string]string{"a": "1,b=2"}),
+		lokiLabelKey(map[string]string{"a": "1", "b": "2"}))
+	assert.NotEqual(t,
+		lokiLabelKey(map[string]string{"a": `x",b="y`}),
+		lokiLabelKey(map[string]string{"a": "x", "b": "y"}))
+}
@@ -0,0 +1,183 @@
+package logger
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ManifestExtension is appended to the name of a rotated file to name its
+// integrity manifest, e.g. app.log.manifest
+const ManifestExtension = ".manifest"
+
+// ManifestEntry is a line of an integrity manifest, recording the checksum of
+// an archived file when it was rotated
+type ManifestEntry struct {
+	File   string    `json:"file"` // base name, in the directory of the manifest
+	Size   int64     `json:"size"`
+	SHA256 string    `json:"sha256"`
+	HMAC   string    `json:"hmac,omitempty"` // HMAC-SHA256 of the file, when a key is configured
+	Time   time.Time `json:"time"`
+}
+
+// ManifestReport describes the archived files checked against their manifest
+type ManifestReport struct {
+	Verified []string // files matching their checksum
+	Modified []string // files whose checksum or HMAC does not match
+	// Missing are the files removed since they were recorded, e.g. by the
+	// retention or once uploaded
+	Missing []string
+}
+
+// Valid reports whether no archived file was modified
+func (r *ManifestReport) Valid() bool {
+	return len(r.Modified) == 0
+}
+
+// manifest records the checksums of the archives of a rotated file, appending
+// one JSON line per archive. Entries are kept once the archives are removed,
+// so the manifest stays an audit trail.
+type manifest struct {
+	path string
+	key  []byte // nil to leave the HMAC out
+
+	mu       sync.Mutex
+	recorded map[string]bool // base names, loaded on first use
+}
+
+func newManifest(filename string, key []byte) *manifest {
+	return &manifest{path: filename + ManifestExtension, key: key}
+}
+
+// record appends the checksum of path unless it was recorded already
+func (m *manifest) record(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.recorded == nil {
+		m.recorded = make(map[string]bool)
+		entries, err := readManifest(m.path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("manifest: %w", err)
+		}
+		for _, e := range entries {
+			m.recorded[e.File] = true
+		}
+	}
+	name := filepath.Base(path)
+	if m.recorded[name] {
+		return nil
+	}
+
+	entry, err := checksumFile(path, m.key)
+	if err != nil {
+		return fmt.Errorf("manifest: %w", err)
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("manifest: %w", err)
+	}
+	f, err := os.OpenFile(m.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("manifest: %w", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("manifest: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("manifest: %w", err)
+	}
+	m.recorded[name] = true
+	return nil
+}
+
+// checksumFile computes the manifest entry of path
+func checksumFile(path string, key []byte) (ManifestEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return ManifestEntry{}, err
+	}
+	defer f.Close()
+
+	sum := sha256.New()
+	var mac hash.Hash
+	w := io.Writer(sum)
+	if key != nil {
+		mac = hmac.New(sha256.New, key)
+		w = io.MultiWriter(sum, mac)
+	}
+	n, err := io.Copy(w, f)
+	if err != nil {
+		return ManifestEntry{}, err
+	}
+	entry := ManifestEntry{
+		File:   filepath.Base(path),
+		Size:   n,
+		SHA256: hex.EncodeToString(sum.Sum(nil)),
+		Time:   time.Now().UTC(),
+	}
+	if mac != nil {
+		entry.HMAC = hex.EncodeToString(mac.Sum(nil))
+	}
+	return entry, nil
+}
+
+// readManifest reads the entries of the manifest at path
+func readManifest(path string) ([]ManifestEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []ManifestEntry
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		var e ManifestEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// VerifyManifest checks the archives of the rotated file filename against the
+// manifest written with RotatingFileConfig.Manifest. With key, the HMAC of every
+// archive is checked too, so a manifest rewritten along with the archives is
+// detected; entries recorded without an HMAC are then reported as modified.
+func VerifyManifest(filename string, key []byte) (*ManifestReport, error) {
+	path := filename + ManifestExtension
+	entries, err := readManifest(path)
+	if err != nil {
+		return nil, fmt.Errorf("verify manifest: %w", err)
+	}
+
+	report := &ManifestReport{}
+	dir := filepath.Dir(path)
+	for _, e := range entries {
+		got, err := checksumFile(filepath.Join(dir, e.File), key)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			report.Missing = append(report.Missing, e.File)
+		case err != nil:
+			return nil, fmt.Errorf("verify manifest: %w", err)
+		case got.SHA256 != e.SHA256 || got.Size != e.Size,
+			key != nil && !hmac.Equal([]byte(got.HMAC), []byte(e.HMAC)):
+			report.Modified = append(report.Modified, e.File)
+		default:
+			report.Verified = append(report.Verified, e.File)
+		}
+	}
+	return report, nil
+}
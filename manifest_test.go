@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatingFileHook_Manifest(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+	key := []byte("audit key")
+	logger, hook := newCompressedFileHook(t, &RotatingFileConfig{Filename: filename, Compress: true, ManifestKey: key})
+
+	for i := 0; i < 2; i++ {
+		logger.Info("archived entry")
+		require.NoError(t, logger.ForceRotateAll())
+		// backups are named after the rotation time, in milliseconds
+		time.Sleep(5 * time.Millisecond)
+	}
+	require.NoError(t, hook.Close())
+
+	entries, err := readManifest(filename + ManifestExtension)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	for _, e := range entries {
+		assert.Regexp(t, `^app-.*\.log\.gz$`, e.File)
+		assert.Len(t, e.SHA256, 64)
+		assert.Len(t, e.HMAC, 64)
+	}
+
+	report, err := VerifyManifest(filename, key)
+	require.NoError(t, err)
+	assert.True(t, report.Valid())
+	assert.Len(t, report.Verified, 2)
+
+	// a tampered archive and a removed one
+	require.NoError(t, os.WriteFile(filepath.Join(dir, entries[0].File), []byte("tampered"), 0o644))
+	require.NoError(t, os.Remove(filepath.Join(dir, entries[1].File)))
+	report, err = VerifyManifest(filename, key)
+	require.NoError(t, err)
+	assert.False(t, report.Valid())
+	assert.Equal(t, []string{entries[0].File}, report.Modified)
+	assert.Equal(t, []string{entries[1].File}, report.Missing)
+}
+
+func TestVerifyManifest_HMAC(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+	archive := filepath.Join(dir, "app-2024-01-02T03-04-05.000.log")
+	require.NoError(t, os.WriteFile(archive, []byte("entry\n"), 0o644))
+
+	m := newManifest(filename, []byte("audit key"))
+	require.NoError(t, m.record(archive))
+	// recorded once
+	require.NoError(t, newManifest(filename, nil).record(archive))
+	entries, err := readManifest(filename + ManifestExtension)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	report, err := VerifyManifest(filename, []byte("audit key"))
+	require.NoError(t, err)
+	assert.True(t, report.Valid())
+
+	// the checksum matches but the HMAC doesn't with another key
+	report, err = VerifyManifest(filename, []byte("other key"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Base(archive)}, report.Modified)
+
+	_, err = VerifyManifest(filepath.Join(dir, "missing.log"), nil)
+	assert.Error(t, err)
+}
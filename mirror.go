@@ -0,0 +1,130 @@
+package logger
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// mirrorKey marks the context of the entries logged through the package level
+// functions while mirroring
+type mirrorKey struct{}
+
+// globalMirror copies the entries logged through the package level functions
+// to the loggers registered with MirrorGlobalTo. It is added to the global
+// logger once mirroring starts, and to the loggers later set as global.
+var globalMirror = &mirrorHook{}
+
+type mirrorHook struct {
+	mu      sync.RWMutex
+	targets []*Logger
+	// logger is the global logger the hook was last added to. The loggers it was
+	// added to before aren't kept, so they can be collected once replaced.
+	logger *logrus.Logger
+}
+
+// MirrorGlobalTo duplicates the entries logged through the package level
+// functions (Info, WithField, ...) into l, e.g. a request-scoped logger, easing
+// the migration of code still using the global logger. Only the entries enabled
+// on the global logger are mirrored, l then applies its own level. The returned
+// func stops mirroring to l.
+func MirrorGlobalTo(l *Logger) (stop func()) {
+	globalMirror.mu.Lock()
+	globalMirror.targets = append(globalMirror.targets, l)
+	globalMirror.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			globalMirror.mu.Lock()
+			defer globalMirror.mu.Unlock()
+			for i, target := range globalMirror.targets {
+				if target == l {
+					globalMirror.targets = append(globalMirror.targets[:i:i], globalMirror.targets[i+1:]...)
+					break
+				}
+			}
+		})
+	}
+}
+
+// globalLogger returns the global Log, or a logger writing to the logrus
+// standard logger once ResetLogger cleared it, so the package level functions
+// never dereference nil
+func globalLogger() *Logger {
+	if l := Log; l != nil && l.Entry != nil {
+		return l
+	}
+	return &Logger{Entry: logrus.NewEntry(logrus.StandardLogger())}
+}
+
+// global returns the entry the package level functions log with, marked for
+// mirroring when MirrorGlobalTo is in use
+func global() *logrus.Entry {
+	entry := globalLogger().Entry
+	if !globalMirror.install(entry.Logger) {
+		return entry
+	}
+	ctx := entry.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return entry.WithContext(context.WithValue(ctx, mirrorKey{}, true))
+}
+
+// install adds the hook to logger when there are targets, reporting whether
+// entries should be marked
+func (h *mirrorHook) install(logger *logrus.Logger) bool {
+	h.mu.RLock()
+	mirroring, installed := len(h.targets) > 0, h.logger == logger
+	h.mu.RUnlock()
+	if !mirroring || installed {
+		return mirroring
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.logger != logger {
+		// a logger set as global again still holds the hook
+		if _, ok := findHook[*mirrorHook](logger); !ok {
+			addHook(logger, h)
+		}
+		h.logger = logger
+	}
+	return true
+}
+
+func (h *mirrorHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *mirrorHook) Fire(entry *logrus.Entry) error {
+	if entry.Context == nil || entry.Context.Value(mirrorKey{}) != true {
+		return nil
+	}
+	h.mu.RLock()
+	targets := h.targets
+	h.mu.RUnlock()
+
+	for _, target := range targets {
+		ctx := target.Entry.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		// unmarked, a target derived from the global logger isn't mirrored again
+		mirrored := target.Entry.WithContext(context.WithValue(ctx, mirrorKey{}, false)).
+			WithFields(entry.Data).WithTime(entry.Time)
+		mirrorEntry(mirrored, entry.Level, entry.Message)
+	}
+	return nil
+}
+
+// mirrorEntry logs message at level, without panicking at panic level: the
+// global logger panics once its hooks have fired
+func mirrorEntry(entry *logrus.Entry, level logrus.Level, message string) {
+	if level == logrus.PanicLevel {
+		defer func() { recover() }()
+	}
+	entry.Log(level, message)
+}
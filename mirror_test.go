@@ -0,0 +1,81 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMirrorGlobalTo(t *testing.T) {
+	previous := Log
+	t.Cleanup(func() { Log = previous })
+
+	global, err := NewLogger(WithNullOutput(), WithLastEntriesCapture(10))
+	require.NoError(t, err)
+	target, err := createNewLogger(WithNullOutput(), WithLastEntriesCapture(10))
+	require.NoError(t, err)
+
+	stop := MirrorGlobalTo(target)
+	Info("through the package")
+	WithField("user_id", "42").Warn("with a field")
+	global.Info("through the instance")
+	assert.Panics(t, func() { Panic("boom") })
+	stop()
+	Info("after stop")
+
+	assert.Len(t, global.Breadcrumbs(0), 5)
+	crumbs := target.Breadcrumbs(0)
+	require.Len(t, crumbs, 3)
+	assert.Equal(t, "through the package", crumbs[0].Message)
+	assert.Equal(t, "with a field", crumbs[1].Message)
+	assert.Equal(t, logrus.WarnLevel, crumbs[1].Level)
+	assert.Equal(t, Fields{"user_id": "42"}, crumbs[1].Data)
+	assert.Equal(t, "boom", crumbs[2].Message)
+}
+
+func TestMirrorGlobalTo_FollowsNewGlobal(t *testing.T) {
+	previous := Log
+	t.Cleanup(func() { Log = previous })
+
+	target, err := createNewLogger(WithNullOutput(), WithLastEntriesCapture(10))
+	require.NoError(t, err)
+	stop := MirrorGlobalTo(target)
+	defer stop()
+
+	_, err = NewLogger(WithNullOutput())
+	require.NoError(t, err)
+	Error("first global")
+	_, err = NewLogger(WithNullOutput())
+	require.NoError(t, err)
+	Error("second global")
+
+	crumbs := target.Breadcrumbs(0)
+	require.Len(t, crumbs, 2)
+	assert.Equal(t, "second global", crumbs[1].Message)
+}
+
+func TestMirrorGlobalTo_ForgetsReplacedGlobal(t *testing.T) {
+	previous := Log
+	t.Cleanup(func() { Log = previous })
+
+	target, err := createNewLogger(WithNullOutput(), WithLastEntriesCapture(10))
+	require.NoError(t, err)
+	stop := MirrorGlobalTo(target)
+	defer stop()
+
+	first, err := NewLogger(WithNullOutput())
+	require.NoError(t, err)
+	Info("first")
+	second, err := NewLogger(WithNullOutput())
+	require.NoError(t, err)
+	Info("second")
+	assert.Same(t, second.Entry.Logger, globalMirror.logger)
+
+	// set as global again, the first logger isn't mirrored twice
+	Log = first
+	Info("first again")
+	assert.Same(t, first.Entry.Logger, globalMirror.logger)
+	assert.Len(t, target.Breadcrumbs(0), 3)
+}
@@ -0,0 +1,31 @@
+package mocks
+
+import (
+	"errors"
+	"testing"
+
+	logger "github.com/alejoacosta74/go-logger"
+	"github.com/stretchr/testify/mock"
+)
+
+// charge is code under test depending on logger.Interface
+func charge(log logger.Interface, order string, err error) {
+	if err != nil {
+		log.WithError(err).WithFields(logger.Fields{"order": order}).Error("payment failed")
+		return
+	}
+	log.Infof("charged order %s", order)
+}
+
+func TestLogger_AssertsCalls(t *testing.T) {
+	failed := NewLogger(t)
+	withFields := NewLogger(t)
+	log := NewLogger(t)
+	log.On("WithError", mock.MatchedBy(func(err error) bool { return err.Error() == "declined" })).Return(failed)
+	failed.On("WithFields", logger.Fields{"order": "A1"}).Return(withFields)
+	withFields.On("Error", "payment failed").Once()
+	log.On("Infof", "charged order %s", "B2").Once()
+
+	charge(log, "A1", errors.New("declined"))
+	charge(log, "B2", nil)
+}
@@ -0,0 +1,21 @@
+// Package muxlog adds the path templates matched by gorilla/mux routers to
+// go-logger access entries.
+package muxlog
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// RoutePattern returns the gorilla/mux path template matched by r, for
+// logger.HTTPMiddlewareOptions.RoutePattern. The middleware must be registered
+// on the router with Use so the route is known.
+func RoutePattern(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if tmpl, err := route.GetPathTemplate(); err == nil {
+			return tmpl
+		}
+	}
+	return ""
+}
@@ -0,0 +1,27 @@
+package muxlog
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	logger "github.com/alejoacosta74/go-logger"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoutePattern(t *testing.T) {
+	log, err := logger.NewLogger(logger.WithNullOutput(), logger.WithLastEntriesCapture(10))
+	require.NoError(t, err)
+
+	r := mux.NewRouter()
+	r.Use(logger.HTTPMiddleware(log, &logger.HTTPMiddlewareOptions{RoutePattern: RoutePattern}))
+	r.HandleFunc("/users/{id}", func(w http.ResponseWriter, r *http.Request) {})
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/42", nil))
+
+	crumbs := log.Breadcrumbs(0)
+	require.Len(t, crumbs, 1)
+	assert.Equal(t, "/users/{id}", crumbs[0].Data["route"])
+	assert.Equal(t, "/users/42", crumbs[0].Data["path"])
+}
@@ -0,0 +1,39 @@
+package logger
+
+// ComponentKey is the field naming the component an entry was logged by, set by
+// Named and WithComponent
+const ComponentKey = "component"
+
+// Named returns a child logger adding name as the component field, joined with
+// a dot to the component of the logger, if any, so Named("http").Named("server")
+// logs component=http.server. The plain and color formatters render the
+// component ahead of the message.
+func (l *Logger) Named(name string) *Logger {
+	if name == "" {
+		return l
+	}
+	if parent, ok := l.Entry.Data[ComponentKey].(string); ok && parent != "" {
+		name = parent + "." + name
+	}
+	return &Logger{Entry: l.Entry.WithField(ComponentKey, name)}
+}
+
+// Named returns a child of the global logger named name, see Logger.Named
+func Named(name string) *Logger {
+	return (&Logger{Entry: global()}).Named(name)
+}
+
+// WithComponent names the logger, as Logger.Named does, so every entry carries
+// the component field
+func WithComponent(name string) Option {
+	return func(l *Logger) error {
+		l.Entry = l.Named(name).Entry
+		return nil
+	}
+}
+
+// entryComponent returns the component of the entry, empty when it has none
+func entryComponent(data Fields) string {
+	component, _ := data[ComponentKey].(string)
+	return component
+}
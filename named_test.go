@@ -0,0 +1,22 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNamed(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := NewLogger(WithOutput(&buf), WithFormatter(&PlainFormatter{}), WithComponent("api"))
+	require.NoError(t, err)
+
+	server := l.Named("http").Named("server")
+	server.WithField("port", 8080).Info("listening")
+	l.Named("").Info("started")
+	assert.Equal(t, "INFO [api.http.server] listening port=8080\nINFO [api] started\n", buf.String())
+	assert.Equal(t, "api.http.server", server.Entry.Data[ComponentKey])
+	assert.Equal(t, "api", l.Entry.Data[ComponentKey])
+}
@@ -0,0 +1,298 @@
+// Package natslog publishes go-logger entries to NATS. Importing it registers
+// the nats scheme with logger.RegisterSink, so nats://server:4222/subject URLs
+// can be used with logger.WithOutputURIs and logger.NewSink.
+package natslog
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	logger "github.com/alejoacosta74/go-logger"
+	"github.com/nats-io/nats.go"
+	"github.com/sirupsen/logrus"
+)
+
+const DefaultFlushTimeout = 5 * time.Second
+
+func init() {
+	if err := logger.RegisterSink("nats", newSink); err != nil {
+		panic(err)
+	}
+}
+
+// Options holds configuration for the NATS hook
+type Options struct {
+	URL string // server URLs, comma separated, defaults to nats.DefaultURL
+	// Subject is the subject entries are published to. {field} placeholders are
+	// replaced with entry fields (and {level} with the entry level), with dots,
+	// wildcards and whitespace in values replaced by underscores.
+	Subject string
+	// JetStream publishes to a JetStream stream, asynchronously with acks, instead
+	// of core NATS. The stream must already exist.
+	JetStream bool
+	// Conn reuses an existing connection instead of dialing URL. The hook doesn't
+	// close it.
+	Conn    *nats.Conn
+	Options []nats.Option // dial options, e.g. nats.UserCredentials
+	// OnError is called for entries that fail to publish
+	OnError func(err error, line []byte)
+	// Retry bounds the attempts to publish an entry, and have it acknowledged
+	// with JetStream, a single one by default. Entries given up on are passed
+	// to its OnFailure, then to OnError.
+	Retry        *logger.RetryPolicy
+	FlushTimeout time.Duration // time allowed to flush pending messages on Close
+	Formatter    logrus.Formatter
+	Levels       []logrus.Level
+}
+
+// publisher is the part of a NATS connection used by the hook
+type publisher interface {
+	Publish(subject string, data []byte) error
+	// Close flushes pending messages, waiting at most timeout
+	Close(timeout time.Duration) error
+}
+
+// hook implements logrus.Hook publishing formatted entries to NATS
+type hook struct {
+	publisher publisher
+	subject   string
+	onError   func(err error, line []byte)
+	retry     logger.RetryPolicy
+	timeout   time.Duration
+	formatter logrus.Formatter
+	levels    []logrus.Level
+	mu        sync.Mutex
+
+	// stop is closed on Close so retries stop waiting
+	stop      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// NewHook creates a new hook publishing entries to a NATS subject
+func NewHook(opts *Options) (*hook, error) {
+	if opts == nil || opts.Subject == "" {
+		return nil, fmt.Errorf("nats: subject is required")
+	}
+
+	conn, owned := opts.Conn, false
+	if conn == nil {
+		url := opts.URL
+		if url == "" {
+			url = nats.DefaultURL
+		}
+		var err error
+		if conn, err = nats.Connect(url, opts.Options...); err != nil {
+			return nil, fmt.Errorf("nats: %w", err)
+		}
+		owned = true
+	}
+
+	var h *hook
+	var p publisher = &corePublisher{conn: conn, owned: owned}
+	if opts.JetStream {
+		js, err := conn.JetStream(nats.PublishAsyncErrHandler(func(js nats.JetStream, msg *nats.Msg, err error) {
+			h.wg.Add(1)
+			go h.redeliver(js, msg, err)
+		}))
+		if err != nil {
+			if owned {
+				conn.Close()
+			}
+			return nil, fmt.Errorf("nats: %w", err)
+		}
+		p = &jetStreamPublisher{corePublisher: corePublisher{conn: conn, owned: owned}, js: js}
+	}
+	h = newHook(p, opts)
+	return h, nil
+}
+
+func newHook(p publisher, opts *Options) *hook {
+	h := &hook{
+		publisher: p,
+		subject:   opts.Subject,
+		onError:   opts.OnError,
+		timeout:   opts.FlushTimeout,
+		stop:      make(chan struct{}),
+		formatter: opts.Formatter,
+		levels:    opts.Levels,
+	}
+	if h.timeout <= 0 {
+		h.timeout = DefaultFlushTimeout
+	}
+	if h.formatter == nil {
+		h.formatter = &logrus.JSONFormatter{}
+	}
+	if len(h.levels) == 0 {
+		h.levels = logrus.AllLevels
+	}
+	if opts.Retry != nil {
+		h.retry = *opts.Retry
+	}
+	return h
+}
+
+// WithHook adds a hook publishing entries to a NATS subject
+func WithHook(opts *Options) logger.Option {
+	return func(l *logger.Logger) error {
+		h, err := NewHook(opts)
+		if err != nil {
+			return err
+		}
+		l.Entry.Logger.AddHook(h)
+		return nil
+	}
+}
+
+// Levels returns the levels this hook should be fired for
+func (h *hook) Levels() []logrus.Level {
+	return h.levels
+}
+
+// Fire publishes the formatted entry to its subject
+func (h *hook) Fire(entry *logrus.Entry) error {
+	h.mu.Lock()
+	line, err := h.formatter.Format(entry)
+	h.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	subject := logger.ExpandFields(h.subject, entry, subjectToken)
+	err = h.retry.Deliver("nats", 1, line, h.stop, func(line []byte) (bool, error) {
+		err := h.publisher.Publish(subject, line)
+		return retryable(err), err
+	})
+	if err != nil && h.onError != nil {
+		h.onError(err, line)
+	}
+	return err
+}
+
+// redeliver publishes a message JetStream failed to acknowledge again, waiting
+// for the acknowledgement, until the retry policy gives up
+func (h *hook) redeliver(js nats.JetStream, msg *nats.Msg, err error) {
+	defer h.wg.Done()
+	attempts := 0
+	err = h.retry.Deliver("nats", 1, msg.Data, h.stop, func(data []byte) (bool, error) {
+		// the first attempt is the failed asynchronous publish
+		if attempts++; attempts > 1 {
+			_, err = js.Publish(msg.Subject, data)
+		}
+		return retryable(err), err
+	})
+	if err != nil && h.onError != nil {
+		h.onError(err, msg.Data)
+	}
+}
+
+// retryable reports whether publishing again may succeed
+func retryable(err error) bool {
+	return err != nil &&
+		!errors.Is(err, nats.ErrConnectionClosed) &&
+		!errors.Is(err, nats.ErrMaxPayload) &&
+		!errors.Is(err, nats.ErrBadSubject)
+}
+
+// Close flushes pending messages and closes the connection if the hook dialed it
+func (h *hook) Close() error {
+	var err error
+	h.closeOnce.Do(func() {
+		close(h.stop)
+		err = h.publisher.Close(h.timeout)
+		h.wg.Wait()
+	})
+	return err
+}
+
+// subjectToken makes a field value safe to use within a subject token
+func subjectToken(value string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '.', '*', '>', ' ', '\t', '\r', '\n':
+			return '_'
+		}
+		return r
+	}, value)
+}
+
+// corePublisher publishes with core NATS
+type corePublisher struct {
+	conn  *nats.Conn
+	owned bool
+}
+
+func (p *corePublisher) Publish(subject string, data []byte) error {
+	return p.conn.Publish(subject, data)
+}
+
+func (p *corePublisher) Close(timeout time.Duration) error {
+	err := p.conn.FlushTimeout(timeout)
+	if p.owned {
+		p.conn.Close()
+	}
+	return err
+}
+
+// jetStreamPublisher publishes asynchronously to JetStream
+type jetStreamPublisher struct {
+	corePublisher
+	js nats.JetStreamContext
+}
+
+func (p *jetStreamPublisher) Publish(subject string, data []byte) error {
+	_, err := p.js.PublishAsync(subject, data)
+	return err
+}
+
+func (p *jetStreamPublisher) Close(timeout time.Duration) error {
+	var err error
+	select {
+	case <-p.js.PublishAsyncComplete():
+	case <-time.After(timeout):
+		err = fmt.Errorf("nats: %d messages not acknowledged", p.js.PublishAsyncPending())
+	}
+	if closeErr := p.corePublisher.Close(timeout); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// newSink connects for nats://server:4222/subject URLs, publishing every write
+// to the subject with core NATS
+func newSink(u *url.URL) (logger.Sink, error) {
+	subject := strings.Trim(u.Path, "/")
+	if u.Host == "" || subject == "" {
+		return nil, fmt.Errorf("server and subject are required, e.g. nats://server:4222/subject")
+	}
+	server := url.URL{Scheme: "nats", Host: u.Host, User: u.User}
+	conn, err := nats.Connect(server.String())
+	if err != nil {
+		return nil, err
+	}
+	return &sink{conn: conn, subject: subject}, nil
+}
+
+// sink is a logger.Sink publishing each write to a NATS subject
+type sink struct {
+	conn    *nats.Conn
+	subject string
+}
+
+func (s *sink) Write(p []byte) (int, error) {
+	if err := s.conn.Publish(s.subject, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close flushes pending messages and closes the connection
+func (s *sink) Close() error {
+	err := s.conn.FlushTimeout(DefaultFlushTimeout)
+	s.conn.Close()
+	return err
+}
@@ -0,0 +1,112 @@
+package natslog
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	logger "github.com/alejoacosta74/go-logger"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePublisher records published messages
+type fakePublisher struct {
+	mu       sync.Mutex
+	subjects []string
+	messages [][]byte
+	err      error
+	closed   bool
+}
+
+func (p *fakePublisher) Publish(subject string, data []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return p.err
+	}
+	p.subjects = append(p.subjects, subject)
+	p.messages = append(p.messages, data)
+	return nil
+}
+
+func (p *fakePublisher) Close(timeout time.Duration) error {
+	p.closed = true
+	return nil
+}
+
+func TestHook_SubjectTemplate(t *testing.T) {
+	pub := &fakePublisher{}
+	hook := newHook(pub, &Options{Subject: "logs.{service}.{level}", Formatter: &logger.PlainFormatter{}})
+
+	log, err := logger.NewLogger(logger.WithNullOutput())
+	require.NoError(t, err)
+	log.Logger.AddHook(hook)
+
+	log.WithField("service", "billing").Info("invoice sent")
+	log.WithField("service", "api.v2 *").Error("failed")
+	log.Warn("no service")
+
+	assert.Equal(t, []string{"logs.billing.info", "logs.api_v2__.error", "logs.unknown.warning"}, pub.subjects)
+	assert.Equal(t, "INFO invoice sent service=billing\n", string(pub.messages[0]))
+
+	require.NoError(t, hook.Close())
+	assert.True(t, pub.closed)
+}
+
+func TestHook_Errors(t *testing.T) {
+	pub := &fakePublisher{err: errors.New("nats: connection closed")}
+	var failed [][]byte
+	hook := newHook(pub, &Options{
+		Subject: "logs",
+		OnError: func(err error, line []byte) { failed = append(failed, line) },
+		Levels:  []logrus.Level{logrus.ErrorLevel},
+	})
+
+	assert.Equal(t, []logrus.Level{logrus.ErrorLevel}, hook.Levels())
+	err := hook.Fire(logrus.NewEntry(logrus.New()).WithField("k", "v"))
+	assert.Error(t, err)
+	assert.Len(t, failed, 1)
+}
+
+func TestHook_Retry(t *testing.T) {
+	pub := &fakePublisher{err: errors.New("nats: outbound buffer limit exceeded")}
+	var (
+		failed []logger.FailedDelivery
+		lines  [][]byte
+	)
+	hook := newHook(pub, &Options{
+		Subject: "logs",
+		Retry: &logger.RetryPolicy{
+			MaxAttempts: 3,
+			MinBackoff:  time.Millisecond,
+			OnFailure:   func(f logger.FailedDelivery) { failed = append(failed, f) },
+		},
+		OnError: func(err error, line []byte) { lines = append(lines, line) },
+	})
+
+	assert.Error(t, hook.Fire(logrus.NewEntry(logrus.New())))
+	require.Len(t, failed, 1)
+	assert.Equal(t, "nats", failed[0].Sink)
+	assert.Equal(t, 3, failed[0].Attempts)
+	assert.Len(t, lines, 1)
+
+	// transient errors are retried
+	pub.err = nil
+	assert.NoError(t, hook.Fire(logrus.NewEntry(logrus.New())))
+	assert.Len(t, pub.messages, 1)
+}
+
+func TestNewHook_Validation(t *testing.T) {
+	_, err := NewHook(nil)
+	assert.Error(t, err)
+	_, err = NewHook(&Options{})
+	assert.Error(t, err)
+}
+
+func TestNewSink(t *testing.T) {
+	_, err := logger.NewSink("nats://server:4222")
+	assert.ErrorContains(t, err, "subject are required")
+}
@@ -0,0 +1,208 @@
+package logger
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultNetworkBufferSize   = 1000
+	DefaultNetworkDialTimeout  = 5 * time.Second
+	DefaultNetworkWriteTimeout = 5 * time.Second
+	DefaultNetworkMinBackoff   = 100 * time.Millisecond
+	DefaultNetworkMaxBackoff   = 30 * time.Second
+)
+
+// NetworkConfig holds configuration for the network writer
+type NetworkConfig struct {
+	// BufferSize is the number of entries buffered while disconnected; entries
+	// written while the buffer is full are dropped and counted
+	BufferSize   int
+	DialTimeout  time.Duration
+	WriteTimeout time.Duration
+	MinBackoff   time.Duration // initial delay between reconnection attempts
+	MaxBackoff   time.Duration // maximum delay between reconnection attempts
+	TLS          *TLSConfig    // connect over TLS, tcp networks only
+	Framing      Framing       // newline delimited records by default
+	// Retry bounds the attempts to send an entry, which is otherwise retried
+	// until the writer is closed, with MinBackoff and MaxBackoff as delays.
+	// Entries given up on are passed to its OnFailure and counted by Dropped.
+	Retry *RetryPolicy
+}
+
+// networkWriter is an io.Writer sending each write to a TCP, UDP or unix socket
+// endpoint. Writes never block the logger: they are queued and sent by a
+// background worker that reconnects with exponential backoff.
+type networkWriter struct {
+	network string
+	address string
+	cfg     NetworkConfig
+	tls     *tls.Config
+	conn    net.Conn
+	// connected is set once the first connection is established
+	connected bool
+	// redialed is set once the connection was dialed again after Close
+	redialed bool
+	retry    RetryPolicy
+	batcher  *Batcher[[]byte]
+
+	reconnects atomic.Uint64
+}
+
+// NewNetworkWriter creates a writer sending log lines to address. The connection
+// is established in the background, so the endpoint doesn't need to be
+// reachable yet.
+func NewNetworkWriter(network, address string, cfg *NetworkConfig) (*networkWriter, error) {
+	switch network {
+	case "tcp", "tcp4", "tcp6", "udp", "udp4", "udp6", "unix", "unixgram":
+	default:
+		return nil, fmt.Errorf("network writer: unsupported network %q", network)
+	}
+	if address == "" {
+		return nil, fmt.Errorf("network writer: address is required")
+	}
+	c := NetworkConfig{}
+	if cfg != nil {
+		c = *cfg
+	}
+	if c.BufferSize <= 0 {
+		c.BufferSize = DefaultNetworkBufferSize
+	}
+	if c.DialTimeout <= 0 {
+		c.DialTimeout = DefaultNetworkDialTimeout
+	}
+	if c.WriteTimeout <= 0 {
+		c.WriteTimeout = DefaultNetworkWriteTimeout
+	}
+	if c.MinBackoff <= 0 {
+		c.MinBackoff = DefaultNetworkMinBackoff
+	}
+	if c.MaxBackoff < c.MinBackoff {
+		c.MaxBackoff = DefaultNetworkMaxBackoff
+	}
+	tlsConfig, err := c.TLS.build()
+	if err != nil {
+		return nil, fmt.Errorf("network writer: %w", err)
+	}
+	if tlsConfig != nil && network != "tcp" && network != "tcp4" && network != "tcp6" {
+		return nil, fmt.Errorf("network writer: TLS requires a tcp network, got %q", network)
+	}
+
+	w := &networkWriter{
+		network: network,
+		address: address,
+		cfg:     c,
+		tls:     tlsConfig,
+		retry:   retryPolicy(c.Retry, RetryPolicy{MaxAttempts: math.MaxInt, MinBackoff: c.MinBackoff, MaxBackoff: c.MaxBackoff}),
+	}
+	w.batcher = NewBatcher(1, time.Second, c.BufferSize, false, w.send)
+	return w, nil
+}
+
+// WithNetworkOutput sets the output of the logger to a network endpoint, e.g.
+// WithNetworkOutput("tcp", "collector:6514"), buffering entries while
+// disconnected and reconnecting automatically
+func WithNetworkOutput(network, address string) Option {
+	return func(l *Logger) error {
+		w, err := NewNetworkWriter(network, address, nil)
+		if err != nil {
+			return err
+		}
+		l.Entry.Logger.SetOutput(w)
+		return nil
+	}
+}
+
+// Write queues a copy of p, framed according to the configured framing. It
+// never fails; entries that can't be buffered are counted by Dropped.
+func (w *networkWriter) Write(p []byte) (int, error) {
+	var line []byte
+	if w.cfg.Framing == FramingLengthPrefixed {
+		line = appendFrame(make([]byte, 0, len(p)+4), p)
+	} else {
+		line = make([]byte, len(p))
+		copy(line, p)
+	}
+	w.batcher.Add(line)
+	return len(p), nil
+}
+
+// Dropped returns the number of entries dropped because the buffer was full, the
+// writer was closed while disconnected or the retry policy gave up on them
+func (w *networkWriter) Dropped() uint64 {
+	return w.batcher.Dropped()
+}
+
+// Reconnects returns the number of connections established after the first one
+func (w *networkWriter) Reconnects() uint64 {
+	return w.reconnects.Load()
+}
+
+// Close sends the buffered entries and closes the connection. When disconnected
+// it dials once more, dropping the remaining entries if that fails.
+func (w *networkWriter) Close() error {
+	w.batcher.Close()
+	if w.conn != nil {
+		return w.conn.Close()
+	}
+	return nil
+}
+
+// send writes lines, retrying with backoff until they are sent, the retry policy
+// gives up or the writer is closed
+func (w *networkWriter) send(lines [][]byte) {
+	for _, line := range lines {
+		if err := w.retry.Deliver("network", 1, line, w.batcher.Closing(), w.write); err != nil {
+			w.batcher.dropped.Add(1)
+		}
+	}
+}
+
+// errNetworkClosed is reported for the entries dropped because the writer was
+// closed while disconnected
+var errNetworkClosed = errors.New("network writer: closed while disconnected")
+
+// write sends a single line, dialing first if needed, and reports whether a
+// failure is worth retrying
+func (w *networkWriter) write(line []byte) (bool, error) {
+	if w.conn == nil {
+		if w.closed() {
+			// dial once when closing rather than once per buffered line
+			if w.redialed {
+				return false, errNetworkClosed
+			}
+			w.redialed = true
+		}
+		conn, err := dialTimeout(w.network, w.address, w.cfg.DialTimeout, w.tls)
+		if err != nil {
+			return !w.closed(), err
+		}
+		if w.connected {
+			w.reconnects.Add(1)
+		}
+		w.conn = conn
+		w.connected = true
+	}
+	w.conn.SetWriteDeadline(time.Now().Add(w.cfg.WriteTimeout))
+	if _, err := w.conn.Write(line); err != nil {
+		w.conn.Close()
+		w.conn = nil
+		return !w.closed(), err
+	}
+	return false, nil
+}
+
+// closed reports whether Close has been called
+func (w *networkWriter) closed() bool {
+	select {
+	case <-w.batcher.Closing():
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,161 @@
+package logger
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetworkWriter_TCPReconnect(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	w, err := NewNetworkWriter("tcp", ln.Addr().String(), &NetworkConfig{
+		MinBackoff: 10 * time.Millisecond,
+		MaxBackoff: 50 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	defer w.Close()
+
+	logger, err := NewLogger(WithOutput(w), WithFormatter(&PlainFormatter{}))
+	require.NoError(t, err)
+	logger.Info("first")
+
+	conn, err := ln.Accept()
+	require.NoError(t, err)
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "INFO first\n", line)
+	conn.Close()
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		c, err := ln.Accept()
+		if err == nil {
+			accepted <- c
+		}
+	}()
+
+	// the first writes after the peer closed may be lost in the kernel buffer,
+	// keep logging until the writer notices and reconnects
+	var second net.Conn
+	for second == nil {
+		logger.Info("after reconnect")
+		select {
+		case second = <-accepted:
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+	defer second.Close()
+
+	second.SetReadDeadline(time.Now().Add(2 * time.Second))
+	line, err = bufio.NewReader(second).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "INFO after reconnect\n", line)
+	assert.Equal(t, uint64(1), w.Reconnects())
+}
+
+func TestNetworkWriter_UDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer pc.Close()
+
+	logger, err := NewLogger(WithNetworkOutput("udp", pc.LocalAddr().String()), WithFormatter(&PlainFormatter{}))
+	require.NoError(t, err)
+	logger.WithField("port", 8080).Warn("datagram")
+
+	buf := make([]byte, 1024)
+	pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "WARNING datagram port=8080\n", string(buf[:n]))
+}
+
+func TestNetworkWriter_DropsWhenBufferFull(t *testing.T) {
+	// reserve a port with nothing listening on it
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	ln.Close()
+
+	w, err := NewNetworkWriter("tcp", addr, &NetworkConfig{BufferSize: 2, MinBackoff: time.Hour})
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		n, err := w.Write([]byte(strings.Repeat("x", i)))
+		require.NoError(t, err)
+		assert.Equal(t, i, n)
+	}
+	// at most one entry in flight plus two buffered
+	assert.GreaterOrEqual(t, w.Dropped(), uint64(7))
+
+	require.NoError(t, w.Close())
+	assert.Equal(t, uint64(10), w.Dropped())
+}
+
+func TestNetworkWriter_RetryPolicy(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	ln.Close()
+
+	failed := make(chan FailedDelivery, 1)
+	w, err := NewNetworkWriter("tcp", addr, &NetworkConfig{
+		Retry: &RetryPolicy{
+			MaxAttempts: 3,
+			MinBackoff:  time.Millisecond,
+			OnFailure:   func(f FailedDelivery) { failed <- f },
+		},
+	})
+	require.NoError(t, err)
+	defer w.Close()
+
+	w.Write([]byte("lost\n"))
+	select {
+	case f := <-failed:
+		assert.Equal(t, "network", f.Sink)
+		assert.Equal(t, 3, f.Attempts)
+		assert.Equal(t, "lost\n", string(f.Payload))
+	case <-time.After(2 * time.Second):
+		t.Fatal("the failed entry was not reported")
+	}
+	assert.Eventually(t, func() bool { return w.Dropped() == 1 }, time.Second, time.Millisecond)
+}
+
+func TestNetworkWriter_Close(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	ln.Close()
+
+	var failed []FailedDelivery
+	w, err := NewNetworkWriter("tcp", addr, &NetworkConfig{
+		MinBackoff: time.Hour,
+		Retry:      &RetryPolicy{MaxAttempts: 100, OnFailure: func(f FailedDelivery) { failed = append(failed, f) }},
+	})
+	require.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		w.Write([]byte("pending\n"))
+	}
+	require.NoError(t, w.Close())
+	assert.Equal(t, uint64(5), w.Dropped())
+	require.Len(t, failed, 5)
+	// a single dial once closed, the other entries are given up right away
+	assert.ErrorIs(t, failed[4].Err, errNetworkClosed)
+	assert.Equal(t, 1, failed[4].Attempts)
+
+	// entries written after Close are counted too
+	w.Write([]byte("late\n"))
+	assert.Equal(t, uint64(6), w.Dropped())
+}
+
+func TestNewNetworkWriter_InvalidNetwork(t *testing.T) {
+	_, err := NewNetworkWriter("http", "localhost:80", nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,29 @@
+package logger
+
+// nopLogger implements Interface discarding everything
+type nopLogger struct{}
+
+// Nop returns a logger discarding every call without allocating, for tests
+// and callers that require an Interface but don't care about its output.
+// Fatal and Panic don't exit or panic.
+func Nop() Interface {
+	return nopLogger{}
+}
+
+func (nopLogger) Trace(args ...interface{})                 {}
+func (nopLogger) Tracef(format string, args ...interface{}) {}
+func (nopLogger) Debug(args ...interface{})                 {}
+func (nopLogger) Debugf(format string, args ...interface{}) {}
+func (nopLogger) Info(args ...interface{})                  {}
+func (nopLogger) Infof(format string, args ...interface{})  {}
+func (nopLogger) Warn(args ...interface{})                  {}
+func (nopLogger) Warnf(format string, args ...interface{})  {}
+func (nopLogger) Error(args ...interface{})                 {}
+func (nopLogger) Errorf(format string, args ...interface{}) {}
+func (nopLogger) Fatal(args ...interface{})                 {}
+func (nopLogger) Fatalf(format string, args ...interface{}) {}
+func (nopLogger) Panic(args ...interface{})                 {}
+func (nopLogger) Panicf(format string, args ...interface{}) {}
+
+func (n nopLogger) WithFields(fields Fields) Interface { return n }
+func (n nopLogger) WithError(err error) Interface      { return n }
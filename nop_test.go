@@ -0,0 +1,21 @@
+package logger
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNop_ZeroAllocs(t *testing.T) {
+	l := Nop()
+	err := errors.New("boom")
+	fields := Fields{"user": "alice"}
+	allocs := testing.AllocsPerRun(100, func() {
+		l.WithFields(fields).WithError(err).Error("failed")
+		l.Infof("user %s", "alice")
+		l.Fatal("does not exit")
+		l.Panic("does not panic")
+	})
+	assert.Zero(t, allocs)
+}
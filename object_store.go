@@ -0,0 +1,298 @@
+package logger
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultObjectStoreRegion  = "us-east-1"
+	DefaultObjectStoreTimeout = 5 * time.Minute
+	DefaultUploadKeyTemplate  = "{hostname}/{name}"
+	DefaultUploadMaxRetries   = 3
+
+	// GCSEndpoint is the S3 compatible endpoint of Google Cloud Storage, used
+	// with HMAC keys
+	GCSEndpoint = "https://storage.googleapis.com"
+)
+
+// Uploader stores rotated files in an object store
+type Uploader interface {
+	// Upload stores the size bytes of body under key. body is rewound before
+	// retries.
+	Upload(ctx context.Context, key string, body io.ReadSeeker, size int64) error
+}
+
+// ObjectStoreConfig holds configuration for uploading to an S3 compatible
+// object store: AWS S3, Google Cloud Storage or MinIO
+type ObjectStoreConfig struct {
+	// Endpoint defaults to the AWS S3 endpoint of Region, objects are addressed
+	// as Endpoint/Bucket/key
+	Endpoint        string
+	Region          string // defaults to DefaultObjectStoreRegion, "auto" for GCS
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string // temporary credentials, optional
+	TLS             *TLSConfig
+	Timeout         time.Duration // per upload, defaults to DefaultObjectStoreTimeout
+}
+
+// objectStore uploads objects with requests signed with AWS Signature Version 4
+type objectStore struct {
+	cfg    ObjectStoreConfig
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewS3Uploader creates an uploader storing objects in an S3 bucket, or any S3
+// compatible store when Endpoint is set
+func NewS3Uploader(cfg *ObjectStoreConfig) (Uploader, error) {
+	if cfg == nil || cfg.Bucket == "" {
+		return nil, fmt.Errorf("object store: bucket is required")
+	}
+	c := *cfg
+	if c.AccessKeyID == "" || c.SecretAccessKey == "" {
+		return nil, fmt.Errorf("object store: credentials are required")
+	}
+	if c.Region == "" {
+		c.Region = DefaultObjectStoreRegion
+	}
+	if c.Endpoint == "" {
+		c.Endpoint = "https://s3." + c.Region + ".amazonaws.com"
+	}
+	c.Endpoint = strings.TrimSuffix(c.Endpoint, "/")
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultObjectStoreTimeout
+	}
+	tlsConfig, err := c.TLS.build()
+	if err != nil {
+		return nil, fmt.Errorf("object store: %w", err)
+	}
+	return &objectStore{cfg: c, client: newHTTPClient(c.Timeout, tlsConfig), now: time.Now}, nil
+}
+
+// NewGCSUploader creates an uploader storing objects in a Google Cloud Storage
+// bucket through its S3 compatible API, authenticating with an HMAC key
+func NewGCSUploader(cfg *ObjectStoreConfig) (Uploader, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("object store: bucket is required")
+	}
+	c := *cfg
+	if c.Endpoint == "" {
+		c.Endpoint = GCSEndpoint
+	}
+	if c.Region == "" {
+		c.Region = "auto"
+	}
+	return NewS3Uploader(&c)
+}
+
+func (s *objectStore) Upload(ctx context.Context, key string, body io.ReadSeeker, size int64) error {
+	path := "/" + awsURIEscape(s.cfg.Bucket) + "/" + awsURIEscape(key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.cfg.Endpoint+path, io.NopCloser(body))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.URL.RawPath = path
+	req.Header.Set("Content-Type", "application/octet-stream")
+	s.sign(req, path, unsignedPayload)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 == 2 {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return &uploadError{status: resp.StatusCode, msg: strings.TrimSpace(string(msg))}
+}
+
+// uploadError is a rejected upload
+type uploadError struct {
+	status int
+	msg    string
+}
+
+func (e *uploadError) Error() string {
+	return fmt.Sprintf("object store: upload failed with status %d: %s", e.status, e.msg)
+}
+
+// retryable reports whether the upload may succeed later
+func (e *uploadError) retryable() bool {
+	return e.status == http.StatusTooManyRequests || e.status >= 500
+}
+
+// unsignedPayload replaces the payload hash in signatures, so files are
+// streamed rather than read twice
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+// sign adds the AWS Signature Version 4 headers to req, path being its
+// escaped path
+func (s *objectStore) sign(req *http.Request, path, payloadHash string) {
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if s.cfg.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.cfg.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(req.Header.Get(name))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method, path, req.URL.RawQuery, canonicalHeaders.String(), signedHeaders, payloadHash,
+	}, "\n")
+	scope := date + "/" + s.cfg.Region + "/s3/aws4_request"
+	hashed := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hashed[:])
+
+	key := []byte("AWS4" + s.cfg.SecretAccessKey)
+	for _, part := range []string{date, s.cfg.Region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.cfg.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// awsURIEscape escapes s as SigV4 canonical URIs require: every byte but the
+// unreserved characters and slashes
+func awsURIEscape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || strings.IndexByte("-._~/", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// UploadConfig holds configuration for uploading rotated files
+type UploadConfig struct {
+	Uploader Uploader
+	// KeyTemplate names the objects, defaults to DefaultUploadKeyTemplate.
+	// {name} is replaced with the name of the rotated file, {hostname} with
+	// the host name and {date} with the rotation date as 2006/01/02.
+	KeyTemplate string
+	// DeleteAfterUpload removes the local copy once uploaded
+	DeleteAfterUpload bool
+	// MaxRetries is the number of retries for uploads failing with a network
+	// error, 429 or 5xx status
+	MaxRetries int
+	Retry      *RetryPolicy // overrides MaxRetries
+}
+
+// uploader uploads the archives of a rotating file
+type uploader struct {
+	cfg      UploadConfig
+	retry    RetryPolicy
+	hostname string
+	uploaded map[string]bool // names uploaded and kept locally
+}
+
+func newUploader(cfg *UploadConfig) (*uploader, error) {
+	if cfg.Uploader == nil {
+		return nil, fmt.Errorf("upload: uploader is required")
+	}
+	c := *cfg
+	if c.KeyTemplate == "" {
+		c.KeyTemplate = DefaultUploadKeyTemplate
+	}
+	if c.MaxRetries < 0 {
+		c.MaxRetries = 0
+	} else if c.MaxRetries == 0 {
+		c.MaxRetries = DefaultUploadMaxRetries
+	}
+	hostname, _ := os.Hostname()
+	return &uploader{
+		cfg:      c,
+		retry:    retryPolicy(c.Retry, RetryPolicy{MaxAttempts: c.MaxRetries + 1}),
+		hostname: hostname,
+		uploaded: make(map[string]bool),
+	}, nil
+}
+
+// key returns the object key of b
+func (u *uploader) key(b backup) string {
+	return strings.NewReplacer(
+		"{name}", filepath.Base(b.path),
+		"{hostname}", u.hostname,
+		"{date}", b.time.Format("2006/01/02"),
+	).Replace(u.cfg.KeyTemplate)
+}
+
+// upload uploads b unless it was already, reporting whether the local copy
+// was deleted. stop cuts the backoff short when the archiver is closing.
+func (u *uploader) upload(b backup, stop <-chan struct{}) (deleted bool, err error) {
+	if u.uploaded[b.path] {
+		return false, nil
+	}
+	f, err := os.Open(b.path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return false, err
+	}
+
+	key := u.key(b)
+	err = u.retry.Deliver("upload", 0, nil, stop, func([]byte) (bool, error) {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return false, err
+		}
+		err := u.cfg.Uploader.Upload(context.Background(), key, f, info.Size())
+		if ue, ok := err.(*uploadError); ok {
+			return ue.retryable(), err
+		}
+		return err != nil, err
+	})
+	if err != nil {
+		return false, fmt.Errorf("upload %s: %w", b.path, err)
+	}
+	if u.cfg.DeleteAfterUpload {
+		f.Close()
+		return true, os.Remove(b.path)
+	}
+	u.uploaded[b.path] = true
+	return false, nil
+}
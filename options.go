@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"reflect"
 	"runtime"
+	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -15,7 +17,17 @@ type Option func(*Logger) error
 // WithFormatter sets a custom formatter for the logger
 func WithFormatter(formatter logrus.Formatter) Option {
 	return func(l *Logger) error {
-		l.Entry.Logger.SetFormatter(formatter)
+		setFormatter(l.Entry.Logger, formatter)
+		return nil
+	}
+}
+
+// WithHooks registers custom hooks on the logger
+func WithHooks(hooks ...logrus.Hook) Option {
+	return func(l *Logger) error {
+		for _, hook := range hooks {
+			l.Entry.Logger.AddHook(hook)
+		}
 		return nil
 	}
 }
@@ -27,7 +39,7 @@ func WithLevel(level string) Option {
 		if err != nil {
 			return err
 		}
-		l.Entry.Logger.SetLevel(parsedLevel)
+		setLoggerLevel(l.Entry.Logger, parsedLevel)
 
 		// Add hook for debug OR trace level
 		if parsedLevel == logrus.DebugLevel || parsedLevel == logrus.TraceLevel {
@@ -59,7 +71,7 @@ func WithRuntimeContext() Option {
 
 		// Preserve existing fields when setting the formatter
 		fields := l.Entry.Data
-		l.Entry.Logger.SetFormatter(formatter)
+		setFormatter(l.Entry.Logger, formatter)
 		l.Entry.Logger.SetReportCaller(true)
 		if len(fields) > 0 {
 			l.Entry = l.Entry.WithFields(fields)
@@ -84,12 +96,19 @@ func WithNullOutput() Option {
 	}
 }
 
-// WithFileOutput sets the output destination to a file
+// WithFileOutput sets the output destination to a file, created when needed
+// and appended to
 func WithFileOutput(file string) Option {
+	return WithFileOutputMode(file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+}
+
+// WithFileOutputMode sets the output destination to a file opened with flag
+// and perm, e.g. os.O_CREATE|os.O_WRONLY|os.O_TRUNC to start from an empty file
+func WithFileOutputMode(file string, flag int, perm os.FileMode) Option {
 	return func(l *Logger) error {
-		f, err := os.Create(file)
+		f, err := openReopenableFile(file, flag, perm)
 		if err != nil {
-			panic(err)
+			return fmt.Errorf("file output: %w", err)
 		}
 		l.Entry.Logger.SetOutput(f)
 		return nil
@@ -111,3 +130,72 @@ func WithMultipleFields(fields ...string) Option {
 		return nil
 	}
 }
+
+// WithFieldsFromStruct adds the exported fields of a struct (or pointer to struct)
+// as default fields on the logger. Field names can be customized with a `log`
+// struct tag: `log:"name"` renames the field, `log:"-"` skips it and
+// `log:",omitempty"` skips zero values. Embedded structs are flattened.
+func WithFieldsFromStruct(v interface{}) Option {
+	return func(l *Logger) error {
+		f, err := structFields(v)
+		if err != nil {
+			return err
+		}
+		l.Entry = l.Entry.WithFields(f)
+		return nil
+	}
+}
+
+// structFields reflects the exported fields of v into logrus.Fields
+func structFields(v interface{}) (logrus.Fields, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, fmt.Errorf("WithFieldsFromStruct: nil %T", v)
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("WithFieldsFromStruct: expected a struct, got %T", v)
+	}
+
+	f := make(logrus.Fields)
+	addStructFields(f, rv)
+	return f, nil
+}
+
+func addStructFields(f logrus.Fields, rv reflect.Value) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		value := rv.Field(i)
+
+		name, opts, _ := strings.Cut(field.Tag.Get("log"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			for value.Kind() == reflect.Pointer {
+				if value.IsNil() {
+					break
+				}
+				value = value.Elem()
+			}
+			if value.Kind() == reflect.Struct {
+				addStructFields(f, value)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if opts == "omitempty" && value.IsZero() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		f[name] = value.Interface()
+	}
+}
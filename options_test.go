@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithFieldsFromStruct(t *testing.T) {
+	type Deployment struct {
+		Region string `log:"region"`
+		Zone   string `log:"zone,omitempty"`
+	}
+	type ServiceInfo struct {
+		Deployment
+		Name     string        `log:"service"`
+		Version  string        `log:"version"`
+		Replicas int           `log:"replicas"`
+		Timeout  time.Duration `log:"timeout,omitempty"`
+		Password string        `log:"-"`
+		Owner    string
+		internal string
+	}
+
+	info := &ServiceInfo{
+		Deployment: Deployment{Region: "eu-west-1"},
+		Name:       "auth",
+		Version:    "1.2.3",
+		Replicas:   3,
+		Password:   "secret",
+		Owner:      "platform",
+		internal:   "hidden",
+	}
+
+	logger, err := NewLogger(WithNullOutput(), WithFieldsFromStruct(info))
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]interface{}{
+		"region":   "eu-west-1",
+		"service":  "auth",
+		"version":  "1.2.3",
+		"replicas": 3,
+		"Owner":    "platform",
+	}, map[string]interface{}(logger.Entry.Data))
+}
+
+func TestWithFieldsFromStruct_Invalid(t *testing.T) {
+	var nilPtr *struct{ Name string }
+
+	_, err := NewLogger(WithFieldsFromStruct("not a struct"))
+	assert.Error(t, err)
+
+	_, err = NewLogger(WithFieldsFromStruct(nilPtr))
+	assert.Error(t, err)
+}
+
+func TestWithFileOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	require.NoError(t, os.WriteFile(path, []byte("INFO previous run\n"), 0o600))
+
+	logger, err := NewLogger(WithFileOutput(path), WithFormatter(&PlainFormatter{}))
+	require.NoError(t, err)
+	logger.Info("appended")
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "INFO previous run\nINFO appended\n", string(content))
+
+	logger, err = NewLogger(WithFileOutputMode(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600), WithFormatter(&PlainFormatter{}))
+	require.NoError(t, err)
+	logger.Info("truncated")
+	content, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "INFO truncated\n", string(content))
+
+	created := filepath.Join(t.TempDir(), "new.log")
+	_, err = NewLogger(WithFileOutputMode(created, os.O_CREATE|os.O_WRONLY, 0o600))
+	require.NoError(t, err)
+	info, err := os.Stat(created)
+	require.NoError(t, err)
+	if runtime.GOOS != "windows" {
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	}
+
+	_, err = NewLogger(WithFileOutput(filepath.Join(t.TempDir(), "missing", "app.log")))
+	assert.Error(t, err)
+}
@@ -0,0 +1,234 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Field keys of the trace context, named after the OpenTelemetry conventions
+// for non-OTLP log formats
+const (
+	TraceIDKey    = "trace_id"
+	SpanIDKey     = "span_id"
+	TraceFlagsKey = "trace_flags"
+)
+
+// WithOTelResource adds attributes of an OpenTelemetry resource (e.g.
+// service.namespace, deployment.environment) as default fields, keeping logs
+// dimensionally consistent with traces and metrics. When no keys are given every
+// attribute of the resource is added.
+func WithOTelResource(res *resource.Resource, keys ...string) Option {
+	return func(l *Logger) error {
+		if res == nil {
+			return nil
+		}
+		f := make(logrus.Fields)
+		if len(keys) == 0 {
+			for _, kv := range res.Attributes() {
+				f[string(kv.Key)] = kv.Value.AsInterface()
+			}
+		} else {
+			set := res.Set()
+			for _, key := range keys {
+				if v, ok := set.Value(attribute.Key(key)); ok {
+					f[key] = v.AsInterface()
+				}
+			}
+		}
+		l.Entry = l.Entry.WithFields(f)
+		return nil
+	}
+}
+
+// WithOTelBaggage copies the selected OpenTelemetry baggage members from the
+// entry context into fields. Entries carry a context when logged through
+// Entry.WithContext.
+func WithOTelBaggage(keys ...string) Option {
+	return func(l *Logger) error {
+		if len(keys) == 0 {
+			return nil
+		}
+		prependHook(l.Entry.Logger, &otelBaggageHook{keys: keys})
+		return nil
+	}
+}
+
+// otelBaggageHook implements logrus.Hook adding baggage members as fields
+type otelBaggageHook struct {
+	keys []string
+}
+
+func (h *otelBaggageHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Enriches implements EnrichingHook, it adds the baggage fields
+func (h *otelBaggageHook) Enriches() bool {
+	return true
+}
+
+func (h *otelBaggageHook) Fire(entry *logrus.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+	bag := baggage.FromContext(entry.Context)
+	if bag.Len() == 0 {
+		return nil
+	}
+	for _, key := range h.keys {
+		if member := bag.Member(key); member.Key() != "" {
+			if _, exists := entry.Data[key]; !exists {
+				entry.Data[key] = member.Value()
+			}
+		}
+	}
+	return nil
+}
+
+// WithOTelTraceContext adds the trace_id, span_id and trace_flags of the span
+// carried by the entry context, hex encoded as in W3C trace context, so logs
+// correlate with traces in Tempo, Jaeger and the like. Entries logged without a
+// valid span are left alone.
+func WithOTelTraceContext() Option {
+	return WithContextExtractor(OTelTraceFields)
+}
+
+// OTelTraceFields is a ContextExtractor returning the trace context fields of
+// the span carried by ctx, see WithOTelTraceContext
+func OTelTraceFields(ctx context.Context) Fields {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+	return Fields{
+		TraceIDKey:    sc.TraceID().String(),
+		SpanIDKey:     sc.SpanID().String(),
+		TraceFlagsKey: sc.TraceFlags().String(),
+	}
+}
+
+// OTelSpanEventsConfig holds configuration for the span events hook
+type OTelSpanEventsConfig struct {
+	Levels []logrus.Level // defaults to error, fatal and panic
+	// SetStatus sets the span status to error with the entry message
+	SetStatus bool
+}
+
+// WithOTelSpanEvents records the entries logged with a context carrying a
+// recording span as events of that span, with the entry fields as attributes,
+// so traces show failures inline. An error field is recorded as an exception.
+func WithOTelSpanEvents(cfg *OTelSpanEventsConfig) Option {
+	return func(l *Logger) error {
+		c := OTelSpanEventsConfig{}
+		if cfg != nil {
+			c = *cfg
+		}
+		if len(c.Levels) == 0 {
+			c.Levels = []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel}
+		}
+		l.Entry.Logger.AddHook(&otelSpanEventHook{cfg: c})
+		return nil
+	}
+}
+
+// otelSpanEventHook implements logrus.Hook adding entries as span events
+type otelSpanEventHook struct {
+	cfg OTelSpanEventsConfig
+}
+
+func (h *otelSpanEventHook) Levels() []logrus.Level {
+	return h.cfg.Levels
+}
+
+func (h *otelSpanEventHook) Fire(entry *logrus.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+	span := trace.SpanFromContext(entry.Context)
+	if !span.IsRecording() {
+		return nil
+	}
+
+	attrs := make([]attribute.KeyValue, 0, len(entry.Data)+2)
+	attrs = append(attrs,
+		attribute.String("log.severity", entry.Level.String()),
+		attribute.String("log.message", entry.Message),
+	)
+	var recorded error
+	for key, value := range entry.Data {
+		if err, ok := value.(error); ok && key == logrus.ErrorKey {
+			recorded = err
+			continue
+		}
+		attrs = append(attrs, otelAttribute(key, value))
+	}
+
+	if recorded != nil {
+		span.RecordError(recorded, trace.WithTimestamp(entry.Time), trace.WithAttributes(attrs...))
+	} else {
+		span.AddEvent("log", trace.WithTimestamp(entry.Time), trace.WithAttributes(attrs...))
+	}
+	if h.cfg.SetStatus {
+		span.SetStatus(codes.Error, entry.Message)
+	}
+	return nil
+}
+
+// otelAttribute converts a field to an attribute, formatting the values of
+// types attributes can't hold
+func otelAttribute(key string, value interface{}) attribute.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return attribute.String(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int64:
+		return attribute.Int64(key, v)
+	case float64:
+		return attribute.Float64(key, v)
+	case []string:
+		return attribute.StringSlice(key, v)
+	case fmt.Stringer:
+		return attribute.String(key, v.String())
+	case error:
+		return attribute.String(key, v.Error())
+	default:
+		return attribute.String(key, fmt.Sprint(v))
+	}
+}
+
+// WithBaggageFields adds the allowlisted keys found in the metadata set with
+// ContextWithMetadata or in the OpenTelemetry baggage of the entry context as
+// fields, metadata taking precedence, so every entry of a request carries e.g.
+// its tenant and feature flags. See WithContextExtractor for the precedence of
+// fields set on the entry.
+func WithBaggageFields(keys ...string) Option {
+	if len(keys) == 0 {
+		return func(*Logger) error { return nil }
+	}
+	return WithContextExtractor(func(ctx context.Context) Fields {
+		md := MetadataFromContext(ctx)
+		bag := baggage.FromContext(ctx)
+		if len(md) == 0 && bag.Len() == 0 {
+			return nil
+		}
+		f := make(Fields)
+		for _, key := range keys {
+			if v, ok := md[key]; ok {
+				f[key] = v
+			} else if member := bag.Member(key); member.Key() != "" {
+				f[key] = member.Value()
+			}
+		}
+		return f
+	})
+}
@@ -0,0 +1,142 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestWithOTelResource(t *testing.T) {
+	res := resource.NewSchemaless(
+		attribute.String("service.name", "checkout"),
+		attribute.String("service.namespace", "shop"),
+		attribute.String("deployment.environment", "prod"),
+	)
+
+	logger, err := NewLogger(WithNullOutput(), WithOTelResource(res, "service.namespace", "deployment.environment", "missing"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"service.namespace":      "shop",
+		"deployment.environment": "prod",
+	}, map[string]interface{}(logger.Entry.Data))
+
+	logger, err = NewLogger(WithNullOutput(), WithOTelResource(res))
+	require.NoError(t, err)
+	assert.Len(t, logger.Entry.Data, 3)
+}
+
+func TestWithOTelBaggage(t *testing.T) {
+	logger, err := NewLogger(WithNullOutput(), WithLastEntriesCapture(10), WithOTelBaggage("tenant", "plan"))
+	require.NoError(t, err)
+
+	tenant, err := baggage.NewMember("tenant", "acme")
+	require.NoError(t, err)
+	region, err := baggage.NewMember("region", "eu")
+	require.NoError(t, err)
+	bag, err := baggage.New(tenant, region)
+	require.NoError(t, err)
+	ctx := baggage.ContextWithBaggage(context.Background(), bag)
+
+	logger.WithContext(ctx).Info("with baggage")
+	logger.Info("without context")
+
+	crumbs := logger.Breadcrumbs(0)
+	require.Len(t, crumbs, 2)
+	assert.Equal(t, Fields{"tenant": "acme"}, crumbs[0].Data)
+	assert.Empty(t, crumbs[1].Data)
+}
+
+func TestWithOTelTraceContext(t *testing.T) {
+	logger, err := NewLogger(WithNullOutput(), WithLastEntriesCapture(10), WithOTelTraceContext())
+	require.NoError(t, err)
+
+	traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	require.NoError(t, err)
+	spanID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+	require.NoError(t, err)
+	sc := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+
+	logger.WithContext(ctx).Info("in span")
+	logger.WithContext(context.Background()).Info("no span")
+
+	crumbs := logger.Breadcrumbs(0)
+	require.Len(t, crumbs, 2)
+	assert.Equal(t, Fields{
+		TraceIDKey:    "4bf92f3577b34da6a3ce929d0e0e4736",
+		SpanIDKey:     "00f067aa0ba902b7",
+		TraceFlagsKey: "01",
+	}, crumbs[0].Data)
+	assert.Empty(t, crumbs[1].Data)
+}
+
+func TestWithOTelSpanEvents(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	logger, err := NewLogger(WithNullOutput(), WithOTelSpanEvents(&OTelSpanEventsConfig{SetStatus: true}))
+	require.NoError(t, err)
+
+	ctx, span := tracer.Start(context.Background(), "checkout")
+	logger.WithContext(ctx).Info("not recorded")
+	logger.WithContext(ctx).WithField("order", 42).Error("payment declined")
+	logger.WithContext(ctx).WithError(errors.New("timeout")).Error("charge failed")
+	logger.Error("no context")
+	span.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	events := spans[0].Events()
+	require.Len(t, events, 2)
+
+	assert.Equal(t, "log", events[0].Name)
+	assert.Contains(t, events[0].Attributes, attribute.String("log.severity", "error"))
+	assert.Contains(t, events[0].Attributes, attribute.String("log.message", "payment declined"))
+	assert.Contains(t, events[0].Attributes, attribute.Int("order", 42))
+
+	assert.Equal(t, "exception", events[1].Name)
+	assert.Contains(t, events[1].Attributes, attribute.String("exception.message", "timeout"))
+	assert.Contains(t, events[1].Attributes, attribute.String("log.message", "charge failed"))
+
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "charge failed", spans[0].Status().Description)
+}
+
+func TestWithBaggageFields(t *testing.T) {
+	logger, err := NewLogger(WithNullOutput(), WithLastEntriesCapture(10), WithBaggageFields("tenant", "feature_flag"))
+	require.NoError(t, err)
+
+	tenant, err := baggage.NewMember("tenant", "acme")
+	require.NoError(t, err)
+	flag, err := baggage.NewMember("feature_flag", "new-checkout")
+	require.NoError(t, err)
+	region, err := baggage.NewMember("region", "eu")
+	require.NoError(t, err)
+	bag, err := baggage.New(tenant, flag, region)
+	require.NoError(t, err)
+	ctx := baggage.ContextWithBaggage(context.Background(), bag)
+
+	logger.WithContext(ctx).Info("baggage")
+	ctx = ContextWithMetadata(ctx, map[string]string{"tenant": "globex"})
+	ctx = ContextWithMetadata(ctx, map[string]string{"plan": "pro"})
+	logger.WithContext(ctx).Info("metadata over baggage")
+	logger.WithContext(ContextWithMetadata(context.Background(), map[string]string{"tenant": "initech"})).Info("metadata")
+	logger.WithContext(context.Background()).Info("none")
+
+	crumbs := logger.Breadcrumbs(0)
+	require.Len(t, crumbs, 4)
+	assert.Equal(t, Fields{"tenant": "acme", "feature_flag": "new-checkout"}, crumbs[0].Data)
+	assert.Equal(t, Fields{"tenant": "globex", "feature_flag": "new-checkout"}, crumbs[1].Data)
+	assert.Equal(t, Fields{"tenant": "initech"}, crumbs[2].Data)
+	assert.Empty(t, crumbs[3].Data)
+}
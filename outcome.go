@@ -0,0 +1,82 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Outcome classifies how a request ended, for log based SLO computation
+type Outcome string
+
+const (
+	OutcomeSuccess     Outcome = "success"
+	OutcomeClientError Outcome = "client_error"
+	OutcomeServerError Outcome = "server_error"
+	OutcomeTimeout     Outcome = "timeout"
+	OutcomeCanceled    Outcome = "canceled"
+)
+
+// DefaultLatencyBuckets are the upper bounds used for the latency_bucket field
+var DefaultLatencyBuckets = []time.Duration{
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// ClassifyHTTPOutcome classifies an HTTP request from its response status and the
+// request context error
+func ClassifyHTTPOutcome(status int, ctxErr error) Outcome {
+	switch {
+	case errors.Is(ctxErr, context.Canceled), status == 499:
+		return OutcomeCanceled
+	case errors.Is(ctxErr, context.DeadlineExceeded),
+		status == http.StatusRequestTimeout, status == http.StatusGatewayTimeout:
+		return OutcomeTimeout
+	case status >= 500:
+		return OutcomeServerError
+	case status >= 400:
+		return OutcomeClientError
+	default:
+		return OutcomeSuccess
+	}
+}
+
+// Level returns the level access entries with the outcome are logged at
+func (o Outcome) Level() logrus.Level {
+	switch o {
+	case OutcomeServerError:
+		return logrus.ErrorLevel
+	case OutcomeTimeout, OutcomeCanceled:
+		return logrus.WarnLevel
+	default:
+		return logrus.InfoLevel
+	}
+}
+
+// LatencyBucket returns the smallest bucket upper bound holding d, or "+Inf",
+// the value of the latency_bucket field
+func LatencyBucket(d time.Duration, buckets []time.Duration) string {
+	for _, b := range buckets {
+		if d <= b {
+			return b.String()
+		}
+	}
+	return "+Inf"
+}
+
+// durationMillis returns d in fractional milliseconds
+func durationMillis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
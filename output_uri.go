@@ -0,0 +1,239 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Sink is a destination for formatted entries opened from a URL
+type Sink interface {
+	io.Writer
+	io.Closer
+}
+
+// SinkFactory opens the sink described by a URL. Query parameters not used by
+// the factory are ignored, so format, min_level and max_level can be used with
+// every scheme.
+type SinkFactory func(u *url.URL) (Sink, error)
+
+var (
+	sinksMu sync.RWMutex
+	// sinks maps URL schemes to their factories
+	sinks = map[string]SinkFactory{
+		"stdout": func(*url.URL) (Sink, error) { return nopCloseSink{os.Stdout}, nil },
+		"stderr": func(*url.URL) (Sink, error) { return nopCloseSink{os.Stderr}, nil },
+		"file":   newFileSink,
+		"tcp":    newNetworkSink,
+		"udp":    newNetworkSink,
+		"tls":    newTLSSink,
+	}
+)
+
+// RegisterSink registers a factory for URLs with the given scheme, making it
+// available to WithOutputURIs and NewSink. It fails if the scheme is already
+// registered.
+func RegisterSink(scheme string, factory SinkFactory) error {
+	if scheme == "" || factory == nil {
+		return fmt.Errorf("register sink: scheme and factory are required")
+	}
+	sinksMu.Lock()
+	defer sinksMu.Unlock()
+	if _, ok := sinks[scheme]; ok {
+		return fmt.Errorf("register sink: scheme %q is already registered", scheme)
+	}
+	sinks[scheme] = factory
+	return nil
+}
+
+// NewSink opens the sink described by rawURL, e.g. to pass it to WithOutput
+func NewSink(rawURL string) (Sink, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("sink: %w", err)
+	}
+	return openSink(u)
+}
+
+// openSink opens a sink with the factory registered for the scheme of u
+func openSink(u *url.URL) (Sink, error) {
+	sinksMu.RLock()
+	factory, ok := sinks[u.Scheme]
+	sinksMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("sink: unsupported scheme %q", u.Scheme)
+	}
+	sink, err := factory(u)
+	if err != nil {
+		return nil, fmt.Errorf("sink %s: %w", u.Scheme, err)
+	}
+	return sink, nil
+}
+
+// nopCloseSink is a sink whose Close does nothing, for the standard streams
+type nopCloseSink struct {
+	io.Writer
+}
+
+func (nopCloseSink) Close() error { return nil }
+
+// WithOutputURIs configures the outputs of the logger from a comma separated
+// list of URIs, replacing the default output, e.g.
+//
+//	file:///var/log/app.json?format=json&min_level=info,stderr://?format=color&min_level=debug
+//
+// The scheme selects a sink registered with RegisterSink; stdout, stderr, file,
+// tcp, udp and tls are built in, and backend packages such as kafkalog register
+// theirs when imported. The format query parameter selects json, text, color or
+// plain output (text by default), strip_ansi=true removes ANSI escape sequences,
+// e.g. colors logged in messages, and min_level and max_level bound the levels
+// written to the destination. The logger level is raised to the most verbose
+// min_level so every output receives its entries.
+func WithOutputURIs(uris string) Option {
+	return func(l *Logger) error {
+		var hooks []*outputHook
+		verbosest := logrus.PanicLevel
+		for _, raw := range strings.Split(uris, ",") {
+			raw = strings.TrimSpace(raw)
+			if raw == "" {
+				continue
+			}
+			hook, err := newOutputHook(raw)
+			if err != nil {
+				for _, h := range hooks {
+					h.Close()
+				}
+				return err
+			}
+			hooks = append(hooks, hook)
+			if min := hook.levels[len(hook.levels)-1]; min > verbosest {
+				verbosest = min
+			}
+		}
+		if len(hooks) == 0 {
+			return fmt.Errorf("output uri: no outputs in %q", uris)
+		}
+
+		l.Entry.Logger.SetOutput(io.Discard)
+		l.Entry.Logger.SetLevel(verbosest)
+		for _, hook := range hooks {
+			l.Entry.Logger.AddHook(hook)
+		}
+		return nil
+	}
+}
+
+// outputHook implements logrus.Hook writing formatted entries to a destination
+// configured by an output URI
+type outputHook struct {
+	uri       string
+	sink      Sink
+	formatter logrus.Formatter
+	levels    []logrus.Level
+	stripANSI bool // remove ANSI escape sequences from the formatted entries
+	mu        sync.Mutex
+}
+
+// newOutputHook parses an output URI and opens its destination
+func newOutputHook(raw string) (*outputHook, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("output uri: %w", err)
+	}
+	query := u.Query()
+	formatter, err := outputFormatter(query.Get("format"))
+	if err != nil {
+		return nil, err
+	}
+	levels, err := outputLevels(query.Get("min_level"), query.Get("max_level"))
+	if err != nil {
+		return nil, err
+	}
+	var strip bool
+	if v := query.Get("strip_ansi"); v != "" {
+		if strip, err = strconv.ParseBool(v); err != nil {
+			return nil, fmt.Errorf("output uri: invalid strip_ansi %q", v)
+		}
+	}
+
+	sink, err := openSink(u)
+	if err != nil {
+		return nil, fmt.Errorf("output uri: %w", err)
+	}
+	return &outputHook{uri: raw, sink: sink, formatter: formatter, levels: levels, stripANSI: strip}, nil
+}
+
+// outputFormatter returns the formatter selected by the format query parameter
+func outputFormatter(format string) (logrus.Formatter, error) {
+	switch format {
+	case "", "text":
+		return &logrus.TextFormatter{DisableColors: true, FullTimestamp: true}, nil
+	case "json":
+		return &logrus.JSONFormatter{}, nil
+	case "color":
+		return colorFormatter, nil
+	case "plain":
+		return &PlainFormatter{}, nil
+	}
+	return nil, fmt.Errorf("output uri: unknown format %q", format)
+}
+
+// outputLevels returns the levels between min and max, ordered from panic to
+// the most verbose. Empty bounds default to trace and panic.
+func outputLevels(min, max string) ([]logrus.Level, error) {
+	minLevel, maxLevel := logrus.TraceLevel, logrus.PanicLevel
+	var err error
+	if min != "" {
+		if minLevel, err = logrus.ParseLevel(min); err != nil {
+			return nil, fmt.Errorf("output uri: %w", err)
+		}
+	}
+	if max != "" {
+		if maxLevel, err = logrus.ParseLevel(max); err != nil {
+			return nil, fmt.Errorf("output uri: %w", err)
+		}
+	}
+	if maxLevel > minLevel {
+		return nil, fmt.Errorf("output uri: max_level %s is more verbose than min_level %s", maxLevel, minLevel)
+	}
+	return LevelRange{Min: minLevel, Max: maxLevel}.Levels(), nil
+}
+
+// Levels returns the levels this hook should be fired for
+func (h *outputHook) Levels() []logrus.Level {
+	return h.levels
+}
+
+// Fire writes the formatted entry to the destination
+func (h *outputHook) Fire(entry *logrus.Entry) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	line, err := h.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+	if h.stripANSI {
+		line = stripANSI(line)
+	}
+	_, err = h.sink.Write(line)
+	return err
+}
+
+// Reopen reopens the file of the sink, see Logger.Reopen
+func (h *outputHook) Reopen() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return reopenWriter(h.sink)
+}
+
+// Close closes the sink
+func (h *outputHook) Close() error {
+	return h.sink.Close()
+}
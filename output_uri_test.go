@@ -0,0 +1,71 @@
+package logger
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithOutputURIs(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "output_uri")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	jsonPath := filepath.Join(tmpDir, "app.json")
+	plainPath := filepath.Join(tmpDir, "debug.log")
+	logger, err := NewLogger(WithOutputURIs(
+		"file://" + jsonPath + "?format=json&min_level=info, file://" + plainPath + "?format=plain&min_level=debug&max_level=info",
+	))
+	require.NoError(t, err)
+	assert.Equal(t, logrus.DebugLevel, logger.Logger.GetLevel())
+
+	logger.Debug("debugging")
+	logger.WithField("user", "alice").Info("logged in")
+	logger.Error("failed")
+
+	for _, hook := range logger.Logger.Hooks[logrus.InfoLevel] {
+		if h, ok := hook.(*outputHook); ok {
+			require.NoError(t, h.Close())
+		}
+	}
+
+	data, err := os.ReadFile(jsonPath)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+	var first map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+	assert.Equal(t, "logged in", first["msg"])
+	assert.Equal(t, "alice", first["user"])
+
+	data, err = os.ReadFile(plainPath)
+	require.NoError(t, err)
+	assert.Equal(t, "DEBUG debugging\nINFO logged in user=alice\n", string(data))
+}
+
+func TestWithOutputURIs_Invalid(t *testing.T) {
+	for _, uris := range []string{
+		"",
+		"ftp://example.com/logs",
+		"stderr://?format=xml",
+		"stderr://?min_level=loud",
+		"stderr://?min_level=error&max_level=debug",
+		"stderr://?strip_ansi=maybe",
+		"file://",
+	} {
+		_, err := NewLogger(WithOutputURIs(uris))
+		assert.Error(t, err, uris)
+	}
+}
+
+func TestOutputLevels(t *testing.T) {
+	levels, err := outputLevels("warn", "")
+	require.NoError(t, err)
+	assert.Equal(t, []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel, logrus.WarnLevel}, levels)
+}
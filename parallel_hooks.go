@@ -0,0 +1,181 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultParallelHookTimeout is the time WithParallelHooks waits for a hook
+// when the timeout isn't set
+const DefaultParallelHookTimeout = 5 * time.Second
+
+// ErrHookTimeout is reported for hooks abandoned by WithParallelHooks
+var ErrHookTimeout = errors.New("hook timed out")
+
+// parallelHooks fires the sink hooks of a logger concurrently. Every hook gets
+// its own copy of the entry, whose context is canceled once the timeout
+// elapses; hooks still running then are abandoned and reported with
+// ErrHookTimeout.
+type parallelHooks struct {
+	timeout time.Duration
+	hooks   logrus.LevelHooks
+}
+
+// EnrichingHook is implemented by hooks changing the entry, e.g. adding fields,
+// or that must run on the goroutine logging it. WithParallelHooks fires them one
+// after the other ahead of the sinks, so their changes reach the formatter and
+// every other hook; the other hooks get a copy of the entry and their changes
+// are lost. Hooks changing the entry must implement it, returning true, to be
+// used with WithParallelHooks.
+type EnrichingHook interface {
+	logrus.Hook
+	Enriches() bool
+}
+
+// parallelHooksConfig holds the timeout set with WithParallelHooks until the
+// hooks are grouped
+type parallelHooksConfig struct {
+	timeout time.Duration
+}
+
+func (c *parallelHooksConfig) Levels() []logrus.Level { return logrus.AllLevels }
+
+func (c *parallelHooksConfig) Fire(*logrus.Entry) error { return nil }
+
+// WithParallelHooks fires the sink hooks concurrently so a slow one doesn't
+// delay the others, waiting up to timeout (DefaultParallelHookTimeout when not
+// positive) for each. Hooks enriching entries, see EnrichingHook, still run
+// first, one after the other. The context of the entry given to a hook is canceled on timeout, so
+// hooks honoring it can give up. It applies to the hooks registered when the
+// logger is created.
+func WithParallelHooks(timeout time.Duration) Option {
+	return func(l *Logger) error {
+		if timeout <= 0 {
+			timeout = DefaultParallelHookTimeout
+		}
+		cfg, ok := findHook[*parallelHooksConfig](l.Entry.Logger)
+		if !ok {
+			cfg = &parallelHooksConfig{}
+			l.Entry.Logger.AddHook(cfg)
+		}
+		cfg.timeout = timeout
+		return nil
+	}
+}
+
+// startParallelHooks groups the sink hooks of l in a parallelHooks fired after
+// the enriching hooks when WithParallelHooks was used. It runs once the options
+// have been applied, after guardHooks and before startAsync.
+func startParallelHooks(l *logrus.Logger) {
+	cfg, ok := findHook[*parallelHooksConfig](l)
+	if !ok {
+		return
+	}
+
+	p := &parallelHooks{timeout: cfg.timeout, hooks: make(logrus.LevelHooks)}
+	hooks := make(logrus.LevelHooks)
+	for level, levelHooks := range l.Hooks {
+		for _, hook := range levelHooks {
+			switch h := unwrapHook(hook).(type) {
+			case *parallelHooksConfig:
+			case EnrichingHook:
+				if h.Enriches() {
+					hooks[level] = append(hooks[level], hook)
+				} else {
+					p.hooks[level] = append(p.hooks[level], hook)
+				}
+			default:
+				p.hooks[level] = append(p.hooks[level], hook)
+			}
+		}
+	}
+	for level := range p.hooks {
+		hooks[level] = append(hooks[level], p)
+	}
+	l.ReplaceHooks(hooks)
+}
+
+func (p *parallelHooks) Levels() []logrus.Level {
+	levels := make([]logrus.Level, 0, len(p.hooks))
+	for _, level := range logrus.AllLevels {
+		if len(p.hooks[level]) > 0 {
+			levels = append(levels, level)
+		}
+	}
+	return levels
+}
+
+// Hooks returns the distinct hooks fired concurrently
+func (p *parallelHooks) Hooks() []logrus.Hook {
+	var hooks []logrus.Hook
+	for _, level := range logrus.AllLevels {
+		for _, hook := range p.hooks[level] {
+			if !containsHook(hooks, hook) {
+				hooks = append(hooks, hook)
+			}
+		}
+	}
+	return hooks
+}
+
+type hookResult struct {
+	index int
+	err   error
+}
+
+// Fire fires the hooks of the entry level concurrently and waits for them up to
+// the timeout. Errors of guarded hooks, timeouts included, go to the hook error
+// guard; the others are returned. Errors of abandoned hooks are dropped.
+func (p *parallelHooks) Fire(entry *logrus.Entry) error {
+	hooks := p.hooks[entry.Level]
+	parent := context.Background()
+	if entry.Context != nil {
+		// a canceled request context must not abandon its log entries
+		parent = context.WithoutCancel(entry.Context)
+	}
+	ctx, cancel := context.WithTimeout(parent, p.timeout)
+	defer cancel()
+
+	// buffered so abandoned hooks don't block once they return
+	results := make(chan hookResult, len(hooks))
+	for i, hook := range hooks {
+		e := cloneEntry(entry)
+		e.Context = ctx
+		// guarded hooks are fired unwrapped so their errors are reported once
+		hook = unwrapHook(hook)
+		go func(i int, hook logrus.Hook) {
+			results <- hookResult{index: i, err: hook.Fire(e)}
+		}(i, hook)
+	}
+
+	var errs []error
+	fail := func(hook logrus.Hook, err error) {
+		if g, ok := hook.(*guardedHook); ok {
+			g.guard.report(g.Hook, entry, err)
+		} else {
+			errs = append(errs, err)
+		}
+	}
+	done := make([]bool, len(hooks))
+	for pending := len(hooks); pending > 0; pending-- {
+		select {
+		case r := <-results:
+			done[r.index] = true
+			if r.err != nil {
+				fail(hooks[r.index], r.err)
+			}
+		case <-ctx.Done():
+			for i, hook := range hooks {
+				if !done[i] {
+					fail(hook, fmt.Errorf("%T: %w after %s", unwrapHook(hook), ErrHookTimeout, p.timeout))
+				}
+			}
+			return errors.Join(errs...)
+		}
+	}
+	return errors.Join(errs...)
+}
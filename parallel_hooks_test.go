@@ -0,0 +1,172 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowHook takes delay to fire, or until the entry context is canceled
+type slowHook struct {
+	delay time.Duration
+
+	mu       sync.Mutex
+	fired    int
+	canceled int
+	fields   []logrus.Fields
+}
+
+func (h *slowHook) Levels() []logrus.Level { return logrus.AllLevels }
+
+func (h *slowHook) Fire(entry *logrus.Entry) error {
+	var done <-chan struct{}
+	if entry.Context != nil {
+		done = entry.Context.Done()
+	}
+	select {
+	case <-time.After(h.delay):
+	case <-done:
+		h.mu.Lock()
+		h.canceled++
+		h.mu.Unlock()
+		return entry.Context.Err()
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.fired++
+	h.fields = append(h.fields, entry.Data)
+	return nil
+}
+
+func (h *slowHook) counts() (fired, canceled int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.fired, h.canceled
+}
+
+func TestWithParallelHooks(t *testing.T) {
+	a := &slowHook{delay: 100 * time.Millisecond}
+	b := &slowHook{delay: 100 * time.Millisecond}
+	logger, err := NewLogger(
+		WithNullOutput(),
+		WithLastEntriesCapture(5),
+		WithHooks(a, b),
+		WithParallelHooks(time.Second),
+		WithDeterminism(&DeterminismConfig{}),
+	)
+	require.NoError(t, err)
+
+	start := time.Now()
+	logger.Info("hello")
+	assert.Less(t, time.Since(start), 190*time.Millisecond, "hooks fire concurrently")
+
+	for _, h := range []*slowHook{a, b} {
+		fired, _ := h.counts()
+		assert.Equal(t, 1, fired)
+		assert.Contains(t, h.fields[0], "seq", "enriching hooks run first")
+	}
+	assert.Len(t, logger.Breadcrumbs(0), 1, "hooks inside the group are still found")
+}
+
+func TestWithParallelHooks_Timeout(t *testing.T) {
+	slow := &slowHook{delay: time.Minute}
+	fast := &flakyHook{}
+	var mu sync.Mutex
+	var failures []error
+	logger, err := NewLogger(
+		WithNullOutput(),
+		WithHooks(slow, fast),
+		WithHookErrorHandler(func(h logrus.Hook, entry *logrus.Entry, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			assert.Same(t, slow, h)
+			failures = append(failures, err)
+		}),
+		WithParallelHooks(50*time.Millisecond),
+	)
+	require.NoError(t, err)
+
+	start := time.Now()
+	logger.Info("hello")
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, []string{"hello"}, fast.messages)
+
+	assert.Eventually(t, func() bool {
+		_, canceled := slow.counts()
+		return canceled == 1
+	}, time.Second, 10*time.Millisecond, "the hook context is canceled")
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, failures, 1, "abandoned hooks are reported once")
+	assert.ErrorIs(t, failures[0], ErrHookTimeout)
+}
+
+func TestWithParallelHooks_IgnoresCanceledContext(t *testing.T) {
+	hook := &slowHook{delay: 10 * time.Millisecond}
+	logger, err := NewLogger(WithNullOutput(), WithHooks(hook), WithParallelHooks(time.Second))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	logger.WithContext(ctx).Info("request done")
+
+	fired, canceled := hook.counts()
+	assert.Equal(t, 1, fired)
+	assert.Zero(t, canceled)
+}
+
+func TestWithParallelHooks_ReturnsErrors(t *testing.T) {
+	p := &parallelHooks{timeout: time.Second, hooks: logrus.LevelHooks{
+		logrus.InfoLevel: {&flakyHook{broken: true}, &flakyHook{}},
+	}}
+	err := p.Fire(&logrus.Entry{Level: logrus.InfoLevel, Data: logrus.Fields{}})
+	assert.EqualError(t, err, "unavailable")
+	assert.False(t, errors.Is(err, ErrHookTimeout))
+}
+
+func TestWithParallelHooks_ClosesSinks(t *testing.T) {
+	hook := &closingHook{}
+	logger, err := NewLogger(WithNullOutput(), WithHooks(hook), WithParallelHooks(0))
+	require.NoError(t, err)
+
+	for _, closer := range sinkClosers(logger.Entry.Logger) {
+		require.NoError(t, closer())
+	}
+	assert.True(t, hook.closed)
+}
+
+// tenantHook adds a tenant field, opting in to run ahead of parallel sinks
+type tenantHook struct{}
+
+func (tenantHook) Levels() []logrus.Level { return logrus.AllLevels }
+
+func (tenantHook) Fire(entry *logrus.Entry) error {
+	entry.Data["tenant"] = "acme"
+	return nil
+}
+
+func (tenantHook) Enriches() bool { return true }
+
+func TestWithParallelHooks_EnrichingHook(t *testing.T) {
+	sink := &slowHook{}
+	var out bytes.Buffer
+	logger, err := NewLogger(
+		WithOutput(&out),
+		WithFormatter(&PlainFormatter{}),
+		WithHooks(sink, tenantHook{}),
+		WithParallelHooks(time.Second),
+	)
+	require.NoError(t, err)
+
+	logger.Info("hello")
+	assert.Equal(t, "INFO hello tenant=acme\n", out.String())
+	require.Len(t, sink.fields, 1)
+	assert.Equal(t, "acme", sink.fields[0]["tenant"])
+}
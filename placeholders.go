@@ -0,0 +1,49 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ExpandFields expands {field} placeholders in tmpl with entry fields, {level}
+// with the entry level and unknown fields with "unknown". When escape is not nil
+// it is applied to every substituted value, e.g. to keep values from breaking
+// the structure of a subject or tag.
+func ExpandFields(tmpl string, entry *logrus.Entry, escape func(string) string) string {
+	if !strings.Contains(tmpl, "{") {
+		return tmpl
+	}
+
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(tmpl, '{')
+		if start == -1 {
+			break
+		}
+		end := strings.IndexByte(tmpl[start:], '}')
+		if end == -1 {
+			break
+		}
+		b.WriteString(tmpl[:start])
+
+		key := tmpl[start+1 : start+end]
+		var value string
+		switch {
+		case key == "level":
+			value = entry.Level.String()
+		case entry.Data[key] != nil:
+			value = fmt.Sprint(entry.Data[key])
+		default:
+			value = "unknown"
+		}
+		if escape != nil {
+			value = escape(value)
+		}
+		b.WriteString(value)
+		tmpl = tmpl[start+end+1:]
+	}
+	b.WriteString(tmpl)
+	return b.String()
+}
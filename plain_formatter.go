@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// PlainFormatter emits minimal `LEVEL message key=value` lines for plain-text
+// consumers such as journald or docker reading a service's stdout
+type PlainFormatter struct {
+	// SyslogPrefix prepends the sd-daemon style `<N>` priority prefix so journald
+	// assigns the right priority to each line
+	SyslogPrefix bool
+}
+
+func (f *PlainFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	var b bytes.Buffer
+
+	if f.SyslogPrefix {
+		fmt.Fprintf(&b, "<%d>", journaldPriorities[entry.Level])
+	}
+	b.WriteString(strings.ToUpper(entry.Level.String()))
+	b.WriteByte(' ')
+	component := entryComponent(entry.Data)
+	if component != "" {
+		b.WriteString("[" + component + "] ")
+	}
+	// each line is a separate record for line based consumers
+	b.WriteString(strings.ReplaceAll(entry.Message, "\n", `\n`))
+
+	keys := make([]string, 0, len(entry.Data))
+	for key := range entry.Data {
+		if key == ComponentKey && component != "" {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		b.WriteByte(' ')
+		b.WriteString(key)
+		b.WriteByte('=')
+		b.WriteString(plainValue(entry.Data[key]))
+	}
+
+	b.WriteString("\n")
+
+	return b.Bytes(), nil
+}
+
+// plainValue renders a field value, quoting it when it contains spaces,
+// quotes, '=' or control characters
+func plainValue(value interface{}) string {
+	var s string
+	if err, ok := value.(error); ok {
+		s = err.Error()
+	} else {
+		s = fmt.Sprint(value)
+	}
+	if s == "" || strings.ContainsAny(s, " \"=\t\n\r") {
+		return strconv.Quote(s)
+	}
+	return s
+}
@@ -0,0 +1,57 @@
+package logger
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlainFormatter(t *testing.T) {
+	tests := []struct {
+		name      string
+		formatter *PlainFormatter
+		level     logrus.Level
+		message   string
+		fields    logrus.Fields
+		want      string
+	}{
+		{
+			name:      "plain",
+			formatter: &PlainFormatter{},
+			level:     logrus.InfoLevel,
+			message:   "server started",
+			fields:    logrus.Fields{"port": 8080, "host": "localhost"},
+			want:      "INFO server started host=localhost port=8080\n",
+		},
+		{
+			name:      "syslog prefix",
+			formatter: &PlainFormatter{SyslogPrefix: true},
+			level:     logrus.ErrorLevel,
+			message:   "request failed",
+			fields:    logrus.Fields{logrus.ErrorKey: errors.New("connection refused")},
+			want:      "<3>ERROR request failed error=\"connection refused\"\n",
+		},
+		{
+			name:      "multi-line message",
+			formatter: &PlainFormatter{SyslogPrefix: true},
+			level:     logrus.DebugLevel,
+			message:   "line1\nline2",
+			want:      "<7>DEBUG line1\\nline2\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := logrus.NewEntry(logrus.New()).WithFields(tt.fields)
+			entry.Level = tt.level
+			entry.Message = tt.message
+
+			out, err := tt.formatter.Format(entry)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(out))
+		})
+	}
+}
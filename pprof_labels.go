@@ -0,0 +1,61 @@
+package logger
+
+import (
+	"fmt"
+	"runtime/pprof"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultPprofLabelKeys are the fields set as pprof labels when no keys are
+// given to WithPprofLabels
+var DefaultPprofLabelKeys = []string{RequestIDKey, "endpoint", "route"}
+
+// WithPprofLabels sets the given fields (DefaultPprofLabelKeys when none) of
+// debug and trace entries logged with a context as pprof labels of the logging
+// goroutine, on top of the labels the context carries, so CPU profiles can be
+// sliced by the same identifiers as the logs. The labels stay set on the
+// goroutine and are inherited by the goroutines it starts. It has no effect
+// unless the logger level is debug or trace, nor with WithAsync, where hooks
+// fire on the background worker.
+func WithPprofLabels(keys ...string) Option {
+	return func(l *Logger) error {
+		if len(keys) == 0 {
+			keys = DefaultPprofLabelKeys
+		}
+		l.Entry.Logger.AddHook(&pprofLabelsHook{keys: keys})
+		return nil
+	}
+}
+
+// pprofLabelsHook implements logrus.Hook setting entry fields as goroutine
+// labels
+type pprofLabelsHook struct {
+	keys []string
+}
+
+func (h *pprofLabelsHook) Levels() []logrus.Level {
+	return []logrus.Level{logrus.DebugLevel, logrus.TraceLevel}
+}
+
+// Enriches implements EnrichingHook, it labels the goroutine logging the entry
+func (h *pprofLabelsHook) Enriches() bool {
+	return true
+}
+
+func (h *pprofLabelsHook) Fire(entry *logrus.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+	var labels []string
+	for _, key := range h.keys {
+		if v, ok := entry.Data[key]; ok {
+			labels = append(labels, key, fmt.Sprint(v))
+		}
+	}
+	if len(labels) == 0 {
+		return nil
+	}
+	pprof.SetGoroutineLabels(pprof.WithLabels(entry.Context, pprof.Labels(labels...)))
+	return nil
+}
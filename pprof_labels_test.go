@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"runtime/pprof"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithPprofLabels(t *testing.T) {
+	logger, err := NewLogger(
+		WithNullOutput(),
+		WithLevel("debug"),
+		WithContextExtractor(RequestIDFields),
+		WithPprofLabels(),
+	)
+	require.NoError(t, err)
+
+	goroutineLabels := func(fn func()) string {
+		done := make(chan string)
+		go func() {
+			fn()
+			var buf bytes.Buffer
+			pprof.Lookup("goroutine").WriteTo(&buf, 1)
+			done <- buf.String()
+		}()
+		return <-done
+	}
+
+	ctx := ContextWithRequestID(context.Background(), "req-42")
+	profile := goroutineLabels(func() {
+		logger.WithContext(ctx).WithField("endpoint", "/orders").Debug("loading order")
+	})
+	assert.Contains(t, profile, `"request_id":"req-42"`)
+	assert.Contains(t, profile, `"endpoint":"/orders"`)
+
+	profile = goroutineLabels(func() {
+		logger.WithContext(ContextWithRequestID(context.Background(), "req-43")).Info("not a debug entry")
+	})
+	assert.NotContains(t, profile, `"request_id":"req-43"`)
+}
@@ -0,0 +1,32 @@
+package logger
+
+import (
+	"os"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Development creates a logger suited to local development: colored output on
+// stderr at debug level, with the calling function and source line of every
+// entry. opts are applied on top of the preset.
+func Development(opts ...Option) (*Logger, error) {
+	preset := []Option{
+		WithOutput(os.Stderr),
+		WithFormatter(&ColorFormatter{}),
+		WithLevel("debug"),
+	}
+	return NewLogger(append(preset, opts...)...)
+}
+
+// Production creates a logger suited to production: JSON on stdout at info
+// level, with repetitive entries sampled using the default SamplingConfig. opts
+// are applied on top of the preset.
+func Production(opts ...Option) (*Logger, error) {
+	preset := []Option{
+		WithOutput(os.Stdout),
+		WithFormatter(&logrus.JSONFormatter{}),
+		WithLevel("info"),
+		WithSampling(nil),
+	}
+	return NewLogger(append(preset, opts...)...)
+}
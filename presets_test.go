@@ -0,0 +1,37 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProduction(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := Production(WithOutput(&buf))
+	require.NoError(t, err)
+	assert.Equal(t, logrus.InfoLevel, l.Entry.Logger.GetLevel())
+
+	l.Debug("hidden")
+	l.WithField("port", 8080).Info("listening")
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "listening", entry["msg"])
+	assert.Equal(t, float64(8080), entry["port"])
+}
+
+func TestDevelopment(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := Development(WithOutput(&buf), WithComponent("dev"))
+	require.NoError(t, err)
+	assert.Equal(t, logrus.DebugLevel, l.Entry.Logger.GetLevel())
+
+	l.Debug("connecting")
+	out := string(stripANSI(buf.Bytes()))
+	assert.Contains(t, out, "[debug] [dev] connecting")
+	assert.Contains(t, out, "presets_test.go:")
+}
@@ -0,0 +1,26 @@
+package logger
+
+import "hash/fnv"
+
+// templateHash returns a hash of the message with every run of digits masked,
+// so entries rendered from the same format string ("user 42 logged in", "user
+// 7 logged in") share a hash that tracing tools can aggregate on
+func templateHash(msg string) uint32 {
+	h := fnv.New32a()
+	buf := make([]byte, 0, len(msg))
+	inDigits := false
+	for i := 0; i < len(msg); i++ {
+		c := msg[i]
+		if c >= '0' && c <= '9' {
+			if !inDigits {
+				buf = append(buf, '#')
+			}
+			inDigits = true
+			continue
+		}
+		inDigits = false
+		buf = append(buf, c)
+	}
+	h.Write(buf)
+	return h.Sum32()
+}
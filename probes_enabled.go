@@ -0,0 +1,46 @@
+//go:build logprobes
+
+package logger
+
+import (
+	"context"
+	"fmt"
+	"runtime/trace"
+
+	"github.com/sirupsen/logrus"
+)
+
+// probeHook implements logrus.Hook firing the tracing probes for every entry
+type probeHook struct{}
+
+// addProbeHook registers the probe hook, built with the logprobes tag
+func addProbeHook(l *logrus.Logger) {
+	l.AddHook(probeHook{})
+}
+
+func (probeHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire calls the uprobe target and, while a runtime trace is being collected,
+// records a "log" user event
+func (probeHook) Fire(entry *logrus.Entry) error {
+	hash := templateHash(entry.Message)
+	logProbe(uint32(entry.Level), hash)
+	if trace.IsEnabled() {
+		ctx := entry.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		trace.Log(ctx, "log", fmt.Sprintf("level=%s template=%08x", entry.Level, hash))
+	}
+	return nil
+}
+
+// logProbe is a static attachment point for uprobes, its arguments are the
+// entry level and template hash:
+//
+//	bpftrace -e 'uprobe:./app:"github.com/alejoacosta74/go-logger.logProbe" { @[reg("ax"), reg("bx")] = count(); }'
+//
+//go:noinline
+func logProbe(level, hash uint32) {}
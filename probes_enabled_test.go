@@ -0,0 +1,25 @@
+//go:build logprobes
+
+package logger
+
+import (
+	"bytes"
+	"runtime/trace"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProbeHook_RuntimeTrace(t *testing.T) {
+	logger, err := NewLogger(WithNullOutput())
+	require.NoError(t, err)
+	_, ok := findHook[probeHook](logger.Logger)
+	require.True(t, ok)
+
+	var buf bytes.Buffer
+	require.NoError(t, trace.Start(&buf))
+	logger.Info("user 42 logged in")
+	trace.Stop()
+	assert.Contains(t, buf.String(), "log")
+}
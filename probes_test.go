@@ -0,0 +1,13 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplateHash(t *testing.T) {
+	assert.Equal(t, templateHash("user 42 logged in after 3 attempts"), templateHash("user 7 logged in after 12 attempts"))
+	assert.NotEqual(t, templateHash("user 42 logged in"), templateHash("user 42 logged out"))
+	assert.NotEqual(t, templateHash("retry 1"), templateHash("retry "))
+}
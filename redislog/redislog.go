@@ -0,0 +1,261 @@
+// Package redislog appends go-logger entries to a capped Redis stream. Importing
+// it registers the redis scheme with logger.RegisterSink, so
+// redis://localhost:6379/stream URLs can be used with logger.WithOutputURIs and
+// logger.NewSink.
+package redislog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	logger "github.com/alejoacosta74/go-logger"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultMaxLen    = 10000
+	DefaultBatchSize = 100
+	DefaultBatchWait = time.Second
+	DefaultQueueSize = 10000
+	DefaultTimeout   = 5 * time.Second
+)
+
+func init() {
+	if err := logger.RegisterSink("redis", newSink); err != nil {
+		panic(err)
+	}
+}
+
+// Options holds configuration for the Redis Streams hook
+type Options struct {
+	Addr     string // host:port of the server, used when Client is nil
+	Password string
+	DB       int
+	// Client reuses an existing client; the hook doesn't close it
+	Client redis.UniversalClient
+	Stream string // stream key, required
+	// MaxLen caps the stream length, defaults to DefaultMaxLen. The cap is
+	// approximate (MAXLEN ~) unless ExactMaxLen is set, which is cheaper for the
+	// server.
+	MaxLen      int64
+	ExactMaxLen bool
+	BatchSize   int
+	BatchWait   time.Duration
+	QueueSize   int
+	Block       bool // block logging when the queue is full instead of dropping
+	Timeout     time.Duration
+	// Retry bounds the attempts to add a batch, a single one by default.
+	// Batches given up on are passed to its OnFailure.
+	Retry  *logger.RetryPolicy
+	Levels []logrus.Level
+}
+
+// hook implements logrus.Hook appending entries to a Redis stream
+type hook struct {
+	opts    Options
+	client  redis.UniversalClient
+	owned   bool
+	retry   logger.RetryPolicy
+	batcher *logger.Batcher[map[string]interface{}]
+	// xadd appends a batch of entries, pipelined
+	xadd func(ctx context.Context, args []*redis.XAddArgs) error
+}
+
+// NewHook creates a new hook adding entries to a Redis stream with XADD, so
+// recent logs are durable and queryable with XRANGE
+func NewHook(opts *Options) (*hook, error) {
+	if opts == nil || opts.Stream == "" {
+		return nil, fmt.Errorf("redis stream: stream is required")
+	}
+	client, owned := opts.Client, false
+	if client == nil {
+		if opts.Addr == "" {
+			return nil, fmt.Errorf("redis stream: an address or client is required")
+		}
+		client = redis.NewClient(&redis.Options{Addr: opts.Addr, Password: opts.Password, DB: opts.DB})
+		owned = true
+	}
+	h := newHook(opts, func(ctx context.Context, args []*redis.XAddArgs) error {
+		_, err := client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, a := range args {
+				pipe.XAdd(ctx, a)
+			}
+			return nil
+		})
+		return err
+	})
+	h.client, h.owned = client, owned
+	return h, nil
+}
+
+func newHook(opts *Options, xadd func(ctx context.Context, args []*redis.XAddArgs) error) *hook {
+	o := *opts
+	if o.MaxLen <= 0 {
+		o.MaxLen = DefaultMaxLen
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = DefaultBatchSize
+	}
+	if o.BatchWait <= 0 {
+		o.BatchWait = DefaultBatchWait
+	}
+	if o.QueueSize <= 0 {
+		o.QueueSize = DefaultQueueSize
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = DefaultTimeout
+	}
+	if len(o.Levels) == 0 {
+		o.Levels = logrus.AllLevels
+	}
+	h := &hook{opts: o, xadd: xadd}
+	if o.Retry != nil {
+		h.retry = *o.Retry
+	}
+	h.batcher = logger.NewBatcher(o.BatchSize, o.BatchWait, o.QueueSize, o.Block, h.ship)
+	return h
+}
+
+// WithHook adds a hook appending entries to a capped Redis stream
+func WithHook(opts *Options) logger.Option {
+	return func(l *logger.Logger) error {
+		h, err := NewHook(opts)
+		if err != nil {
+			return err
+		}
+		l.Entry.Logger.AddHook(h)
+		return nil
+	}
+}
+
+func (h *hook) Levels() []logrus.Level {
+	return h.opts.Levels
+}
+
+// Fire queues the entry for the next batch. Stream entries are flat, so fields
+// are stored next to the time, level and msg values.
+func (h *hook) Fire(entry *logrus.Entry) error {
+	values := make(map[string]interface{}, len(entry.Data)+3)
+	for k, v := range entry.Data {
+		switch v := v.(type) {
+		case string:
+			values[k] = v
+		case error:
+			values[k] = v.Error()
+		default:
+			values[k] = fmt.Sprint(v)
+		}
+	}
+	values["time"] = entry.Time.Format(time.RFC3339Nano)
+	values["level"] = entry.Level.String()
+	values["msg"] = entry.Message
+	h.batcher.Add(values)
+	return nil
+}
+
+// Dropped returns the number of entries discarded because the queue was full
+func (h *hook) Dropped() uint64 {
+	return h.batcher.Dropped()
+}
+
+// Flush writes the queued entries without stopping the background worker
+func (h *hook) Flush() error {
+	h.batcher.Sync()
+	return nil
+}
+
+// Close writes pending entries and closes the client if the hook created it
+func (h *hook) Close() error {
+	h.batcher.Close()
+	if h.owned {
+		return h.client.Close()
+	}
+	return nil
+}
+
+// ship appends a batch of entries to the stream
+func (h *hook) ship(batch []map[string]interface{}) {
+	args := make([]*redis.XAddArgs, len(batch))
+	for i, values := range batch {
+		args[i] = &redis.XAddArgs{
+			Stream: h.opts.Stream,
+			MaxLen: h.opts.MaxLen,
+			Approx: !h.opts.ExactMaxLen,
+			Values: values,
+		}
+	}
+	h.retry.Deliver("redis", len(batch), nil, h.batcher.Closing(), func([]byte) (bool, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), h.opts.Timeout)
+		defer cancel()
+		err := h.xadd(ctx, args)
+		// error replies, e.g. WRONGTYPE, fail again
+		var reply redis.Error
+		return err != nil && !errors.As(err, &reply), err
+	})
+}
+
+// newSink connects for redis://localhost:6379/stream URLs, adding every write
+// to the stream in a line value. The maxlen query parameter caps the stream,
+// DefaultMaxLen by default, and db selects the database.
+func newSink(u *url.URL) (logger.Sink, error) {
+	stream := strings.Trim(u.Path, "/")
+	if u.Host == "" || stream == "" {
+		return nil, fmt.Errorf("address and stream are required, e.g. redis://localhost:6379/stream")
+	}
+	query := u.Query()
+	s := &sink{stream: stream, maxLen: DefaultMaxLen}
+	if v := query.Get("maxlen"); v != "" {
+		maxLen, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || maxLen <= 0 {
+			return nil, fmt.Errorf("invalid maxlen %q", v)
+		}
+		s.maxLen = maxLen
+	}
+	opts := &redis.Options{Addr: u.Host}
+	if v := query.Get("db"); v != "" {
+		db, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid db %q", v)
+		}
+		opts.DB = db
+	}
+	if u.User != nil {
+		opts.Username = u.User.Username()
+		opts.Password, _ = u.User.Password()
+	}
+	s.client = redis.NewClient(opts)
+	return s, nil
+}
+
+// sink is a logger.Sink adding each write to a Redis stream
+type sink struct {
+	client *redis.Client
+	stream string
+	maxLen int64
+}
+
+func (s *sink) Write(p []byte) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	err := s.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: s.stream,
+		MaxLen: s.maxLen,
+		Approx: true,
+		Values: map[string]interface{}{"line": strings.TrimRight(string(p), "\n")},
+	}).Err()
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close closes the client
+func (s *sink) Close() error {
+	return s.client.Close()
+}
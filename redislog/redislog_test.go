@@ -0,0 +1,100 @@
+package redislog
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	logger "github.com/alejoacosta74/go-logger"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHook_XAdd(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		sent []*redis.XAddArgs
+	)
+	hook := newHook(&Options{Stream: "logs", MaxLen: 500}, func(ctx context.Context, args []*redis.XAddArgs) error {
+		mu.Lock()
+		defer mu.Unlock()
+		sent = append(sent, args...)
+		return nil
+	})
+
+	log, err := logger.NewLogger(logger.WithNullOutput())
+	require.NoError(t, err)
+	log.Logger.AddHook(hook)
+
+	log.WithFields(logger.Fields{"user": "alice", "attempt": 2}).Info("login")
+	log.WithError(errors.New("boom")).Error("failed")
+	require.NoError(t, hook.Close())
+
+	require.Len(t, sent, 2)
+	assert.Equal(t, "logs", sent[0].Stream)
+	assert.Equal(t, int64(500), sent[0].MaxLen)
+	assert.True(t, sent[0].Approx)
+
+	values := sent[0].Values.(map[string]interface{})
+	assert.Equal(t, "login", values["msg"])
+	assert.Equal(t, "info", values["level"])
+	assert.Equal(t, "alice", values["user"])
+	assert.Equal(t, "2", values["attempt"])
+	assert.Equal(t, "boom", sent[1].Values.(map[string]interface{})["error"])
+}
+
+func TestHook_Retry(t *testing.T) {
+	var (
+		calls  int
+		failed []logger.FailedDelivery
+	)
+	hook := newHook(&Options{
+		Stream: "logs",
+		Retry: &logger.RetryPolicy{
+			MaxAttempts: 3,
+			MinBackoff:  time.Millisecond,
+			OnFailure:   func(f logger.FailedDelivery) { failed = append(failed, f) },
+		},
+	}, func(ctx context.Context, args []*redis.XAddArgs) error {
+		calls++
+		return errors.New("i/o timeout")
+	})
+
+	log, err := logger.NewLogger(logger.WithNullOutput())
+	require.NoError(t, err)
+	log.Logger.AddHook(hook)
+	log.Info("retried")
+	require.NoError(t, hook.Close())
+
+	assert.Equal(t, 3, calls)
+	require.Len(t, failed, 1)
+	assert.Equal(t, "redis", failed[0].Sink)
+	assert.Equal(t, 1, failed[0].Entries)
+}
+
+func TestNewHook_Validation(t *testing.T) {
+	_, err := NewHook(nil)
+	assert.Error(t, err)
+	_, err = NewHook(&Options{Stream: "logs"})
+	assert.Error(t, err)
+
+	hook, err := NewHook(&Options{Stream: "logs", Addr: "localhost:6379"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(DefaultMaxLen), hook.opts.MaxLen)
+	require.NoError(t, hook.Close())
+}
+
+func TestNewSink(t *testing.T) {
+	_, err := logger.NewSink("redis://localhost:6379")
+	assert.ErrorContains(t, err, "stream are required")
+	_, err = logger.NewSink("redis://localhost:6379/logs?maxlen=none")
+	assert.ErrorContains(t, err, "invalid maxlen")
+
+	s, err := logger.NewSink("redis://localhost:6379/logs?maxlen=500&db=2")
+	require.NoError(t, err)
+	assert.Equal(t, int64(500), s.(*sink).maxLen)
+	require.NoError(t, s.Close())
+}
@@ -0,0 +1,142 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// reopener is implemented by the outputs and hooks writing to files that can
+// be reopened after an external tool such as logrotate renamed them
+type reopener interface {
+	Reopen() error
+}
+
+// reopenableFile is a file output that can be reopened at the same path
+type reopenableFile struct {
+	path string
+	perm os.FileMode
+
+	mu sync.Mutex
+	f  *os.File
+}
+
+// openReopenableFile opens path with flag and perm, reopening it for appending
+// later
+func openReopenableFile(path string, flag int, perm os.FileMode) (*reopenableFile, error) {
+	f, err := os.OpenFile(path, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	return &reopenableFile{path: path, perm: perm, f: f}, nil
+}
+
+func (r *reopenableFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.f.Write(p)
+}
+
+// Reopen opens the file at its path again, creating it when it was moved
+// away, and closes the previous handle
+func (r *reopenableFile) Reopen() error {
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, r.perm)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	old := r.f
+	r.f = f
+	r.mu.Unlock()
+	return old.Close()
+}
+
+// Flush syncs the file, like the standard streams it is never closed on flush
+func (r *reopenableFile) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.f.Sync()
+}
+
+func (r *reopenableFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.f.Close()
+}
+
+// reopenWriter reopens the file w writes to, looking through the writers
+// wrapping it. Writers that aren't files are left alone.
+func reopenWriter(w io.Writer) error {
+	switch out := w.(type) {
+	case reopener:
+		return out.Reopen()
+	case *lumberjack.Logger:
+		// the file is opened again on the next write
+		return out.Close()
+	}
+	return nil
+}
+
+// Reopen reopens the files the logger writes to: the output set with
+// WithFileOutput, file output URIs and rotating file hooks. Call it once an
+// external tool such as logrotate renamed the files, so entries stop going to
+// the renamed inode.
+func (l *Logger) Reopen() error {
+	loggers := []*logrus.Logger{l.Entry.Logger}
+	if d, ok := findHook[*asyncDispatcher](l.Entry.Logger); ok && d.shadow != nil {
+		loggers = append(loggers, d.shadow)
+	}
+
+	var errs []error
+	for _, lg := range loggers {
+		if err := reopenWriter(lg.Out); err != nil {
+			errs = append(errs, err)
+		}
+		for _, hook := range registeredHooks(lg) {
+			if r, ok := hook.(reopener); ok {
+				if err := r.Reopen(); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("reopen: %w", err)
+	}
+	return nil
+}
+
+// ReopenOnSignal reopens the files of the logger (see Reopen) whenever one of
+// sigs is received, typically syscall.SIGHUP sent by logrotate's postrotate
+// script. Errors are reported on stderr. The returned func stops listening.
+func (l *Logger) ReopenOnSignal(sigs ...os.Signal) (stop func()) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ch:
+				if err := l.Reopen(); err != nil {
+					fmt.Fprintf(os.Stderr, "logger: %v\n", err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
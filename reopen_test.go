@@ -0,0 +1,71 @@
+package logger
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// logrotate renames the file, then asks the logger to reopen it
+func TestReopen_FileOutput(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	logger, err := NewLogger(WithFileOutput(path), WithFormatter(&PlainFormatter{}))
+	require.NoError(t, err)
+
+	logger.Info("before")
+	require.NoError(t, os.Rename(path, path+".1"))
+	logger.Info("renamed")
+	require.NoError(t, logger.Reopen())
+	logger.Info("after")
+
+	rotated, err := os.ReadFile(path + ".1")
+	require.NoError(t, err)
+	assert.Equal(t, "INFO before\nINFO renamed\n", string(rotated))
+	current, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "INFO after\n", string(current))
+}
+
+func TestReopen_Sinks(t *testing.T) {
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "plain.log")
+	rotated := filepath.Join(dir, "rotated.log")
+	hookFile := filepath.Join(dir, "hook.log")
+	hook, err := newRotatingFileHook(&RotatingFileConfig{Filename: hookFile, BatchSize: 10, FlushInterval: time.Hour})
+	require.NoError(t, err)
+	defer hook.Close()
+
+	logger, err := NewLogger(
+		WithOutputURIs("file://"+plain+"?format=plain,file://"+rotated+"?format=plain&maxsize=10"),
+		WithHooks(hook),
+		WithAsync(10, Block),
+	)
+	require.NoError(t, err)
+
+	logger.Info("before")
+	for _, path := range []string{plain, rotated, hookFile} {
+		require.Eventually(t, func() bool {
+			// the hook batches entries until reopened
+			logger.Reopen()
+			b, _ := os.ReadFile(path)
+			return len(b) > 0
+		}, time.Second, 5*time.Millisecond)
+		require.NoError(t, os.Rename(path, path+".1"))
+	}
+	require.NoError(t, logger.Reopen())
+	logger.Info("after")
+	require.NoError(t, logger.Shutdown(context.Background()))
+
+	for _, path := range []string{plain, rotated, hookFile} {
+		content, err := os.ReadFile(path)
+		require.NoError(t, err, path)
+		assert.Contains(t, string(content), "after", path)
+		assert.NotContains(t, string(content), "before", path)
+	}
+}
@@ -0,0 +1,31 @@
+//go:build unix
+
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReopenOnSignal(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	logger, err := NewLogger(WithFileOutput(path), WithFormatter(&PlainFormatter{}))
+	require.NoError(t, err)
+	stop := logger.ReopenOnSignal(syscall.SIGHUP)
+	defer stop()
+
+	logger.Info("before")
+	require.NoError(t, os.Rename(path, path+".1"))
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(path)
+		return err == nil
+	}, time.Second, 5*time.Millisecond, "the file is reopened")
+}
@@ -0,0 +1,137 @@
+package logger
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultRequestBufferSize is the number of entries held per request when no
+// limit is given to ContextWithRequestBuffer
+const DefaultRequestBufferSize = 256
+
+// requestBufferKey is the context key of the request buffer
+type requestBufferKey struct{}
+
+// requestReplayKey marks the entries replayed from a request buffer
+type requestReplayKey struct{}
+
+// requestBuffer holds the verbose entries of a single request, dropping the
+// oldest ones past its limit
+type requestBuffer struct {
+	limit   int
+	entries []bufferedEntry
+	mu      sync.Mutex
+}
+
+// bufferedEntry is a single entry held by requestBuffer
+type bufferedEntry struct {
+	time    time.Time
+	level   logrus.Level
+	message string
+	data    Fields
+}
+
+// ContextWithRequestBuffer returns a copy of ctx holding the verbose entries
+// logged with it, see WithRequestBuffering. At most limit entries are held, the
+// oldest being dropped first; DefaultRequestBufferSize when limit is zero.
+func ContextWithRequestBuffer(ctx context.Context, limit int) context.Context {
+	if limit <= 0 {
+		limit = DefaultRequestBufferSize
+	}
+	return context.WithValue(ctx, requestBufferKey{}, &requestBuffer{limit: limit})
+}
+
+// WithRequestBuffering holds the entries down to level (e.g. debug) logged with
+// a context created by ContextWithRequestBuffer, emitting them only if the
+// request later logs an error and discarding them otherwise. Entries below the
+// logger level set so far are held; the logger level is lowered to level so
+// they are created at all, so apply this option after WithLevel. Verbose
+// entries logged without a request buffer are discarded as before.
+func WithRequestBuffering(level string) Option {
+	return func(l *Logger) error {
+		parsedLevel, err := logrus.ParseLevel(level)
+		if err != nil {
+			return err
+		}
+		threshold := l.Entry.Logger.GetLevel()
+		if parsedLevel <= threshold {
+			return nil
+		}
+		l.Entry.Logger.SetLevel(parsedLevel)
+		return WithRules(&requestBufferRule{threshold: threshold})(l)
+	}
+}
+
+// requestBufferRule implements Rule holding the entries below threshold in the
+// request buffer of their context and replaying them before an error
+type requestBufferRule struct {
+	threshold logrus.Level
+}
+
+// Apply suppresses the entries below threshold, holding them when the entry
+// context carries a request buffer
+func (r *requestBufferRule) Apply(entry *logrus.Entry) bool {
+	buf := requestBufferFrom(entry.Context)
+	if entry.Level <= r.threshold {
+		if buf != nil && entry.Level <= logrus.ErrorLevel {
+			buf.replay(entry)
+		}
+		return false
+	}
+	if entry.Context != nil && entry.Context.Value(requestReplayKey{}) != nil {
+		return false
+	}
+	if buf != nil {
+		buf.add(entry)
+	}
+	return true
+}
+
+func requestBufferFrom(ctx context.Context) *requestBuffer {
+	if ctx == nil {
+		return nil
+	}
+	buf, _ := ctx.Value(requestBufferKey{}).(*requestBuffer)
+	return buf
+}
+
+func (b *requestBuffer) add(entry *logrus.Entry) {
+	data := make(Fields, len(entry.Data))
+	for k, v := range entry.Data {
+		data[k] = v
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.entries) == b.limit {
+		b.entries = append(b.entries[:0], b.entries[1:]...)
+	}
+	b.entries = append(b.entries, bufferedEntry{
+		time:    entry.Time,
+		level:   entry.Level,
+		message: entry.Message,
+		data:    data,
+	})
+}
+
+// replay logs the held entries ahead of the error entry that triggered it and
+// empties the buffer
+func (b *requestBuffer) replay(entry *logrus.Entry) {
+	b.mu.Lock()
+	entries := b.entries
+	b.entries = nil
+	b.mu.Unlock()
+
+	if len(entries) == 0 {
+		return
+	}
+	ctx := context.WithValue(entry.Context, requestReplayKey{}, true)
+	for _, e := range entries {
+		replayed := entry.Logger.WithContext(ctx).WithFields(e.data)
+		replayed.Time = e.time
+		replayed.Log(e.level, e.message)
+	}
+}
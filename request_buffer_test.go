@@ -0,0 +1,38 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRequestBuffering(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := NewLogger(
+		WithOutput(&buf),
+		WithFormatter(&PlainFormatter{}),
+		WithRequestBuffering("debug"),
+	)
+	require.NoError(t, err)
+
+	ok := logger.WithContext(ContextWithRequestBuffer(context.Background(), 0))
+	ok.Debug("cache miss")
+	ok.Info("request served")
+	assert.Equal(t, "INFO request served\n", buf.String())
+
+	buf.Reset()
+	failed := logger.WithContext(ContextWithRequestBuffer(context.Background(), 2))
+	failed.Debug("dropped")
+	failed.Debug("cache miss")
+	failed.WithField("attempt", 2).Debug("retrying")
+	failed.Error("request failed")
+	assert.Equal(t, "DEBUG cache miss\nDEBUG retrying attempt=2\nERROR request failed\n", buf.String())
+
+	buf.Reset()
+	logger.Debug("no request buffer")
+	logger.Error("no request buffer")
+	assert.Equal(t, "ERROR no request buffer\n", buf.String())
+}
@@ -0,0 +1,119 @@
+package logger
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+const (
+	RequestIDKey           = "request_id"
+	DefaultRequestIDHeader = "X-Request-ID"
+
+	// maxRequestIDLength bounds the request ids accepted from headers
+	maxRequestIDLength = 128
+)
+
+// requestIDKey is the context key of the request id
+type requestIDKey struct{}
+
+// requestIDSlotKey is the context key of the requestIDSlot set by HTTPMiddleware
+type requestIDSlotKey struct{}
+
+// requestIDSlot receives the request id from a RequestIDMiddleware wrapped by
+// HTTPMiddleware, whose request context doesn't carry it
+type requestIDSlot struct {
+	id string
+}
+
+// requestIDOf returns the request id of r, carried by its context or reported
+// by a RequestIDMiddleware it was passed to
+func requestIDOf(r *http.Request) string {
+	if id := RequestIDFromContext(r.Context()); id != "" {
+		return id
+	}
+	if slot, ok := r.Context().Value(requestIDSlotKey{}).(*requestIDSlot); ok {
+		return slot.id
+	}
+	return ""
+}
+
+// NewRequestID returns a new random request id, a UUID
+func NewRequestID() string {
+	return uuid.NewString()
+}
+
+// ContextWithRequestID returns a copy of ctx carrying the request id
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request id carried by ctx, empty when there
+// is none
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// RequestIDFields is a ContextExtractor adding the request id carried by the
+// context under RequestIDKey, for WithContextExtractor
+func RequestIDFields(ctx context.Context) Fields {
+	if id := RequestIDFromContext(ctx); id != "" {
+		return Fields{RequestIDKey: id}
+	}
+	return nil
+}
+
+// RequestIDMiddleware returns net/http middleware storing the request id of
+// every request in its context, read from header (DefaultRequestIDHeader when
+// empty) or generated when missing or malformed, and echoing it in the response
+// header. Loggers retrieved with FromContext carry it under RequestIDKey, and
+// HTTPMiddleware adds it to the access entry whichever middleware wraps the
+// other.
+func RequestIDMiddleware(header string) func(http.Handler) http.Handler {
+	if header == "" {
+		header = DefaultRequestIDHeader
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(header)
+			if !validRequestID(id) {
+				id = NewRequestID()
+			}
+			w.Header().Set(header, id)
+			if slot, ok := r.Context().Value(requestIDSlotKey{}).(*requestIDSlot); ok {
+				slot.id = id
+			}
+			ctx := ContextWithRequestID(r.Context(), id)
+			ctx = NewContext(ctx, FromContext(ctx).WithFieldsMap(Fields{RequestIDKey: id}))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// InjectRequestID sets header (DefaultRequestIDHeader when empty) of an
+// outgoing request to the request id carried by its context, propagating it to
+// downstream services
+func InjectRequestID(req *http.Request, header string) {
+	if header == "" {
+		header = DefaultRequestIDHeader
+	}
+	if id := RequestIDFromContext(req.Context()); id != "" {
+		req.Header.Set(header, id)
+	}
+}
+
+// validRequestID reports whether id can be logged as is: not empty, bounded
+// and made of printable ASCII, so clients can't inject lines or escape codes
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,106 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := NewLogger(WithOutput(&buf), WithFormatter(&logrus.JSONFormatter{}), WithContextExtractor(RequestIDFields))
+	require.NoError(t, err)
+
+	var outgoing string
+	handler := RequestIDMiddleware("")(HTTPMiddleware(logger, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		FromContext(r.Context()).Info("handling")
+		req := httptest.NewRequest(http.MethodGet, "http://downstream/", nil).WithContext(r.Context())
+		InjectRequestID(req, "")
+		outgoing = req.Header.Get(DefaultRequestIDHeader)
+	})))
+
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{name: "propagated", incoming: "abc-123", keep: true},
+		{name: "missing"},
+		{name: "malformed", incoming: "bad\nid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.incoming != "" {
+				req.Header.Set(DefaultRequestIDHeader, tt.incoming)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			id := rec.Header().Get(DefaultRequestIDHeader)
+			if tt.keep {
+				assert.Equal(t, tt.incoming, id)
+			} else {
+				assert.Len(t, id, 36)
+			}
+			assert.Equal(t, id, outgoing)
+
+			// the handler entry and the access entry
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			require.Len(t, lines, 2)
+			for _, line := range lines {
+				var entry map[string]interface{}
+				require.NoError(t, json.Unmarshal([]byte(line), &entry))
+				assert.Equal(t, id, entry[RequestIDKey])
+			}
+		})
+	}
+}
+
+func TestRequestIDMiddleware_Order(t *testing.T) {
+	var buf bytes.Buffer
+	// no RequestIDFields extractor, the middleware attaches the id itself
+	logger, err := NewLogger(WithOutput(&buf), WithFormatter(&logrus.JSONFormatter{}))
+	require.NoError(t, err)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		FromContext(r.Context()).Info("handling")
+	})
+	chains := map[string]http.Handler{
+		"request id outside": RequestIDMiddleware("")(HTTPMiddleware(logger, nil)(handler)),
+		"request id inside":  HTTPMiddleware(logger, nil)(RequestIDMiddleware("")(handler)),
+	}
+	for name, chain := range chains {
+		t.Run(name, func(t *testing.T) {
+			buf.Reset()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(DefaultRequestIDHeader, "abc-123")
+			chain.ServeHTTP(httptest.NewRecorder(), req)
+
+			// the handler entry and the access entry
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			require.Len(t, lines, 2)
+			for _, line := range lines {
+				var entry map[string]interface{}
+				require.NoError(t, json.Unmarshal([]byte(line), &entry))
+				assert.Equal(t, "abc-123", entry[RequestIDKey], line)
+			}
+		})
+	}
+}
+
+func TestRequestIDFields(t *testing.T) {
+	assert.Nil(t, RequestIDFields(context.Background()))
+	ctx := ContextWithRequestID(context.Background(), "r-1")
+	assert.Equal(t, Fields{RequestIDKey: "r-1"}, RequestIDFields(ctx))
+	assert.NotEqual(t, NewRequestID(), NewRequestID())
+}
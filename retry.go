@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"math/rand"
+	"time"
+)
+
+const (
+	DefaultRetryMinBackoff = 500 * time.Millisecond
+	DefaultRetryMaxBackoff = 30 * time.Second
+	DefaultRetryJitter     = 0.2
+)
+
+// RetryPolicy configures how the network sinks (Loki, Datadog, Fluentd, the HTTP
+// shipper, webhooks, the network writer and the backend packages) retry a
+// delivery failing with a transient error, such as a network error, 429 or 5xx
+// status. Zero values select the defaults.
+type RetryPolicy struct {
+	// MaxAttempts bounds the deliveries of a batch, the first included. Sinks
+	// default it from their MaxRetries setting.
+	MaxAttempts int
+	// MinBackoff is the delay before the first retry, doubled on every further
+	// retry up to MaxBackoff
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// Jitter randomizes every delay by up to the given fraction (0.2 stands for
+	// ±20%) so sinks don't retry in lockstep; negative disables it
+	Jitter float64
+	// OnFailure is called with the batches that couldn't be delivered, either
+	// because the attempts were exhausted or the error wasn't retryable
+	OnFailure func(FailedDelivery)
+}
+
+// FailedDelivery describes a batch a sink gave up delivering
+type FailedDelivery struct {
+	Sink     string // e.g. "loki", "datadog"
+	Entries  int    // number of entries in the batch
+	Payload  []byte // encoded request body
+	Attempts int
+	Err      error
+}
+
+// retryPolicy returns the policy of a sink, cfg when set and otherwise one
+// derived from its legacy retry settings, with the defaults applied
+func retryPolicy(cfg *RetryPolicy, legacy RetryPolicy) RetryPolicy {
+	p := legacy
+	if cfg != nil {
+		p = *cfg
+	}
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = 1
+	}
+	if p.MinBackoff <= 0 {
+		p.MinBackoff = DefaultRetryMinBackoff
+	}
+	if p.MaxBackoff < p.MinBackoff {
+		p.MaxBackoff = DefaultRetryMaxBackoff
+	}
+	if p.Jitter == 0 {
+		p.Jitter = DefaultRetryJitter
+	} else if p.Jitter > 1 {
+		p.Jitter = 1
+	}
+	return p
+}
+
+// Deliver sends payload until send succeeds, reports a permanent error or the
+// attempts are exhausted, reporting failed batches to OnFailure. send returns
+// whether its error is worth retrying. Once stop is closed the remaining
+// attempts are made without waiting so shutdown isn't delayed.
+func (p RetryPolicy) Deliver(sink string, entries int, payload []byte, stop <-chan struct{}, send func([]byte) (bool, error)) error {
+	p = retryPolicy(&p, RetryPolicy{})
+	backoff := p.MinBackoff
+	for attempt := 1; ; attempt++ {
+		retry, err := send(payload)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= p.MaxAttempts {
+			if p.OnFailure != nil {
+				p.OnFailure(FailedDelivery{Sink: sink, Entries: entries, Payload: payload, Attempts: attempt, Err: err})
+			}
+			return err
+		}
+		select {
+		case <-time.After(p.jittered(backoff)):
+		case <-stop:
+		}
+		if backoff *= 2; backoff > p.MaxBackoff {
+			backoff = p.MaxBackoff
+		}
+	}
+}
+
+// jittered randomizes d by up to ±Jitter
+func (p RetryPolicy) jittered(d time.Duration) time.Duration {
+	if p.Jitter <= 0 {
+		return d
+	}
+	return d + time.Duration((rand.Float64()*2-1)*p.Jitter*float64(d))
+}
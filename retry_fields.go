@@ -0,0 +1,25 @@
+package logger
+
+// Standard field keys used to correlate retries across services
+const (
+	AttemptKey        = "attempt"
+	MaxAttemptsKey    = "max_attempts"
+	IdempotencyKeyKey = "idempotency_key"
+)
+
+// Attempt returns a logger stamping entries with the attempt number n and, when
+// max is positive, the max_attempts allowed, so the entries of a retry loop can
+// be grouped
+func (l *Logger) Attempt(n, max int) *Logger {
+	fields := Fields{AttemptKey: n}
+	if max > 0 {
+		fields[MaxAttemptsKey] = max
+	}
+	return &Logger{Entry: l.Entry.WithFields(fields)}
+}
+
+// WithIdempotencyKey returns a logger stamping entries with the idempotency key
+// of the operation being performed or retried
+func (l *Logger) WithIdempotencyKey(key string) *Logger {
+	return &Logger{Entry: l.Entry.WithField(IdempotencyKeyKey, key)}
+}
@@ -0,0 +1,24 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttemptAndIdempotencyKey(t *testing.T) {
+	logger, err := NewLogger(WithNullOutput(), WithLastEntriesCapture(10))
+	require.NoError(t, err)
+
+	op := logger.WithIdempotencyKey("charge-7")
+	for n := 1; n <= 2; n++ {
+		op.Attempt(n, 3).Warn("charge failed, retrying")
+	}
+	logger.Attempt(1, 0).Info("no limit")
+
+	crumbs := logger.Breadcrumbs(0)
+	require.Len(t, crumbs, 3)
+	assert.Equal(t, Fields{IdempotencyKeyKey: "charge-7", AttemptKey: 2, MaxAttemptsKey: 3}, crumbs[1].Data)
+	assert.Equal(t, Fields{AttemptKey: 1}, crumbs[2].Data)
+}
@@ -0,0 +1,104 @@
+package logger
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryPolicy_Deliver(t *testing.T) {
+	policy := retryPolicy(&RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond}, RetryPolicy{})
+
+	t.Run("succeeds after transient errors", func(t *testing.T) {
+		calls := 0
+		err := policy.Deliver("test", 1, []byte("x"), nil, func([]byte) (bool, error) {
+			if calls++; calls < 3 {
+				return true, errors.New("unavailable")
+			}
+			return false, nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("reports exhausted attempts", func(t *testing.T) {
+		var failed []FailedDelivery
+		p := policy
+		p.OnFailure = func(f FailedDelivery) { failed = append(failed, f) }
+		err := p.Deliver("test", 2, []byte("payload"), nil, func([]byte) (bool, error) {
+			return true, errors.New("unavailable")
+		})
+		assert.EqualError(t, err, "unavailable")
+		require.Len(t, failed, 1)
+		assert.Equal(t, FailedDelivery{Sink: "test", Entries: 2, Payload: []byte("payload"), Attempts: 3, Err: err}, failed[0])
+	})
+
+	t.Run("stops on permanent errors", func(t *testing.T) {
+		calls := 0
+		err := policy.Deliver("test", 1, nil, nil, func([]byte) (bool, error) {
+			calls++
+			return false, errors.New("bad request")
+		})
+		assert.Error(t, err)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestRetryPolicy_Jitter(t *testing.T) {
+	p := retryPolicy(&RetryPolicy{Jitter: 0.5}, RetryPolicy{})
+	for i := 0; i < 100; i++ {
+		d := p.jittered(time.Second)
+		assert.GreaterOrEqual(t, d, 500*time.Millisecond)
+		assert.LessOrEqual(t, d, 1500*time.Millisecond)
+	}
+	p.Jitter = -1
+	assert.Equal(t, time.Second, p.jittered(time.Second))
+}
+
+func TestHTTPShipperHook_RetryPolicy(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	var (
+		mu     sync.Mutex
+		failed []FailedDelivery
+	)
+	hook, err := NewHTTPShipperHook(&HTTPShipperConfig{
+		URL:           srv.URL,
+		FlushInterval: time.Hour,
+		Retry: &RetryPolicy{
+			MaxAttempts: 2,
+			MinBackoff:  time.Millisecond,
+			OnFailure: func(f FailedDelivery) {
+				mu.Lock()
+				failed = append(failed, f)
+				mu.Unlock()
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	logger, err := NewLogger(WithNullOutput())
+	require.NoError(t, err)
+	logger.Logger.AddHook(hook)
+	logger.Info("lost")
+	require.NoError(t, hook.Close())
+
+	assert.Equal(t, int32(2), requests.Load())
+	require.Len(t, failed, 1)
+	assert.Equal(t, "http", failed[0].Sink)
+	assert.Equal(t, 1, failed[0].Entries)
+	assert.Contains(t, string(failed[0].Payload), `"msg":"lost"`)
+	assert.EqualError(t, failed[0].Err, "http shipper: request failed with status 503")
+}
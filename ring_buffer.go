@@ -0,0 +1,157 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultRingBufferSize is the number of entries kept by the ring buffer when no
+// size is configured
+const DefaultRingBufferSize = 1000
+
+// RingBufferConfig holds configuration for the ring buffer
+type RingBufferConfig struct {
+	Size int // number of entries kept, defaults to DefaultRingBufferSize
+	// DumpTo receives the recent entries when a fatal or panic entry is logged,
+	// defaults to os.Stderr
+	DumpTo io.Writer
+	// DisableDump turns off the dump on fatal and panic entries
+	DisableDump bool
+}
+
+// ringBufferRule implements Rule keeping the last entries of every level,
+// including the ones below the logger level, which it suppresses
+type ringBufferRule struct {
+	cfg       RingBufferConfig
+	threshold logrus.Level
+	entries   []bufferedEntry
+	next      int
+	full      bool
+	mu        sync.Mutex
+}
+
+// WithRingBuffer keeps the last entries of every level in memory, even those
+// below the logger level, so they can be written with Logger.DumpRecent for
+// post-mortem context and are dumped automatically when a fatal or panic entry
+// is logged. The logger level is lowered to trace so verbose entries are
+// created, and entries below the level set so far are kept but not output, so
+// apply this option after WithLevel.
+func WithRingBuffer(cfg *RingBufferConfig) Option {
+	return func(l *Logger) error {
+		c := RingBufferConfig{}
+		if cfg != nil {
+			c = *cfg
+		}
+		if c.Size <= 0 {
+			c.Size = DefaultRingBufferSize
+		}
+		if c.DumpTo == nil {
+			c.DumpTo = os.Stderr
+		}
+		rule := &ringBufferRule{
+			cfg:       c,
+			threshold: l.Entry.Logger.GetLevel(),
+			entries:   make([]bufferedEntry, c.Size),
+		}
+		l.Entry.Logger.SetLevel(logrus.TraceLevel)
+		return WithRules(rule)(l)
+	}
+}
+
+// Apply keeps the entry, suppressing it when it is below the logger level the
+// rule was added with
+func (r *ringBufferRule) Apply(entry *logrus.Entry) bool {
+	if entry.Level <= logrus.FatalLevel && !r.cfg.DisableDump {
+		fmt.Fprintln(r.cfg.DumpTo, "logger: recent entries")
+		if err := r.dump(entry.Logger, r.cfg.DumpTo); err != nil {
+			fmt.Fprintf(os.Stderr, "logger: dumping recent entries: %v\n", err)
+		}
+	}
+	r.add(entry)
+	return entry.Level > r.threshold
+}
+
+func (r *ringBufferRule) add(entry *logrus.Entry) {
+	data := make(Fields, len(entry.Data))
+	for k, v := range entry.Data {
+		data[k] = v
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries[r.next] = bufferedEntry{
+		time:    entry.Time,
+		level:   entry.Level,
+		message: entry.Message,
+		data:    data,
+	}
+	r.next = (r.next + 1) % len(r.entries)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// snapshot returns the kept entries, oldest first
+func (r *ringBufferRule) snapshot() []bufferedEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.full {
+		return append([]bufferedEntry(nil), r.entries[:r.next]...)
+	}
+	out := make([]bufferedEntry, 0, len(r.entries))
+	out = append(out, r.entries[r.next:]...)
+	return append(out, r.entries[:r.next]...)
+}
+
+// dump renders the kept entries with the formatter of l into w
+func (r *ringBufferRule) dump(l *logrus.Logger, w io.Writer) error {
+	for _, e := range r.snapshot() {
+		entry := logrus.NewEntry(l)
+		entry.Time = e.time
+		entry.Level = e.level
+		entry.Message = e.message
+		entry.Data = e.data
+		line, err := l.Formatter.Format(entry)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DumpRecent writes the entries kept by the ring buffer to w, oldest first,
+// rendered with the logger's formatter. It does nothing unless the logger was
+// created with WithRingBuffer.
+func (l *Logger) DumpRecent(w io.Writer) error {
+	hook, ok := findHook[*rulesHook](l.Entry.Logger)
+	if !ok {
+		return nil
+	}
+	hook.mu.RLock()
+	var ring *ringBufferRule
+	for _, rule := range hook.rules {
+		if r, ok := rule.(*ringBufferRule); ok {
+			ring = r
+			break
+		}
+	}
+	hook.mu.RUnlock()
+	if ring == nil {
+		return nil
+	}
+	return ring.dump(l.Entry.Logger, w)
+}
+
+// DumpRecent writes the entries kept by the ring buffer of the global logger to
+// w, see Logger.DumpRecent
+func DumpRecent(w io.Writer) error {
+	return globalLogger().DumpRecent(w)
+}
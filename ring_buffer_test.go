@@ -0,0 +1,45 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRingBuffer(t *testing.T) {
+	var out, dump bytes.Buffer
+	var exitCode int
+	logger, err := NewLogger(
+		WithOutput(&out),
+		WithFormatter(&PlainFormatter{}),
+		WithExitFunc(func(code int) { exitCode = code }),
+		WithRingBuffer(&RingBufferConfig{Size: 3, DumpTo: &dump}),
+	)
+	require.NoError(t, err)
+
+	logger.Trace("dropped")
+	logger.Debug("cache miss")
+	logger.WithField("attempt", 2).Debug("retrying")
+	logger.Info("request served")
+	assert.Equal(t, "INFO request served\n", out.String())
+
+	var recent bytes.Buffer
+	require.NoError(t, logger.DumpRecent(&recent))
+	assert.Equal(t, "DEBUG cache miss\nDEBUG retrying attempt=2\nINFO request served\n", recent.String())
+
+	logger.Fatal("out of memory")
+	assert.Equal(t, 1, exitCode)
+	assert.Equal(t, "logger: recent entries\nDEBUG cache miss\nDEBUG retrying attempt=2\nINFO request served\n", dump.String())
+	assert.Equal(t, "INFO request served\nFATAL out of memory\n", out.String())
+}
+
+func TestDumpRecentWithoutRingBuffer(t *testing.T) {
+	logger, err := NewLogger(WithNullOutput())
+	require.NoError(t, err)
+
+	var recent bytes.Buffer
+	require.NoError(t, logger.DumpRecent(&recent))
+	assert.Empty(t, recent.String())
+}
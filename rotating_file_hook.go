@@ -1,7 +1,13 @@
 package logger
 
 import (
+	"compress/gzip"
+	"crypto/cipher"
+	"fmt"
+	"io"
+	"os"
 	"sync"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"gopkg.in/natefinch/lumberjack.v2"
@@ -16,19 +22,75 @@ const (
 // RotatingFileHook implements logrus.Hook interface with log rotation support
 type rotatingFileHook struct {
 	config    *lumberjack.Logger
+	writer    io.Writer
+	batch     *batchWriter // set when entries are batched
+	watcher   *rotationWatcher
+	archiver  *archiver       // set when backups are compressed, encrypted, recorded, uploaded or size capped
+	fallback  *fallbackWriter // set when failed writes are mirrored
 	formatter logrus.Formatter
 	levels    []logrus.Level
+	stripANSI bool // remove ANSI escape sequences from the formatted entries
 	mu        sync.Mutex
 }
 
+// LevelMode selects how the Levels of a RotatingFileConfig are matched
+type LevelMode int
+
+const (
+	// LevelExactMatch logs the configured levels only, e.g. to route errors to a
+	// file of their own
+	LevelExactMatch LevelMode = iota
+	// LevelThreshold logs the configured levels and every more severe one, e.g.
+	// warn and up for []logrus.Level{logrus.WarnLevel}
+	LevelThreshold
+)
+
 // RotatingFileConfig holds configuration for log rotation
 type RotatingFileConfig struct {
 	Filename   string
 	MaxSize    int  // megabytes
 	MaxBackups int  // number of backups
 	MaxAge     int  // days
-	Compress   bool // compress rotated files
-	Levels     []logrus.Level
+	Compress   bool // compress rotated files with gzip
+	// Compressor compresses rotated files on a background worker instead,
+	// e.g. ZstdCompressor(3). It takes precedence over Compress.
+	Compressor Compressor
+	// MaxTotalSize caps the combined bytes of the current and rotated files,
+	// deleting the oldest backups on rotation regardless of MaxBackups and
+	// MaxAge. The current file is counted at MaxSize, so the cap holds between
+	// rotations; it must be at least MaxSize.
+	MaxTotalSize int64
+	// EncryptionKey encrypts rotated files with AES-GCM on the background
+	// worker, after compression, adding EncryptedExtension to their name. It
+	// is 16, 24 or 32 bytes long; ReadArchive reads the files back.
+	EncryptionKey []byte
+	// Upload uploads rotated files to an object store on the background
+	// worker, once compressed and encrypted
+	Upload *UploadConfig
+	// Manifest records the SHA-256 checksum of every rotated file, once
+	// compressed and encrypted, in Filename+ManifestExtension, so archives can
+	// be checked with VerifyManifest. ManifestKey adds an HMAC-SHA256 of every
+	// file, so a manifest rewritten along with the archives is detected too.
+	Manifest    bool
+	ManifestKey []byte
+	// Fallback mirrors the writes failing on the file, e.g. on ENOSPC, to
+	// stderr or another writer, see WriteFallbackConfig
+	Fallback *WriteFallbackConfig
+	Checksum bool // append a CRC-32C checksum to every line
+	// BatchSize enables batching: entries are written to the file every
+	// BatchSize entries or FlushInterval (DefaultBatchWriterFlushInterval when
+	// zero), and when the hook is closed
+	BatchSize     int
+	FlushInterval time.Duration
+	// Formatter renders the entries written to the file, defaults to a
+	// TextFormatter without colors, e.g. &logrus.JSONFormatter{} to write JSON
+	// while the console stays colored text
+	Formatter logrus.Formatter
+	// StripANSI removes ANSI escape sequences from the entries, so the file
+	// never receives color codes, e.g. from a ColorFormatter or in messages
+	StripANSI bool
+	Levels    []logrus.Level
+	LevelMode LevelMode
 }
 
 // NewRotatingFileHook creates a new hook with log rotation support
@@ -53,6 +115,38 @@ func newRotatingFileHook(cfg *RotatingFileConfig) (*rotatingFileHook, error) {
 	if len(cfg.Levels) == 0 {
 		cfg.Levels = logrus.AllLevels
 	}
+	levels := cfg.Levels
+	if cfg.LevelMode == LevelThreshold {
+		levels = thresholdLevels(cfg.Levels)
+	}
+	if cfg.MaxTotalSize > 0 && cfg.MaxTotalSize < int64(cfg.MaxSize)*1024*1024 {
+		return nil, fmt.Errorf("rotating file: MaxTotalSize %d is below MaxSize of %dMB", cfg.MaxTotalSize, cfg.MaxSize)
+	}
+
+	compressor := cfg.Compressor
+	var aead cipher.AEAD
+	if cfg.EncryptionKey != nil {
+		var err error
+		if aead, err = newArchiveCipher(cfg.EncryptionKey); err != nil {
+			return nil, err
+		}
+	}
+	var upload *uploader
+	if cfg.Upload != nil {
+		var err error
+		if upload, err = newUploader(cfg.Upload); err != nil {
+			return nil, err
+		}
+	}
+	var sums *manifest
+	if cfg.Manifest || cfg.ManifestKey != nil {
+		sums = newManifest(cfg.Filename, cfg.ManifestKey)
+	}
+	if compressor == nil && cfg.Compress && (aead != nil || upload != nil || sums != nil) {
+		// the archiver compresses the backups before they are encrypted,
+		// recorded or uploaded, rather than lumberjack on its own schedule
+		compressor = &gzipCompressor{level: gzip.DefaultCompression}
+	}
 
 	hook := &rotatingFileHook{
 		config: &lumberjack.Logger{
@@ -60,13 +154,43 @@ func newRotatingFileHook(cfg *RotatingFileConfig) (*rotatingFileHook, error) {
 			MaxSize:    cfg.MaxSize,
 			MaxBackups: cfg.MaxBackups,
 			MaxAge:     cfg.MaxAge,
-			Compress:   cfg.Compress,
+			Compress:   cfg.Compress && compressor == nil,
 		},
-		formatter: &logrus.TextFormatter{
+		formatter: cfg.Formatter,
+		levels:    levels,
+		stripANSI: cfg.StripANSI,
+	}
+	if hook.formatter == nil {
+		hook.formatter = &logrus.TextFormatter{
 			DisableColors: true,
 			FullTimestamp: true,
-		},
-		levels: cfg.Levels,
+		}
+	}
+	hook.writer = hook.config
+	if compressor != nil || aead != nil || upload != nil || sums != nil || cfg.MaxTotalSize > 0 {
+		hook.archiver = (&archiver{
+			compressor: compressor,
+			aead:       aead,
+			uploader:   upload,
+			manifest:   sums,
+			maxTotal:   cfg.MaxTotalSize,
+			onError: func(err error) {
+				fmt.Fprintf(os.Stderr, "logger: archiving %s: %v\n", cfg.Filename, err)
+			},
+		}).start(hook.config)
+		hook.watcher = &rotationWatcher{file: hook.config, onRotate: hook.archiver.rotated}
+		hook.writer = hook.watcher
+	}
+	if cfg.Fallback != nil {
+		hook.fallback = newFallbackWriter(hook.writer, cfg.Filename, newWriteFallbackConfig(cfg.Fallback))
+		hook.writer = hook.fallback
+	}
+	if cfg.BatchSize > 0 {
+		hook.batch = NewBatchWriter(hook.writer, cfg.BatchSize, cfg.FlushInterval)
+		hook.writer = hook.batch
+	}
+	if cfg.Checksum {
+		hook.writer = NewChecksumWriter(hook.writer)
 	}
 
 	return hook, nil
@@ -74,11 +198,6 @@ func newRotatingFileHook(cfg *RotatingFileConfig) (*rotatingFileHook, error) {
 
 // Fire writes the log entry to the file
 func (h *rotatingFileHook) Fire(entry *logrus.Entry) error {
-	// First check if entry.Level is within the threshold levels
-	if !h.shouldLog(entry.Level) {
-		return nil
-	}
-
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
@@ -86,25 +205,47 @@ func (h *rotatingFileHook) Fire(entry *logrus.Entry) error {
 	if err != nil {
 		return err
 	}
+	if h.stripANSI {
+		line = stripANSI(line)
+	}
 
-	_, err = h.config.Write(line)
+	_, err = h.writer.Write(line)
 	return err
 }
 
-// shouldLog checks if the log level should be logged
-func (h *rotatingFileHook) shouldLog(level logrus.Level) bool {
-	if len(h.levels) == 0 {
-		return true
-	}
+// SetFormatter sets the formatter rendering the entries written to the file
+func (h *rotatingFileHook) SetFormatter(formatter logrus.Formatter) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.formatter = formatter
+}
 
-	minLevel := logrus.PanicLevel
-	for _, l := range h.levels {
-		if l < minLevel {
-			minLevel = l
+// SetFileFormatter sets the formatter of the rotating file hooks of the logger,
+// leaving the formatter of its output alone
+func (l *Logger) SetFileFormatter(formatter logrus.Formatter) {
+	for _, hook := range allHooks(l.Entry.Logger) {
+		if h, ok := hook.(*rotatingFileHook); ok {
+			h.SetFormatter(formatter)
 		}
 	}
+}
 
-	return level >= minLevel
+// thresholdLevels returns the levels at least as severe as the least severe
+// of levels
+func thresholdLevels(levels []logrus.Level) []logrus.Level {
+	least := logrus.PanicLevel
+	for _, l := range levels {
+		if l > least {
+			least = l
+		}
+	}
+	var threshold []logrus.Level
+	for _, l := range logrus.AllLevels {
+		if l <= least {
+			threshold = append(threshold, l)
+		}
+	}
+	return threshold
 }
 
 // Levels returns the levels this hook should be fired for
@@ -112,7 +253,159 @@ func (h *rotatingFileHook) Levels() []logrus.Level {
 	return h.levels
 }
 
-// Close implements io.Closer
-func (h *rotatingFileHook) Close() error {
+// writeFailures returns the number of failed writes on the file
+func (h *rotatingFileHook) writeFailures() (string, uint64) {
+	if h.fallback == nil {
+		return h.config.Filename, 0
+	}
+	return h.config.Filename, h.fallback.Failures()
+}
+
+// Reopen flushes the batched entries and closes the file, which is opened
+// again at its path on the next write, see Logger.Reopen
+func (h *rotatingFileHook) Reopen() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.batch != nil {
+		if err := h.batch.Flush(); err != nil {
+			return err
+		}
+	}
+	if h.watcher != nil {
+		return h.watcher.reopen()
+	}
 	return h.config.Close()
 }
+
+// Close implements io.Closer, flushing batched entries first and waiting for
+// the pending backups to be compressed
+func (h *rotatingFileHook) Close() error {
+	var err error
+	if c, ok := h.writer.(io.Closer); ok {
+		err = c.Close()
+	} else {
+		err = h.config.Close()
+	}
+	if h.archiver != nil {
+		h.archiver.close()
+	}
+	return err
+}
+
+// lumberjack names backups <name>-<timestamp><ext>
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// FileStats describes the state of a rotated log file
+type FileStats struct {
+	Filename string
+	Size     int64 // bytes in the current file
+	Backups  int   // rotated files kept, compressed or not
+	// LastRotation is the time of the most recent backup, zero when the file
+	// was never rotated
+	LastRotation time.Time
+	// CompressionBacklog is the number of backups waiting to be compressed
+	CompressionBacklog int
+}
+
+// stats returns the state of the file written by the hook
+func (h *rotatingFileHook) stats() FileStats {
+	return lumberjackStats(h.config, h.config.Compress || h.archiver != nil && h.archiver.transforms())
+}
+
+// rotate flushes the batched entries and starts a new file
+func (h *rotatingFileHook) rotate() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.batch != nil {
+		if err := h.batch.Flush(); err != nil {
+			return err
+		}
+	}
+	if h.watcher != nil {
+		return h.watcher.rotate()
+	}
+	return h.config.Rotate()
+}
+
+// lumberjackStats reads the state of the files of l from disk. Uncompressed
+// backups are counted as backlog when compress is set.
+func lumberjackStats(l *lumberjack.Logger, compress bool) FileStats {
+	s := FileStats{Filename: l.Filename}
+	if info, err := os.Stat(l.Filename); err == nil {
+		s.Size = info.Size()
+	}
+	for _, b := range listBackups(l) {
+		s.Backups++
+		if b.time.After(s.LastRotation) {
+			s.LastRotation = b.time
+		}
+		if compress && !b.compressed {
+			s.CompressionBacklog++
+		}
+	}
+	return s
+}
+
+// rotationWatcher mirrors the size accounting of a lumberjack logger to tell
+// when a write rotates the file
+type rotationWatcher struct {
+	file     *lumberjack.Logger
+	onRotate func()
+
+	mu     sync.Mutex
+	size   int64
+	opened bool
+}
+
+func (w *rotationWatcher) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	max := int64(w.file.MaxSize) * 1024 * 1024
+	if max == 0 {
+		max = DefaultMaxSize * 1024 * 1024
+	}
+	rotates := false
+	if !w.opened {
+		w.opened = true
+		if info, err := os.Stat(w.file.Filename); err == nil {
+			w.size = info.Size()
+			rotates = w.size+int64(len(p)) >= max
+		}
+	} else {
+		rotates = w.size+int64(len(p)) > max
+	}
+	if rotates {
+		w.size = 0
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	if rotates && err == nil {
+		w.onRotate()
+	}
+	return n, err
+}
+
+// rotate rotates the file now
+func (w *rotationWatcher) rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.file.Rotate(); err != nil {
+		return err
+	}
+	w.size, w.opened = 0, true
+	w.onRotate()
+	return nil
+}
+
+// reopen closes the file, its size is read again on the next write
+func (w *rotationWatcher) reopen() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.opened = false
+	return w.file.Close()
+}
+
+func (w *rotationWatcher) Close() error {
+	return w.file.Close()
+}
@@ -1,16 +1,20 @@
 package logger
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 func TestRotatingFileHook(t *testing.T) {
@@ -259,3 +263,97 @@ func TestRotatingFileHook_Levels(t *testing.T) {
 		})
 	}
 }
+
+func TestRotatingFileHook_LevelMode(t *testing.T) {
+	dir := t.TempDir()
+	levels := []logrus.Level{logrus.WarnLevel}
+
+	exact, err := newRotatingFileHook(&RotatingFileConfig{Filename: filepath.Join(dir, "exact.log"), Levels: levels})
+	require.NoError(t, err)
+	defer exact.Close()
+	assert.Equal(t, []logrus.Level{logrus.WarnLevel}, exact.Levels())
+
+	threshold, err := newRotatingFileHook(&RotatingFileConfig{Filename: filepath.Join(dir, "threshold.log"), Levels: levels, LevelMode: LevelThreshold})
+	require.NoError(t, err)
+	defer threshold.Close()
+	assert.Equal(t, []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel, logrus.WarnLevel}, threshold.Levels())
+}
+
+func TestRotatingFileHook_Formatter(t *testing.T) {
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "app.log")
+	hook, err := newRotatingFileHook(&RotatingFileConfig{Filename: logFile, Formatter: &logrus.JSONFormatter{}})
+	require.NoError(t, err)
+	defer hook.Close()
+
+	var console bytes.Buffer
+	logger, err := NewLogger(WithOutput(&console), WithFormatter(&PlainFormatter{}), WithHooks(hook))
+	require.NoError(t, err)
+
+	logger.Info("as json")
+	logger.SetFileFormatter(&PlainFormatter{})
+	logger.Info("as plain")
+
+	content, err := os.ReadFile(logFile)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	require.Len(t, lines, 2)
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+	assert.Equal(t, "as json", record["msg"])
+	assert.Equal(t, "INFO as plain", lines[1])
+	assert.Equal(t, "INFO as json\nINFO as plain\n", console.String())
+}
+
+func TestForceRotateAll(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+	hook, err := newRotatingFileHook(&RotatingFileConfig{Filename: filename, BatchSize: 100, FlushInterval: time.Hour})
+	require.NoError(t, err)
+	logger, err := NewLogger(WithNullOutput(), WithHooks(hook), WithStats())
+	require.NoError(t, err)
+	defer hook.Close()
+
+	logger.Info("before rotation")
+	stats, ok := logger.Stats()
+	require.True(t, ok)
+	require.Len(t, stats.Files, 1)
+	assert.Equal(t, filename, stats.Files[0].Filename)
+	assert.Zero(t, stats.Files[0].Backups)
+	assert.True(t, stats.Files[0].LastRotation.IsZero())
+
+	start := time.Now().Add(-time.Second)
+	require.NoError(t, logger.ForceRotateAll())
+	logger.Info("after rotation")
+	require.NoError(t, hook.batch.Flush())
+
+	stats, _ = logger.Stats()
+	file := stats.Files[0]
+	assert.Equal(t, 1, file.Backups)
+	assert.True(t, file.LastRotation.After(start), "last rotation %s", file.LastRotation)
+	assert.Zero(t, file.CompressionBacklog)
+
+	backups, err := filepath.Glob(filepath.Join(dir, "app-*.log"))
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	rotated, err := os.ReadFile(backups[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(rotated), "before rotation", "batched entries are flushed before rotating")
+	current, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Contains(t, string(current), "after rotation")
+	assert.Equal(t, int64(len(current)), file.Size)
+}
+
+func TestLumberjackStats_CompressionBacklog(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"app.log", "app-2024-01-02T03-04-05.000.log", "app-2024-01-01T03-04-05.000.log.gz", "other.log"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("x\n"), 0o644))
+	}
+
+	stats := lumberjackStats(&lumberjack.Logger{Filename: filepath.Join(dir, "app.log")}, true)
+	assert.Equal(t, 2, stats.Backups)
+	assert.Equal(t, 1, stats.CompressionBacklog)
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), stats.LastRotation)
+	assert.Equal(t, int64(2), stats.Size)
+}
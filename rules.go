@@ -0,0 +1,221 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Rule inspects entries before they reach hooks and the output. Rules are
+// evaluated in order; once a rule suppresses an entry the remaining rules are
+// skipped.
+type Rule interface {
+	// Apply reports whether the entry should be suppressed
+	Apply(entry *logrus.Entry) (suppress bool)
+}
+
+// RuleFunc adapts a function to the Rule interface
+type RuleFunc func(entry *logrus.Entry) bool
+
+func (f RuleFunc) Apply(entry *logrus.Entry) bool {
+	return f(entry)
+}
+
+// suppressedKey marks suppressed entries in their context
+type suppressedKey struct{}
+
+// isSuppressed reports whether a rule suppressed the entry
+func isSuppressed(entry *logrus.Entry) bool {
+	return entry.Context != nil && entry.Context.Value(suppressedKey{}) != nil
+}
+
+// suppressibleHook skips the entries suppressed by a rule, so the hook it wraps
+// never receives them
+type suppressibleHook struct {
+	logrus.Hook
+}
+
+func (h *suppressibleHook) Fire(entry *logrus.Entry) error {
+	if isSuppressed(entry) {
+		return nil
+	}
+	return h.Hook.Fire(entry)
+}
+
+// Unwrap returns the wrapped hook
+func (h *suppressibleHook) Unwrap() logrus.Hook {
+	return h.Hook
+}
+
+// filterSuppressed wraps the hooks of l in suppressibleHooks, whatever
+// registered them, wrapping a hook registered for several levels once. It runs
+// once the options have been applied, after the hooks are guarded and grouped
+// and before they move to the async worker.
+func filterSuppressed(l *logrus.Logger) {
+	// a slice rather than a map, as hooks may not be comparable
+	var wrapped []*suppressibleHook
+	wrap := func(hook logrus.Hook) *suppressibleHook {
+		for _, sh := range wrapped {
+			if containsHook([]logrus.Hook{sh.Hook}, hook) {
+				return sh
+			}
+		}
+		sh := &suppressibleHook{Hook: hook}
+		wrapped = append(wrapped, sh)
+		return sh
+	}
+	hooks := make(logrus.LevelHooks, len(l.Hooks))
+	for level, levelHooks := range l.Hooks {
+		for _, hook := range levelHooks {
+			_, filtered := hook.(*suppressibleHook)
+			// the rules see every entry to decide which to suppress
+			_, rules := unwrapHook(hook).(*rulesHook)
+			if !filtered && !rules {
+				hook = wrap(hook)
+			}
+			hooks[level] = append(hooks[level], hook)
+		}
+	}
+	l.ReplaceHooks(hooks)
+}
+
+// addHook registers hook on a logger already created, skipping the suppressed
+// entries like the hooks registered by options
+func addHook(l *logrus.Logger, hook logrus.Hook) {
+	l.AddHook(&suppressibleHook{Hook: hook})
+}
+
+// rulesHook implements logrus.Hook evaluating the logger's rules. It is
+// registered when the logger is created so it fires before any other hook.
+type rulesHook struct {
+	rules []Rule
+	mu    sync.RWMutex
+}
+
+func (h *rulesHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Enriches implements EnrichingHook, it marks suppressed entries
+func (h *rulesHook) Enriches() bool {
+	return true
+}
+
+// Fire marks the entry as suppressed when a rule says so
+func (h *rulesHook) Fire(entry *logrus.Entry) error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, rule := range h.rules {
+		if rule.Apply(entry) {
+			ctx := entry.Context
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			entry.Context = context.WithValue(ctx, suppressedKey{}, true)
+			return nil
+		}
+	}
+	return nil
+}
+
+// Flush flushes the rules buffering entries, e.g. logging the summaries of the
+// cancellation flood rule
+func (h *rulesHook) Flush() error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, rule := range h.rules {
+		if f, ok := rule.(interface{ Flush() }); ok {
+			f.Flush()
+		}
+	}
+	return nil
+}
+
+// Close closes the rules holding timers or goroutines
+func (h *rulesHook) Close() error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	var errs []error
+	for _, rule := range h.rules {
+		if c, ok := rule.(io.Closer); ok {
+			errs = append(errs, c.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// flushRules flushes the rules of l ahead of its sinks, so the entries they log
+// reach the sinks before these are flushed or closed
+func flushRules(l *logrus.Logger) {
+	for _, hook := range allHooks(l) {
+		if h, ok := hook.(*rulesHook); ok {
+			h.Flush()
+		}
+	}
+}
+
+func (h *rulesHook) active() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.rules) > 0
+}
+
+func (h *rulesHook) add(rules ...Rule) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.rules = append(h.rules, rules...)
+}
+
+// suppressingFormatter wraps the logger's formatter, rendering nothing for
+// suppressed entries
+type suppressingFormatter struct {
+	logrus.Formatter
+}
+
+func (f *suppressingFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	if isSuppressed(entry) {
+		return nil, nil
+	}
+	return f.Formatter.Format(entry)
+}
+
+// setFormatter sets the logger formatter, keeping rule suppression in place.
+// Once the async worker was started, it sets the formatter of the worker.
+func setFormatter(l *logrus.Logger, formatter logrus.Formatter) {
+	hook, ok := findHook[*rulesHook](l)
+	d, started := startedAsync(l)
+	if !ok && started {
+		hook, ok = findHook[*rulesHook](d.shadow)
+	}
+	if ok && hook.active() {
+		if _, wrapped := formatter.(*suppressingFormatter); !wrapped {
+			formatter = &suppressingFormatter{Formatter: formatter}
+		}
+	}
+	if !started {
+		l.SetFormatter(formatter)
+		return
+	}
+	d.shadowMu.Lock()
+	defer d.shadowMu.Unlock()
+	d.shadow.Formatter = formatter
+}
+
+// WithRules adds rules evaluated for every entry before it reaches hooks and the
+// output, e.g. to collapse floods of repetitive errors
+func WithRules(rules ...Rule) Option {
+	return func(l *Logger) error {
+		hook, ok := findHook[*rulesHook](l.Entry.Logger)
+		if !ok {
+			hook = &rulesHook{}
+			l.Entry.Logger.AddHook(hook)
+		}
+		hook.add(rules...)
+		setFormatter(l.Entry.Logger, l.Entry.Logger.Formatter)
+		return nil
+	}
+}
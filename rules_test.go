@@ -0,0 +1,154 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRules(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := NewLogger(
+		WithOutput(&buf),
+		WithRules(RuleFunc(func(entry *logrus.Entry) bool {
+			return entry.Data["health_check"] == true
+		})),
+		// formatters set after the rules still honor them
+		WithFormatter(&PlainFormatter{}),
+		WithLastEntriesCapture(10),
+	)
+	require.NoError(t, err)
+
+	logger.WithField("health_check", true).Info("GET /healthz")
+	logger.Info("GET /orders")
+
+	assert.Equal(t, "INFO GET /orders\n", buf.String())
+	assert.Equal(t, []string{"INFO GET /orders"}, logger.LastEntries())
+}
+
+func TestCancellationFloodRule(t *testing.T) {
+	var buf bytes.Buffer
+	rule := NewCancellationFloodRule(&CancellationFloodConfig{Window: time.Hour, Burst: 2})
+	logger, err := NewLogger(
+		WithOutput(&buf),
+		WithFormatter(&PlainFormatter{}),
+		WithRules(rule),
+	)
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		logger.WithError(fmt.Errorf("read request %d: %w", i, context.Canceled)).Error("request failed")
+	}
+	logger.Error("write tcp 10.0.0.1:443: connection reset by peer")
+	logger.Error("database unavailable")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 4)
+	assert.Contains(t, lines[0], "read request 0")
+	assert.Contains(t, lines[1], "read request 1")
+	assert.Contains(t, lines[2], "connection reset by peer")
+	assert.Contains(t, lines[3], "database unavailable")
+
+	buf.Reset()
+	rule.Flush()
+	assert.Equal(t,
+		"ERROR suppressed 8 entries matching \"context canceled\" flood_summary=true pattern=\"context canceled\" suppressed=8 window=1h0m0s\n",
+		buf.String())
+}
+
+func TestCancellationFloodRule_WindowExpiry(t *testing.T) {
+	var buf safeBuffer
+	level := logrus.WarnLevel
+	logger, err := NewLogger(
+		WithOutput(&buf),
+		WithFormatter(&PlainFormatter{}),
+		WithCancellationFloodControl(&CancellationFloodConfig{Window: 20 * time.Millisecond, SummaryLevel: &level}),
+	)
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		logger.Warn("client went away: broken pipe")
+	}
+
+	assert.Eventually(t, func() bool {
+		return strings.Contains(buf.String(), "WARNING suppressed 4 entries matching \"broken pipe\"")
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestCancellationFloodRule_FlushedOnClose(t *testing.T) {
+	var buf safeBuffer
+	level := logrus.PanicLevel
+	logger, err := NewLogger(
+		WithOutput(&buf),
+		WithFormatter(&PlainFormatter{}),
+		WithCancellationFloodControl(&CancellationFloodConfig{Window: time.Hour, SummaryLevel: &level}),
+	)
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		logger.Error("request failed: context canceled")
+	}
+	require.NoError(t, logger.Close())
+	assert.Contains(t, buf.String(), "ERROR suppressed 2 entries matching \"context canceled\"")
+
+	// the rule no longer opens windows
+	logger.Error("request failed: context canceled")
+	logger.Error("request failed: context canceled")
+	assert.Equal(t, 3, strings.Count(buf.String(), "ERROR request failed"))
+}
+
+// safeBuffer is a bytes.Buffer safe for concurrent use
+type safeBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *safeBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *safeBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestRules_UserHooksSkipSuppressed(t *testing.T) {
+	dropDebug := RuleFunc(func(e *logrus.Entry) bool { return e.Level == logrus.DebugLevel })
+	for name, build := range map[string]func(logrus.Hook) (*Logger, error){
+		"WithHooks": func(h logrus.Hook) (*Logger, error) {
+			return NewLogger(WithNullOutput(), WithHooks(h), WithRules(dropDebug))
+		},
+		"Builder": func(h logrus.Hook) (*Logger, error) {
+			return Builder().Output(io.Discard).AddHook(h).With(WithRules(dropDebug)).Build()
+		},
+		"async": func(h logrus.Hook) (*Logger, error) {
+			return NewLogger(WithNullOutput(), WithHooks(h), WithRules(dropDebug), WithAsync(10, Block))
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			hook := new(test.Hook)
+			logger, err := build(hook)
+			require.NoError(t, err)
+			logger.Entry.Logger.SetLevel(logrus.DebugLevel)
+
+			logger.Debug("dropped")
+			logger.Info("kept")
+			require.NoError(t, logger.Close())
+			require.Len(t, hook.AllEntries(), 1)
+			assert.Equal(t, "kept", hook.LastEntry().Message)
+		})
+	}
+}
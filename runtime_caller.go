@@ -1,11 +1,44 @@
 package logger
 
 import (
+	"fmt"
+	"os"
+	"path"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
+	"sync/atomic"
 )
 
+// PathMode controls how source file paths are rendered in runtime context fields
+type PathMode int32
+
+const (
+	// PathModeShort renders the parent directory and file name (e.g. "test/main.go")
+	PathModeShort PathMode = iota
+	// PathModeModuleRelative renders the path relative to the root of the module
+	// containing the file (the nearest directory holding a go.mod file). It falls
+	// back to PathModeShort when no module root can be found.
+	PathModeModuleRelative
+	// PathModeFull renders the path exactly as reported by the runtime
+	PathModeFull
+)
+
+var (
+	// callerPathMode is the PathMode used when extracting caller information
+	callerPathMode atomic.Int32
+
+	// moduleRoots caches the module root found for each source directory
+	moduleRoots sync.Map
+)
+
+// SetCallerPathMode sets how source file paths are rendered by the runtime context
+// hook and formatter
+func SetCallerPathMode(mode PathMode) {
+	callerPathMode.Store(int32(mode))
+}
+
 // callerInfo holds the extracted runtime caller information
 type callerInfo struct {
 	funcName  string
@@ -18,6 +51,7 @@ type callerInfo struct {
 // extractCallerInfo without anonymous function filtering
 func extractCallerInfo(skipFrames int) (callerInfo, bool) {
 	var info callerInfo
+	mode := PathMode(callerPathMode.Load())
 	for i := skipFrames; i < skipFrames+15; i++ {
 		if pc, file, line, ok := runtime.Caller(i); ok {
 			funcName := runtime.FuncForPC(pc).Name()
@@ -30,15 +64,7 @@ func extractCallerInfo(skipFrames int) (callerInfo, bool) {
 				!strings.Contains(funcName, "WithRuntimeContext") {
 
 				info.funcName = funcName
-
-				var fileName string
-				fileParts := strings.Split(file, string(filepath.Separator))
-				if len(fileParts) >= 2 {
-					fileName = filepath.Join(fileParts[len(fileParts)-2], fileParts[len(fileParts)-1])
-				} else {
-					fileName = fileParts[len(fileParts)-1]
-				}
-				info.fileName = fileName
+				info.fileName = formatCallerPath(file, mode)
 				info.line = line
 
 				lastDot := strings.LastIndex(funcName, ".")
@@ -55,3 +81,93 @@ func extractCallerInfo(skipFrames int) (callerInfo, bool) {
 	}
 	return info, false
 }
+
+// Stack returns the stack of the calling goroutine, one "function" and
+// "\tfile:line" line pair per frame, skipping skip frames above the caller of
+// Stack and the frames of the runtime (e.g. those of a panic being recovered).
+// File paths are rendered according to SetCallerPathMode.
+func Stack(skip int) string {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skip+2, pcs)
+	return formatStack(pcs[:n])
+}
+
+// formatStack renders the frames of the program counters pcs, as returned by
+// runtime.Callers, leaving out the frames of the runtime
+func formatStack(pcs []uintptr) string {
+	frames := runtime.CallersFrames(pcs)
+	mode := PathMode(callerPathMode.Load())
+
+	var b strings.Builder
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "runtime.") {
+			fmt.Fprintf(&b, "%s\n\t%s:%d\n", frame.Function, formatCallerPath(frame.File, mode), frame.Line)
+		}
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
+
+// formatCallerPath renders a runtime file path according to mode. Paths reported by
+// the runtime always use forward slashes, regardless of the platform.
+func formatCallerPath(file string, mode PathMode) string {
+	switch mode {
+	case PathModeFull:
+		return file
+	case PathModeModuleRelative:
+		if rel, ok := moduleRelativePath(file); ok {
+			return rel
+		}
+	}
+	return shortPath(file)
+}
+
+// shortPath returns the last two elements of a slash separated path
+func shortPath(file string) string {
+	dir, base := path.Split(file)
+	parent := path.Base(strings.TrimSuffix(dir, "/"))
+	if dir == "" || parent == "/" || parent == "." || isVolumeName(parent) {
+		return base
+	}
+	return path.Join(parent, base)
+}
+
+// moduleRelativePath returns file relative to the nearest parent directory that
+// contains a go.mod file
+func moduleRelativePath(file string) (string, bool) {
+	dir := path.Dir(file)
+	root, ok := moduleRoots.Load(dir)
+	if !ok {
+		root = findModuleRoot(dir)
+		moduleRoots.Store(dir, root)
+	}
+	rootDir := root.(string)
+	if rootDir == "" {
+		return "", false
+	}
+	return strings.TrimPrefix(file, rootDir+"/"), true
+}
+
+// findModuleRoot walks up from dir looking for a go.mod file. It returns an empty
+// string when no module root is found.
+func findModuleRoot(dir string) string {
+	for {
+		if _, err := os.Stat(filepath.Join(filepath.FromSlash(dir), "go.mod")); err == nil {
+			return dir
+		}
+		parent := path.Dir(dir)
+		if parent == dir || isVolumeName(parent) || parent == "." {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// isVolumeName reports whether p is a bare Windows drive letter such as "C:"
+func isVolumeName(p string) bool {
+	return len(p) == 2 && p[1] == ':' &&
+		(('a' <= p[0] && p[0] <= 'z') || ('A' <= p[0] && p[0] <= 'Z'))
+}
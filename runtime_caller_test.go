@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShortPath(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want string
+	}{
+		{name: "linux", file: "/home/user/app/pkg/main.go", want: "pkg/main.go"},
+		{name: "macos", file: "/Users/user/go/src/app/handler.go", want: "app/handler.go"},
+		{name: "windows", file: "C:/Users/user/go/src/app/handler.go", want: "app/handler.go"},
+		{name: "windows drive root", file: "C:/main.go", want: "main.go"},
+		{name: "filesystem root", file: "/main.go", want: "main.go"},
+		{name: "bare file name", file: "main.go", want: "main.go"},
+		{name: "trimpath", file: "github.com/org/app/cmd/main.go", want: "cmd/main.go"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, shortPath(tt.file))
+		})
+	}
+}
+
+func TestFormatCallerPath_ModuleRelative(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "runtime_caller_module")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "internal", "server"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module example.com/app\n"), 0o644))
+
+	root := filepath.ToSlash(tmpDir)
+	file := root + "/internal/server/server.go"
+
+	assert.Equal(t, "internal/server/server.go", formatCallerPath(file, PathModeModuleRelative))
+	assert.Equal(t, "server/server.go", formatCallerPath(file, PathModeShort))
+	assert.Equal(t, file, formatCallerPath(file, PathModeFull))
+
+	// files outside any module fall back to the short form
+	assert.Equal(t, "src/main.go", formatCallerPath("/nonexistent/src/main.go", PathModeModuleRelative))
+}
+
+func TestExtractCallerInfo_PathMode(t *testing.T) {
+	t.Cleanup(func() { SetCallerPathMode(PathModeShort) })
+
+	SetCallerPathMode(PathModeModuleRelative)
+	info, ok := extractCallerInfo(1)
+	require.True(t, ok)
+	assert.Equal(t, "runtime_caller_test.go", info.fileName)
+	assert.Equal(t, "TestExtractCallerInfo_PathMode", info.shortFunc)
+}
+
+func TestStack(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+
+	var stack string
+	func() {
+		defer func() {
+			recover()
+			stack = Stack(0)
+		}()
+		panic("boom")
+	}()
+
+	assert.Contains(t, stack, "logger.TestStack.func1.1\n\t"+filepath.Base(wd)+"/runtime_caller_test.go:")
+	assert.Contains(t, stack, "logger.TestStack\n\t")
+	assert.NotContains(t, stack, "runtime.gopanic")
+}
@@ -0,0 +1,19 @@
+//go:build windows
+
+package logger
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractCallerInfo_WindowsPaths(t *testing.T) {
+	info, ok := extractCallerInfo(1)
+	require.True(t, ok)
+	assert.False(t, strings.Contains(info.fileName, `\`), "file name should use forward slashes: %s", info.fileName)
+	assert.False(t, strings.Contains(info.fileName, ":"), "file name should not contain a volume name: %s", info.fileName)
+	assert.True(t, strings.HasSuffix(info.fileName, "/runtime_caller_windows_test.go"))
+}
@@ -29,6 +29,12 @@ func (h *runtimeContextHook) Levels() []logrus.Level {
 	}
 }
 
+// Enriches implements EnrichingHook, the func and src fields are those of the
+// goroutine logging the entry
+func (h *runtimeContextHook) Enriches() bool {
+	return true
+}
+
 // Hook implementation
 func (h *runtimeContextHook) Fire(entry *logrus.Entry) error {
 	if info, ok := extractCallerInfo(h.skipFrames); ok {
@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultSamplingTick       = time.Second
+	DefaultSamplingFirst      = 100
+	DefaultSamplingThereafter = 100
+)
+
+// SamplingConfig holds configuration for the sampling rule
+type SamplingConfig struct {
+	Tick  time.Duration // sampling period, defaults to DefaultSamplingTick
+	First int           // entries with the same level and message let through per tick, defaults to DefaultSamplingFirst
+	// Thereafter lets through one entry out of Thereafter past First in a tick,
+	// defaults to DefaultSamplingThereafter. A negative value drops them all.
+	Thereafter int
+}
+
+// samplingRule implements Rule sampling repetitive entries
+type samplingRule struct {
+	cfg    SamplingConfig
+	now    func() time.Time
+	mu     sync.Mutex
+	start  time.Time
+	counts map[samplingKey]int
+}
+
+// samplingKey identifies the entries counted together
+type samplingKey struct {
+	level   logrus.Level
+	message string
+}
+
+// WithSampling caps the cost of hot paths by letting through the first First
+// entries with the same level and message in each tick, then one out of
+// Thereafter, like zap's sampler. Error, fatal and panic entries are never
+// sampled.
+func WithSampling(cfg *SamplingConfig) Option {
+	c := SamplingConfig{}
+	if cfg != nil {
+		c = *cfg
+	}
+	if c.Tick <= 0 {
+		c.Tick = DefaultSamplingTick
+	}
+	if c.First <= 0 {
+		c.First = DefaultSamplingFirst
+	}
+	if c.Thereafter == 0 {
+		c.Thereafter = DefaultSamplingThereafter
+	}
+	return WithRules(&samplingRule{cfg: c, now: time.Now, counts: make(map[samplingKey]int)})
+}
+
+// Apply suppresses the entries sampled out in the current tick
+func (r *samplingRule) Apply(entry *logrus.Entry) bool {
+	if entry.Level <= logrus.ErrorLevel {
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if now := r.now(); now.Sub(r.start) >= r.cfg.Tick {
+		r.start = now
+		clear(r.counts)
+	}
+	key := samplingKey{level: entry.Level, message: entry.Message}
+	r.counts[key]++
+	n := r.counts[key]
+	if n <= r.cfg.First {
+		return false
+	}
+	return r.cfg.Thereafter < 0 || (n-r.cfg.First)%r.cfg.Thereafter != 0
+}
@@ -0,0 +1,46 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithSampling(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := NewLogger(WithOutput(&buf), WithFormatter(&PlainFormatter{}),
+		WithSampling(&SamplingConfig{Tick: time.Hour, First: 2, Thereafter: 3}))
+	require.NoError(t, err)
+
+	for i := 0; i < 8; i++ {
+		l.WithField("i", i).Info("polling")
+		l.Error("poll failed")
+	}
+	l.Warn("polling")
+	assert.Equal(t, "INFO polling i=0\nINFO polling i=1\nINFO polling i=4\nINFO polling i=7\nWARNING polling\n",
+		strings.ReplaceAll(buf.String(), "ERROR poll failed\n", ""))
+	assert.Equal(t, 8, strings.Count(buf.String(), "ERROR poll failed\n"))
+}
+
+func TestSamplingTick(t *testing.T) {
+	now := time.Now()
+	r := &samplingRule{
+		cfg:    SamplingConfig{Tick: time.Second, First: 1, Thereafter: -1},
+		now:    func() time.Time { return now },
+		counts: make(map[samplingKey]int),
+	}
+	l, err := NewLogger(WithNullOutput(), WithRules(r))
+	require.NoError(t, err)
+
+	entry := l.Entry.WithField("k", "v")
+	entry.Level, entry.Message = logrus.InfoLevel, "tick"
+	assert.False(t, r.Apply(entry))
+	assert.True(t, r.Apply(entry))
+	now = now.Add(time.Second)
+	assert.False(t, r.Apply(entry))
+}
@@ -0,0 +1,143 @@
+// Package sentrylog reports go-logger entries to Sentry as events, attaching the
+// entries captured with logger.WithLastEntriesCapture as breadcrumbs.
+package sentrylog
+
+import (
+	"time"
+
+	logger "github.com/alejoacosta74/go-logger"
+	"github.com/getsentry/sentry-go"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultBreadcrumbLimit = 30
+	DefaultFlushTimeout    = 2 * time.Second
+)
+
+// Options holds configuration for the Sentry hook
+type Options struct {
+	Hub    *sentry.Hub    // defaults to sentry.CurrentHub()
+	Levels []logrus.Level // defaults to error, fatal and panic
+	// BreadcrumbLimit caps the recent entries attached to each event. Breadcrumbs
+	// require logger.WithLastEntriesCapture; a negative limit disables them.
+	BreadcrumbLimit  int
+	BreadcrumbLevels []logrus.Level // levels included as breadcrumbs, all when empty
+	FlushTimeout     time.Duration  // wait for delivery on fatal and panic entries
+}
+
+// hook implements logrus.Hook reporting entries as Sentry events
+type hook struct {
+	hub  *sentry.Hub
+	opts Options
+}
+
+// sentryLevels maps logrus levels to Sentry levels
+var sentryLevels = map[logrus.Level]sentry.Level{
+	logrus.PanicLevel: sentry.LevelFatal,
+	logrus.FatalLevel: sentry.LevelFatal,
+	logrus.ErrorLevel: sentry.LevelError,
+	logrus.WarnLevel:  sentry.LevelWarning,
+	logrus.InfoLevel:  sentry.LevelInfo,
+	logrus.DebugLevel: sentry.LevelDebug,
+	logrus.TraceLevel: sentry.LevelDebug,
+}
+
+// NewHook creates a new hook reporting entries to Sentry
+func NewHook(opts *Options) *hook {
+	o := Options{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Hub == nil {
+		o.Hub = sentry.CurrentHub()
+	}
+	if len(o.Levels) == 0 {
+		o.Levels = []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel}
+	}
+	if o.BreadcrumbLimit == 0 {
+		o.BreadcrumbLimit = DefaultBreadcrumbLimit
+	}
+	if o.FlushTimeout <= 0 {
+		o.FlushTimeout = DefaultFlushTimeout
+	}
+	return &hook{hub: o.Hub, opts: o}
+}
+
+// WithHook adds a hook that reports error entries to Sentry, attaching recent
+// entries as breadcrumbs when logger.WithLastEntriesCapture is enabled
+func WithHook(opts *Options) logger.Option {
+	return func(l *logger.Logger) error {
+		l.Entry.Logger.AddHook(NewHook(opts))
+		return nil
+	}
+}
+
+func (h *hook) Levels() []logrus.Level {
+	return h.opts.Levels
+}
+
+// Fire sends the entry to Sentry as an event
+func (h *hook) Fire(entry *logrus.Entry) error {
+	event := sentry.NewEvent()
+	event.Level = sentryLevels[entry.Level]
+	event.Message = entry.Message
+	event.Timestamp = entry.Time
+	event.Logger = "go-logger"
+
+	for key, value := range entry.Data {
+		if key == logrus.ErrorKey {
+			if err, ok := value.(error); ok {
+				event.SetException(err, -1)
+				continue
+			}
+		}
+		event.Extra[key] = value
+	}
+
+	if h.opts.BreadcrumbLimit > 0 {
+		l := &logger.Logger{Entry: logrus.NewEntry(entry.Logger)}
+		if crumbs := l.Breadcrumbs(h.opts.BreadcrumbLimit+1, h.opts.BreadcrumbLevels...); len(crumbs) > 0 {
+			event.Breadcrumbs = sentryBreadcrumbs(entry, crumbs, h.opts.BreadcrumbLimit)
+		}
+	}
+
+	h.hub.CaptureEvent(event)
+
+	if entry.Level <= logrus.FatalLevel {
+		h.hub.Flush(h.opts.FlushTimeout)
+	}
+	return nil
+}
+
+// sentryBreadcrumbs converts breadcrumbs, leaving out the entry being reported
+func sentryBreadcrumbs(entry *logrus.Entry, crumbs []logger.Breadcrumb, limit int) []*sentry.Breadcrumb {
+	if n := len(crumbs); n > 0 {
+		last := crumbs[n-1]
+		if last.Timestamp.Equal(entry.Time) && last.Message == entry.Message && last.Level == entry.Level {
+			crumbs = crumbs[:n-1]
+		}
+	}
+	if len(crumbs) > limit {
+		crumbs = crumbs[len(crumbs)-limit:]
+	}
+
+	out := make([]*sentry.Breadcrumb, 0, len(crumbs))
+	for _, c := range crumbs {
+		out = append(out, &sentry.Breadcrumb{
+			Type:      c.Type,
+			Category:  c.Category,
+			Message:   c.Message,
+			Data:      c.Data,
+			Level:     sentryLevels[c.Level],
+			Timestamp: c.Timestamp,
+		})
+	}
+	return out
+}
+
+// Close flushes buffered events
+func (h *hook) Close() error {
+	h.hub.Flush(h.opts.FlushTimeout)
+	return nil
+}
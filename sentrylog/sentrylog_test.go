@@ -0,0 +1,69 @@
+package sentrylog
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	logger "github.com/alejoacosta74/go-logger"
+	"github.com/getsentry/sentry-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sentryTransportMock struct {
+	mu     sync.Mutex
+	events []*sentry.Event
+}
+
+func (t *sentryTransportMock) Flush(timeout time.Duration) bool       { return true }
+func (t *sentryTransportMock) Configure(options sentry.ClientOptions) {}
+func (t *sentryTransportMock) SendEvent(event *sentry.Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.events = append(t.events, event)
+}
+
+func newTestSentryHub(t *testing.T) (*sentry.Hub, *sentryTransportMock) {
+	transport := &sentryTransportMock{}
+	client, err := sentry.NewClient(sentry.ClientOptions{
+		Dsn:       "https://public@example.com/1",
+		Transport: transport,
+	})
+	require.NoError(t, err)
+	return sentry.NewHub(client, sentry.NewScope()), transport
+}
+
+func TestHook(t *testing.T) {
+	hub, transport := newTestSentryHub(t)
+
+	l, err := logger.NewLogger(
+		logger.WithNullOutput(),
+		logger.WithLevel("info"),
+		logger.WithLastEntriesCapture(10),
+		WithHook(&Options{Hub: hub}),
+	)
+	require.NoError(t, err)
+
+	l.Info("loading config")
+	l.WithField("user", "42").Info("charging card")
+	l.Warn("not reported")
+	l.WithField("order", "A1").WithError(errors.New("card declined")).Error("payment failed")
+
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+	require.Len(t, transport.events, 1)
+
+	event := transport.events[0]
+	assert.Equal(t, sentry.LevelError, event.Level)
+	assert.Equal(t, "payment failed", event.Message)
+	assert.Equal(t, "A1", event.Extra["order"])
+	require.NotEmpty(t, event.Exception)
+	assert.Equal(t, "card declined", event.Exception[len(event.Exception)-1].Value)
+
+	require.Len(t, event.Breadcrumbs, 3)
+	assert.Equal(t, "loading config", event.Breadcrumbs[0].Message)
+	assert.Equal(t, "42", event.Breadcrumbs[1].Data["user"])
+	assert.Equal(t, sentry.LevelWarning, event.Breadcrumbs[2].Level)
+}
@@ -0,0 +1,339 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// outputStatsKey names the logger output in Stats.Bytes
+const outputStatsKey = "output"
+
+// Stats describes the logging behavior of a logger since it was created
+type Stats struct {
+	Entries map[string]uint64 // entries logged per level
+	Dropped map[string]uint64 // entries dropped per reason, e.g. "async_queue" or a sink name
+	Bytes   map[string]uint64 // bytes written per sink, "output" for the logger output
+	// Failures counts the failed writes per file mirrored to a fallback (see
+	// WriteFallbackConfig), "output" for the logger output
+	Failures map[string]uint64
+	Uptime   time.Duration
+	Files    []FileStats // state of the rotated files
+}
+
+// statsHook counts the entries logged per level and holds the writers counting
+// the bytes written per sink
+type statsHook struct {
+	summary bool // log the stats on Shutdown
+	start   time.Time
+	entries [logrus.TraceLevel + 1]atomic.Uint64
+	writers map[string]*countingSink
+}
+
+// WithStats collects logging stats, see Stats
+func WithStats() Option {
+	return func(l *Logger) error {
+		useStatsHook(l.Entry.Logger)
+		return nil
+	}
+}
+
+// WithShutdownSummary collects logging stats (see Stats) and logs them as a
+// final summary entry on Shutdown, so every process leaves a machine readable
+// footprint of its logging behavior. The summary is logged at info level, or
+// the most verbose of warn and error enabled.
+func WithShutdownSummary() Option {
+	return func(l *Logger) error {
+		useStatsHook(l.Entry.Logger).summary = true
+		return nil
+	}
+}
+
+// useStatsHook returns the stats hook registered on l, registering one first
+// when needed
+func useStatsHook(l *logrus.Logger) *statsHook {
+	if h, ok := findHook[*statsHook](l); ok {
+		return h
+	}
+	h := &statsHook{start: time.Now(), writers: make(map[string]*countingSink)}
+	l.AddHook(h)
+	return h
+}
+
+// startStats wraps the output of l and of its output URIs to count the bytes
+// written when WithShutdownSummary was used. It runs once the options have been
+// applied, before startAsync moves the output to the async worker.
+func startStats(l *logrus.Logger) {
+	h, ok := findHook[*statsHook](l)
+	if !ok {
+		return
+	}
+	out := &countingSink{w: l.Out}
+	h.writers[outputStatsKey] = out
+	l.SetOutput(out)
+	for _, hook := range registeredHooks(l) {
+		if o, ok := hook.(*outputHook); ok {
+			sink := &countingSink{w: o.sink}
+			h.writers[sinkStatsKey(o.uri)] = sink
+			o.sink = sink
+		}
+	}
+}
+
+func (h *statsHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Enriches implements EnrichingHook, it counts entries ahead of the sinks
+func (h *statsHook) Enriches() bool {
+	return true
+}
+
+func (h *statsHook) Fire(entry *logrus.Entry) error {
+	if int(entry.Level) < len(h.entries) {
+		h.entries[entry.Level].Add(1)
+	}
+	return nil
+}
+
+// Stats returns the logging stats collected since the logger was created,
+// false when neither WithStats nor WithShutdownSummary was used
+func (l *Logger) Stats() (Stats, bool) {
+	h, ok := findHook[*statsHook](l.Entry.Logger)
+	if !ok {
+		return Stats{}, false
+	}
+
+	s := Stats{
+		Entries:  make(map[string]uint64),
+		Dropped:  make(map[string]uint64),
+		Bytes:    make(map[string]uint64),
+		Failures: make(map[string]uint64),
+		Uptime:   time.Since(h.start),
+	}
+	for _, level := range logrus.AllLevels {
+		if n := h.entries[level].Load(); n > 0 {
+			s.Entries[level.String()] = n
+		}
+	}
+	for name, w := range h.writers {
+		s.Bytes[name] = w.n.Load()
+	}
+
+	hooks := allHooks(l.Entry.Logger)
+	for _, hook := range hooks {
+		if file, ok := rotatedFile(hook); ok {
+			s.Files = append(s.Files, file.stats())
+		}
+		if failer, ok := hook.(interface{ writeFailures() (string, uint64) }); ok {
+			if name, n := failer.writeFailures(); n > 0 {
+				s.Failures[name] += n
+			}
+		}
+		dropper, ok := hook.(interface{ Dropped() uint64 })
+		if !ok {
+			continue
+		}
+		if n := dropper.Dropped(); n > 0 {
+			s.Dropped[dropReason(hook)] += n
+		}
+	}
+	if dropper, ok := h.writers[outputStatsKey].w.(interface{ Dropped() uint64 }); ok {
+		if n := dropper.Dropped(); n > 0 {
+			s.Dropped[outputStatsKey] += n
+		}
+	}
+	return s, true
+}
+
+// ForceRotateAll rotates every rotated file of the logger now, e.g. before
+// taking a disk snapshot
+func (l *Logger) ForceRotateAll() error {
+	hooks := allHooks(l.Entry.Logger)
+	var errs []error
+	for _, hook := range hooks {
+		if file, ok := rotatedFile(hook); ok {
+			if err := file.rotate(); err != nil {
+				errs = append(errs, fmt.Errorf("rotate %s: %w", file.stats().Filename, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// allHooks returns the hooks of l, including those moved to the async worker
+func allHooks(l *logrus.Logger) []logrus.Hook {
+	hooks := registeredHooks(l)
+	if d, ok := findHook[*asyncDispatcher](l); ok && d.shadow != nil {
+		hooks = append(hooks, registeredHooks(d.shadow)...)
+	}
+	return hooks
+}
+
+// rotator is a hook writing to a rotated file
+type rotator interface {
+	stats() FileStats
+	rotate() error
+}
+
+// rotatedFile returns the rotated file hook writes to, if any
+func rotatedFile(hook logrus.Hook) (rotator, bool) {
+	switch h := hook.(type) {
+	case *rotatingFileHook:
+		return h, true
+	case *outputHook:
+		sink := io.Writer(h.sink)
+		if c, ok := sink.(*countingSink); ok {
+			sink = c.w
+		}
+		if lj, ok := sink.(*lumberjack.Logger); ok {
+			return &outputFile{hook: h, file: lj}, true
+		}
+	}
+	return nil, false
+}
+
+// outputFile is the rotated file of an output URI
+type outputFile struct {
+	hook *outputHook
+	file *lumberjack.Logger
+}
+
+func (f *outputFile) stats() FileStats {
+	return lumberjackStats(f.file, f.file.Compress)
+}
+
+func (f *outputFile) rotate() error {
+	f.hook.mu.Lock()
+	defer f.hook.mu.Unlock()
+	return f.file.Rotate()
+}
+
+// Shutdown logs the shutdown summary when WithShutdownSummary was used, then
+// closes the sinks and flushes the output, waiting until ctx is done or for
+// DefaultFatalFlushTimeout when ctx has no deadline
+func (l *Logger) Shutdown(ctx context.Context) error {
+	if h, ok := findHook[*statsHook](l.Entry.Logger); ok && h.summary {
+		s, _ := l.Stats()
+		l.logSummary(s)
+	}
+
+	timeout := DefaultFatalFlushTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	if err := flushSinks(l.Entry.Logger, timeout); err != nil {
+		return fmt.Errorf("shutdown: %w", err)
+	}
+	return nil
+}
+
+// Close flushes the buffered entries, drains the async queue and closes every
+// hook that can be closed and the output, waiting for DefaultFatalFlushTimeout
+// at most. The standard streams are synced but left open. The logger must not
+// be used afterwards.
+func (l *Logger) Close() error {
+	lg := l.Entry.Logger
+	outputs := []io.Writer{lg.Out}
+	if d, ok := findHook[*asyncDispatcher](lg); ok && d.shadow != nil {
+		outputs = append(outputs, d.shadow.Out)
+	}
+
+	errs := []error{flushSinks(lg, DefaultFatalFlushTimeout)}
+	for _, out := range outputs {
+		// outputs without Flush are closed when flushed
+		if err := closeOutput(out); !errors.Is(err, os.ErrClosed) {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("close: %w", err)
+	}
+	return nil
+}
+
+// closeOutput closes w, syncing the standard streams instead
+func closeOutput(w io.Writer) error {
+	if f, ok := w.(*os.File); ok && (f == os.Stdout || f == os.Stderr) {
+		// syncing a pipe or terminal fails harmlessly
+		f.Sync()
+		return nil
+	}
+	if c, ok := w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// logSummary logs s at the most verbose of info, warn and error enabled
+func (l *Logger) logSummary(s Stats) {
+	for _, level := range []logrus.Level{logrus.InfoLevel, logrus.WarnLevel, logrus.ErrorLevel} {
+		if !l.Entry.Logger.IsLevelEnabled(level) {
+			continue
+		}
+		l.Entry.WithFields(logrus.Fields{
+			"entries":   s.Entries,
+			"dropped":   s.Dropped,
+			"bytes":     s.Bytes,
+			"uptime_ms": durationMillis(s.Uptime),
+		}).Log(level, "logger shutdown summary")
+		return
+	}
+}
+
+// dropReason names the drops of hook in Stats.Dropped
+func dropReason(hook logrus.Hook) string {
+	if _, ok := hook.(*asyncDispatcher); ok {
+		return "async_queue"
+	}
+	name := strings.TrimPrefix(fmt.Sprintf("%T", hook), "*logger.")
+	return strings.TrimSuffix(name, "Hook")
+}
+
+// sinkStatsKey names an output URI in Stats.Bytes, leaving out credentials and
+// query parameters
+func sinkStatsKey(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	u.User = nil
+	u.RawQuery = ""
+	return u.String()
+}
+
+// countingSink counts the bytes written to w, forwarding Flush and Close
+type countingSink struct {
+	w io.Writer
+	n atomic.Uint64
+}
+
+func (c *countingSink) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n.Add(uint64(n))
+	return n, err
+}
+
+func (c *countingSink) Flush() error {
+	if flush := outputFlusher(c.w); flush != nil {
+		return flush()
+	}
+	return nil
+}
+
+func (c *countingSink) Reopen() error {
+	return reopenWriter(c.w)
+}
+
+func (c *countingSink) Close() error {
+	return closeOutput(c.w)
+}
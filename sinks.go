@@ -0,0 +1,94 @@
+package logger
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// newFileSink opens the file of a file URL for appending. Both absolute
+// (file:///var/log/app.log) and relative (file://app.log) paths are accepted.
+// Setting any of the maxsize (megabytes), maxbackups, maxage (days) or compress
+// query parameters enables rotation.
+func newFileSink(u *url.URL) (Sink, error) {
+	path := u.Host + u.Path
+	if u.Opaque != "" {
+		path = u.Opaque
+	}
+	if path == "" {
+		return nil, fmt.Errorf("file path is required")
+	}
+
+	query := u.Query()
+	if !query.Has("maxsize") && !query.Has("maxbackups") && !query.Has("maxage") && !query.Has("compress") {
+		return openReopenableFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	}
+
+	rotating := &lumberjack.Logger{
+		Filename:   path,
+		MaxSize:    DefaultMaxSize,
+		MaxBackups: DefaultMaxBackups,
+		MaxAge:     DefaultMaxAge,
+	}
+	for param, dst := range map[string]*int{
+		"maxsize":    &rotating.MaxSize,
+		"maxbackups": &rotating.MaxBackups,
+		"maxage":     &rotating.MaxAge,
+	} {
+		if v := query.Get(param); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid %s %q", param, v)
+			}
+			*dst = n
+		}
+	}
+	if v := query.Get("compress"); v != "" {
+		compress, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid compress %q", v)
+		}
+		rotating.Compress = compress
+	}
+	return rotating, nil
+}
+
+// newNetworkSink opens a network writer for tcp://host:port and udp://host:port
+// URLs. The framing query parameter selects newline (default) or length
+// prefixed frames.
+func newNetworkSink(u *url.URL) (Sink, error) {
+	framing, err := parseFraming(u.Query().Get("framing"))
+	if err != nil {
+		return nil, err
+	}
+	return NewNetworkWriter(u.Scheme, u.Host, &NetworkConfig{Framing: framing})
+}
+
+// newTLSSink opens a network writer connecting over TLS for tls://host:port
+// URLs, e.g. to a syslog TLS listener. The ca, cert, key, server_name and
+// insecure query parameters set the fields of TLSConfig, framing is the one of
+// newNetworkSink.
+func newTLSSink(u *url.URL) (Sink, error) {
+	q := u.Query()
+	cfg := &TLSConfig{
+		CAFile:     q.Get("ca"),
+		CertFile:   q.Get("cert"),
+		KeyFile:    q.Get("key"),
+		ServerName: q.Get("server_name"),
+	}
+	if v := q.Get("insecure"); v != "" {
+		insecure, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid insecure %q", v)
+		}
+		cfg.InsecureSkipVerify = insecure
+	}
+	framing, err := parseFraming(q.Get("framing"))
+	if err != nil {
+		return nil, err
+	}
+	return NewNetworkWriter("tcp", u.Host, &NetworkConfig{TLS: cfg, Framing: framing})
+}
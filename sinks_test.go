@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// memorySink is a Sink collecting writes in memory
+type memorySink struct {
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	closed bool
+}
+
+func (s *memorySink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buf.Write(p)
+}
+
+func (s *memorySink) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestRegisterSink(t *testing.T) {
+	// schemes can't be unregistered, keep the test repeatable with -count
+	scheme := fmt.Sprintf("memory%d", time.Now().UnixNano())
+	sinks := map[string]*memorySink{}
+	require.NoError(t, RegisterSink(scheme, func(u *url.URL) (Sink, error) {
+		s := &memorySink{}
+		sinks[u.Host] = s
+		return s, nil
+	}))
+	assert.Error(t, RegisterSink(scheme, func(u *url.URL) (Sink, error) { return nil, nil }))
+	assert.Error(t, RegisterSink("stdout", func(u *url.URL) (Sink, error) { return nil, nil }))
+
+	logger, err := NewLogger(WithOutputURIs(scheme + "://errors?format=plain&min_level=error," + scheme + "://all?format=plain"))
+	require.NoError(t, err)
+	logger.Info("hello")
+	logger.Error("boom")
+
+	assert.Equal(t, "ERROR boom\n", sinks["errors"].buf.String())
+	assert.Equal(t, "INFO hello\nERROR boom\n", sinks["all"].buf.String())
+
+	require.NoError(t, flushSinks(logger.Logger, DefaultFatalFlushTimeout))
+	assert.True(t, sinks["all"].closed)
+}
+
+func TestNewSink_File(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sinks")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	sink, err := NewSink("file://" + filepath.Join(tmpDir, "app.log") + "?maxsize=50&maxbackups=2&compress=true")
+	require.NoError(t, err)
+	rotating, ok := sink.(*lumberjack.Logger)
+	require.True(t, ok)
+	assert.Equal(t, 50, rotating.MaxSize)
+	assert.Equal(t, 2, rotating.MaxBackups)
+	assert.Equal(t, DefaultMaxAge, rotating.MaxAge)
+	assert.True(t, rotating.Compress)
+	require.NoError(t, sink.Close())
+
+	sink, err = NewSink("file://" + filepath.Join(tmpDir, "plain.log"))
+	require.NoError(t, err)
+	_, ok = sink.(*reopenableFile)
+	assert.True(t, ok)
+	require.NoError(t, sink.Close())
+
+	_, err = NewSink("file://" + filepath.Join(tmpDir, "bad.log") + "?maxsize=big")
+	assert.Error(t, err)
+	_, err = NewSink("kafka://broker:9092/logs")
+	assert.ErrorContains(t, err, "unsupported scheme")
+}
+
+func TestNewSink_Network(t *testing.T) {
+	sink, err := NewSink("udp://127.0.0.1:9")
+	require.NoError(t, err)
+	_, ok := sink.(*networkWriter)
+	assert.True(t, ok)
+	require.NoError(t, sink.Close())
+}
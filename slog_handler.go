@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/sirupsen/logrus"
+)
+
+// slogHandler implements slog.Handler logging records through a Logger, so
+// libraries written against log/slog reach its hooks and formatter
+type slogHandler struct {
+	logger *Logger
+	fields Fields
+	prefix string // group names joined with dots, each followed by a dot
+}
+
+// NewSlogHandler returns a slog.Handler logging records on l (the global Log
+// when nil). Attributes become fields, those of groups being keyed by the group
+// names joined with dots (e.g. http.method). Levels below debug map to trace
+// and levels above error to error, so records never exit or panic.
+func NewSlogHandler(l *Logger) slog.Handler {
+	if l == nil {
+		l = globalLogger()
+	}
+	return &slogHandler{logger: l}
+}
+
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.logger.Entry.Logger.IsLevelEnabled(slogLevel(level))
+}
+
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	fields := make(Fields, len(h.fields)+r.NumAttrs())
+	for k, v := range h.fields {
+		fields[k] = v
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		addSlogAttr(fields, h.prefix, a)
+		return true
+	})
+
+	entry := h.logger.Entry.WithContext(ctx).WithFields(fields)
+	entry.Time = r.Time
+	entry.Log(slogLevel(r.Level), r.Message)
+	return nil
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := make(Fields, len(h.fields)+len(attrs))
+	for k, v := range h.fields {
+		fields[k] = v
+	}
+	for _, a := range attrs {
+		addSlogAttr(fields, h.prefix, a)
+	}
+	return &slogHandler{logger: h.logger, fields: fields, prefix: h.prefix}
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &slogHandler{logger: h.logger, fields: h.fields, prefix: h.prefix + name + "."}
+}
+
+// addSlogAttr adds a to fields under prefix, flattening groups. Empty attributes
+// and empty groups are left out, and groups without a key are inlined, as
+// slog.Handler requires.
+func addSlogAttr(fields Fields, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() != slog.KindGroup {
+		fields[prefix+a.Key] = a.Value.Any()
+		return
+	}
+	if a.Key != "" {
+		prefix += a.Key + "."
+	}
+	for _, ga := range a.Value.Group() {
+		addSlogAttr(fields, prefix, ga)
+	}
+}
+
+// slogLevel maps a slog level to the closest logrus level, never beyond error
+func slogLevel(level slog.Level) logrus.Level {
+	switch {
+	case level >= slog.LevelError:
+		return logrus.ErrorLevel
+	case level >= slog.LevelWarn:
+		return logrus.WarnLevel
+	case level >= slog.LevelInfo:
+		return logrus.InfoLevel
+	case level >= slog.LevelDebug:
+		return logrus.DebugLevel
+	default:
+		return logrus.TraceLevel
+	}
+}
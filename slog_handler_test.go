@@ -0,0 +1,32 @@
+package logger
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSlogHandler(t *testing.T) {
+	logger, err := NewLogger(WithNullOutput(), WithLevel("info"), WithLastEntriesCapture(10))
+	require.NoError(t, err)
+
+	log := slog.New(NewSlogHandler(logger)).With("service", "checkout")
+	log.Debug("not logged")
+	log.WithGroup("http").Info("request", "method", "GET", slog.Group("response", "status", 200))
+	log.Error("failed", slog.Group(""), slog.Group("", "inline", true))
+
+	crumbs := logger.Breadcrumbs(0)
+	require.Len(t, crumbs, 2)
+	assert.Equal(t, logrus.InfoLevel, crumbs[0].Level)
+	assert.Equal(t, "request", crumbs[0].Message)
+	assert.Equal(t, Fields{
+		"service":              "checkout",
+		"http.method":          "GET",
+		"http.response.status": int64(200),
+	}, crumbs[0].Data)
+	assert.Equal(t, logrus.ErrorLevel, crumbs[1].Level)
+	assert.Equal(t, Fields{"service": "checkout", "inline": true}, crumbs[1].Data)
+}
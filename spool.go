@@ -0,0 +1,492 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultSpoolMaxBytes    = 100 << 20 // 100MB
+	DefaultSpoolSegmentSize = 4 << 20   // 4MB
+	DefaultSpoolBatchSize   = 100
+	DefaultSpoolMinBackoff  = 500 * time.Millisecond
+	DefaultSpoolMaxBackoff  = 30 * time.Second
+)
+
+// DropPolicy selects which entries are discarded when a bounded queue is full
+type DropPolicy int
+
+const (
+	// DropOldest discards the oldest queued entries to make room
+	DropOldest DropPolicy = iota
+	// DropNewest discards the entry being added
+	DropNewest
+)
+
+// SpoolConfig holds configuration for the on-disk spool
+type SpoolConfig struct {
+	Dir string // directory holding the queue, required
+	// MaxBytes bounds the size of the queue on disk, defaults to
+	// DefaultSpoolMaxBytes. Policy selects what is dropped when it is full.
+	MaxBytes    int64
+	Policy      DropPolicy
+	SegmentSize int64 // size at which a new segment file is started
+	BatchSize   int   // entries read from disk per delivery round
+	// Sync fsyncs every entry, so it survives a machine crash and not only a
+	// process restart
+	Sync bool
+	// MinBackoff and MaxBackoff bound the delay before retrying an entry the
+	// sink failed to deliver
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// spoolRecord is the on-disk form of an entry
+type spoolRecord struct {
+	Time    time.Time              `json:"time"`
+	Level   logrus.Level           `json:"level"`
+	Message string                 `json:"msg"`
+	Data    map[string]interface{} `json:"data,omitempty"`
+}
+
+// encodeSpoolRecord returns the JSON line of entry, errors being stored as
+// their message
+func encodeSpoolRecord(entry *logrus.Entry) ([]byte, error) {
+	data := make(map[string]interface{}, len(entry.Data))
+	for k, v := range entry.Data {
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+		data[k] = v
+	}
+	line, err := json.Marshal(spoolRecord{Time: entry.Time, Level: entry.Level, Message: entry.Message, Data: data})
+	if err != nil {
+		return nil, err
+	}
+	return append(line, '\n'), nil
+}
+
+// decodeSpoolRecord returns the entry of a line written by encodeSpoolRecord
+func decodeSpoolRecord(line []byte, logger *logrus.Logger) (*logrus.Entry, error) {
+	var rec spoolRecord
+	if err := json.Unmarshal(line, &rec); err != nil {
+		return nil, err
+	}
+	entry := &logrus.Entry{Logger: logger, Data: rec.Data, Time: rec.Time, Level: rec.Level, Message: rec.Message}
+	if entry.Data == nil {
+		entry.Data = make(logrus.Fields)
+	}
+	return entry, nil
+}
+
+// spoolSegment is a file of the queue, entries are JSON lines
+type spoolSegment struct {
+	seq     int64
+	size    int64
+	entries int
+}
+
+// spoolHook implements logrus.Hook appending entries to a write-ahead queue on
+// disk, drained to the wrapped hook by a background worker. Entries survive
+// process restarts and sink outages; delivery is at least once.
+type spoolHook struct {
+	inner  logrus.Hook
+	cfg    SpoolConfig
+	retry  RetryPolicy
+	logger *logrus.Logger
+
+	mu       sync.Mutex
+	segments []*spoolSegment // oldest first, the last one is written to
+	w        *os.File
+	readOff  int64 // offset of the first undelivered entry in segments[0]
+	total    int64 // bytes of undelivered entries
+
+	notify    chan struct{}
+	done      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+	dropped   atomic.Uint64
+}
+
+// NewSpoolHook creates a hook spooling entries to cfg.Dir before delivering them
+// to inner, resuming the delivery of entries left by a previous run
+func NewSpoolHook(inner logrus.Hook, cfg *SpoolConfig) (*spoolHook, error) {
+	if inner == nil {
+		return nil, fmt.Errorf("spool: hook is required")
+	}
+	if cfg == nil || cfg.Dir == "" {
+		return nil, fmt.Errorf("spool: dir is required")
+	}
+	c := *cfg
+	if c.MaxBytes <= 0 {
+		c.MaxBytes = DefaultSpoolMaxBytes
+	}
+	if c.SegmentSize <= 0 {
+		c.SegmentSize = DefaultSpoolSegmentSize
+	}
+	if c.SegmentSize > c.MaxBytes {
+		c.SegmentSize = c.MaxBytes
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = DefaultSpoolBatchSize
+	}
+	if c.MinBackoff <= 0 {
+		c.MinBackoff = DefaultSpoolMinBackoff
+	}
+	if c.MaxBackoff < c.MinBackoff {
+		c.MaxBackoff = DefaultSpoolMaxBackoff
+	}
+	if c.Policy == Block {
+		return nil, fmt.Errorf("spool: the Block policy isn't supported")
+	}
+	if err := os.MkdirAll(c.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("spool: %w", err)
+	}
+
+	h := &spoolHook{
+		inner:  inner,
+		cfg:    c,
+		retry:  retryPolicy(nil, RetryPolicy{MinBackoff: c.MinBackoff, MaxBackoff: c.MaxBackoff}),
+		logger: logrus.New(),
+		notify: make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+	if err := h.open(); err != nil {
+		return nil, err
+	}
+	h.wg.Add(1)
+	go h.run()
+	return h, nil
+}
+
+// WithSpool adds a hook delivering entries to inner through an on-disk queue
+func WithSpool(inner logrus.Hook, cfg *SpoolConfig) Option {
+	return func(l *Logger) error {
+		hook, err := NewSpoolHook(inner, cfg)
+		if err != nil {
+			return err
+		}
+		hook.logger = l.Entry.Logger
+		l.Entry.Logger.AddHook(hook)
+		return nil
+	}
+}
+
+func (h *spoolHook) Levels() []logrus.Level {
+	return h.inner.Levels()
+}
+
+// Fire appends the entry to the queue
+func (h *spoolHook) Fire(entry *logrus.Entry) error {
+	line, err := encodeSpoolRecord(entry)
+	if err != nil {
+		return err
+	}
+	if err := h.append(line); err != nil {
+		return err
+	}
+	select {
+	case h.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Pending returns the number of bytes queued on disk
+func (h *spoolHook) Pending() int64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.total
+}
+
+// Dropped returns the number of entries discarded because the queue was full
+func (h *spoolHook) Dropped() uint64 {
+	return h.dropped.Load()
+}
+
+// Close delivers the queued entries the sink accepts, keeps the rest on disk
+// for the next run and closes the wrapped hook
+func (h *spoolHook) Close() error {
+	var err error
+	h.closeOnce.Do(func() {
+		close(h.done)
+		h.wg.Wait()
+		h.mu.Lock()
+		err = h.w.Close()
+		h.mu.Unlock()
+		if c, ok := h.inner.(io.Closer); ok {
+			if cerr := c.Close(); err == nil {
+				err = cerr
+			}
+		}
+	})
+	return err
+}
+
+// open loads the segments and delivery cursor left by a previous run
+func (h *spoolHook) open() error {
+	names, err := filepath.Glob(filepath.Join(h.cfg.Dir, "*.spool"))
+	if err != nil {
+		return fmt.Errorf("spool: %w", err)
+	}
+	for _, name := range names {
+		seq, err := strconv.ParseInt(strings.TrimSuffix(filepath.Base(name), ".spool"), 10, 64)
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return fmt.Errorf("spool: %w", err)
+		}
+		h.segments = append(h.segments, &spoolSegment{seq: seq, size: int64(len(data)), entries: bytes.Count(data, []byte{'\n'})})
+	}
+	sort.Slice(h.segments, func(i, j int) bool { return h.segments[i].seq < h.segments[j].seq })
+
+	if len(h.segments) > 0 {
+		if b, err := os.ReadFile(h.cursorPath()); err == nil {
+			var seq, off int64
+			if _, err := fmt.Sscanf(string(b), "%d %d", &seq, &off); err == nil {
+				// segments before the cursor were delivered
+				for len(h.segments) > 1 && h.segments[0].seq < seq {
+					os.Remove(h.segmentPath(h.segments[0].seq))
+					h.segments = h.segments[1:]
+				}
+				if first := h.segments[0]; first.seq == seq && off <= first.size {
+					h.readOff = off
+					if data, err := os.ReadFile(h.segmentPath(seq)); err == nil {
+						first.entries = bytes.Count(data[off:], []byte{'\n'})
+					}
+				}
+			}
+		}
+	}
+	for _, s := range h.segments {
+		h.total += s.size
+	}
+	h.total -= h.readOff
+
+	seq := int64(1)
+	if len(h.segments) > 0 {
+		seq = h.segments[len(h.segments)-1].seq + 1
+	}
+	return h.startSegment(seq)
+}
+
+// startSegment creates the segment seq and writes to it. Segments of previous
+// runs are never appended to, so a torn last line only affects its own file.
+func (h *spoolHook) startSegment(seq int64) error {
+	f, err := os.OpenFile(h.segmentPath(seq), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("spool: %w", err)
+	}
+	if h.w != nil {
+		h.w.Close()
+	}
+	h.w = f
+	h.segments = append(h.segments, &spoolSegment{seq: seq})
+	return nil
+}
+
+// append writes a line to the current segment, applying the drop policy when
+// the queue is full
+func (h *spoolHook) append(line []byte) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	n := int64(len(line))
+	for h.total+n > h.cfg.MaxBytes {
+		if h.cfg.Policy == DropNewest || len(h.segments) == 1 && h.segments[0].size == 0 {
+			h.dropped.Add(1)
+			return nil
+		}
+		if len(h.segments) == 1 {
+			if err := h.startSegment(h.segments[0].seq + 1); err != nil {
+				return err
+			}
+		}
+		h.dropOldest()
+	}
+
+	cur := h.segments[len(h.segments)-1]
+	if cur.size > 0 && cur.size+n > h.cfg.SegmentSize {
+		if err := h.startSegment(cur.seq + 1); err != nil {
+			return err
+		}
+		cur = h.segments[len(h.segments)-1]
+	}
+	if _, err := h.w.Write(line); err != nil {
+		return err
+	}
+	if h.cfg.Sync {
+		if err := h.w.Sync(); err != nil {
+			return err
+		}
+	}
+	cur.size += n
+	cur.entries++
+	h.total += n
+	return nil
+}
+
+// dropOldest removes the oldest segment with its undelivered entries
+func (h *spoolHook) dropOldest() {
+	s := h.segments[0]
+	h.total -= s.size - h.readOff
+	h.dropped.Add(uint64(s.entries))
+	os.Remove(h.segmentPath(s.seq))
+	h.segments = h.segments[1:]
+	h.readOff = 0
+}
+
+// run delivers queued entries until the hook is closed
+func (h *spoolHook) run() {
+	defer h.wg.Done()
+	backoff := h.retry.MinBackoff
+	for {
+		delivered, err := h.deliver()
+		if err == nil {
+			backoff = h.retry.MinBackoff
+			if delivered {
+				continue
+			}
+			select {
+			case <-h.notify:
+				continue
+			case <-h.done:
+				h.drain()
+				return
+			}
+		}
+		select {
+		case <-time.After(h.retry.jittered(backoff)):
+		case <-h.done:
+			h.drain()
+			return
+		}
+		if backoff *= 2; backoff > h.retry.MaxBackoff {
+			backoff = h.retry.MaxBackoff
+		}
+	}
+}
+
+// drain delivers queued entries until the queue is empty or the sink fails
+func (h *spoolHook) drain() {
+	for {
+		if delivered, err := h.deliver(); err != nil || !delivered {
+			return
+		}
+	}
+}
+
+// deliver sends the next batch of queued entries to the wrapped hook and
+// reports whether any entry was delivered
+func (h *spoolHook) deliver() (bool, error) {
+	h.mu.Lock()
+	seg := h.segments[0]
+	off := h.readOff
+	if off >= seg.size {
+		if len(h.segments) == 1 {
+			h.mu.Unlock()
+			return false, nil
+		}
+		// fully delivered, continue with the next segment
+		os.Remove(h.segmentPath(seg.seq))
+		h.segments = h.segments[1:]
+		h.readOff = 0
+		h.saveCursor()
+		h.mu.Unlock()
+		return true, nil
+	}
+	lines, err := h.readLines(seg.seq, off, seg.size)
+	h.mu.Unlock()
+	if err != nil {
+		return false, err
+	}
+
+	delivered := false
+	defer func() {
+		if delivered {
+			h.mu.Lock()
+			h.saveCursor()
+			h.mu.Unlock()
+		}
+	}()
+	for _, line := range lines {
+		if entry, err := decodeSpoolRecord(line, h.logger); err == nil {
+			if err := h.inner.Fire(entry); err != nil {
+				return delivered, err
+			}
+		}
+		h.commit(seg, int64(len(line)))
+		delivered = true
+	}
+	return delivered, nil
+}
+
+// readLines reads up to BatchSize complete lines of segment seq between off
+// and end. A torn line left by a crash is skipped.
+func (h *spoolHook) readLines(seq, off, end int64) ([][]byte, error) {
+	f, err := os.Open(h.segmentPath(seq))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := bufio.NewReader(io.NewSectionReader(f, off, end-off))
+	var lines [][]byte
+	for len(lines) < h.cfg.BatchSize {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			if len(line) > 0 {
+				lines = append(lines, line)
+			}
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+// commit advances the delivery cursor past n bytes of seg, unless seg was
+// dropped in the meantime
+func (h *spoolHook) commit(seg *spoolSegment, n int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.segments[0] != seg {
+		return
+	}
+	h.readOff += n
+	h.total -= n
+	seg.entries--
+}
+
+// saveCursor persists the position of the first undelivered entry
+func (h *spoolHook) saveCursor() {
+	tmp := h.cursorPath() + ".tmp"
+	if err := os.WriteFile(tmp, []byte(fmt.Sprintf("%d %d\n", h.segments[0].seq, h.readOff)), 0o644); err == nil {
+		os.Rename(tmp, h.cursorPath())
+	}
+}
+
+func (h *spoolHook) segmentPath(seq int64) string {
+	return filepath.Join(h.cfg.Dir, fmt.Sprintf("%020d.spool", seq))
+}
+
+func (h *spoolHook) cursorPath() string {
+	return filepath.Join(h.cfg.Dir, "cursor")
+}
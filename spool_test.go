@@ -0,0 +1,99 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (h *flakyHook) received() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.messages...)
+}
+
+func TestSpoolHook_DeliversAfterOutage(t *testing.T) {
+	sink := &flakyHook{broken: true}
+	logger, err := NewLogger(
+		WithNullOutput(),
+		WithSpool(sink, &SpoolConfig{Dir: t.TempDir(), MinBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}),
+	)
+	require.NoError(t, err)
+	hook, ok := findHook[*spoolHook](logger.Logger)
+	require.True(t, ok)
+	logger.WithField("n", 1).Info("one")
+	logger.Info("two")
+	time.Sleep(20 * time.Millisecond)
+	assert.Empty(t, sink.received())
+	assert.Positive(t, hook.Pending())
+
+	sink.setBroken(false)
+	assert.Eventually(t, func() bool { return len(sink.received()) == 2 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"one", "two"}, sink.received())
+	assert.Zero(t, hook.Pending())
+	require.NoError(t, hook.Close())
+}
+
+func TestSpoolHook_SurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	down := &flakyHook{broken: true}
+	hook, err := NewSpoolHook(down, &SpoolConfig{Dir: dir, SegmentSize: 100})
+	require.NoError(t, err)
+	logger, err := NewLogger(WithNullOutput())
+	require.NoError(t, err)
+	logger.Logger.AddHook(hook)
+	for i := 0; i < 5; i++ {
+		logger.Infof("entry %d", i)
+	}
+	require.NoError(t, hook.Close())
+
+	up := &flakyHook{}
+	hook, err = NewSpoolHook(up, &SpoolConfig{Dir: dir})
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool { return len(up.received()) == 5 }, time.Second, 5*time.Millisecond)
+	require.NoError(t, hook.Close())
+	assert.Equal(t, "entry 0", up.received()[0])
+
+	// delivered entries aren't delivered again
+	again := &flakyHook{}
+	hook, err = NewSpoolHook(again, &SpoolConfig{Dir: dir})
+	require.NoError(t, err)
+	require.NoError(t, hook.Close())
+	assert.Empty(t, again.received())
+}
+
+func TestSpoolHook_DropPolicy(t *testing.T) {
+	for _, policy := range []DropPolicy{DropOldest, DropNewest} {
+		t.Run(map[DropPolicy]string{DropOldest: "oldest", DropNewest: "newest"}[policy], func(t *testing.T) {
+			dir := t.TempDir()
+			sink := &flakyHook{broken: true}
+			hook, err := NewSpoolHook(sink, &SpoolConfig{Dir: dir, MaxBytes: 1000, SegmentSize: 200, Policy: policy})
+			require.NoError(t, err)
+			logger, err := NewLogger(WithNullOutput())
+			require.NoError(t, err)
+			logger.Logger.AddHook(hook)
+			for i := 0; i < 50; i++ {
+				logger.Infof("entry %02d", i)
+			}
+			assert.LessOrEqual(t, hook.Pending(), int64(1000))
+			dropped := int(hook.Dropped())
+			assert.Positive(t, dropped)
+			require.NoError(t, hook.Close())
+
+			up := &flakyHook{}
+			hook, err = NewSpoolHook(up, &SpoolConfig{Dir: dir})
+			require.NoError(t, err)
+			require.NoError(t, hook.Close())
+			received := up.received()
+			require.NotEmpty(t, received)
+			assert.Equal(t, 50, len(received)+dropped)
+			if policy == DropOldest {
+				assert.Equal(t, "entry 49", received[len(received)-1])
+			} else {
+				assert.Equal(t, "entry 00", received[0])
+			}
+		})
+	}
+}
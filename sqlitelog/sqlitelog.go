@@ -0,0 +1,308 @@
+// Package sqlitelog keeps go-logger entries in a local SQLite database, using
+// the pure Go modernc.org/sqlite driver. Importing it registers the sqlite
+// scheme with logger.RegisterSink, so sqlite:///var/lib/app/logs.db URLs can be
+// used with logger.WithOutputURIs and logger.NewSink.
+package sqlitelog
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	logger "github.com/alejoacosta74/go-logger"
+	"github.com/sirupsen/logrus"
+	// registers the pure Go "sqlite" database/sql driver
+	_ "modernc.org/sqlite"
+)
+
+const (
+	DefaultTable         = "logs"
+	DefaultBatchSize     = 100
+	DefaultBatchWait     = time.Second
+	DefaultQueueSize     = 10000
+	DefaultPruneInterval = time.Minute
+
+	// timeLayout is fixed width so times sort as text, and is understood by the
+	// SQLite date and time functions
+	timeLayout = "2006-01-02 15:04:05.000000"
+)
+
+// tableName restricts table names to plain identifiers
+var tableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func init() {
+	if err := logger.RegisterSink("sqlite", newSink); err != nil {
+		panic(err)
+	}
+}
+
+// Options holds configuration for the SQLite hook
+type Options struct {
+	Path string // database file, created if needed
+	// DB reuses an open database instead of opening Path; the hook doesn't
+	// close it
+	DB    *sql.DB
+	Table string // defaults to DefaultTable
+	// MaxAge and MaxRows bound the retained entries, older entries are pruned
+	// every PruneInterval. Zero disables the bound.
+	MaxAge        time.Duration
+	MaxRows       int64
+	PruneInterval time.Duration
+	BatchSize     int
+	BatchWait     time.Duration
+	QueueSize     int
+	Block         bool // block logging when the queue is full instead of dropping
+	Levels        []logrus.Level
+}
+
+// record is a row of the logs table
+type record struct {
+	time    string
+	level   string
+	message string
+	fields  []byte
+}
+
+// hook implements logrus.Hook writing entries to a SQLite table
+type hook struct {
+	opts    Options
+	db      *sql.DB
+	owned   bool
+	insert  string
+	batcher *logger.Batcher[record]
+
+	mu         sync.Mutex
+	lastPruned time.Time
+}
+
+// NewHook creates a new hook writing entries to a local SQLite database, with
+// one row per entry holding the time, level, message and the fields as a JSON
+// object queryable with json_extract
+func NewHook(opts *Options) (*hook, error) {
+	if opts == nil || (opts.Path == "" && opts.DB == nil) {
+		return nil, fmt.Errorf("sqlite: a path or database is required")
+	}
+	c := *opts
+	if c.Table == "" {
+		c.Table = DefaultTable
+	}
+	if !tableName.MatchString(c.Table) {
+		return nil, fmt.Errorf("sqlite: invalid table name %q", c.Table)
+	}
+	if c.PruneInterval <= 0 {
+		c.PruneInterval = DefaultPruneInterval
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = DefaultBatchSize
+	}
+	if c.BatchWait <= 0 {
+		c.BatchWait = DefaultBatchWait
+	}
+	if c.QueueSize <= 0 {
+		c.QueueSize = DefaultQueueSize
+	}
+	if len(c.Levels) == 0 {
+		c.Levels = logrus.AllLevels
+	}
+
+	db, owned := c.DB, false
+	if db == nil {
+		var err error
+		if db, err = sql.Open("sqlite", c.Path); err != nil {
+			return nil, fmt.Errorf("sqlite: %w", err)
+		}
+		owned = true
+	}
+	schema := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %[1]s (
+	id      INTEGER PRIMARY KEY AUTOINCREMENT,
+	time    TEXT NOT NULL,
+	level   TEXT NOT NULL,
+	message TEXT NOT NULL,
+	fields  TEXT NOT NULL DEFAULT '{}'
+);
+CREATE INDEX IF NOT EXISTS %[1]s_time ON %[1]s (time);`, c.Table)
+	if _, err := db.Exec(schema); err != nil {
+		if owned {
+			db.Close()
+		}
+		return nil, fmt.Errorf("sqlite: %w", err)
+	}
+
+	h := &hook{
+		opts:   c,
+		db:     db,
+		owned:  owned,
+		insert: fmt.Sprintf("INSERT INTO %s (time, level, message, fields) VALUES (?, ?, ?, ?)", c.Table),
+	}
+	h.batcher = logger.NewBatcher(c.BatchSize, c.BatchWait, c.QueueSize, c.Block, h.write)
+	return h, nil
+}
+
+// WithHook adds a hook writing entries to a local SQLite database
+func WithHook(opts *Options) logger.Option {
+	return func(l *logger.Logger) error {
+		h, err := NewHook(opts)
+		if err != nil {
+			return err
+		}
+		l.Entry.Logger.AddHook(h)
+		return nil
+	}
+}
+
+func (h *hook) Levels() []logrus.Level {
+	return h.opts.Levels
+}
+
+// Fire queues the entry for the next batch
+func (h *hook) Fire(entry *logrus.Entry) error {
+	data := make(logger.Fields, len(entry.Data))
+	for k, v := range entry.Data {
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+		data[k] = v
+	}
+	fields, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	h.batcher.Add(record{
+		time:    entry.Time.UTC().Format(timeLayout),
+		level:   entry.Level.String(),
+		message: entry.Message,
+		fields:  fields,
+	})
+	return nil
+}
+
+// Dropped returns the number of entries discarded because the queue was full
+func (h *hook) Dropped() uint64 {
+	return h.batcher.Dropped()
+}
+
+// Flush writes the queued entries without stopping the background worker
+func (h *hook) Flush() error {
+	h.batcher.Sync()
+	return nil
+}
+
+// Close writes pending entries, prunes and closes the database if the hook
+// opened it
+func (h *hook) Close() error {
+	h.batcher.Close()
+	err := h.Prune()
+	if h.owned {
+		if closeErr := h.db.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// write inserts a batch in a single transaction and prunes old entries when due
+func (h *hook) write(records []record) {
+	tx, err := h.db.Begin()
+	if err != nil {
+		return
+	}
+	stmt, err := tx.Prepare(h.insert)
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+	for _, r := range records {
+		if _, err := stmt.Exec(r.time, r.level, r.message, string(r.fields)); err != nil {
+			stmt.Close()
+			tx.Rollback()
+			return
+		}
+	}
+	stmt.Close()
+	if err := tx.Commit(); err != nil {
+		return
+	}
+
+	h.mu.Lock()
+	due := time.Since(h.lastPruned) >= h.opts.PruneInterval
+	if due {
+		h.lastPruned = time.Now()
+	}
+	h.mu.Unlock()
+	if due {
+		h.Prune()
+	}
+}
+
+// Prune deletes the entries exceeding the retention bounds
+func (h *hook) Prune() error {
+	if h.opts.MaxAge > 0 {
+		cutoff := time.Now().Add(-h.opts.MaxAge).UTC().Format(timeLayout)
+		if _, err := h.db.Exec(fmt.Sprintf("DELETE FROM %s WHERE time < ?", h.opts.Table), cutoff); err != nil {
+			return fmt.Errorf("sqlite: %w", err)
+		}
+	}
+	if h.opts.MaxRows > 0 {
+		query := fmt.Sprintf("DELETE FROM %[1]s WHERE id <= (SELECT MAX(id) FROM %[1]s) - ?", h.opts.Table)
+		if _, err := h.db.Exec(query, h.opts.MaxRows); err != nil {
+			return fmt.Errorf("sqlite: %w", err)
+		}
+	}
+	return nil
+}
+
+// newSink opens the database for sqlite:///var/lib/app/logs.db URLs, inserting
+// every write as a row holding the formatted line in its message, with an empty
+// level. The table, max_age and max_rows query parameters set the matching
+// Options.
+func newSink(u *url.URL) (logger.Sink, error) {
+	if u.Path == "" {
+		return nil, fmt.Errorf("path is required, e.g. sqlite:///var/lib/app/logs.db")
+	}
+	query := u.Query()
+	opts := &Options{Path: u.Path, Table: query.Get("table")}
+	if v := query.Get("max_age"); v != "" {
+		maxAge, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max_age %q", v)
+		}
+		opts.MaxAge = maxAge
+	}
+	if v := query.Get("max_rows"); v != "" {
+		maxRows, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max_rows %q", v)
+		}
+		opts.MaxRows = maxRows
+	}
+	h, err := NewHook(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &sink{hook: h}, nil
+}
+
+// sink is a logger.Sink inserting each write in a SQLite table
+type sink struct {
+	hook *hook
+}
+
+func (s *sink) Write(p []byte) (int, error) {
+	s.hook.batcher.Add(record{
+		time:    time.Now().UTC().Format(timeLayout),
+		message: strings.TrimRight(string(p), "\n"),
+		fields:  []byte("{}"),
+	})
+	return len(p), nil
+}
+
+// Close writes pending rows and closes the database
+func (s *sink) Close() error {
+	return s.hook.Close()
+}
@@ -0,0 +1,103 @@
+package sqlitelog
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	logger "github.com/alejoacosta74/go-logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHook(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "sqlite_hook")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	path := filepath.Join(tmpDir, "logs.db")
+	hook, err := NewHook(&Options{Path: path, MaxRows: 3})
+	require.NoError(t, err)
+
+	log, err := logger.NewLogger(logger.WithNullOutput())
+	require.NoError(t, err)
+	log.Logger.AddHook(hook)
+
+	for i := 0; i < 5; i++ {
+		log.WithFields(logger.Fields{"user": "alice", "n": i}).Info("request")
+	}
+	log.WithField("user", "bob").Error("failed")
+	require.NoError(t, hook.Close())
+
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+	defer db.Close()
+
+	var count int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM logs").Scan(&count))
+	assert.Equal(t, 3, count)
+
+	var level, message string
+	require.NoError(t, db.QueryRow(
+		"SELECT level, message FROM logs WHERE json_extract(fields, '$.user') = ?", "bob",
+	).Scan(&level, &message))
+	assert.Equal(t, "error", level)
+	assert.Equal(t, "failed", message)
+}
+
+func TestHook_MaxAge(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	hook, err := NewHook(&Options{DB: db, Table: "app_logs", MaxAge: time.Hour})
+	require.NoError(t, err)
+
+	log, err := logger.NewLogger(logger.WithNullOutput())
+	require.NoError(t, err)
+	log.Logger.AddHook(hook)
+
+	log.WithTime(time.Now().Add(-2 * time.Hour)).Info("old")
+	log.Info("recent")
+	require.NoError(t, hook.Close())
+
+	var messages []string
+	rows, err := db.Query("SELECT message FROM app_logs ORDER BY time")
+	require.NoError(t, err)
+	defer rows.Close()
+	for rows.Next() {
+		var m string
+		require.NoError(t, rows.Scan(&m))
+		messages = append(messages, m)
+	}
+	assert.Equal(t, []string{"recent"}, messages)
+}
+
+func TestNewHook_Validation(t *testing.T) {
+	_, err := NewHook(nil)
+	assert.Error(t, err)
+	_, err = NewHook(&Options{Path: ":memory:", Table: "logs; DROP TABLE x"})
+	assert.Error(t, err)
+}
+
+func TestNewSink(t *testing.T) {
+	_, err := logger.NewSink("sqlite://")
+	assert.ErrorContains(t, err, "path is required")
+
+	path := filepath.Join(t.TempDir(), "logs.db")
+	s, err := logger.NewSink("sqlite://" + path + "?table=lines")
+	require.NoError(t, err)
+	_, err = s.Write([]byte("INFO started\n"))
+	require.NoError(t, err)
+	require.NoError(t, s.Close())
+
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+	defer db.Close()
+	var message string
+	require.NoError(t, db.QueryRow("SELECT message FROM lines").Scan(&message))
+	assert.Equal(t, "INFO started", message)
+}
@@ -0,0 +1,25 @@
+package logger
+
+import (
+	stdlog "log"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SourceKey is the field naming the component a standard library logger
+// created with NewStdLog logs for
+const SourceKey = "source"
+
+// NewStdLog returns a standard library logger logging every message on l (the
+// global Log when nil) at level, with a source field set to source when not
+// empty, for http.Server.ErrorLog, httputil.ReverseProxy.ErrorLog and other APIs
+// only accepting a *log.Logger
+func NewStdLog(l *Logger, level logrus.Level, source string) *stdlog.Logger {
+	if l == nil {
+		l = globalLogger()
+	}
+	if source != "" {
+		l = &Logger{Entry: l.Entry.WithField(SourceKey, source)}
+	}
+	return stdlog.New(l.WriterLevel(level), "", 0)
+}
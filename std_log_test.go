@@ -0,0 +1,36 @@
+package logger
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewStdLog(t *testing.T) {
+	logger, err := NewLogger(WithNullOutput(), WithLastEntriesCapture(10))
+	require.NoError(t, err)
+
+	srv := httptest.NewUnstartedServer(http.NotFoundHandler())
+	srv.Config.ErrorLog = NewStdLog(logger, logrus.WarnLevel, "http_server")
+	srv.StartTLS()
+	defer srv.Close()
+
+	// a plain text client fails the TLS handshake
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	require.NoError(t, err)
+	conn.Write([]byte("GET / HTTP/1.1\r\n\r\n"))
+	conn.Close()
+
+	require.Eventually(t, func() bool { return len(logger.Breadcrumbs(0)) == 1 }, time.Second, 10*time.Millisecond)
+	crumb := logger.Breadcrumbs(0)[0]
+	assert.Equal(t, logrus.WarnLevel, crumb.Level)
+	assert.True(t, strings.HasPrefix(crumb.Message, "http: TLS handshake error"), crumb.Message)
+	assert.Equal(t, "http_server", crumb.Data[SourceKey])
+}
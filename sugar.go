@@ -0,0 +1,128 @@
+package logger
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// badKey is the field of a trailing value without a key
+const badKey = "!BADKEY"
+
+// sugarFields pairs keysAndValues into fields. Keys that aren't strings are
+// formatted, and a trailing value without a key is kept under !BADKEY; the
+// returned error reports either.
+func sugarFields(keysAndValues []interface{}) (Fields, error) {
+	f := make(Fields, (len(keysAndValues)+1)/2)
+	var err error
+	for i := 0; i < len(keysAndValues); i += 2 {
+		if i == len(keysAndValues)-1 {
+			f[badKey] = keysAndValues[i]
+			err = fmt.Errorf("odd number of arguments, value %v has no key", keysAndValues[i])
+			break
+		}
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			key = fmt.Sprint(keysAndValues[i])
+			err = fmt.Errorf("key %v is a %T, not a string", keysAndValues[i], keysAndValues[i])
+		}
+		f[key] = keysAndValues[i+1]
+	}
+	return f, err
+}
+
+// logw logs msg with keysAndValues as fields, building them only when level is
+// enabled
+func logw(entry *logrus.Entry, level logrus.Level, msg string, keysAndValues []interface{}) {
+	if !entry.Logger.IsLevelEnabled(level) {
+		return
+	}
+	f, _ := sugarFields(keysAndValues)
+	entry.WithFields(f).Log(level, msg)
+}
+
+// Tracew logs msg at the trace level with alternating keys and values as fields,
+// e.g. Tracew("cache hit", "key", key, "size", n)
+func (l *Logger) Tracew(msg string, keysAndValues ...interface{}) {
+	logw(l.Entry, logrus.TraceLevel, msg, keysAndValues)
+}
+
+// Debugw logs msg at the debug level with alternating keys and values as fields
+func (l *Logger) Debugw(msg string, keysAndValues ...interface{}) {
+	logw(l.Entry, logrus.DebugLevel, msg, keysAndValues)
+}
+
+// Infow logs msg at the info level with alternating keys and values as fields
+func (l *Logger) Infow(msg string, keysAndValues ...interface{}) {
+	logw(l.Entry, logrus.InfoLevel, msg, keysAndValues)
+}
+
+// Warnw logs msg at the warn level with alternating keys and values as fields
+func (l *Logger) Warnw(msg string, keysAndValues ...interface{}) {
+	logw(l.Entry, logrus.WarnLevel, msg, keysAndValues)
+}
+
+// Errorw logs msg at the error level with alternating keys and values as fields
+func (l *Logger) Errorw(msg string, keysAndValues ...interface{}) {
+	logw(l.Entry, logrus.ErrorLevel, msg, keysAndValues)
+}
+
+// Fatalw logs msg at the fatal level with alternating keys and values as fields
+// and exits, as Fatal does
+func (l *Logger) Fatalw(msg string, keysAndValues ...interface{}) {
+	logw(l.Entry, logrus.FatalLevel, msg, keysAndValues)
+	l.Entry.Logger.Exit(1)
+}
+
+// Panicw logs msg at the panic level with alternating keys and values as fields,
+// flushes the sinks without closing them and panics
+func (l *Logger) Panicw(msg string, keysAndValues ...interface{}) {
+	defer flushOnPanic(l.Entry.Logger)
+	logw(l.Entry, logrus.PanicLevel, msg, keysAndValues)
+}
+
+// Tracew logs msg at the trace level with alternating keys and values as fields
+// using the global Log instance
+func Tracew(msg string, keysAndValues ...interface{}) {
+	logw(global(), logrus.TraceLevel, msg, keysAndValues)
+}
+
+// Debugw logs msg at the debug level with alternating keys and values as fields
+// using the global Log instance
+func Debugw(msg string, keysAndValues ...interface{}) {
+	logw(global(), logrus.DebugLevel, msg, keysAndValues)
+}
+
+// Infow logs msg at the info level with alternating keys and values as fields
+// using the global Log instance
+func Infow(msg string, keysAndValues ...interface{}) {
+	logw(global(), logrus.InfoLevel, msg, keysAndValues)
+}
+
+// Warnw logs msg at the warn level with alternating keys and values as fields
+// using the global Log instance
+func Warnw(msg string, keysAndValues ...interface{}) {
+	logw(global(), logrus.WarnLevel, msg, keysAndValues)
+}
+
+// Errorw logs msg at the error level with alternating keys and values as fields
+// using the global Log instance
+func Errorw(msg string, keysAndValues ...interface{}) {
+	logw(global(), logrus.ErrorLevel, msg, keysAndValues)
+}
+
+// Fatalw logs msg at the fatal level with alternating keys and values as fields
+// using the global Log instance and exits, as Fatal does
+func Fatalw(msg string, keysAndValues ...interface{}) {
+	entry := global()
+	logw(entry, logrus.FatalLevel, msg, keysAndValues)
+	entry.Logger.Exit(1)
+}
+
+// Panicw logs msg at the panic level with alternating keys and values as fields
+// using the global Log instance, flushes the sinks and panics
+func Panicw(msg string, keysAndValues ...interface{}) {
+	entry := global()
+	defer flushOnPanic(entry.Logger)
+	logw(entry, logrus.PanicLevel, msg, keysAndValues)
+}
@@ -0,0 +1,36 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSugaredMethods(t *testing.T) {
+	var exitCode int
+	logger, err := NewLogger(
+		WithNullOutput(),
+		WithLastEntriesCapture(10),
+		WithExitFunc(func(code int) { exitCode = code }),
+	)
+	require.NoError(t, err)
+
+	logger.Debugw("not logged", "expensive", 1)
+	logger.Infow("order placed", "order", 42, "total", 9.99, "took", time.Second)
+	logger.Errorw("dangling", "status", 500, 7, "seven", "orphan")
+	logger.Fatalw("shutting down", "reason", "config")
+
+	crumbs := logger.Breadcrumbs(0)
+	require.Len(t, crumbs, 3)
+	assert.Equal(t, logrus.InfoLevel, crumbs[0].Level)
+	assert.Equal(t, Fields{"order": 42, "total": 9.99, "took": time.Second}, crumbs[0].Data)
+	assert.Equal(t, Fields{"status": 500, "7": "seven", "!BADKEY": "orphan"}, crumbs[1].Data)
+	assert.Equal(t, logrus.FatalLevel, crumbs[2].Level)
+	assert.Equal(t, 1, exitCode)
+
+	assert.Panics(t, func() { logger.Panicw("corrupt state", "shard", 3) })
+	assert.Equal(t, Fields{"shard": 3}, logger.Breadcrumbs(1)[0].Data)
+}
@@ -0,0 +1,62 @@
+package logger
+
+import (
+	"strings"
+	"sync"
+)
+
+// TestTB is the part of testing.TB used by the test logger
+type TestTB interface {
+	Helper()
+	Log(args ...interface{})
+	Errorf(format string, args ...interface{})
+	Cleanup(func())
+}
+
+// NewTestLogger returns a logger writing every entry to t.Log, without colors,
+// so go test shows the entries of failed tests, or of every test with -v,
+// next to the test output. Fatal fails the test instead of exiting the test
+// binary. Entries logged once the test completed are discarded. It doesn't
+// replace the global Log.
+func NewTestLogger(t TestTB, opts ...Option) *Logger {
+	t.Helper()
+	w := &testWriter{t: t}
+	defaults := []Option{
+		WithOutput(w),
+		WithExitFunc(func(code int) {
+			t.Errorf("logger: Fatal called, exit code %d", code)
+		}),
+	}
+
+	l, err := createNewLogger(append(defaults, opts...)...)
+	if err != nil {
+		t.Errorf("logger: creating test logger: %v", err)
+		l, _ = createNewLogger(defaults...)
+	}
+	t.Cleanup(w.close)
+	return l
+}
+
+// testWriter implements io.Writer logging every write with t.Log
+type testWriter struct {
+	t      TestTB
+	mu     sync.Mutex
+	closed bool
+}
+
+func (w *testWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return len(p), nil
+	}
+	w.t.Log(strings.TrimSuffix(string(stripANSI(p)), "\n"))
+	return len(p), nil
+}
+
+// close stops logging to t, which panics once the test completed
+func (w *testWriter) close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+}
@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTB records the calls of the test logger
+type fakeTB struct {
+	logs     []string
+	errors   []string
+	cleanups []func()
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Log(args ...interface{}) {
+	f.logs = append(f.logs, fmt.Sprint(args...))
+}
+
+func (f *fakeTB) Errorf(format string, args ...interface{}) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeTB) Cleanup(fn func()) {
+	f.cleanups = append(f.cleanups, fn)
+}
+
+func TestNewTestLogger(t *testing.T) {
+	tb := &fakeTB{}
+	logger := NewTestLogger(tb, WithFormatter(&ColorFormatter{}), WithLevel("info"))
+
+	logger.WithField("order", 42).Info("order placed")
+	logger.Fatal("out of stock")
+	if assert.Len(t, tb.logs, 2) {
+		assert.Contains(t, tb.logs[0], "order placed")
+		assert.Contains(t, tb.logs[0], "order: 42")
+		assert.Contains(t, tb.logs[1], "out of stock")
+		assert.NotContains(t, tb.logs[0], "\x1b[")
+	}
+	assert.Equal(t, []string{"logger: Fatal called, exit code 1"}, tb.errors)
+
+	for _, fn := range tb.cleanups {
+		fn()
+	}
+	logger.Info("after the test")
+	assert.Len(t, tb.logs, 2)
+}
+
+func TestNewTestLoggerWithT(t *testing.T) {
+	logger := NewTestLogger(t)
+	logger.Info("visible with -v")
+}
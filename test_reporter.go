@@ -0,0 +1,227 @@
+package logger
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// testNameKey carries the name of the test an entry was logged from
+const testNameKey = "test"
+
+// TestingT is the part of testing.TB used by the test reporter
+type TestingT interface {
+	Name() string
+}
+
+// TestLogEntry is an entry collected by the test reporter
+type TestLogEntry struct {
+	Time    time.Time `json:"time"`
+	Level   string    `json:"level"`
+	Message string    `json:"message"`
+	Fields  Fields    `json:"fields,omitempty"`
+}
+
+// TestReport summarizes the entries collected for a test
+type TestReport struct {
+	Name    string         `json:"name"`
+	Entries []TestLogEntry `json:"entries"`
+}
+
+// testReporterHook implements logrus.Hook collecting error entries per test, so
+// CI can surface errors logged during integration tests even when all
+// assertions passed
+type testReporterHook struct {
+	levels []logrus.Level
+	mu     sync.Mutex
+	tests  map[string]*TestReport
+}
+
+// NewTestReporter creates a hook collecting the entries at the given levels
+// (error and above when empty). Entries are attributed to tests through the
+// loggers returned by Track; other entries are reported under an empty name.
+func NewTestReporter(levels ...logrus.Level) *testReporterHook {
+	if len(levels) == 0 {
+		levels = []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel}
+	}
+	return &testReporterHook{levels: levels, tests: make(map[string]*TestReport)}
+}
+
+// WithTestReporter adds a test reporter hook to the logger
+func WithTestReporter(r *testReporterHook) Option {
+	return func(l *Logger) error {
+		l.Entry.Logger.AddHook(r)
+		return nil
+	}
+}
+
+// Track returns a logger attributing its entries to t, registering the
+// reporter on l if needed. Tracked tests appear in the report even when they
+// logged no errors.
+func (r *testReporterHook) Track(t TestingT, l *Logger) *Logger {
+	if l == nil {
+		l = Log
+	}
+	if _, ok := findHook[*testReporterHook](l.Entry.Logger); !ok {
+		addHook(l.Entry.Logger, r)
+	}
+	r.mu.Lock()
+	r.report(t.Name())
+	r.mu.Unlock()
+	return &Logger{Entry: l.Entry.WithField(testNameKey, t.Name())}
+}
+
+func (r *testReporterHook) Levels() []logrus.Level {
+	return r.levels
+}
+
+// Fire records the entry for its test
+func (r *testReporterHook) Fire(entry *logrus.Entry) error {
+	name, _ := entry.Data[testNameKey].(string)
+	fields := make(Fields, len(entry.Data))
+	for k, v := range entry.Data {
+		if k == testNameKey {
+			continue
+		}
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+		fields[k] = v
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	report := r.report(name)
+	report.Entries = append(report.Entries, TestLogEntry{
+		Time:    entry.Time,
+		Level:   entry.Level.String(),
+		Message: entry.Message,
+		Fields:  fields,
+	})
+	return nil
+}
+
+// report returns the report of the named test, creating it if needed. It must
+// be called with r.mu held.
+func (r *testReporterHook) report(name string) *TestReport {
+	if r.tests[name] == nil {
+		r.tests[name] = &TestReport{Name: name}
+	}
+	return r.tests[name]
+}
+
+// Reports returns the collected reports sorted by test name
+func (r *testReporterHook) Reports() []TestReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	reports := make([]TestReport, 0, len(r.tests))
+	for _, report := range r.tests {
+		reports = append(reports, TestReport{Name: report.Name, Entries: append([]TestLogEntry(nil), report.Entries...)})
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].Name < reports[j].Name })
+	return reports
+}
+
+// WriteJSON writes the reports as a JSON array
+func (r *testReporterHook) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r.Reports())
+}
+
+// junitTestSuites is the root of a JUnit XML report
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name    string        `xml:"name,attr"`
+	Failure *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the reports as JUnit XML, with one test case per test.
+// Tests that logged errors are reported as failures listing the entries.
+func (r *testReporterHook) WriteJUnit(w io.Writer) error {
+	suite := junitTestSuite{Name: "logged errors"}
+	for _, report := range r.Reports() {
+		tc := junitTestCase{Name: report.Name}
+		if tc.Name == "" {
+			tc.Name = "(untracked)"
+		}
+		if len(report.Entries) > 0 {
+			var b strings.Builder
+			for _, e := range report.Entries {
+				fmt.Fprintf(&b, "%s %s %s", e.Time.Format(time.RFC3339Nano), strings.ToUpper(e.Level), e.Message)
+				keys := make([]string, 0, len(e.Fields))
+				for k := range e.Fields {
+					keys = append(keys, k)
+				}
+				sort.Strings(keys)
+				for _, k := range keys {
+					fmt.Fprintf(&b, " %s=%v", k, e.Fields[k])
+				}
+				b.WriteByte('\n')
+			}
+			tc.Failure = &junitFailure{
+				Message: fmt.Sprintf("%d error entries logged", len(report.Entries)),
+				Type:    "logged-error",
+				Text:    b.String(),
+			}
+			suite.Failures++
+		}
+		suite.Tests++
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(junitTestSuites{Suites: []junitTestSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// WriteFile writes the report to path, as JSON when the extension is .json and
+// as JUnit XML otherwise. Call it from TestMain after m.Run.
+func (r *testReporterHook) WriteFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = r.WriteJSON(f)
+	} else {
+		err = r.WriteJUnit(f)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTestReporter(t *testing.T) {
+	base, err := NewLogger(WithNullOutput())
+	require.NoError(t, err)
+	reporter := NewTestReporter()
+
+	t.Run("clean", func(t *testing.T) {
+		l := reporter.Track(t, base)
+		l.Info("all good")
+	})
+	t.Run("noisy", func(t *testing.T) {
+		l := reporter.Track(t, base)
+		l.WithField("order", 42).Error("payment failed")
+		l.Warn("not collected")
+	})
+	base.Error("outside any test")
+
+	reports := reporter.Reports()
+	require.Len(t, reports, 3)
+	assert.Equal(t, "", reports[0].Name)
+	assert.Equal(t, "TestTestReporter/clean", reports[1].Name)
+	assert.Empty(t, reports[1].Entries)
+	assert.Equal(t, "TestTestReporter/noisy", reports[2].Name)
+	require.Len(t, reports[2].Entries, 1)
+	assert.Equal(t, Fields{"order": 42}, reports[2].Entries[0].Fields)
+
+	var junit bytes.Buffer
+	require.NoError(t, reporter.WriteJUnit(&junit))
+	out := junit.String()
+	assert.Contains(t, out, `<testsuite name="logged errors" tests="3" failures="2">`)
+	assert.Contains(t, out, `<testcase name="TestTestReporter/clean"></testcase>`)
+	assert.Contains(t, out, `<failure message="1 error entries logged" type="logged-error">`)
+	assert.Contains(t, out, "ERROR payment failed order=42")
+
+	dir, err := os.MkdirTemp("", "test_reporter")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "errors.json")
+	require.NoError(t, reporter.WriteFile(path))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var decoded []TestReport
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Len(t, decoded, 3)
+	assert.True(t, strings.HasSuffix(decoded[2].Name, "noisy"))
+}
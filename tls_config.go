@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+)
+
+// TLSConfig holds the TLS settings shared by the network sinks (the network
+// writer, Fluentd, Datadog, Loki, the HTTP shipper and webhooks). Setting a
+// client certificate enables mutual TLS.
+type TLSConfig struct {
+	CAFile   string // PEM bundle verifying the server, the system roots when empty
+	CertFile string // PEM client certificate, requires KeyFile
+	KeyFile  string
+	// ServerName overrides the name verified against the server certificate,
+	// which defaults to the host being dialed
+	ServerName         string
+	InsecureSkipVerify bool
+	MinVersion         uint16 // defaults to tls.VersionTLS12
+}
+
+// build returns the crypto/tls configuration, nil when cfg is nil
+func (cfg *TLSConfig) build() (*tls.Config, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	c := &tls.Config{
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+		MinVersion:         cfg.MinVersion,
+	}
+	if c.MinVersion == 0 {
+		c.MinVersion = tls.VersionTLS12
+	}
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("tls: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("tls: no certificates found in %s", cfg.CAFile)
+		}
+		c.RootCAs = pool
+	}
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("tls: %w", err)
+		}
+		c.Certificates = []tls.Certificate{cert}
+	}
+	return c, nil
+}
+
+// dialTimeout dials address, over TLS when tlsConfig is set
+func dialTimeout(network, address string, timeout time.Duration, tlsConfig *tls.Config) (net.Conn, error) {
+	if tlsConfig == nil {
+		return net.DialTimeout(network, address, timeout)
+	}
+	return tls.DialWithDialer(&net.Dialer{Timeout: timeout}, network, address, tlsConfig)
+}
+
+// newHTTPClient returns the client of the HTTP sinks, using tlsConfig for https
+// requests when set
+func newHTTPClient(timeout time.Duration, tlsConfig *tls.Config) *http.Client {
+	client := &http.Client{Timeout: timeout}
+	if tlsConfig != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		client.Transport = transport
+	}
+	return client
+}
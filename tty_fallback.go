@@ -0,0 +1,89 @@
+package logger
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/mattn/go-isatty"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultTTYCheckInterval is how often TTYFallbackFormatter checks whether its
+// output is still a terminal
+const DefaultTTYCheckInterval = time.Second
+
+// isTerminal reports whether fd is a terminal, replaced in tests
+var isTerminal = func(fd uintptr) bool {
+	return isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd)
+}
+
+// TTYFallbackFormatter renders entries with Formatter, e.g. a ColorFormatter,
+// while Fd is a terminal, and with Fallback once it stops being one, e.g. after
+// the process was daemonized or its output redirected, so long-running
+// processes don't keep emitting ANSI codes. The check runs at most every
+// CheckInterval, on the next entry.
+type TTYFallbackFormatter struct {
+	Formatter logrus.Formatter
+	Fallback  logrus.Formatter // defaults to a PlainFormatter
+	Fd        uintptr
+	// CheckInterval defaults to DefaultTTYCheckInterval
+	CheckInterval time.Duration
+
+	next     atomic.Int64 // unix nanos of the next check
+	terminal atomic.Bool
+}
+
+func (f *TTYFallbackFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	if f.onTerminal() {
+		return f.Formatter.Format(entry)
+	}
+	if f.Fallback == nil {
+		return (&PlainFormatter{}).Format(entry)
+	}
+	return f.Fallback.Format(entry)
+}
+
+// onTerminal returns whether Fd was a terminal at the last check, checking
+// again when CheckInterval elapsed
+func (f *TTYFallbackFormatter) onTerminal() bool {
+	now := time.Now().UnixNano()
+	next := f.next.Load()
+	if now < next {
+		return f.terminal.Load()
+	}
+	interval := f.CheckInterval
+	if interval <= 0 {
+		interval = DefaultTTYCheckInterval
+	}
+	// a single caller checks, the others use the last result
+	if f.next.CompareAndSwap(next, now+int64(interval)) {
+		f.terminal.Store(isTerminal(f.Fd))
+	} else if next == 0 {
+		// the first check is still running
+		return isTerminal(f.Fd)
+	}
+	return f.terminal.Load()
+}
+
+// WithTTYFallback renders entries with the current formatter while the logger
+// output is a terminal and with fallback (a PlainFormatter when nil) once it
+// isn't, see TTYFallbackFormatter. Outputs that aren't files use fallback right
+// away. Apply it after WithFormatter and WithOutput.
+func WithTTYFallback(fallback logrus.Formatter) Option {
+	return func(l *Logger) error {
+		if fallback == nil {
+			fallback = &PlainFormatter{}
+		}
+		file, ok := l.Entry.Logger.Out.(interface{ Fd() uintptr })
+		if !ok {
+			setFormatter(l.Entry.Logger, fallback)
+			return nil
+		}
+		current := l.Entry.Logger.Formatter
+		if s, ok := current.(*suppressingFormatter); ok {
+			current = s.Formatter
+		}
+		setFormatter(l.Entry.Logger, &TTYFallbackFormatter{Formatter: current, Fallback: fallback, Fd: file.Fd()})
+		return nil
+	}
+}
@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"bytes"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTTYFallbackFormatter(t *testing.T) {
+	var terminal atomic.Bool
+	terminal.Store(true)
+	previous := isTerminal
+	isTerminal = func(uintptr) bool { return terminal.Load() }
+	t.Cleanup(func() { isTerminal = previous })
+
+	var buf bytes.Buffer
+	logger, err := NewLogger(WithOutput(&buf))
+	require.NoError(t, err)
+	logger.Entry.Logger.SetFormatter(&TTYFallbackFormatter{
+		Formatter:     &logrus.JSONFormatter{},
+		CheckInterval: 20 * time.Millisecond,
+	})
+
+	logger.Info("colored")
+	assert.Contains(t, buf.String(), `"msg":"colored"`)
+
+	terminal.Store(false)
+	assert.Eventually(t, func() bool {
+		buf.Reset()
+		logger.Info("redirected")
+		return buf.String() == "INFO redirected\n"
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestWithTTYFallback(t *testing.T) {
+	previous := isTerminal
+	isTerminal = func(fd uintptr) bool { return fd == os.Stderr.Fd() }
+	t.Cleanup(func() { isTerminal = previous })
+
+	logger, err := NewLogger(WithFormatter(&ColorFormatter{}), WithTTYFallback(nil))
+	require.NoError(t, err)
+	formatter, ok := logger.Entry.Logger.Formatter.(*TTYFallbackFormatter)
+	require.True(t, ok)
+	assert.IsType(t, &ColorFormatter{}, formatter.Formatter)
+	assert.Equal(t, os.Stderr.Fd(), formatter.Fd)
+
+	// not a file, never a terminal
+	var buf bytes.Buffer
+	logger, err = NewLogger(WithFormatter(&ColorFormatter{}), WithOutput(&buf), WithTTYFallback(&logrus.JSONFormatter{}))
+	require.NoError(t, err)
+	logger.Info("json")
+	assert.Contains(t, buf.String(), `"msg":"json"`)
+}
@@ -0,0 +1,52 @@
+package logger
+
+// UnitSuffix is appended to the key of a numeric field to name its unit
+// companion, e.g. latency_unit for latency
+const UnitSuffix = "_unit"
+
+// Units in UCUM notation, the notation used by OpenTelemetry metrics
+const (
+	UnitMilliseconds = "ms"
+	UnitSeconds      = "s"
+	UnitBytes        = "By"
+	UnitPercent      = "%"
+)
+
+// WithUnit returns the fields holding value under key and unit under key
+// followed by UnitSuffix, so aggregation downstream doesn't have to guess the
+// unit from the field name
+func WithUnit(key string, value interface{}, unit string) Fields {
+	return Fields{key: value, key + UnitSuffix: unit}
+}
+
+// Ms returns the fields holding a duration in milliseconds, see WithUnit
+func Ms(key string, ms float64) Fields {
+	return WithUnit(key, ms, UnitMilliseconds)
+}
+
+// Seconds returns the fields holding a duration in seconds, see WithUnit
+func Seconds(key string, s float64) Fields {
+	return WithUnit(key, s, UnitSeconds)
+}
+
+// BytesN returns the fields holding a size in bytes, see WithUnit
+func BytesN(key string, n int64) Fields {
+	return WithUnit(key, n, UnitBytes)
+}
+
+// Percent returns the fields holding a percentage, see WithUnit
+func Percent(key string, p float64) Fields {
+	return WithUnit(key, p, UnitPercent)
+}
+
+// WithMeasures returns a logger adding the fields of every measure, e.g.
+// l.WithMeasures(Ms("latency", 12.3), BytesN("size", n))
+func (l *Logger) WithMeasures(measures ...Fields) *Logger {
+	fields := make(Fields)
+	for _, m := range measures {
+		for k, v := range m {
+			fields[k] = v
+		}
+	}
+	return &Logger{Entry: l.Entry.WithFields(fields)}
+}
@@ -0,0 +1,32 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitFields(t *testing.T) {
+	assert.Equal(t, Fields{"latency": 12.3, "latency_unit": "ms"}, Ms("latency", 12.3))
+	assert.Equal(t, Fields{"size": int64(512), "size_unit": "By"}, BytesN("size", 512))
+	assert.Equal(t, Fields{"cpu": 75.0, "cpu_unit": "%"}, Percent("cpu", 75))
+	assert.Equal(t, Fields{"ttl": 1.5, "ttl_unit": "s"}, Seconds("ttl", 1.5))
+	assert.Equal(t, Fields{"items": 3, "items_unit": "{item}"}, WithUnit("items", 3, "{item}"))
+}
+
+func TestWithMeasures(t *testing.T) {
+	logger, err := NewLogger(WithNullOutput(), WithLastEntriesCapture(10))
+	require.NoError(t, err)
+
+	logger.WithMeasures(Ms("latency", 12.3), BytesN("size", 2048)).Info("request served")
+
+	crumbs := logger.Breadcrumbs(0)
+	require.Len(t, crumbs, 1)
+	assert.Equal(t, Fields{
+		"latency":      12.3,
+		"latency_unit": UnitMilliseconds,
+		"size":         int64(2048),
+		"size_unit":    UnitBytes,
+	}, crumbs[0].Data)
+}
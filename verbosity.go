@@ -0,0 +1,157 @@
+package logger
+
+import (
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+// verbositiesMu serializes the registration of verbosity thresholds
+var verbositiesMu sync.Mutex
+
+// verbosityThreshold holds the verbosity threshold of a logger, set with
+// SetVerbosity, and the logger returned by V above it. It registers like a hook
+// for the panic level so it can be found, but firing it does nothing.
+type verbosityThreshold struct {
+	v     atomic.Int32
+	muted *Logger
+}
+
+// useVerbosity returns the verbosity threshold registered on l, registering one
+// first when needed
+func useVerbosity(l *logrus.Logger) *verbosityThreshold {
+	if h, ok := findHook[*verbosityThreshold](l); ok {
+		return h
+	}
+	verbositiesMu.Lock()
+	defer verbositiesMu.Unlock()
+	if h, ok := findHook[*verbosityThreshold](l); ok {
+		return h
+	}
+	h := &verbosityThreshold{muted: &Logger{Entry: logrus.NewEntry(mutedLogger(l))}}
+	l.AddHook(h)
+	return h
+}
+
+func (h *verbosityThreshold) Levels() []logrus.Level {
+	return []logrus.Level{logrus.PanicLevel}
+}
+
+func (h *verbosityThreshold) Fire(*logrus.Entry) error {
+	return nil
+}
+
+// mutedOutput discards the entries of the loggers returned by V above the
+// threshold
+type mutedOutput struct{}
+
+func (mutedOutput) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+// mutedLogger returns a logger discarding its entries, except for fatal and
+// panic entries, which are logged by l, and exiting with the exit func of l
+func mutedLogger(l *logrus.Logger) *logrus.Logger {
+	muted := &logrus.Logger{
+		Out:       mutedOutput{},
+		Formatter: &logrus.TextFormatter{},
+		Hooks:     make(logrus.LevelHooks),
+		Level:     logrus.FatalLevel,
+		ExitFunc: func(code int) {
+			if l.ExitFunc == nil {
+				os.Exit(code)
+			}
+			l.ExitFunc(code)
+		},
+	}
+	muted.AddHook(&severeForwarder{logger: l})
+	return muted
+}
+
+// severeForwarder implements logrus.Hook logging the fatal and panic entries of
+// a muted logger with the logger it mutes
+type severeForwarder struct {
+	logger *logrus.Logger
+}
+
+func (h *severeForwarder) Levels() []logrus.Level {
+	return []logrus.Level{logrus.PanicLevel, logrus.FatalLevel}
+}
+
+// Fire logs the entry with the logger it was muted for, panicking for panic
+// entries once the sinks are flushed
+func (h *severeForwarder) Fire(entry *logrus.Entry) error {
+	e := h.logger.WithFields(entry.Data).WithTime(entry.Time).WithContext(entry.Context)
+	if entry.Level == logrus.PanicLevel {
+		defer flushOnPanic(h.logger)
+	}
+	e.Log(entry.Level, entry.Message)
+	return nil
+}
+
+// WithVerbosity sets the verbosity threshold of the logger, see Logger.V
+func WithVerbosity(v int) Option {
+	return func(l *Logger) error {
+		l.SetVerbosity(v)
+		return nil
+	}
+}
+
+// SetVerbosity sets the verbosity threshold of the logger, see V. It applies to
+// every logger derived from the same logger.
+func (l *Logger) SetVerbosity(v int) {
+	useVerbosity(l.Entry.Logger).v.Store(int32(v))
+}
+
+// V returns the logger when n is at most the verbosity threshold set with
+// SetVerbosity or WithVerbosity (0 by default), and a logger discarding its
+// entries otherwise, gating chatty paths at a finer granularity than levels,
+// like klog. Test Enabled to skip building expensive entries:
+//
+//	if l := logger.V(4); l.Enabled() {
+//		l.Debugf("cache state: %v", cache.Dump())
+//	}
+//
+// The entries of an enabled V logger are still subject to the logger level.
+// Fatal and panic entries are logged either way, and still exit and panic.
+func (l *Logger) V(n int) *Logger {
+	if !l.Enabled() {
+		return l
+	}
+	h, ok := findHook[*verbosityThreshold](l.Entry.Logger)
+	var threshold int
+	if ok {
+		threshold = int(h.v.Load())
+	}
+	if n <= threshold {
+		return l
+	}
+	if !ok {
+		h = useVerbosity(l.Entry.Logger)
+	}
+	if len(l.Entry.Data) == 0 && l.Entry.Context == nil {
+		return h.muted
+	}
+	// keep the fields for the fatal and panic entries
+	return &Logger{Entry: &logrus.Entry{Logger: h.muted.Entry.Logger, Data: l.Entry.Data, Context: l.Entry.Context}}
+}
+
+// Enabled reports whether the logger logs entries, false for the loggers
+// returned by V above the verbosity threshold
+func (l *Logger) Enabled() bool {
+	_, muted := l.Entry.Logger.Out.(mutedOutput)
+	return !muted
+}
+
+// SetVerbosity sets the verbosity threshold of the global logger
+func SetVerbosity(v int) {
+	globalLogger().SetVerbosity(v)
+}
+
+// V returns the global logger when n is at most its verbosity threshold, see
+// Logger.V
+func V(n int) *Logger {
+	return (&Logger{Entry: global()}).V(n)
+}
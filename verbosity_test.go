@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestV(t *testing.T) {
+	exitCode := -1
+	logger, err := NewLogger(WithNullOutput(), WithLevel("debug"), WithLastEntriesCapture(10), WithVerbosity(2),
+		WithExitFunc(func(code int) { exitCode = code }))
+	require.NoError(t, err)
+
+	assert.True(t, logger.V(0).Enabled())
+	assert.True(t, logger.V(2).Enabled())
+	assert.False(t, logger.V(3).Enabled())
+
+	logger.V(2).Debug("verbose")
+	logger.V(3).Info("too verbose")
+	(&Logger{Entry: logger.WithField("cache", "users")}).V(1).Info("derived")
+	(&Logger{Entry: logger.WithField("cache", "users")}).V(3).Fatal("exiting")
+	assert.Equal(t, 1, exitCode)
+
+	crumbs := logger.Breadcrumbs(0)
+	require.Len(t, crumbs, 3)
+	assert.Equal(t, "verbose", crumbs[0].Message)
+	assert.Equal(t, "derived", crumbs[1].Message)
+	assert.Equal(t, "exiting", crumbs[2].Message)
+	assert.Equal(t, "users", crumbs[2].Data["cache"])
+	assert.PanicsWithValue(t, "boom", func() {
+		defer func() {
+			if e, ok := recover().(*logrus.Entry); ok {
+				panic(e.Message)
+			}
+		}()
+		logger.V(3).Panic("boom")
+	})
+
+	logger.SetVerbosity(4)
+	assert.True(t, logger.V(3).Enabled())
+}
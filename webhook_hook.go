@@ -0,0 +1,235 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultWebhookRateLimit    = 10
+	DefaultWebhookRateInterval = time.Minute
+	DefaultWebhookTimeout      = 5 * time.Second
+
+	// DefaultSlackTemplate renders the Slack message text
+	DefaultSlackTemplate = "*[{{.Level}}]* {{.Message}}{{range $k, $v := .Data}} `{{$k}}={{$v}}`{{end}}" +
+		"{{if .Suppressed}} _(+{{.Suppressed}} suppressed)_{{end}}"
+)
+
+// WebhookConfig holds configuration for the webhook alert hook
+type WebhookConfig struct {
+	URL string
+	// Slack wraps the rendered template in a Slack incoming webhook payload
+	Slack bool
+	// Template renders the request body (or the Slack message text) from a
+	// WebhookMessage. Without a template, generic webhooks receive the message as
+	// JSON and Slack webhooks use DefaultSlackTemplate.
+	Template    string
+	ContentType string // defaults to application/json
+	Headers     map[string]string
+	Levels      []logrus.Level // defaults to error, fatal and panic
+	// RateLimit caps the messages posted per RateInterval so an error storm
+	// doesn't flood the channel; entries over the limit are counted and reported
+	// with the next message
+	RateLimit    int
+	RateInterval time.Duration
+	Timeout      time.Duration
+	// Retry retries posts failing with a network error, 429 or 5xx status;
+	// alerts are posted once when nil
+	Retry *RetryPolicy
+	TLS   *TLSConfig
+}
+
+// WebhookMessage is the data rendered by the webhook template
+type WebhookMessage struct {
+	Level      string    `json:"level"`
+	Message    string    `json:"message"`
+	Time       time.Time `json:"time"`
+	Data       Fields    `json:"fields,omitempty"`
+	Suppressed int       `json:"suppressed,omitempty"`
+}
+
+// webhookHook implements logrus.Hook posting alerts to a webhook
+type webhookHook struct {
+	cfg      WebhookConfig
+	tmpl     *template.Template
+	client   *http.Client
+	retry    RetryPolicy
+	batcher  *Batcher[WebhookMessage]
+	mu       sync.Mutex
+	window   time.Time
+	sent     int
+	rejected int
+}
+
+// NewWebhookHook creates a new hook posting entries to a Slack or generic webhook
+func NewWebhookHook(cfg *WebhookConfig) (*webhookHook, error) {
+	if cfg == nil || cfg.URL == "" {
+		return nil, fmt.Errorf("webhook: url is required")
+	}
+	c := *cfg
+	if c.ContentType == "" {
+		c.ContentType = "application/json"
+	}
+	if len(c.Levels) == 0 {
+		c.Levels = []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel}
+	}
+	if c.RateLimit <= 0 {
+		c.RateLimit = DefaultWebhookRateLimit
+	}
+	if c.RateInterval <= 0 {
+		c.RateInterval = DefaultWebhookRateInterval
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultWebhookTimeout
+	}
+	if c.Slack && c.Template == "" {
+		c.Template = DefaultSlackTemplate
+	}
+
+	tlsConfig, err := c.TLS.build()
+	if err != nil {
+		return nil, fmt.Errorf("webhook: %w", err)
+	}
+
+	hook := &webhookHook{
+		cfg:    c,
+		client: newHTTPClient(c.Timeout, tlsConfig),
+		retry:  retryPolicy(c.Retry, RetryPolicy{MaxAttempts: 1}),
+	}
+	if c.Template != "" {
+		tmpl, err := template.New("webhook").Parse(c.Template)
+		if err != nil {
+			return nil, fmt.Errorf("webhook: %w", err)
+		}
+		hook.tmpl = tmpl
+	}
+	hook.batcher = NewBatcher(1, time.Second, c.RateLimit*2, false, hook.post)
+	return hook, nil
+}
+
+// WithWebhook adds a hook posting alerts for error entries to a webhook
+func WithWebhook(cfg *WebhookConfig) Option {
+	return func(l *Logger) error {
+		hook, err := NewWebhookHook(cfg)
+		if err != nil {
+			return err
+		}
+		l.Entry.Logger.AddHook(hook)
+		return nil
+	}
+}
+
+// WithSlack adds a hook posting alerts for entries at the given levels (error
+// and above when empty) to a Slack incoming webhook
+func WithSlack(webhookURL string, levels ...logrus.Level) Option {
+	return WithWebhook(&WebhookConfig{URL: webhookURL, Slack: true, Levels: levels})
+}
+
+func (h *webhookHook) Levels() []logrus.Level {
+	return h.cfg.Levels
+}
+
+// Fire queues an alert for the entry unless the rate limit is exceeded
+func (h *webhookHook) Fire(entry *logrus.Entry) error {
+	h.mu.Lock()
+	now := time.Now()
+	if now.Sub(h.window) >= h.cfg.RateInterval {
+		h.window = now
+		h.sent = 0
+	}
+	if h.sent >= h.cfg.RateLimit {
+		h.rejected++
+		h.mu.Unlock()
+		return nil
+	}
+	h.sent++
+	suppressed := h.rejected
+	h.rejected = 0
+	h.mu.Unlock()
+
+	data := make(Fields, len(entry.Data))
+	for k, v := range entry.Data {
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+		data[k] = v
+	}
+	h.batcher.Add(WebhookMessage{
+		Level:      entry.Level.String(),
+		Message:    entry.Message,
+		Time:       entry.Time,
+		Data:       data,
+		Suppressed: suppressed,
+	})
+	return nil
+}
+
+// Flush posts the queued alerts without stopping the background worker
+func (h *webhookHook) Flush() error {
+	h.batcher.Sync()
+	return nil
+}
+
+// Close posts pending alerts
+func (h *webhookHook) Close() error {
+	h.batcher.Close()
+	return nil
+}
+
+// post renders and sends each message
+func (h *webhookHook) post(messages []WebhookMessage) {
+	for _, msg := range messages {
+		body, err := h.render(msg)
+		if err != nil {
+			continue
+		}
+		h.retry.Deliver("webhook", 1, body, h.batcher.Closing(), h.send)
+	}
+}
+
+// send performs a single post and reports whether it should be retried
+func (h *webhookHook) send(body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, h.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", h.cfg.ContentType)
+	for k, v := range h.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode/100 == 2 {
+		return false, nil
+	}
+	err = fmt.Errorf("webhook: post failed with status %d", resp.StatusCode)
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500, err
+}
+
+// render builds the request body for a message
+func (h *webhookHook) render(msg WebhookMessage) ([]byte, error) {
+	if h.tmpl == nil {
+		return json.Marshal(msg)
+	}
+	var b bytes.Buffer
+	if err := h.tmpl.Execute(&b, msg); err != nil {
+		return nil, err
+	}
+	if h.cfg.Slack {
+		return json.Marshal(map[string]string{"text": b.String()})
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,105 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type webhookRecorder struct {
+	mu     sync.Mutex
+	bodies []string
+	header http.Header
+}
+
+func (rec *webhookRecorder) handler(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.bodies = append(rec.bodies, string(body))
+	rec.header = r.Header
+}
+
+func TestWebhookHook_Slack(t *testing.T) {
+	rec := &webhookRecorder{}
+	server := httptest.NewServer(http.HandlerFunc(rec.handler))
+	defer server.Close()
+
+	hook, err := NewWebhookHook(&WebhookConfig{URL: server.URL, Slack: true})
+	require.NoError(t, err)
+
+	logger := logrus.New()
+	logger.AddHook(hook)
+	logger.SetOutput(io.Discard)
+
+	logger.Warn("not an alert")
+	logger.WithError(errors.New("timeout")).Error("payment failed")
+	require.NoError(t, hook.Close())
+
+	require.Len(t, rec.bodies, 1)
+	var payload map[string]string
+	require.NoError(t, json.Unmarshal([]byte(rec.bodies[0]), &payload))
+	assert.Equal(t, "*[error]* payment failed `error=timeout`", payload["text"])
+}
+
+func TestWebhookHook_GenericTemplate(t *testing.T) {
+	rec := &webhookRecorder{}
+	server := httptest.NewServer(http.HandlerFunc(rec.handler))
+	defer server.Close()
+
+	hook, err := NewWebhookHook(&WebhookConfig{
+		URL:         server.URL,
+		Template:    "{{.Level}}: {{.Message}} ({{.Data.service}})",
+		ContentType: "text/plain",
+		Headers:     map[string]string{"Authorization": "Bearer token"},
+		Levels:      []logrus.Level{logrus.WarnLevel},
+	})
+	require.NoError(t, err)
+
+	logger := logrus.New()
+	logger.AddHook(hook)
+	logger.SetOutput(io.Discard)
+
+	logger.WithField("service", "api").Warn("disk almost full")
+	require.NoError(t, hook.Close())
+
+	require.Len(t, rec.bodies, 1)
+	assert.Equal(t, "warning: disk almost full (api)", rec.bodies[0])
+	assert.Equal(t, "Bearer token", rec.header.Get("Authorization"))
+	assert.Equal(t, "text/plain", rec.header.Get("Content-Type"))
+}
+
+func TestWebhookHook_RateLimit(t *testing.T) {
+	rec := &webhookRecorder{}
+	server := httptest.NewServer(http.HandlerFunc(rec.handler))
+	defer server.Close()
+
+	hook, err := NewWebhookHook(&WebhookConfig{URL: server.URL, RateLimit: 2, RateInterval: 50 * time.Millisecond})
+	require.NoError(t, err)
+
+	logger := logrus.New()
+	logger.AddHook(hook)
+	logger.SetOutput(io.Discard)
+
+	for i := 0; i < 10; i++ {
+		logger.Error("storm")
+	}
+	time.Sleep(60 * time.Millisecond)
+	logger.Error("after storm")
+	require.NoError(t, hook.Close())
+
+	require.Len(t, rec.bodies, 3)
+	var last WebhookMessage
+	require.NoError(t, json.Unmarshal([]byte(rec.bodies[2]), &last))
+	assert.Equal(t, "after storm", last.Message)
+	assert.Equal(t, 8, last.Suppressed)
+}
@@ -0,0 +1,200 @@
+package logger
+
+import (
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+const (
+	DefaultWriteFallbackRateLimit    = 100
+	DefaultWriteFallbackRateInterval = time.Second
+)
+
+// WriteFallbackConfig holds configuration for mirroring the writes failing on a
+// file, e.g. because the disk is full or permissions changed, so entries don't
+// vanish
+type WriteFallbackConfig struct {
+	// Writer takes the failed writes, defaults to os.Stderr
+	Writer io.Writer
+	// RateLimit caps the writes mirrored per RateInterval, defaults to
+	// DefaultWriteFallbackRateLimit and DefaultWriteFallbackRateInterval.
+	// Writes over the limit are dropped.
+	RateLimit    int
+	RateInterval time.Duration
+	// OnFailure is called with the file name and the error of every failed
+	// write. It may be called concurrently.
+	OnFailure func(name string, err error)
+}
+
+// WithWriteFallback mirrors the writes failing on the logger output to the
+// fallback writer, typically stderr when the file set with WithFileOutput hits
+// ENOSPC. Failed writes are counted in Stats.Failures. Rotating file hooks
+// take their own fallback, see RotatingFileConfig.Fallback.
+func WithWriteFallback(cfg *WriteFallbackConfig) Option {
+	return func(l *Logger) error {
+		useWriteFallback(l.Entry.Logger).cfg = newWriteFallbackConfig(cfg)
+		return nil
+	}
+}
+
+func newWriteFallbackConfig(cfg *WriteFallbackConfig) WriteFallbackConfig {
+	c := WriteFallbackConfig{}
+	if cfg != nil {
+		c = *cfg
+	}
+	if c.Writer == nil {
+		c.Writer = os.Stderr
+	}
+	if c.RateLimit <= 0 {
+		c.RateLimit = DefaultWriteFallbackRateLimit
+	}
+	if c.RateInterval <= 0 {
+		c.RateInterval = DefaultWriteFallbackRateInterval
+	}
+	return c
+}
+
+// writeFallback holds the fallback configured for the logger output. It
+// registers like a hook so it can be found, but firing it does nothing: the
+// output is wrapped by startWriteFallback.
+type writeFallback struct {
+	cfg    WriteFallbackConfig
+	output *fallbackWriter // set once the output is wrapped
+}
+
+// useWriteFallback returns the fallback registered on l, registering one first
+// when needed
+func useWriteFallback(l *logrus.Logger) *writeFallback {
+	if h, ok := findHook[*writeFallback](l); ok {
+		return h
+	}
+	h := &writeFallback{}
+	l.AddHook(h)
+	return h
+}
+
+// startWriteFallback wraps the file written by the output of l when
+// WithWriteFallback was used. It runs once the options have been applied,
+// before startStats counts the bytes written to the output.
+func startWriteFallback(l *logrus.Logger) {
+	h, ok := findHook[*writeFallback](l)
+	if !ok {
+		return
+	}
+	out := l.Out
+	if b, ok := out.(*batchWriter); ok {
+		// the batches are mirrored when written
+		out = b.w
+	}
+	name, ok := fileName(out)
+	if !ok {
+		return
+	}
+	h.output = newFallbackWriter(out, name, h.cfg)
+	if b, ok := l.Out.(*batchWriter); ok {
+		b.w = h.output
+	} else {
+		l.SetOutput(h.output)
+	}
+}
+
+// fileName returns the name of the file w writes to, false when w isn't a
+// file or is a standard stream
+func fileName(w io.Writer) (string, bool) {
+	switch f := w.(type) {
+	case *reopenableFile:
+		return f.path, true
+	case *lumberjack.Logger:
+		return f.Filename, true
+	case *os.File:
+		return f.Name(), f != os.Stdout && f != os.Stderr
+	}
+	return "", false
+}
+
+func (h *writeFallback) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *writeFallback) Fire(*logrus.Entry) error {
+	return nil
+}
+
+// writeFailures returns the number of failed writes on the output
+func (h *writeFallback) writeFailures() (string, uint64) {
+	if h.output == nil {
+		return outputStatsKey, 0
+	}
+	return outputStatsKey, h.output.Failures()
+}
+
+// fallbackWriter mirrors the writes failing on w to the fallback writer,
+// forwarding Flush, Reopen and Close
+type fallbackWriter struct {
+	w    io.Writer
+	name string
+	cfg  WriteFallbackConfig
+
+	mu       sync.Mutex
+	window   time.Time
+	mirrored int // writes mirrored in the current window
+
+	failures atomic.Uint64
+}
+
+func newFallbackWriter(w io.Writer, name string, cfg WriteFallbackConfig) *fallbackWriter {
+	return &fallbackWriter{w: w, name: name, cfg: cfg}
+}
+
+// Write writes p to w, mirroring it when the write fails. The failure is
+// reported through OnFailure and the counter rather than returned, as the
+// entry was handled.
+func (f *fallbackWriter) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	if err == nil {
+		return n, nil
+	}
+	f.failures.Add(1)
+	if f.cfg.OnFailure != nil {
+		f.cfg.OnFailure(f.name, err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := time.Now()
+	if now.Sub(f.window) >= f.cfg.RateInterval {
+		f.window = now
+		f.mirrored = 0
+	}
+	if f.mirrored < f.cfg.RateLimit {
+		f.mirrored++
+		f.cfg.Writer.Write(p)
+	}
+	return len(p), nil
+}
+
+// Failures returns the number of failed writes
+func (f *fallbackWriter) Failures() uint64 {
+	return f.failures.Load()
+}
+
+func (f *fallbackWriter) Flush() error {
+	if flush := outputFlusher(f.w); flush != nil {
+		return flush()
+	}
+	return nil
+}
+
+func (f *fallbackWriter) Reopen() error {
+	return reopenWriter(f.w)
+}
+
+func (f *fallbackWriter) Close() error {
+	return closeOutput(f.w)
+}
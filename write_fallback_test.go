@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithWriteFallback(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	var fallback bytes.Buffer
+	var failed atomic.Int32
+	logger, err := NewLogger(
+		WithFileOutput(path),
+		WithFormatter(&PlainFormatter{}),
+		WithWriteFallback(&WriteFallbackConfig{
+			Writer:    &fallback,
+			RateLimit: 2,
+			OnFailure: func(name string, err error) {
+				assert.Equal(t, path, name)
+				failed.Add(1)
+			},
+		}),
+		WithStats(),
+	)
+	require.NoError(t, err)
+
+	logger.Info("written")
+	// the file is gone from under the logger, like on a failing disk
+	out := logger.Logger.Out.(*countingSink).w.(*fallbackWriter)
+	require.NoError(t, out.w.(*reopenableFile).f.Close())
+	logger.Info("one")
+	logger.Info("two")
+	logger.Info("three")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "INFO written\n", string(data))
+	// mirrored up to the rate limit
+	assert.Equal(t, "INFO one\nINFO two\n", fallback.String())
+	assert.Equal(t, int32(3), failed.Load())
+
+	s, _ := logger.Stats()
+	assert.Equal(t, map[string]uint64{outputStatsKey: 3}, s.Failures)
+}
+
+func TestWithWriteFallback_StandardStreams(t *testing.T) {
+	logger, err := NewLogger(WithWriteFallback(nil))
+	require.NoError(t, err)
+	// nothing to fall back from
+	assert.Equal(t, os.Stderr, logger.Logger.Out)
+}
+
+func TestRotatingFileHook_Fallback(t *testing.T) {
+	dir := t.TempDir()
+	// the directory of the log file can't be created
+	blocker := filepath.Join(dir, "blocker")
+	require.NoError(t, os.WriteFile(blocker, nil, 0o644))
+	filename := filepath.Join(blocker, "app.log")
+
+	var fallback bytes.Buffer
+	hook, err := newRotatingFileHook(&RotatingFileConfig{
+		Filename:  filename,
+		Formatter: &PlainFormatter{},
+		Fallback:  &WriteFallbackConfig{Writer: &fallback, RateInterval: time.Hour},
+	})
+	require.NoError(t, err)
+	defer hook.Close()
+
+	logger, err := NewLogger(WithNullOutput(), WithHooks(hook), WithStats())
+	require.NoError(t, err)
+	logger.Error("disk full")
+
+	assert.Equal(t, "ERROR disk full\n", fallback.String())
+	s, _ := logger.Stats()
+	assert.Equal(t, map[string]uint64{filename: 1}, s.Failures)
+}